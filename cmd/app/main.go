@@ -12,7 +12,11 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/darksuei/suei-intelligence/internal/application/authorization"
+	"github.com/darksuei/suei-intelligence/internal/application/datasource"
 	"github.com/darksuei/suei-intelligence/internal/application/metadata"
+	"github.com/darksuei/suei-intelligence/internal/application/secret"
+	"github.com/darksuei/suei-intelligence/internal/application/setup"
+	"github.com/darksuei/suei-intelligence/internal/application/signingkey"
 	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server"
@@ -28,6 +32,11 @@ func main() {
 	// Load config
 	config.Initialize()
 
+	// Load datasource connectors
+	if err := datasource.LoadConnectors(config.Connector()); err != nil {
+		log.Fatalf("Failed to load connectors: %v", err)
+	}
+
 	// Initialize database
 	database.Initialize(config.Database())
 
@@ -37,8 +46,26 @@ func main() {
 	// Load bootstrap token
 	metadata.LoadBootstrapToken(config.Common().BootstrapToken, config.Database())
 
+	// Lock setup on instances that are already set up
+	if err := setup.ReconcileSetup(config.Database()); err != nil {
+		log.Fatalf("Failed to reconcile setup state: %v", err)
+	}
+
 	// Initialize authorization module
-	authorization.Initialize(config.Casbin())
+	authorization.Initialize(config.Casbin(), config.Database())
+
+	// Initialize secrets encryption
+	secret.Initialize(config.Secrets(), config.Database(), func() error {
+		return datasource.ReencryptConfigurations(config.Database())
+	}, func() error {
+		return signingkey.ReencryptKeys(config.Database())
+	})
+
+	// Initialize access token signing keys, their private keys are encrypted
+	signingkey.Initialize(config.JWT(), config.Database())
+
+	// Purge datasources deleted past the retention period
+	datasource.InitializePurge(config.Datasource(), config.Database())
 
 	// Initialize router
 	router := server.InitializeRouter()
@@ -2,19 +2,34 @@ package account
 
 import (
 	"errors"
+	"strconv"
+	"time"
 
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	mfaService "github.com/darksuei/suei-intelligence/internal/application/mfa"
 	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/authentication"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/domain/invitation"
 	"github.com/darksuei/suei-intelligence/internal/domain/mfa"
+	"github.com/darksuei/suei-intelligence/internal/domain/project"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
 )
 
-func NewAccount(name string, email string, password string, role account.AccountRole, internalRoleJson map[string]string, cfg *config.DatabaseConfig) (*account.Account, error) {
+func NewAccount(name string, email string, password string, role account.AccountRole, internalRoleJson map[string]string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
 	_accountRepository := database.NewAccountRepository(cfg)
 
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if email already exists - Fail fast
 	_account, err := _accountRepository.FindOneByEmail(email)
-	
+
 	if err != nil || _account != nil {
 		return nil, errors.New("Email already registered.")
 	}
@@ -33,30 +48,94 @@ func NewAccount(name string, email string, password string, role account.Account
 		return nil, err
 	}
 
+	return createAccount(name, email, passwordEnc, role, internalRoleJson, _organization.ID, cfg)
+}
+
+// NewAccountWithoutPassword creates an account that signs in through an identity provider.
+// It has no password until one is set through a password reset
+func NewAccountWithoutPassword(name string, email string, role account.AccountRole, internalRoleJson map[string]string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if email already exists - Fail fast
+	_account, err := _accountRepository.FindOneByEmail(email)
+
+	if err != nil || _account != nil {
+		return nil, errors.New("Email already registered.")
+	}
+
+	return createAccount(name, email, "", role, internalRoleJson, _organization.ID, cfg)
+}
+
+// BuildAccount prepares an account with a password for an organization that is yet to be
+// stored. The organization repository stores both together
+func BuildAccount(name string, email string, password string, role account.AccountRole, internalRoleJson map[string]string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	// Check if email already exists - Fail fast
+	_account, err := database.NewAccountRepository(cfg).FindOneByEmail(email)
+
+	if err != nil || _account != nil {
+		return nil, errors.New("Email already registered.")
+	}
+
+	// Check password against password requirements
+	if err := account.CheckPassword(password); err != nil {
+		return nil, err
+	}
+
+	// Encrypt password
+	passwordEnc, err := account.EncryptPassword(password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAccount(name, email, passwordEnc, role, internalRoleJson, 0)
+}
+
+func createAccount(name string, email string, passwordEnc string, role account.AccountRole, internalRoleJson map[string]string, organizationId uint, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_account, err := buildAccount(name, email, passwordEnc, role, internalRoleJson, organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return database.NewAccountRepository(cfg).Create(_account)
+}
+
+func buildAccount(name string, email string, passwordEnc string, role account.AccountRole, internalRoleJson map[string]string, organizationId uint) (*account.Account, error) {
 	mfaSecret, err := mfa.GenerateMFASecret()
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Create account
-	_account = &account.Account{
+	return &account.Account{
 		Name: name,
 		Email: email,
 		Role: role,
 		InternalRoles: internalRoleJson,
+		OrganizationID: organizationId,
 		PasswordEnc: passwordEnc,
 		MFAEnabled: false,
 		MFASecret: mfaSecret,
-	}
-
-	return _accountRepository.Create(_account)
+	}, nil
 }
 
-func RetrieveAccounts(cfg *config.DatabaseConfig) (*[]account.Account, error) {
+func RetrieveAccounts(organizationKey string, cfg *config.DatabaseConfig) (*[]account.Account, error) {
 	_accountRepository := database.NewAccountRepository(cfg)
 
-	return _accountRepository.Find()
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _accountRepository.Find(_organization.ID)
 }
 
 func RetrieveAccount(email string, cfg *config.DatabaseConfig) (*account.Account, error) {
@@ -65,6 +144,64 @@ func RetrieveAccount(email string, cfg *config.DatabaseConfig) (*account.Account
 	return _accountRepository.FindOneByEmail(email)
 }
 
+func RetrieveAccountByID(id uint, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	return _accountRepository.FindOneByID(id)
+}
+
+// RevokeAccessTokens invalidates every access token issued to the account so far
+func RevokeAccessTokens(id uint, cfg *config.DatabaseConfig) error {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	if err := _accountRepository.IncrementTokenVersion(id); err != nil {
+		return err
+	}
+
+	return cache.GetCache().Delete(authentication.BuildTokenVersionKey(id))
+}
+
+// RetrieveTokenVersion returns the account's current token version,
+// cached for the lifetime of an access token
+func RetrieveTokenVersion(id uint, cfg *config.DatabaseConfig) (uint, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	key := authentication.BuildTokenVersionKey(id)
+
+	if value, err := cache.GetCache().Get(key); err == nil {
+		if version, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return uint(version), nil
+		}
+	}
+
+	_account, err := _accountRepository.FindOneByID(id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _account == nil {
+		return 0, errors.New("Invalid account.")
+	}
+
+	_ = cache.GetCache().Set(key, strconv.FormatUint(uint64(_account.TokenVersion), 10), authentication.AccessTokenTTL)
+
+	return _account.TokenVersion, nil
+}
+
+// RetrieveOrganizationAccount retrieves an account by email, only if it belongs to the organization
+func RetrieveOrganizationAccount(email string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _accountRepository.FindOneByEmailInOrganization(email, _organization.ID)
+}
+
 func RetrieveAccountWithPassword(email string, password string, cfg *config.DatabaseConfig) (*account.Account, error) {
 	_accountRepository := database.NewAccountRepository(cfg)
 
@@ -105,28 +242,761 @@ func EnableTOTP(email string, cfg *config.DatabaseConfig) error {
 	return _accountRepository.Update(_account)
 }
 
-func UpdateAccount(oldEmail string, name *string, email *string, cfg *config.DatabaseConfig) (*account.Account, error) {
+// GenerateRecoveryCodes replaces an account's recovery codes with a fresh set.
+// The plain codes are only ever returned here
+func GenerateRecoveryCodes(id uint, cfg *config.DatabaseConfig) ([]string, error) {
+	codes, hashes, err := mfa.GenerateRecoveryCodes()
+
+	if err != nil {
+		return nil, errors.New("Failed to generate recovery codes.")
+	}
+
+	if err := database.NewAccountRepository(cfg).UpdateRecoveryCodes(id, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces an account's recovery codes after verifying its
+// password and TOTP code
+func RegenerateRecoveryCodes(email string, organizationKey string, password string, code uint32, cfg *config.DatabaseConfig) ([]string, error) {
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if !_account.MFAEnabled {
+		return nil, errors.New("MFA is not enabled.")
+	}
+
+	if err := account.VerifyPassword(_account.PasswordEnc, password); err != nil {
+		return nil, errors.New("Invalid password.")
+	}
+
+	if !mfaService.VerifyTOTP(_account.MFASecret, code, time.Now()) {
+		return nil, errors.New("Invalid TOTP code.")
+	}
+
+	return GenerateRecoveryCodes(_account.ID, cfg)
+}
+
+// UseRecoveryCode consumes one of an account's recovery codes and returns how many remain
+func UseRecoveryCode(email string, code string, cfg *config.DatabaseConfig) (int, error) {
 	_accountRepository := database.NewAccountRepository(cfg)
 
-	// Check if email doesnt exist - Fail fast
-	_account, err := _accountRepository.FindOneByEmail(oldEmail)
-	
+	_account, err := _accountRepository.FindOneByEmail(email)
+
 	if err != nil || _account == nil {
-		return nil, errors.New("Inavlid account.")
+		return 0, errors.New("Invalid account.")
 	}
 
-	if name != nil {
-		_account.Name = *name
+	remaining, err := _accountRepository.RemoveRecoveryCode(_account.ID, mfa.HashRecoveryCode(code))
+
+	if errors.Is(err, account.ErrInvalidRecovery) {
+		return 0, errors.New("Invalid recovery code.")
 	}
 
-	if email != nil {
-		_account.Email = *email
+	if err != nil {
+		return 0, err
+	}
+
+	return remaining, nil
+}
+
+// RetrieveRecoveryCodeCount returns how many unused recovery codes an account has left
+func RetrieveRecoveryCodeCount(email string, organizationKey string, cfg *config.DatabaseConfig) (int, error) {
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return 0, errors.New("Invalid account.")
+	}
+
+	return len(_account.MFARecoveryCodes), nil
+}
+
+// StartMFAEnrollment generates a new MFA secret awaiting confirmation. The current secret
+// stays in use until the new one is confirmed with ConfirmMFAEnrollment
+func StartMFAEnrollment(email string, organizationKey string, password string, code *uint32, cfg *config.DatabaseConfig) (string, error) {
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return "", errors.New("Invalid account.")
+	}
+
+	if err := account.VerifyPassword(_account.PasswordEnc, password); err != nil {
+		return "", errors.New("Invalid password.")
+	}
+
+	if _account.MFAEnabled {
+		if code == nil {
+			return "", errors.New("TOTP code is required.")
+		}
+
+		if !mfaService.VerifyTOTP(_account.MFASecret, *code, time.Now()) {
+			return "", errors.New("Invalid TOTP code.")
+		}
+	}
+
+	secret, err := mfa.GenerateMFASecret()
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := cache.GetCache().Set(mfa.BuildEnrollmentKey(_account.ID), secret, mfa.EnrollmentTTL); err != nil {
+		return "", err
+	}
+
+	return mfaService.RetrieveTotpURI(_account.Email, secret)
+}
+
+// ConfirmMFAEnrollment switches an account to its pending MFA secret once a code from it is
+// verified, and replaces its recovery codes
+func ConfirmMFAEnrollment(email string, organizationKey string, code uint32, cfg *config.DatabaseConfig) ([]string, error) {
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	key := mfa.BuildEnrollmentKey(_account.ID)
+
+	secret, err := cache.GetCache().Get(key)
+
+	if err != nil {
+		return nil, errors.New("No pending MFA enrollment. Please restart enrollment.")
+	}
+
+	if !mfaService.VerifyTOTP(secret, code, time.Now()) {
+		return nil, errors.New("Invalid TOTP code.")
+	}
+
+	codes, hashes, err := mfa.GenerateRecoveryCodes()
+
+	if err != nil {
+		return nil, errors.New("Failed to generate recovery codes.")
+	}
+
+	if err := database.NewAccountRepository(cfg).UpdateMFA(_account.ID, true, secret, hashes); err != nil {
+		return nil, err
+	}
+
+	_ = cache.GetCache().Delete(key)
+
+	return codes, nil
+}
+
+// DisableMFA turns MFA off after verifying the account's password and TOTP code.
+// The secret is rotated so the old authenticator entry can never be used again
+func DisableMFA(email string, organizationKey string, password string, code uint32, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if !_account.MFAEnabled {
+		return nil, errors.New("MFA is not enabled.")
+	}
+
+	if err := account.VerifyPassword(_account.PasswordEnc, password); err != nil {
+		return nil, errors.New("Invalid password.")
+	}
+
+	if !mfaService.VerifyTOTP(_account.MFASecret, code, time.Now()) {
+		return nil, errors.New("Invalid TOTP code.")
+	}
+
+	if err := clearMFA(_account, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// ResetMFA turns MFA off for an account without verification, removing its security keys too,
+// so it has to enroll again, and signs it out everywhere. Callers must have authorized the reset
+func ResetMFA(email string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if err := clearMFA(_account, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := database.NewCredentialRepository(cfg).DeleteAll(_account.ID); err != nil {
+		return nil, err
+	}
+
+	if err := database.NewSessionRepository(cfg).RevokeAll(_account.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// clearMFA disables MFA with a fresh secret, dropping recovery codes and any pending enrollment
+func clearMFA(_account *account.Account, cfg *config.DatabaseConfig) error {
+	secret, err := mfa.GenerateMFASecret()
+
+	if err != nil {
+		return err
+	}
+
+	if err := database.NewAccountRepository(cfg).UpdateMFA(_account.ID, false, secret, []string{}); err != nil {
+		return err
+	}
+
+	_ = cache.GetCache().Delete(mfa.BuildEnrollmentKey(_account.ID))
+
+	_account.MFAEnabled = false
+	_account.MFASecret = secret
+	_account.MFARecoveryCodes = []string{}
+
+	return nil
+}
+
+// ChangePassword replaces an account's password after verifying the current one,
+// and the TOTP code when MFA is enabled
+func ChangePassword(email string, organizationKey string, currentPassword string, newPassword string, code *uint32, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if err := account.VerifyPassword(_account.PasswordEnc, currentPassword); err != nil {
+		return nil, account.ErrWrongPassword
+	}
+
+	if _account.MFAEnabled {
+		if code == nil {
+			return nil, errors.New("TOTP code is required.")
+		}
+
+		if !mfaService.VerifyTOTP(_account.MFASecret, *code, time.Now()) {
+			return nil, account.ErrInvalidTOTP
+		}
+	}
+
+	if err := setPassword(_account, newPassword); err != nil {
+		return nil, err
 	}
 
-	// Save updated account
 	if err := _accountRepository.Update(_account); err != nil {
 		return nil, err
 	}
 
+	// Sign the account out everywhere
+	if err := database.NewSessionRepository(cfg).RevokeAll(_account.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
 	return _account, nil
-}
\ No newline at end of file
+}
+
+// ResetPassword replaces an account's password without verifying the current one.
+// Callers must have verified a password reset token
+func ResetPassword(email string, newPassword string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := _accountRepository.FindOneByEmail(email)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if err := setPassword(_account, newPassword); err != nil {
+		return nil, err
+	}
+
+	if err := _accountRepository.Update(_account); err != nil {
+		return nil, err
+	}
+
+	// Sign the account out everywhere
+	if err := database.NewSessionRepository(cfg).RevokeAll(_account.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// setPassword encrypts the new password and records when it was changed
+func setPassword(_account *account.Account, password string) error {
+	if err := account.CheckPassword(password); err != nil {
+		return err
+	}
+
+	passwordEnc, err := account.EncryptPassword(password)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_account.PasswordEnc = passwordEnc
+	_account.PasswordChangedAt = &now
+
+	return nil
+}
+
+func UpdateAccount(oldEmail string, organizationKey string, name *string, email *string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	// Check if email doesnt exist - Fail fast
+	_account, err := RetrieveOrganizationAccount(oldEmail, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Inavlid account.")
+	}
+
+	if name != nil {
+		_account.Name = *name
+	}
+
+	if email != nil && *email != _account.Email {
+		_existing, err := _accountRepository.FindOneByEmail(*email)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _existing != nil {
+			return nil, account.ErrEmailInUse
+		}
+
+		_account.Email = *email
+	}
+
+	// Save updated account
+	if err := _accountRepository.Update(_account); err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before the change still carry the old email and roles
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// UpdateAccountRole replaces an account's organization role
+func UpdateAccountRole(email string, organizationKey string, role account.AccountRole, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if _account.InternalRoles == nil {
+		_account.InternalRoles = map[string]string{}
+	}
+
+	if role != account.SuperAdmin {
+		if err := ensureNotLastSuperAdmin(_account, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	_account.Role = role
+
+	entryKey := account.BuildRoleEntryKey(organizationKey, authorization.AuthorizationDomainOrg)
+	_account.InternalRoles[entryKey] = account.BuildRoleKey(organizationKey, authorization.AuthorizationDomainOrg, string(role))
+
+	if err := _accountRepository.Update(_account); err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before the change still carry the old roles
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// SetAccountDisabled disables or enables an account. A disabled account is signed out everywhere
+// and cannot sign in until it is enabled again
+func SetAccountDisabled(email string, organizationKey string, disabled bool, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if _account.IsDisabled() == disabled {
+		return _account, nil
+	}
+
+	if disabled {
+		if err := ensureNotLastSuperAdmin(_account, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var disabledAt *time.Time
+
+	if disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+
+	if err := _accountRepository.UpdateDisabledAt(_account.ID, disabledAt); err != nil {
+		return nil, err
+	}
+
+	_account.DisabledAt = disabledAt
+
+	if !disabled {
+		return _account, nil
+	}
+
+	if err := database.NewSessionRepository(cfg).RevokeAll(_account.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// TransferOwnership makes another account of the organization the creator of everything an
+// account created: projects, datasources, invitations and API keys
+func TransferOwnership(fromEmail string, toEmail string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_account, err := RetrieveOrganizationAccount(fromEmail, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	_target, err := retrieveTransferTarget(_account, toEmail, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := reassignCreatedBy(_account, account.BuildCreatedBy(_target), cfg); err != nil {
+		return nil, err
+	}
+
+	return _target, nil
+}
+
+// DeleteAccount deletes an account and erases its personal data. What it created is transferred
+// to another account of the organization when transferToEmail is set, and attributed to a
+// deleted account otherwise
+func DeleteAccount(email string, organizationKey string, transferToEmail string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if err := ensureNotLastSuperAdmin(_account, cfg); err != nil {
+		return nil, err
+	}
+
+	createdBy := account.BuildCreatedBy(nil)
+
+	if transferToEmail != "" {
+		_target, err := retrieveTransferTarget(_account, transferToEmail, organizationKey, cfg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		createdBy = account.BuildCreatedBy(_target)
+	}
+
+	if err := reassignCreatedBy(_account, createdBy, cfg); err != nil {
+		return nil, err
+	}
+
+	// Sign the account out everywhere before its sessions are removed
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := database.NewSessionRepository(cfg).DeleteAll(_account.ID); err != nil {
+		return nil, err
+	}
+
+	if err := database.NewCredentialRepository(cfg).DeleteAll(_account.ID); err != nil {
+		return nil, err
+	}
+
+	if err := removeFromDirectoryGroups(_account, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := _accountRepository.Erase(_account.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+func retrieveTransferTarget(_account *account.Account, toEmail string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_target, err := RetrieveOrganizationAccount(toEmail, organizationKey, cfg)
+
+	if err != nil || _target == nil {
+		return nil, errors.New("Invalid account to transfer to.")
+	}
+
+	if _target.ID == _account.ID {
+		return nil, errors.New("Cannot transfer to the same account.")
+	}
+
+	if _target.IsDisabled() {
+		return nil, errors.New("Cannot transfer to a disabled account.")
+	}
+
+	return _target, nil
+}
+
+// reassignCreatedBy replaces the creator snapshot of every record an account created,
+// including deleted datasources
+func reassignCreatedBy(_account *account.Account, createdBy map[string]string, cfg *config.DatabaseConfig) error {
+	_projectRepository := database.NewProjectRepository(cfg)
+	_datasourceRepository := database.NewDatasourceRepository(cfg)
+	_invitationRepository := database.NewInvitationRepository(cfg)
+
+	createdByAccount := func(snapshot map[string]string) bool {
+		return snapshot != nil && snapshot["Email"] == _account.Email
+	}
+
+	_projects, err := _projectRepository.Find(_account.OrganizationID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, _project := range *_projects {
+		if createdByAccount(_project.CreatedBy) {
+			if err := _projectRepository.Update(&project.Project{Model: _project.Model, CreatedBy: createdBy}); err != nil {
+				return err
+			}
+		}
+
+		_datasources, err := _datasourceRepository.FindWithDeleted(_project.ID)
+
+		if err != nil {
+			return err
+		}
+
+		for _, _datasource := range *_datasources {
+			if createdByAccount(_datasource.CreatedBy) {
+				if err := _datasourceRepository.UpdateCreatedBy(_datasource.ID, createdBy); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	_invitations, err := _invitationRepository.Find(_account.OrganizationID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, _invitation := range *_invitations {
+		if createdByAccount(_invitation.InvitedBy) {
+			if err := _invitationRepository.Update(&invitation.Invitation{Model: _invitation.Model, InvitedBy: createdBy}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return database.NewAPIKeyRepository(cfg).ReassignCreatedBy(_account.OrganizationID, _account.Email, createdBy["Email"])
+}
+
+func removeFromDirectoryGroups(_account *account.Account, cfg *config.DatabaseConfig) error {
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	_groups, err := _groupRepository.Find(_account.OrganizationID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, _group := range *_groups {
+		members := []uint{}
+
+		for _, member := range _group.Members {
+			if member != _account.ID {
+				members = append(members, member)
+			}
+		}
+
+		if len(members) == len(_group.Members) {
+			continue
+		}
+
+		_group.Members = members
+
+		if err := _groupRepository.Update(&_group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureNotLastSuperAdmin prevents disabling, demoting or deleting the last enabled superadmin
+// of an organization, which would leave nobody able to administer it
+func ensureNotLastSuperAdmin(_account *account.Account, cfg *config.DatabaseConfig) error {
+	if _account.Role != account.SuperAdmin || _account.IsDisabled() {
+		return nil
+	}
+
+	_accounts, err := database.NewAccountRepository(cfg).Find(_account.OrganizationID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, other := range *_accounts {
+		if other.ID != _account.ID && other.Role == account.SuperAdmin && !other.IsDisabled() {
+			return nil
+		}
+	}
+
+	return account.ErrLastSuperAdmin
+}
+
+// UpdateExternalID replaces the identifier an account has in the organization's directory
+func UpdateExternalID(email string, organizationKey string, externalId string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if _account.ExternalID == externalId {
+		return _account, nil
+	}
+
+	if err := _accountRepository.UpdateExternalID(_account.ID, externalId); err != nil {
+		return nil, err
+	}
+
+	_account.ExternalID = externalId
+
+	return _account, nil
+}
+
+func GrantProjectRole(email string, projectKey string, organizationKey string, role project.ProjectRole, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if _account.InternalRoles == nil {
+		_account.InternalRoles = map[string]string{}
+	}
+
+	entryKey := account.BuildRoleEntryKey(projectKey, authorization.AuthorizationDomainProject)
+	_account.InternalRoles[entryKey] = account.BuildRoleKey(projectKey, authorization.AuthorizationDomainProject, string(role))
+
+	if err := _accountRepository.Update(_account); err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before the change still carry the old email and roles
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+func RevokeProjectRole(email string, projectKey string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	entryKey := account.BuildRoleEntryKey(projectKey, authorization.AuthorizationDomainProject)
+
+	revoked := false
+	for key := range _account.InternalRoles {
+		if account.HasRoleEntryFor(key, entryKey) {
+			delete(_account.InternalRoles, key)
+			revoked = true
+		}
+	}
+
+	if !revoked {
+		return nil, errors.New("Account has no role on this project.")
+	}
+
+	if err := _accountRepository.Update(_account); err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before the change still carry the old email and roles
+	if err := RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+func RetrieveProjectMembers(projectKey string, organizationKey string, cfg *config.DatabaseConfig) (*[]account.Account, error) {
+	_accounts, err := RetrieveAccounts(organizationKey, cfg)
+
+	if err != nil || _accounts == nil {
+		return _accounts, err
+	}
+
+	entryKey := account.BuildRoleEntryKey(projectKey, authorization.AuthorizationDomainProject)
+
+	members := []account.Account{}
+	for _, _account := range *_accounts {
+		for key := range _account.InternalRoles {
+			if account.HasRoleEntryFor(key, entryKey) {
+				members = append(members, _account)
+				break
+			}
+		}
+	}
+
+	return &members, nil
+}
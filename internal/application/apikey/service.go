@@ -0,0 +1,172 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	projectService "github.com/darksuei/suei-intelligence/internal/application/project"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/apikey"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+func RetrieveKeys(projectKey string, organizationKey string, cfg *config.DatabaseConfig) (*[]apikey.APIKey, error) {
+	_apiKeyRepository := database.NewAPIKeyRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _apiKeyRepository.Find(_organization.ID, projectKey)
+}
+
+// NewKey creates an API key for a project, returning the key itself, which is not stored.
+// A key can only be granted permissions its creator holds on the project
+func NewKey(name string, permissions []authorization.Permission, expiresIn time.Duration, projectKey string, organizationKey string, creatorEmail string, creatorRoles []string, cfg *config.DatabaseConfig) (*apikey.APIKey, string, error) {
+	_apiKeyRepository := database.NewAPIKeyRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	_project, err := projectService.RetrieveProject(projectKey, organizationKey, cfg)
+
+	if err != nil || _project == nil {
+		return nil, "", errors.New("Project not found.")
+	}
+
+	if err := apikey.ValidatePermissions(permissions); err != nil {
+		return nil, "", err
+	}
+
+	for _, permission := range permissions {
+		allow, err := authorizationService.EnforceProjectRoles(creatorRoles, projectKey, permission.Object, permission.Action)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		if !allow {
+			return nil, "", fmt.Errorf("Cannot grant a permission you do not hold: %s %s.", permission.Action, permission.Object)
+		}
+	}
+
+	if expiresIn <= 0 {
+		expiresIn = apikey.DefaultTTL
+	}
+
+	if expiresIn > apikey.MaxTTL {
+		return nil, "", errors.New("API keys expire within 365 days at most.")
+	}
+
+	key, prefix, hash, err := apikey.GenerateKey()
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	_key, err := _apiKeyRepository.Create(&apikey.APIKey{
+		OrganizationID: _organization.ID,
+		ProjectKey: _project.Key,
+		Name: name,
+		Prefix: prefix,
+		KeyHash: hash,
+		Permissions: permissions,
+		CreatedBy: creatorEmail,
+		ExpiresAt: time.Now().Add(expiresIn),
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	subject := apikey.BuildRoleSubject(_key.ID)
+
+	if err := authorizationService.SetRolePolicies(subject, authorization.BuildRolePolicies(subject, authorization.AuthorizationDomainProject, permissions)); err != nil {
+		return nil, "", err
+	}
+
+	return _key, key, nil
+}
+
+// RevokeKey revokes a key of the project, it can never be used again
+func RevokeKey(id uint, projectKey string, organizationKey string, cfg *config.DatabaseConfig) (*apikey.APIKey, error) {
+	_apiKeyRepository := database.NewAPIKeyRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_key, err := _apiKeyRepository.FindOne(id, _organization.ID)
+
+	if err != nil || _key == nil || _key.ProjectKey != projectKey {
+		return nil, errors.New("Invalid API key.")
+	}
+
+	if _key.RevokedAt != nil {
+		return nil, errors.New("API key is already revoked.")
+	}
+
+	now := time.Now()
+
+	if err := _apiKeyRepository.Revoke(_key.ID, now); err != nil {
+		return nil, err
+	}
+
+	if err := authorizationService.SetRolePolicies(apikey.BuildRoleSubject(_key.ID), nil); err != nil {
+		return nil, err
+	}
+
+	_key.RevokedAt = &now
+
+	return _key, nil
+}
+
+// AuthenticateKey resolves an API key and the organization it belongs to
+func AuthenticateKey(key string, cfg *config.DatabaseConfig) (*apikey.APIKey, string, error) {
+	_apiKeyRepository := database.NewAPIKeyRepository(cfg)
+
+	prefix, err := apikey.ParsePrefix(key)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	_key, err := _apiKeyRepository.FindOneByPrefix(prefix)
+
+	if err != nil || _key == nil || !apikey.VerifyKey(key, _key.KeyHash) {
+		return nil, "", apikey.ErrInvalidKey
+	}
+
+	now := time.Now()
+
+	if err := _key.Check(now); err != nil {
+		return nil, "", err
+	}
+
+	_organization, err := organizationService.RetrieveOrganizationByID(_key.OrganizationID, cfg)
+
+	if err != nil || _organization == nil {
+		return nil, "", apikey.ErrInvalidKey
+	}
+
+	// Keys used by integrations are used constantly, only record their use once in a while
+	if _key.LastUsedAt == nil || now.Sub(*_key.LastUsedAt) >= apikey.LastUsedInterval {
+		if err := _apiKeyRepository.RecordUse(_key.ID, now); err != nil {
+			log.Printf("Failed to record api key use: %v", err)
+		}
+	}
+
+	return _key, _organization.Key, nil
+}
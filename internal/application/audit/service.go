@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"log"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/audit"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+// Record appends an event to the audit log.
+// Failing to record is logged but never fails the audited action
+func Record(event audit.AuditEvent, cfg *config.DatabaseConfig) {
+	_auditRepository := database.NewAuditRepository(cfg)
+
+	if event.Outcome == "" {
+		event.Outcome = audit.Success
+	}
+
+	if _, err := _auditRepository.Create(&event); err != nil {
+		log.Printf("Error recording audit event %s: %v", event.Action, err)
+	}
+}
+
+func RetrieveEvents(filter audit.AuditFilter, cfg *config.DatabaseConfig) (*[]audit.AuditEvent, error) {
+	_auditRepository := database.NewAuditRepository(cfg)
+
+	return _auditRepository.Find(filter)
+}
@@ -3,58 +3,242 @@ package authentication
 import (
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/darksuei/suei-intelligence/internal/application/account"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	sessionService "github.com/darksuei/suei-intelligence/internal/application/session"
+	signingkeyService "github.com/darksuei/suei-intelligence/internal/application/signingkey"
+	webauthnService "github.com/darksuei/suei-intelligence/internal/application/webauthn"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
 	"github.com/darksuei/suei-intelligence/internal/domain/authentication"
+	"github.com/darksuei/suei-intelligence/internal/domain/mfa"
+	"github.com/darksuei/suei-intelligence/internal/domain/session"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/notifier"
 )
 
-func Login(email string, password string, commonCfg *config.CommonConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
+func Login(email string, password string, client session.Client, commonCfg *config.CommonConfig, jwtCfg *config.JWTConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
 	_account, err := account.RetrieveAccountWithPassword(email, password, databaseCfg)
 
 	if err != nil || _account == nil {
 		return nil, errors.New("Invalid email or password")
 	}
 
-	internalRoles := make([]string, 0, len(_account.InternalRoles))
+	return startSession(_account, client, session.AuthMethodPassword, commonCfg, jwtCfg, databaseCfg)
+}
 
-	for _, v := range _account.InternalRoles {
-		internalRoles = append(internalRoles, v)
+func LoginWithoutPassword(email string, client session.Client, commonCfg *config.CommonConfig, jwtCfg *config.JWTConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
+	_account, err := account.RetrieveAccount(email, databaseCfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid email or password")
 	}
 
-	accessToken, err := authentication.GenerateJWT(authentication.JWTParams{
-		Subject:   _account.ID,
-		Email:     _account.Email,
-		Roles:	   internalRoles,
-		TTL:       time.Hour,
-		SecretKey: []byte(commonCfg.JWTSecret),
-	})
-	
+	return startSession(_account, client, session.AuthMethodPassword, commonCfg, jwtCfg, databaseCfg)
+}
+
+// LoginWithSSO signs in an account whose identity an SSO provider verified
+func LoginWithSSO(_account *accountDomain.Account, client session.Client, commonCfg *config.CommonConfig, jwtCfg *config.JWTConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
+	return startSession(_account, client, session.AuthMethodSSO, commonCfg, jwtCfg, databaseCfg)
+}
+
+// Refresh rotates a refresh token within its session. Replaying a token that was already
+// rotated out revokes the whole session, since either the client or an attacker holds a stolen copy
+func Refresh(rawRefresh string, client session.Client, commonCfg *config.CommonConfig, jwtCfg *config.JWTConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
+	_sessionRepository := database.NewSessionRepository(databaseCfg)
+
+	now := time.Now()
+
+	// 1. Get refresh token and its session
+	_token, err := _sessionRepository.FindOneToken(authentication.HashRefreshToken(rawRefresh))
+
+	if err != nil || _token == nil {
+		return nil, errors.New("Invalid refresh token")
+	}
+
+	_session, err := _sessionRepository.FindOne(_token.SessionID)
+
+	if err != nil || _session == nil || !_session.IsActive(now) {
+		return nil, errors.New("Invalid refresh token")
+	}
+
+	// 2. Rotate out the presented token, detecting reuse
+	rotated, err := _sessionRepository.RotateToken(_token.ID, now)
+
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, refreshTokenHash, err := authentication.GenerateRefreshToken()
+	if !rotated {
+		if err := _sessionRepository.Revoke(_session.ID, now); err != nil {
+			log.Printf("Failed to revoke session: %v", err)
+		}
+		if err := sessionService.RevokeAccessTokens(_session.ID); err != nil {
+			log.Printf("Failed to revoke session access tokens: %v", err)
+		}
+		return nil, session.ErrTokenReused
+	}
 
-	err = cache.GetCache().Set(fmt.Sprintf("refresh-token-%s", refreshTokenHash), email, 7*24*time.Hour)
+	// 3. Get account
+	_account, err := account.RetrieveAccountByID(_session.AccountID, databaseCfg)
 
-	if err != nil {
-		return nil, errors.New("Failed to rotate refresh token")
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account")
 	}
 
-	return &authentication.LoginDTO{
-		AccessToken: accessToken,
-		RefreshToken: refreshToken,
-	}, nil
+	// 4. Issue new tokens within the same session
+	_session.IP = client.IP
+	_session.UserAgent = client.UserAgent
+	_session.LastUsedAt = now
+	_session.ExpiresAt = now.Add(session.TTL)
+
+	if err := _sessionRepository.Update(_session); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(_account, _session, commonCfg, jwtCfg, databaseCfg)
 }
 
-func LoginWithoutPassword(email string, commonCfg *config.CommonConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
-	_account, err := account.RetrieveAccount(email, databaseCfg)
+// RevokeRefreshToken revokes the session a refresh token belongs to, returning the owner's email
+func RevokeRefreshToken(rawRefresh string, databaseCfg *config.DatabaseConfig) (string, error) {
+	_sessionRepository := database.NewSessionRepository(databaseCfg)
+
+	_token, err := _sessionRepository.FindOneToken(authentication.HashRefreshToken(rawRefresh))
+
+	if err != nil || _token == nil {
+		return "", errors.New("Invalid refresh token")
+	}
+
+	if err := _sessionRepository.Revoke(_token.SessionID, time.Now()); err != nil {
+		return "", err
+	}
+
+	if err := sessionService.RevokeAccessTokens(_token.SessionID); err != nil {
+		return "", err
+	}
+
+	return RetrieveRefreshTokenOwner(rawRefresh, databaseCfg), nil
+}
+
+// RetrieveRefreshTokenOwner returns the email a refresh token was issued to, empty when unknown
+func RetrieveRefreshTokenOwner(rawRefresh string, databaseCfg *config.DatabaseConfig) string {
+	_sessionRepository := database.NewSessionRepository(databaseCfg)
+
+	_token, err := _sessionRepository.FindOneToken(authentication.HashRefreshToken(rawRefresh))
+
+	if err != nil || _token == nil {
+		return ""
+	}
+
+	_session, err := _sessionRepository.FindOne(_token.SessionID)
+
+	if err != nil || _session == nil {
+		return ""
+	}
+
+	_account, err := account.RetrieveAccountByID(_session.AccountID, databaseCfg)
 
 	if err != nil || _account == nil {
-		return nil, errors.New("Invalid email or password")
+		return ""
+	}
+
+	return _account.Email
+}
+
+// ValidateAccessToken rejects an access token revoked after it was issued: on its own,
+// together with its session, or by a newer token version of its account
+func ValidateAccessToken(claims authentication.AccessTokenClaims, databaseCfg *config.DatabaseConfig) error {
+	if claims.ID != "" {
+		if _, err := cache.GetCache().Get(authentication.BuildRevokedAccessTokenKey(claims.ID)); err == nil {
+			return errors.New("Token has been revoked")
+		}
+	}
+
+	if claims.Session != 0 {
+		if _, err := cache.GetCache().Get(authentication.BuildRevokedSessionKey(claims.Session)); err == nil {
+			return errors.New("Session has been revoked")
+		}
+	}
+
+	version, err := account.RetrieveTokenVersion(claims.Subject, databaseCfg)
+
+	if err != nil {
+		return errors.New("Invalid account")
+	}
+
+	if claims.Version != version {
+		return errors.New("Token has been revoked")
+	}
+
+	return nil
+}
+
+// Logout revokes an access token until it expires, together with its session
+func Logout(claims authentication.AccessTokenClaims, databaseCfg *config.DatabaseConfig) error {
+	if claims.ID != "" {
+		ttl := time.Until(claims.ExpiresAt)
+
+		if ttl > 0 {
+			if err := cache.GetCache().Set(authentication.BuildRevokedAccessTokenKey(claims.ID), "1", ttl); err != nil {
+				return err
+			}
+		}
+	}
+
+	if claims.Session == 0 {
+		return nil
+	}
+
+	if err := database.NewSessionRepository(databaseCfg).Revoke(claims.Session, time.Now()); err != nil {
+		return err
+	}
+
+	return sessionService.RevokeAccessTokens(claims.Session)
+}
+
+// IsMFAEnrolled reports whether the account has a second factor: a confirmed TOTP secret
+// or a WebAuthn credential
+func IsMFAEnrolled(_account *accountDomain.Account, databaseCfg *config.DatabaseConfig) bool {
+	return _account.MFAEnabled || webauthnService.HasCredentials(_account.ID, databaseCfg)
+}
+
+func startSession(_account *accountDomain.Account, client session.Client, method session.AuthMethod, commonCfg *config.CommonConfig, jwtCfg *config.JWTConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
+	_sessionRepository := database.NewSessionRepository(databaseCfg)
+
+	now := time.Now()
+
+	_session, err := _sessionRepository.Create(&session.Session{
+		AccountID: _account.ID,
+		IP: client.IP,
+		UserAgent: client.UserAgent,
+		AuthMethod: method,
+		LastUsedAt: now,
+		ExpiresAt: now.Add(session.TTL),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return issueTokens(_account, _session, commonCfg, jwtCfg, databaseCfg)
+}
+
+// issueTokens issues an access token and the next refresh token of a session
+func issueTokens(_account *accountDomain.Account, _session *session.Session, commonCfg *config.CommonConfig, jwtCfg *config.JWTConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
+	_sessionRepository := database.NewSessionRepository(databaseCfg)
+
+	if _account.IsDisabled() {
+		return nil, accountDomain.ErrAccountDisabled
+	}
+
+	// Accounts that have not enrolled in MFA get no tokens while MFA is enforced,
+	// unless they signed in through their identity provider
+	if commonCfg.EnforceMfa && _session.AuthMethod != session.AuthMethodSSO && !IsMFAEnrolled(_account, databaseCfg) {
+		return nil, mfa.ErrEnrollmentRequired
 	}
 
 	internalRoles := make([]string, 0, len(_account.InternalRoles))
@@ -63,80 +247,127 @@ func LoginWithoutPassword(email string, commonCfg *config.CommonConfig, database
 		internalRoles = append(internalRoles, v)
 	}
 
+	_organization, err := organizationService.RetrieveOrganizationByID(_account.OrganizationID, databaseCfg)
+
+	if err != nil || _organization == nil {
+		return nil, errors.New("Invalid organization")
+	}
+
+	_signingKey, privateKey, err := signingkeyService.SigningKey()
+
+	if err != nil {
+		return nil, err
+	}
+
 	accessToken, err := authentication.GenerateJWT(authentication.JWTParams{
 		Subject:   _account.ID,
 		Email:     _account.Email,
+		Organization: _organization.Key,
+		Session:   _session.ID,
+		Version:   _account.TokenVersion,
 		Roles:	   internalRoles,
-		TTL:       time.Hour,
-		SecretKey: []byte(commonCfg.JWTSecret),
+		Issuer:    jwtCfg.JWTIssuer,
+		Audience:  jwtCfg.JWTAudience,
+		TTL:       authentication.AccessTokenTTL,
+		KeyID:     _signingKey.KeyID,
+		Method:    _signingKey.SigningMethod(),
+		SigningKey: privateKey,
 	})
-	
+
 	if err != nil {
 		return nil, err
 	}
 
 	refreshToken, refreshTokenHash, err := authentication.GenerateRefreshToken()
 
-	err = cache.GetCache().Set(fmt.Sprintf("refresh-token-%s", refreshTokenHash), email, 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = _sessionRepository.CreateToken(&session.RefreshToken{
+		SessionID: _session.ID,
+		TokenHash: refreshTokenHash,
+	})
 
 	if err != nil {
 		return nil, errors.New("Failed to rotate refresh token")
 	}
-	
+
 	return &authentication.LoginDTO{
 		AccessToken: accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func Refresh(rawRefresh string, commonCfg *config.CommonConfig, databaseCfg *config.DatabaseConfig) (*authentication.LoginDTO, error) {
-	// 1. Get refresh token from repository
-	oldRefreshTokenKey := fmt.Sprintf("refresh-token-%s", authentication.HashRefreshToken(rawRefresh))
-	email, err := cache.GetCache().Get(oldRefreshTokenKey)
+// RequestPasswordReset delivers a single-use password reset token through the notifier.
+// Unknown emails are ignored so callers cannot probe for accounts
+func RequestPasswordReset(email string, databaseCfg *config.DatabaseConfig, notifierCfg *config.NotifierConfig) error {
+	_account, err := account.RetrieveAccount(email, databaseCfg)
 
 	if err != nil {
-		return nil, errors.New("Invalid refresh token")
+		return err
 	}
 
-	// 2. Get account
-	_account, err := account.RetrieveAccount(email, databaseCfg)
+	if _account == nil {
+		return nil
+	}
+
+	token, tokenHash, err := authentication.GenerateRefreshToken()
 
 	if err != nil {
-		return nil, errors.New("Invalid account")
+		return err
 	}
 
-	internalRoles := make([]string, 0, len(_account.InternalRoles))
+	// Only the latest reset token of an account is valid
+	accountKey := fmt.Sprintf("password-reset-account-%s", _account.Email)
 
-	for _, v := range _account.InternalRoles {
-		internalRoles = append(internalRoles, v)
+	if previousHash, err := cache.GetCache().Get(accountKey); err == nil {
+		_ = cache.GetCache().Delete(fmt.Sprintf("password-reset-%s", previousHash))
 	}
-	
-	// 3. Issue new access token
-	accessToken, _ := authentication.GenerateJWT(authentication.JWTParams{
-		Subject:   _account.ID,
-		Email:     _account.Email,
-		Roles:	   internalRoles,
-		TTL:       time.Hour,
-		SecretKey: []byte(commonCfg.JWTSecret),
-	})
 
-	// 4. Rotate refresh token
-	newRaw, newHash, _ := authentication.GenerateRefreshToken()
+	if err := cache.GetCache().Set(fmt.Sprintf("password-reset-%s", tokenHash), _account.Email, authentication.PasswordResetTTL); err != nil {
+		return errors.New("Failed to store password reset token")
+	}
 
-	err = cache.GetCache().Delete(oldRefreshTokenKey)
+	if err := cache.GetCache().Set(accountKey, tokenHash, authentication.PasswordResetTTL); err != nil {
+		return errors.New("Failed to store password reset token")
+	}
 
-	if err != nil {
-		return nil, errors.New("Failed to revoke refresh token")
+	message := authentication.BuildPasswordResetMessage(_account.Email, token, notifierCfg.PasswordResetURL, time.Now().Add(authentication.PasswordResetTTL))
+
+	if err := notifier.GetNotifier().Send(message); err != nil {
+		log.Printf("Error delivering password reset: %v", err)
+
+		_ = cache.GetCache().Delete(fmt.Sprintf("password-reset-%s", tokenHash))
+
+		return errors.New("Failed to deliver password reset.")
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a password reset token and sets the new password,
+// which revokes every refresh token issued to the account
+func ResetPassword(token string, password string, databaseCfg *config.DatabaseConfig) (*accountDomain.Account, error) {
+	resetKey := fmt.Sprintf("password-reset-%s", authentication.HashRefreshToken(token))
+
+	if _, err := cache.GetCache().Get(resetKey); err != nil {
+		return nil, errors.New("Invalid or expired password reset token")
 	}
-	
-	err = cache.GetCache().Set(fmt.Sprintf("refresh-token-%s", newHash), email, 7*24*time.Hour)
+
+	// Validate before consuming the token so a weak password can be retried
+	if err := accountDomain.CheckPassword(password); err != nil {
+		return nil, err
+	}
+
+	// Taking the token consumes it atomically, so concurrent requests cannot both redeem it
+	email, err := cache.GetCache().Take(resetKey)
 
 	if err != nil {
-		return nil, errors.New("Failed to rotate refresh token")
+		return nil, errors.New("Invalid or expired password reset token")
 	}
 
-	return &authentication.LoginDTO{
-		AccessToken:  accessToken,
-		RefreshToken: newRaw,
-	}, nil
-}
\ No newline at end of file
+	_ = cache.GetCache().Delete(fmt.Sprintf("password-reset-account-%s", email))
+
+	return account.ResetPassword(email, password, databaseCfg)
+}
@@ -3,27 +3,105 @@ package authorization
 import (
 	"log"
 	"strings"
+	"time"
 
 	"github.com/casbin/casbin/v3"
 
 	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
 )
 
-var enforcer *casbin.Enforcer
+var (
+	enforcer    *casbin.SyncedEnforcer
+	databaseCfg *config.DatabaseConfig
+)
 
-func Initialize(casbinCfg *config.CasbinConfig) {
+func Initialize(casbinCfg *config.CasbinConfig, dbCfg *config.DatabaseConfig) {
 	var err error
-	enforcer, err = casbin.NewEnforcer("./data/model.conf", "./data/policy.csv")
+	databaseCfg = dbCfg
+
+	enforcer, err = casbin.NewSyncedEnforcer(casbinCfg.ModelConfPath, database.NewPolicyAdapter(dbCfg))
 	if err != nil {
 		log.Fatalf("failed to load Casbin enforcer: %v", err)
 	}
+
+	if err := seedPolicies(casbinCfg); err != nil {
+		log.Fatalf("failed to seed Casbin policies: %v", err)
+	}
+
+	if casbinCfg.PolicyReloadInterval > 0 {
+		go watchPolicies(casbinCfg.PolicyReloadInterval)
+	} else {
+		log.Print("Reloading of authorization policies is disabled")
+	}
+
 	log.Print("Successfully initialized authorizer")
+}
+
+// seedPolicies copies the CSV policies into the database on first start
+func seedPolicies(casbinCfg *config.CasbinConfig) error {
+	count, err := database.NewPolicyRepository(databaseCfg).Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	log.Printf("Seeding authorization policies from %s..", casbinCfg.PolicyCsvPath)
+
+	seed, err := casbin.NewEnforcer(casbinCfg.ModelConfPath, casbinCfg.PolicyCsvPath)
+	if err != nil {
+		return err
+	}
+
+	if err := database.NewPolicyAdapter(databaseCfg).SavePolicy(seed.GetModel()); err != nil {
+		return err
+	}
+
+	return enforcer.LoadPolicy()
+}
+
+// watchPolicies reloads the policies whenever another instance changed them
+func watchPolicies(interval time.Duration) {
+	loadedVersion := currentPolicyVersion()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		version := currentPolicyVersion()
+		if version == loadedVersion {
+			continue
+		}
+
+		if err := enforcer.LoadPolicy(); err != nil {
+			log.Printf("Error reloading authorization policies: %v", err)
+			continue
+		}
+
+		loadedVersion = version
+		log.Printf("Reloaded authorization policies (version %d)", version)
+	}
+}
+
+func currentPolicyVersion() uint {
+	_metadata, err := database.NewMetadataRepository(databaseCfg).FindOne()
+
+	if err != nil || _metadata == nil {
+		return 0
+	}
 
-	// Optional: load policy from file
-	// if err := enforcer.LoadPolicy(); err != nil {
-	// 	log.Fatalf("failed to load Casbin policy: %v", err)
-	// }
+	return _metadata.PolicyVersion
+}
+
+// notifyPolicyChange signals other instances to reload their policies
+func notifyPolicyChange() {
+	if err := database.NewMetadataRepository(databaseCfg).IncrementPolicyVersion(); err != nil {
+		log.Printf("Error notifying policy change: %v", err)
+	}
 }
 
 // EnforceRoles checks if **any** of the given roles can perform the action on object/domain
@@ -34,8 +112,132 @@ func Initialize(casbinCfg *config.CasbinConfig) {
 // Returns true if any role allows, false otherwise
 func EnforceRoles(roles []string, domain authorization.AuthorizationDomain, object authorization.AuthorizationObject, action string) (bool, error) {
 	for _, role := range roles {
-		return enforcer.Enforce(strings.SplitN(role, "__", 2)[0], string(domain), string(object), action)
+		subject, _ := splitRoleKey(role)
+
+		allow, err := enforcer.Enforce(subject, string(domain), string(object), action)
+		if err != nil {
+			return false, err
+		}
+		if allow {
+			return true, nil
+		}
+	}
+	// No role was successful
+	return false, nil
+}
+
+// EnforceProjectRoles checks if **any** of the given roles can perform the action on object
+// within the project identified by projectKey.
+// Project roles (e.g. "project_editor__project-9") only apply to the project they were granted on,
+// org roles apply to every project through their g2 inheritance (e.g. org_superadmin -> project_owner)
+func EnforceProjectRoles(roles []string, projectKey string, object authorization.AuthorizationObject, action string) (bool, error) {
+	for _, role := range roles {
+		subject, entityKey := splitRoleKey(role)
+
+		if strings.HasPrefix(subject, string(authorization.AuthorizationDomainProject)+"_") && entityKey != projectKey {
+			continue
+		}
+
+		allow, err := enforcer.Enforce(subject, string(authorization.AuthorizationDomainProject), string(object), action)
+		if err != nil {
+			return false, err
+		}
+		if allow {
+			return true, nil
+		}
 	}
 	// No role was successful
 	return false, nil
-}
\ No newline at end of file
+}
+
+// splitRoleKey splits "ROLE__ENTITYKEY" into its role and entity key
+func splitRoleKey(role string) (string, string) {
+	parts := strings.SplitN(role, "__", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func RetrievePolicies() ([]authorization.PolicyRule, error) {
+	policies, err := enforcer.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]authorization.PolicyRule, 0, len(policies))
+	for _, p := range policies {
+		if len(p) < 4 {
+			continue
+		}
+		rules = append(rules, authorization.PolicyRule{Role: p[0], Domain: p[1], Object: p[2], Action: p[3]})
+	}
+
+	return rules, nil
+}
+
+func AddPolicy(rule authorization.PolicyRule) (bool, error) {
+	added, err := enforcer.AddPolicy(rule.Role, rule.Domain, rule.Object, rule.Action)
+	if err == nil && added {
+		notifyPolicyChange()
+	}
+	return added, err
+}
+
+func RemovePolicy(rule authorization.PolicyRule) (bool, error) {
+	removed, err := enforcer.RemovePolicy(rule.Role, rule.Domain, rule.Object, rule.Action)
+	if err == nil && removed {
+		notifyPolicyChange()
+	}
+	return removed, err
+}
+
+func RetrieveRoleInheritance() ([]authorization.RoleInheritanceRule, error) {
+	policies, err := enforcer.GetNamedGroupingPolicy("g2")
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]authorization.RoleInheritanceRule, 0, len(policies))
+	for _, p := range policies {
+		if len(p) < 2 {
+			continue
+		}
+		rules = append(rules, authorization.RoleInheritanceRule{Role: p[0], InheritedRole: p[1]})
+	}
+
+	return rules, nil
+}
+
+func AddRoleInheritance(rule authorization.RoleInheritanceRule) (bool, error) {
+	added, err := enforcer.AddNamedGroupingPolicy("g2", rule.Role, rule.InheritedRole)
+	if err == nil && added {
+		notifyPolicyChange()
+	}
+	return added, err
+}
+
+func RemoveRoleInheritance(rule authorization.RoleInheritanceRule) (bool, error) {
+	removed, err := enforcer.RemoveNamedGroupingPolicy("g2", rule.Role, rule.InheritedRole)
+	if err == nil && removed {
+		notifyPolicyChange()
+	}
+	return removed, err
+}
+
+// SetRolePolicies replaces every policy held by subject with the given policies
+func SetRolePolicies(subject string, policies [][]string) error {
+	if _, err := enforcer.RemoveFilteredPolicy(0, subject); err != nil {
+		return err
+	}
+
+	if len(policies) > 0 {
+		if _, err := enforcer.AddPolicies(policies); err != nil {
+			return err
+		}
+	}
+
+	notifyPolicyChange()
+
+	return nil
+}
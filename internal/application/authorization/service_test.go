@@ -0,0 +1,215 @@
+package authorization
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v3"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	databaseDomain "github.com/darksuei/suei-intelligence/internal/domain/database"
+	"github.com/darksuei/suei-intelligence/internal/domain/metadata"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+var testCasbinConfig = &config.CasbinConfig{
+	ModelConfPath: "../../../data/model.conf",
+	PolicyCsvPath: "../../../data/policy.csv",
+	PolicyReloadInterval: time.Hour,
+}
+
+// useEnforcer loads the shipped model and policies for the duration of a test
+func useEnforcer(t *testing.T) {
+	t.Helper()
+
+	_enforcer, err := casbin.NewSyncedEnforcer(testCasbinConfig.ModelConfPath, testCasbinConfig.PolicyCsvPath)
+	if err != nil {
+		t.Fatalf("failed to load Casbin enforcer: %v", err)
+	}
+
+	previous := enforcer
+	enforcer = _enforcer
+
+	t.Cleanup(func() { enforcer = previous })
+}
+
+// useDatabase initializes the authorizer against a new sqlite database
+func useDatabase(t *testing.T) *config.DatabaseConfig {
+	t.Helper()
+
+	cfg := &config.DatabaseConfig{
+		DatabaseType: databaseDomain.DatabaseTypeSqlite,
+		DatabasePath: filepath.Join(t.TempDir(), "test.db"),
+	}
+
+	database.Initialize(cfg)
+	database.Migrate(cfg)
+
+	if _, err := database.NewMetadataRepository(cfg).Create(&metadata.Metadata{BootstrapToken: "bootstrap", Language: "en"}); err != nil {
+		t.Fatalf("failed to create metadata: %v", err)
+	}
+
+	previous := enforcer
+	t.Cleanup(func() { enforcer = previous })
+
+	Initialize(testCasbinConfig, cfg)
+
+	return cfg
+}
+
+func TestEnforceRoles(t *testing.T) {
+	useEnforcer(t)
+
+	tests := []struct {
+		name   string
+		roles  []string
+		action string
+		want   bool
+	}{
+		{name: "a role allowed the action", roles: []string{"org_admin__org-1"}, action: "write", want: true},
+		{name: "a role not allowed the action", roles: []string{"org_guest__org-1"}, action: "write"},
+		{name: "any of several roles", roles: []string{"org_guest__org-1", "org_superadmin__org-1"}, action: "admin", want: true},
+		{name: "no roles", action: "read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EnforceRoles(tt.roles, authorization.AuthorizationDomainOrg, authorization.Organization, tt.action)
+			if err != nil || got != tt.want {
+				t.Fatalf("EnforceRoles() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnforceProjectRoles(t *testing.T) {
+	useEnforcer(t)
+
+	tests := []struct {
+		name   string
+		roles  []string
+		action string
+		want   bool
+	}{
+		{name: "a project role of the project", roles: []string{"project_editor__alpha"}, action: "write", want: true},
+		{name: "a project role of another project", roles: []string{"project_editor__beta"}, action: "read"},
+		{name: "a project role without project", roles: []string{"project_owner"}, action: "read"},
+		{name: "a project role not allowed the action", roles: []string{"project_viewer__alpha"}, action: "write"},
+		{name: "a project role among roles of other projects", roles: []string{"project_owner__beta", "project_viewer__alpha"}, action: "read", want: true},
+		{name: "an org role inheriting a project role", roles: []string{"org_superadmin__org-1"}, action: "admin", want: true},
+		{name: "an org role inheriting a read-only project role", roles: []string{"org_guest__org-1"}, action: "write"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EnforceProjectRoles(tt.roles, "alpha", authorization.Project, tt.action)
+			if err != nil || got != tt.want {
+				t.Fatalf("EnforceProjectRoles() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatabasePolicies(t *testing.T) {
+	cfg := useDatabase(t)
+
+	seed, err := casbin.NewEnforcer(testCasbinConfig.ModelConfPath, testCasbinConfig.PolicyCsvPath)
+	if err != nil {
+		t.Fatalf("failed to load Casbin enforcer: %v", err)
+	}
+
+	seeded, _ := seed.GetPolicy()
+	seededInheritance, _ := seed.GetNamedGroupingPolicy("g2")
+
+	// reload reads the stored policies the way another instance would
+	reload := func(t *testing.T) *casbin.Enforcer {
+		t.Helper()
+
+		_enforcer, err := casbin.NewEnforcer(testCasbinConfig.ModelConfPath, database.NewPolicyAdapter(cfg))
+		if err != nil {
+			t.Fatalf("failed to load stored policies: %v", err)
+		}
+		return _enforcer
+	}
+
+	rule := authorization.PolicyRule{Role: "org_guest", Domain: "org", Object: "organization", Action: "write"}
+
+	t.Run("seeds the CSV policies on first start", func(t *testing.T) {
+		if count, err := database.NewPolicyRepository(cfg).Count(); err != nil || count != int64(len(seeded)+len(seededInheritance)) {
+			t.Fatalf("Count() = %d, %v, want %d", count, err, len(seeded)+len(seededInheritance))
+		}
+
+		if policies, _ := RetrievePolicies(); len(policies) != len(seeded) {
+			t.Fatalf("RetrievePolicies() = %d policies, want %d", len(policies), len(seeded))
+		}
+
+		if allow, _ := EnforceProjectRoles([]string{"org_guest__org-1"}, "alpha", authorization.Project, "read"); !allow {
+			t.Fatal("seeded role inheritance was not applied")
+		}
+	})
+
+	t.Run("stores added policies", func(t *testing.T) {
+		if added, err := AddPolicy(rule); err != nil || !added {
+			t.Fatalf("AddPolicy() = %v, %v", added, err)
+		}
+
+		if allow, _ := reload(t).Enforce("org_guest", "org", "organization", "write"); !allow {
+			t.Fatal("added policy was not stored")
+		}
+
+		if version := currentPolicyVersion(); version != 1 {
+			t.Fatalf("policy version = %d, want 1", version)
+		}
+	})
+
+	t.Run("does not seed again", func(t *testing.T) {
+		Initialize(testCasbinConfig, cfg)
+
+		if allow, _ := EnforceRoles([]string{"org_guest__org-1"}, authorization.AuthorizationDomainOrg, authorization.Organization, "write"); !allow {
+			t.Fatal("stored policies were replaced with the CSV policies")
+		}
+	})
+
+	t.Run("starts without a reload interval", func(t *testing.T) {
+		Initialize(&config.CasbinConfig{
+			ModelConfPath: testCasbinConfig.ModelConfPath,
+			PolicyCsvPath: testCasbinConfig.PolicyCsvPath,
+		}, cfg)
+
+		if allow, _ := EnforceRoles([]string{"org_guest__org-1"}, authorization.AuthorizationDomainOrg, authorization.Organization, "write"); !allow {
+			t.Fatal("stored policies were not loaded")
+		}
+	})
+
+	t.Run("removes stored policies", func(t *testing.T) {
+		if removed, err := RemovePolicy(rule); err != nil || !removed {
+			t.Fatalf("RemovePolicy() = %v, %v", removed, err)
+		}
+
+		if allow, _ := reload(t).Enforce("org_guest", "org", "organization", "write"); allow {
+			t.Fatal("removed policy is still stored")
+		}
+	})
+
+	t.Run("stores role inheritance", func(t *testing.T) {
+		inheritance := authorization.RoleInheritanceRule{Role: "org_guest", InheritedRole: "project_editor"}
+
+		if added, err := AddRoleInheritance(inheritance); err != nil || !added {
+			t.Fatalf("AddRoleInheritance() = %v, %v", added, err)
+		}
+
+		if allow, _ := reload(t).Enforce("org_guest", "project", "project", "write"); !allow {
+			t.Fatal("added role inheritance was not stored")
+		}
+
+		if removed, err := RemoveRoleInheritance(inheritance); err != nil || !removed {
+			t.Fatalf("RemoveRoleInheritance() = %v, %v", removed, err)
+		}
+
+		if allow, _ := reload(t).Enforce("org_guest", "project", "project", "write"); allow {
+			t.Fatal("removed role inheritance is still stored")
+		}
+	})
+}
@@ -1,32 +1,103 @@
 package datasource
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/darksuei/suei-intelligence/internal/application/account"
+	"github.com/darksuei/suei-intelligence/internal/application/audit"
 	"github.com/darksuei/suei-intelligence/internal/application/project"
+	"github.com/darksuei/suei-intelligence/internal/application/secret"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/apikey"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
 	"github.com/darksuei/suei-intelligence/internal/domain/datasource"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/etl"
 )
 
-func NewDatasource(key string, sourceType string, sourceId string, createdByEmail string, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
-	_datasourceRepository := database.NewDatasourceRepository(cfg)
-
-	createdByAccount, err := account.RetrieveAccount(createdByEmail, cfg)
+// purgeCheckInterval is how often datasources deleted past the retention period are purged
+const purgeCheckInterval = time.Hour
 
+// LoadConnectors loads the connector catalog from the built-in definitions and the plugin
+// directory. Plugin definitions override built-in ones of the same source type
+func LoadConnectors(cfg *config.ConnectorConfig) error {
+	builtin, err := loadConnectorDefinitions(datasource.BuiltinConnectors, "connectors")
 	if err != nil {
-		return nil, errors.New("Failed to get account")
+		return err
 	}
 
-	createdBy := map[string]string{
-		"Email": createdByEmail,
-		"Name": createdByAccount.Name,
+	connectors := builtin
+
+	if cfg.ConnectorPluginDir != "" {
+		plugins, err := loadConnectorDefinitions(os.DirFS(cfg.ConnectorPluginDir), ".")
+		if err != nil {
+			return err
+		}
+
+		for _, plugin := range plugins {
+			index := slices.IndexFunc(connectors, func(c datasource.Connector) bool {
+				return c.SourceType == plugin.SourceType
+			})
+
+			if index < 0 {
+				connectors = append(connectors, plugin)
+				continue
+			}
+
+			log.Printf("Connector %s from %s overrides the built-in definition", plugin.SourceType, cfg.ConnectorPluginDir)
+			connectors[index] = plugin
+		}
 	}
 
-	_project, err := project.RetrieveProject(key, cfg)
+	datasource.SupportedDatasources = connectors
+
+	log.Printf("Loaded %d connectors", len(connectors))
+
+	return nil
+}
+
+// RetrieveCreator builds the creator snapshot of a new datasource. Datasources created with an
+// API key are attributed to the account that created the key, along with the key itself
+func RetrieveCreator(email string, _key *apikey.APIKey, organizationKey string, cfg *config.DatabaseConfig) (map[string]string, error) {
+	if _key == nil {
+		_account, err := account.RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+		if err != nil || _account == nil {
+			return nil, errors.New("Failed to get account")
+		}
+
+		return accountDomain.BuildCreatedBy(_account), nil
+	}
+
+	// The creator of the key may have been deleted since, without a transfer
+	_account, err := account.RetrieveOrganizationAccount(_key.CreatedBy, organizationKey, cfg)
 
 	if err != nil {
+		return nil, errors.New("Failed to get account")
+	}
+
+	createdBy := accountDomain.BuildCreatedBy(_account)
+	createdBy["APIKey"] = _key.Prefix
+
+	return createdBy, nil
+}
+
+func NewDatasource(key string, organizationKey string, sourceType string, sourceId string, configuration map[string]interface{}, createdBy map[string]string, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
+	_datasourceRepository := database.NewDatasourceRepository(cfg)
+
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
+
+	if err != nil || _project == nil {
 		return nil, errors.New("Invalid project key")
 	}
 
@@ -37,69 +108,237 @@ func NewDatasource(key string, sourceType string, sourceId string, createdByEmai
 		CreatedBy: createdBy,
 	}
 
-	return _datasourceRepository.Create(_datasource)
+	if err := sealConfiguration(_datasource, configuration); err != nil {
+		log.Printf("Error encrypting datasource configuration: %v", err)
+		return nil, errors.New("Failed to encrypt datasource configuration.")
+	}
+
+	_datasource, err = _datasourceRepository.Create(_datasource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maskConfiguration(_datasource)
+
+	return _datasource, nil
 }
 
-func RetrieveDatasource(datasourceID uint, key string, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
+func RetrieveDatasource(datasourceID uint, key string, organizationKey string, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
 	_datasourceRepository := database.NewDatasourceRepository(cfg)
 
-	_project, err := project.RetrieveProject(key, cfg)
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
 
-	if err != nil {
+	if err != nil || _project == nil {
 		return nil, errors.New("Invalid project key")
 	}
 
-	return _datasourceRepository.FindOne(datasourceID, _project.ID)
+	_datasource, err := _datasourceRepository.FindOne(datasourceID, _project.ID)
+
+	if err != nil || _datasource == nil {
+		return _datasource, err
+	}
+
+	maskConfiguration(_datasource)
+
+	return _datasource, nil
 }
 
-func RetrieveDatasources(key string, cfg *config.DatabaseConfig) (*[]datasource.Datasource, error) {
+func RetrieveDatasources(key string, organizationKey string, cfg *config.DatabaseConfig) (*[]datasource.Datasource, error) {
 	_datasourceRepository := database.NewDatasourceRepository(cfg)
 
-	_project, err := project.RetrieveProject(key, cfg)
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
 
-	if err != nil {
+	if err != nil || _project == nil {
 		return nil, errors.New("Invalid project key")
 	}
 
-	return _datasourceRepository.Find(_project.ID)
+	_datasources, err := _datasourceRepository.Find(_project.ID)
+
+	if err != nil || _datasources == nil {
+		return _datasources, err
+	}
+
+	for i := range *_datasources {
+		maskConfiguration(&(*_datasources)[i])
+	}
+
+	return _datasources, nil
 }
 
-func SoftDeleteDatasource(datasourceID uint, key string, cfg *config.DatabaseConfig) error {
+func SoftDeleteDatasource(datasourceID uint, key string, organizationKey string, cfg *config.DatabaseConfig) error {
 	_datasourceRepository := database.NewDatasourceRepository(cfg)
 
-	_project, err := project.RetrieveProject(key, cfg)
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
 
-	if err != nil {
+	if err != nil || _project == nil {
 		return errors.New("Invalid project key")
 	}
 
 	return _datasourceRepository.SoftDelete(datasourceID, _project.ID)
 }
 
-func HardDeleteDatasource(datasourceID uint, key string, cfg *config.DatabaseConfig) error {
+// RetrieveDeletedDatasources lists the deleted datasources of a project that can still be restored
+func RetrieveDeletedDatasources(key string, organizationKey string, cfg *config.DatabaseConfig) (*[]datasource.Datasource, error) {
 	_datasourceRepository := database.NewDatasourceRepository(cfg)
 
-	_project, err := project.RetrieveProject(key, cfg)
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
 
-	if err != nil {
-		return errors.New("Invalid project key")
+	if err != nil || _project == nil {
+		return nil, errors.New("Invalid project key")
+	}
+
+	_datasources, err := _datasourceRepository.FindDeleted(_project.ID)
+
+	if err != nil || _datasources == nil {
+		return _datasources, err
 	}
 
-	return _datasourceRepository.HardDelete(datasourceID, _project.ID)
+	for i := range *_datasources {
+		maskConfiguration(&(*_datasources)[i])
+	}
+
+	return _datasources, nil
 }
 
-func UpdateSchemaMapping(key string, datasourceID uint, schemaMapping map[string]interface{}, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
+// RestoreDatasource restores a deleted datasource, returning nil when it is not deleted
+func RestoreDatasource(datasourceID uint, key string, organizationKey string, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
 	_datasourceRepository := database.NewDatasourceRepository(cfg)
 
-	_project, err := project.RetrieveProject(key, cfg)
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
+
+	if err != nil || _project == nil {
+		return nil, errors.New("Invalid project key")
+	}
+
+	_datasource, err := _datasourceRepository.FindOneDeleted(datasourceID, _project.ID)
+
+	if err != nil || _datasource == nil {
+		return nil, err
+	}
+
+	if err := _datasourceRepository.Restore(datasourceID, _project.ID); err != nil {
+		return nil, err
+	}
+
+	return RetrieveDatasource(datasourceID, key, organizationKey, cfg)
+}
+
+// PurgeDatasource permanently deletes a deleted datasource along with its ETL source, returning
+// the purged datasource, or nil when it is not deleted
+func PurgeDatasource(datasourceID uint, key string, organizationKey string, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
+	_datasourceRepository := database.NewDatasourceRepository(cfg)
+
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
+
+	if err != nil || _project == nil {
+		return nil, errors.New("Invalid project key")
+	}
+
+	_datasource, err := _datasourceRepository.FindOneDeleted(datasourceID, _project.ID)
 
+	if err != nil || _datasource == nil {
+		return nil, err
+	}
+
+	maskConfiguration(_datasource)
+
+	if err := purge(_datasource, cfg); err != nil {
+		return nil, err
+	}
+
+	return _datasource, nil
+}
+
+// InitializePurge starts purging datasources deleted longer ago than the retention period
+func InitializePurge(dsCfg *config.DatasourceConfig, dbCfg *config.DatabaseConfig) {
+	if dsCfg.DatasourceRetention <= 0 {
+		log.Print("Purging of deleted datasources is disabled")
+		return
+	}
+
+	go watchDeleted(dsCfg, dbCfg)
+}
+
+func watchDeleted(dsCfg *config.DatasourceConfig, dbCfg *config.DatabaseConfig) {
+	ticker := time.NewTicker(purgeCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := purgeExpired(dsCfg, dbCfg); err != nil {
+			log.Printf("Error purging deleted datasources: %v", err)
+		}
+
+		<-ticker.C
+	}
+}
+
+// purgeExpired purges the datasources deleted before the retention period. A datasource that
+// fails to purge is left for the next run
+func purgeExpired(dsCfg *config.DatasourceConfig, cfg *config.DatabaseConfig) error {
+	_datasourceRepository := database.NewDatasourceRepository(cfg)
+	_projectRepository := database.NewProjectRepository(cfg)
+	_organizationRepository := database.NewOrganizationRepository(cfg)
+
+	_datasources, err := _datasourceRepository.FindDeletedBefore(time.Now().Add(-dsCfg.DatasourceRetention))
 	if err != nil {
+		return err
+	}
+
+	for i := range *_datasources {
+		_datasource := &(*_datasources)[i]
+
+		maskConfiguration(_datasource)
+
+		if err := purge(_datasource, cfg); err != nil {
+			log.Printf("Error purging datasource %d: %v", _datasource.ID, err)
+			continue
+		}
+
+		log.Printf("Purged datasource %d, deleted at %s", _datasource.ID, _datasource.DeletedAt.Time.Format(time.RFC3339))
+
+		event := auditDomain.AuditEvent{
+			Action:     auditDomain.DatasourcePurged,
+			TargetType: "datasource",
+			TargetID:   strconv.FormatUint(uint64(_datasource.ID), 10),
+			Changes:    auditDomain.BuildChanges(_datasource, nil),
+		}
+
+		if _project, err := _projectRepository.FindOneByID(_datasource.ProjectID); err == nil && _project != nil {
+			event.ProjectKey = _project.Key
+
+			if _organization, err := _organizationRepository.FindOneByID(_project.OrganizationID); err == nil && _organization != nil {
+				event.OrganizationKey = _organization.Key
+			}
+		}
+
+		audit.Record(event, cfg)
+	}
+
+	return nil
+}
+
+// purge deletes the ETL source of a datasource before its row, so a failed purge can be retried
+func purge(_datasource *datasource.Datasource, cfg *config.DatabaseConfig) error {
+	if err := etl.GetInstance().DeleteSourceConnection(_datasource.SourceID); err != nil {
+		return err
+	}
+
+	return database.NewDatasourceRepository(cfg).HardDelete(_datasource.ID, _datasource.ProjectID)
+}
+
+func UpdateSchemaMapping(key string, organizationKey string, datasourceID uint, schemaMapping map[string]interface{}, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
+	_datasourceRepository := database.NewDatasourceRepository(cfg)
+
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
+
+	if err != nil || _project == nil {
 		return nil, errors.New("Invalid project key")
 	}
 
 	_datasource, err := _datasourceRepository.FindOne(datasourceID, _project.ID)
 
-	if err != nil {
+	if err != nil || _datasource == nil {
 		return nil, errors.New("Invalid datasource")
 	}
 
@@ -110,5 +349,166 @@ func UpdateSchemaMapping(key string, datasourceID uint, schemaMapping map[string
 		return nil, err
 	}
 
+	maskConfiguration(_datasource)
+
 	return _datasource, nil
 }
+
+// UpdateConfiguration encrypts and stores the configuration of a datasource, once the ETL source
+// has been updated with it
+func UpdateConfiguration(key string, organizationKey string, datasourceID uint, configuration map[string]interface{}, cfg *config.DatabaseConfig) (*datasource.Datasource, error) {
+	_datasourceRepository := database.NewDatasourceRepository(cfg)
+
+	_project, err := project.RetrieveProject(key, organizationKey, cfg)
+
+	if err != nil || _project == nil {
+		return nil, errors.New("Invalid project key")
+	}
+
+	_datasource, err := _datasourceRepository.FindOne(datasourceID, _project.ID)
+
+	if err != nil || _datasource == nil {
+		return nil, errors.New("Invalid datasource")
+	}
+
+	if err := sealConfiguration(_datasource, configuration); err != nil {
+		log.Printf("Error encrypting datasource configuration: %v", err)
+		return nil, errors.New("Failed to encrypt datasource configuration.")
+	}
+
+	if err := _datasourceRepository.UpdateConfiguration(_datasource.ID, _datasource.Configuration); err != nil {
+		return nil, err
+	}
+
+	_datasource, err = _datasourceRepository.FindOne(datasourceID, _project.ID)
+
+	if err != nil || _datasource == nil {
+		return nil, errors.New("Invalid datasource")
+	}
+
+	maskConfiguration(_datasource)
+
+	return _datasource, nil
+}
+
+// RetrieveConfiguration decrypts the configuration of a datasource, secrets included.
+// It must never be returned in responses, use MaskedConfiguration instead
+func RetrieveConfiguration(_datasource *datasource.Datasource) (map[string]interface{}, error) {
+	configuration := map[string]interface{}{}
+
+	// Datasources created before configurations were stored have none
+	if _datasource.Configuration == "" {
+		return configuration, nil
+	}
+
+	plaintext, err := secret.Decrypt(_datasource.Configuration, _datasource.BuildAdditionalData())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(plaintext, &configuration); err != nil {
+		return nil, err
+	}
+
+	return configuration, nil
+}
+
+// ReencryptConfigurations re-encrypts every configuration not encrypted with the active data key
+func ReencryptConfigurations(cfg *config.DatabaseConfig) error {
+	_datasourceRepository := database.NewDatasourceRepository(cfg)
+
+	_datasources, err := _datasourceRepository.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for i := range *_datasources {
+		_datasource := &(*_datasources)[i]
+
+		if _datasource.Configuration == "" || secret.IsCurrent(_datasource.Configuration) {
+			continue
+		}
+
+		configuration, err := RetrieveConfiguration(_datasource)
+		if err != nil {
+			return fmt.Errorf("datasource %d: %w", _datasource.ID, err)
+		}
+
+		if err := sealConfiguration(_datasource, configuration); err != nil {
+			return err
+		}
+
+		if err := _datasourceRepository.UpdateConfiguration(_datasource.ID, _datasource.Configuration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sealConfiguration(_datasource *datasource.Datasource, configuration map[string]interface{}) error {
+	plaintext, err := json.Marshal(configuration)
+	if err != nil {
+		return err
+	}
+
+	envelope, err := secret.Encrypt(plaintext, _datasource.BuildAdditionalData())
+	if err != nil {
+		return err
+	}
+
+	_datasource.Configuration = envelope
+
+	return nil
+}
+
+// loadConnectorDefinitions parses the definition files of a directory, failing on any invalid
+// definition or source type defined twice
+func loadConnectorDefinitions(fsys fs.FS, dir string) ([]datasource.Connector, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var connectors []datasource.Connector
+	definedIn := map[string]string{}
+
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		connector, err := datasource.ParseConnector(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		if other, ok := definedIn[connector.SourceType]; ok {
+			return nil, fmt.Errorf("%s: connector %s is already defined in %s", entry.Name(), connector.SourceType, other)
+		}
+
+		definedIn[connector.SourceType] = entry.Name()
+		connectors = append(connectors, *connector)
+	}
+
+	return connectors, nil
+}
+
+// maskConfiguration fills the masked configuration returned in responses
+func maskConfiguration(_datasource *datasource.Datasource) {
+	configuration, err := RetrieveConfiguration(_datasource)
+
+	if err != nil {
+		log.Printf("Error decrypting configuration of datasource %d: %v", _datasource.ID, err)
+		return
+	}
+
+	_datasource.MaskedConfiguration = datasource.MaskSecrets(_datasource.SourceType, configuration)
+}
@@ -0,0 +1,152 @@
+package datasource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/datasource"
+)
+
+const testDefinition = `
+version: 1
+sourceType: test
+name: Test
+form:
+- title: host
+  type: string
+  required: true
+- title: password
+  type: string
+  secret: true
+`
+
+func TestLoadConnectorDefinitions(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     fstest.MapFS
+		wantTypes []string
+		wantErr   bool
+	}{
+		{
+			name: "a definition",
+			files: fstest.MapFS{"test.yaml": {Data: []byte(testDefinition)}},
+			wantTypes: []string{"test"},
+		},
+		{
+			name: "a JSON definition",
+			files: fstest.MapFS{"test.json": {Data: []byte(`{"version": 1, "sourceType": "test", "name": "Test", "form": [{"title": "host", "type": "string"}]}`)}},
+			wantTypes: []string{"test"},
+		},
+		{
+			name: "ignores other files",
+			files: fstest.MapFS{
+				"test.yaml": {Data: []byte(testDefinition)},
+				"README.md": {Data: []byte("# Connectors")},
+			},
+			wantTypes: []string{"test"},
+		},
+		{
+			name: "malformed YAML",
+			files: fstest.MapFS{"test.yaml": {Data: []byte("version: 1\nsourceType: [test\n")}},
+			wantErr: true,
+		},
+		{
+			name: "an unknown property",
+			files: fstest.MapFS{"test.yaml": {Data: []byte(testDefinition + "icon: test.png\n")}},
+			wantErr: true,
+		},
+		{
+			name: "an unsupported version",
+			files: fstest.MapFS{"test.yaml": {Data: []byte("version: 2\nsourceType: test\nname: Test\nform:\n- title: host\n  type: string\n")}},
+			wantErr: true,
+		},
+		{
+			name: "an unsupported field type",
+			files: fstest.MapFS{"test.yaml": {Data: []byte("version: 1\nsourceType: test\nname: Test\nform:\n- title: host\n  type: date\n")}},
+			wantErr: true,
+		},
+		{
+			name: "a source type defined twice",
+			files: fstest.MapFS{
+				"test.yaml": {Data: []byte(testDefinition)},
+				"test.yml": {Data: []byte(testDefinition)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connectors, err := loadConnectorDefinitions(tt.files, ".")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadConnectorDefinitions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(connectors) != len(tt.wantTypes) {
+				t.Fatalf("loadConnectorDefinitions() = %d connectors, want %d", len(connectors), len(tt.wantTypes))
+			}
+
+			for i, connector := range connectors {
+				if connector.SourceType != tt.wantTypes[i] {
+					t.Fatalf("connector %d = %s, want %s", i, connector.SourceType, tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConnectors(t *testing.T) {
+	previous := datasource.SupportedDatasources
+	t.Cleanup(func() { datasource.SupportedDatasources = previous })
+
+	// plugins writes definition files to a new plugin directory
+	plugins := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatalf("failed to write definition: %v", err)
+			}
+		}
+		return dir
+	}
+
+	t.Run("loads the built-in connectors", func(t *testing.T) {
+		if err := LoadConnectors(&config.ConnectorConfig{}); err != nil {
+			t.Fatalf("LoadConnectors() error = %v", err)
+		}
+
+		if datasource.FindConnector("postgres") == nil {
+			t.Fatal("built-in postgres connector was not loaded")
+		}
+	})
+
+	t.Run("adds and overrides connectors from the plugin directory", func(t *testing.T) {
+		override := "version: 1\nsourceType: postgres\nname: Custom Postgres\nform:\n- title: host\n  type: string\n"
+
+		dir := plugins(t, map[string]string{"test.yaml": testDefinition, "postgres.yaml": override})
+
+		if err := LoadConnectors(&config.ConnectorConfig{ConnectorPluginDir: dir}); err != nil {
+			t.Fatalf("LoadConnectors() error = %v", err)
+		}
+
+		if datasource.FindConnector("test") == nil {
+			t.Fatal("plugin connector was not loaded")
+		}
+		if _connector := datasource.FindConnector("postgres"); _connector == nil || _connector.Name != "Custom Postgres" {
+			t.Fatalf("postgres connector = %v, want the plugin definition", _connector)
+		}
+	})
+
+	t.Run("rejects an invalid plugin definition", func(t *testing.T) {
+		dir := plugins(t, map[string]string{"test.yaml": "version: 1\nsourceType: Test Connector\nname: Test\nform: []\n"})
+
+		if err := LoadConnectors(&config.ConnectorConfig{ConnectorPluginDir: dir}); err == nil {
+			t.Fatal("LoadConnectors() accepted an invalid plugin definition")
+		}
+	})
+}
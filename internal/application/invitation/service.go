@@ -0,0 +1,193 @@
+package invitation
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	projectService "github.com/darksuei/suei-intelligence/internal/application/project"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/domain/invitation"
+	"github.com/darksuei/suei-intelligence/internal/domain/project"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/notifier"
+)
+
+// NewInvitation invites an email to the organization and delivers the invite token through the notifier.
+// Any earlier pending invitation for the same email is replaced
+func NewInvitation(email string, role account.AccountRole, projectRoles map[string]project.ProjectRole, invitedByEmail string, organizationKey string, cfg *config.DatabaseConfig, notifierCfg *config.NotifierConfig) (*invitation.Invitation, error) {
+	_invitationRepository := database.NewInvitationRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if email already exists - Fail fast
+	_account, err := accountService.RetrieveAccount(email, cfg)
+
+	if err != nil || _account != nil {
+		return nil, errors.New("Email already registered.")
+	}
+
+	_projectRoles := map[string]string{}
+
+	for projectKey, projectRole := range projectRoles {
+		_project, err := projectService.RetrieveProject(projectKey, organizationKey, cfg)
+
+		if err != nil || _project == nil {
+			return nil, fmt.Errorf("Invalid project: %s.", projectKey)
+		}
+
+		_projectRoles[projectKey] = string(projectRole)
+	}
+
+	invitedByAccount, err := accountService.RetrieveOrganizationAccount(invitedByEmail, organizationKey, cfg)
+
+	if err != nil || invitedByAccount == nil {
+		return nil, errors.New("Failed to get account")
+	}
+
+	if err := _invitationRepository.DeletePending(email, _organization.ID); err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := invitation.GenerateToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_invitation, err := _invitationRepository.Create(&invitation.Invitation{
+		Email: email,
+		Role: role,
+		ProjectRoles: _projectRoles,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(invitation.TTL),
+		InvitedBy: map[string]string{
+			"Email": invitedByAccount.Email,
+			"Name": invitedByAccount.Name,
+		},
+		OrganizationID: _organization.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The invite token is only ever delivered to the invitee
+	message := invitation.BuildMessage(_invitation, _organization.Name, token, notifierCfg.InvitationURL)
+
+	if err := notifier.GetNotifier().Send(message); err != nil {
+		log.Printf("Error delivering invitation: %v", err)
+
+		// Rollback CREATED invitation
+		_ = _invitationRepository.Delete(_invitation.ID, _organization.ID)
+
+		return nil, errors.New("Failed to deliver invitation.")
+	}
+
+	return _invitation, nil
+}
+
+func RetrieveInvitations(organizationKey string, cfg *config.DatabaseConfig) (*[]invitation.Invitation, error) {
+	_invitationRepository := database.NewInvitationRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _invitationRepository.Find(_organization.ID)
+}
+
+func RevokeInvitation(id uint, organizationKey string, cfg *config.DatabaseConfig) (*invitation.Invitation, error) {
+	_invitationRepository := database.NewInvitationRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_invitation, err := _invitationRepository.FindOne(id, _organization.ID)
+
+	if err != nil || _invitation == nil {
+		return nil, errors.New("Invitation not found.")
+	}
+
+	if _invitation.AcceptedAt != nil {
+		return nil, invitation.ErrInvitationAccepted
+	}
+
+	if err := _invitationRepository.Delete(id, _organization.ID); err != nil {
+		return nil, err
+	}
+
+	return _invitation, nil
+}
+
+// RetrieveInvitationByToken looks up a still acceptable invitation from its raw invite token
+func RetrieveInvitationByToken(token string, cfg *config.DatabaseConfig) (*invitation.Invitation, error) {
+	_invitationRepository := database.NewInvitationRepository(cfg)
+
+	_invitation, err := _invitationRepository.FindOneByTokenHash(invitation.HashToken(token))
+
+	if err != nil || _invitation == nil {
+		return nil, errors.New("Invalid invitation.")
+	}
+
+	if err := _invitation.CheckAcceptable(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return _invitation, nil
+}
+
+// AcceptInvitation creates the invitee's account with the invited org and project roles
+// and consumes the invitation
+func AcceptInvitation(token string, name string, password string, cfg *config.DatabaseConfig) (*account.Account, *invitation.Invitation, error) {
+	_invitationRepository := database.NewInvitationRepository(cfg)
+
+	_invitation, err := RetrieveInvitationByToken(token, cfg)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_organization, err := organizationService.RetrieveOrganizationByID(_invitation.OrganizationID, cfg)
+
+	if err != nil || _organization == nil {
+		return nil, nil, errors.New("Invalid organization.")
+	}
+
+	internalRoleJson := map[string]string{
+		account.BuildRoleEntryKey(_organization.Key, authorization.AuthorizationDomainOrg): account.BuildRoleKey(_organization.Key, authorization.AuthorizationDomainOrg, string(_invitation.Role)),
+	}
+
+	for projectKey, projectRole := range _invitation.ProjectRoles {
+		internalRoleJson[account.BuildRoleEntryKey(projectKey, authorization.AuthorizationDomainProject)] = account.BuildRoleKey(projectKey, authorization.AuthorizationDomainProject, projectRole)
+	}
+
+	_account, err := accountService.NewAccount(name, _invitation.Email, password, _invitation.Role, internalRoleJson, _organization.Key, cfg)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	_invitation.AcceptedAt = &now
+
+	if err := _invitationRepository.Update(_invitation); err != nil {
+		return nil, nil, err
+	}
+
+	return _account, _invitation, nil
+}
@@ -0,0 +1,127 @@
+package lockout
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/lockout"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/metrics"
+)
+
+// CheckLockout returns the active lockout of the account or the client IP, or the progressive
+// delay the account still has to wait out, nil when none applies
+func CheckLockout(email string, ip string) *lockout.Lockout {
+	subjects := map[lockout.LockoutScope]string{
+		lockout.ScopeAccount: email,
+		lockout.ScopeIP:      ip,
+	}
+
+	for _, scope := range []lockout.LockoutScope{lockout.ScopeAccount, lockout.ScopeIP} {
+		until, ok := cachedTime(lockout.BuildLockoutKey(scope, subjects[scope]))
+
+		if !ok {
+			continue
+		}
+
+		metrics.AuthThrottledRequests.WithLabelValues(string(scope)).Inc()
+
+		return &lockout.Lockout{Scope: scope, Until: until}
+	}
+
+	if notBefore, ok := cachedTime(lockout.BuildNotBeforeKey(email)); ok && time.Now().Before(notBefore) {
+		metrics.AuthThrottledRequests.WithLabelValues(string(lockout.ScopeAccount)).Inc()
+
+		return &lockout.Lockout{Scope: lockout.ScopeAccount, Until: notBefore}
+	}
+
+	return nil
+}
+
+// RecordFailedAttempt counts a failed attempt against the account and the client IP, locking
+// whichever reaches its limit. The account may not try again before the progressive delay has
+// passed. It returns any new lockouts
+func RecordFailedAttempt(email string, ip string, kind lockout.AttemptKind, cfg *config.LockoutConfig) []lockout.Lockout {
+	metrics.FailedAuthAttempts.WithLabelValues(string(kind)).Inc()
+
+	var lockouts []lockout.Lockout
+
+	accountFailures, err := cache.GetCache().Increment(lockout.BuildFailedAttemptsKey(lockout.ScopeAccount, email), cfg.LockoutWindow)
+
+	if err == nil && accountFailures >= int64(cfg.LockoutMaxAttempts) {
+		lockouts = append(lockouts, lock(lockout.ScopeAccount, email, cfg))
+	} else if delay := lockout.ProgressiveDelay(accountFailures, cfg.LockoutDelayBase, cfg.LockoutDelayMax); delay > 0 {
+		notBefore := time.Now().Add(delay)
+		_ = cache.GetCache().Set(lockout.BuildNotBeforeKey(email), strconv.FormatInt(notBefore.UnixMilli(), 10), delay)
+	}
+
+	ipFailures, err := cache.GetCache().Increment(lockout.BuildFailedAttemptsKey(lockout.ScopeIP, ip), cfg.LockoutWindow)
+
+	if err == nil && ipFailures >= int64(cfg.LockoutIPMaxAttempts) {
+		lockouts = append(lockouts, lock(lockout.ScopeIP, ip, cfg))
+	}
+
+	return lockouts
+}
+
+// ClearFailedAttempts resets the failed attempts of an account after a successful sign-in
+func ClearFailedAttempts(email string) {
+	_ = cache.GetCache().Delete(lockout.BuildFailedAttemptsKey(lockout.ScopeAccount, email))
+	_ = cache.GetCache().Delete(lockout.BuildNotBeforeKey(email))
+}
+
+// UnlockAccount lifts the lockout of an account and resets its failed attempts
+func UnlockAccount(email string) error {
+	if err := cache.GetCache().Delete(lockout.BuildLockoutKey(lockout.ScopeAccount, email)); err != nil {
+		return err
+	}
+
+	ClearFailedAttempts(email)
+
+	return nil
+}
+
+// RecordChallengeAttempt counts a failed code against an MFA challenge and reports
+// whether the challenge has run out of attempts
+func RecordChallengeAttempt(challengeID string, cfg *config.LockoutConfig) bool {
+	attempts, err := cache.GetCache().Increment(lockout.BuildChallengeAttemptsKey(challengeID), time.Hour)
+
+	if err != nil || attempts < int64(cfg.MFAChallengeMaxAttempts) {
+		return false
+	}
+
+	_ = cache.GetCache().Delete(lockout.BuildChallengeAttemptsKey(challengeID))
+
+	metrics.MFAChallengesExhausted.Inc()
+
+	return true
+}
+
+func lock(scope lockout.LockoutScope, subject string, cfg *config.LockoutConfig) lockout.Lockout {
+	until := time.Now().Add(cfg.LockoutDuration)
+
+	_ = cache.GetCache().Set(lockout.BuildLockoutKey(scope, subject), strconv.FormatInt(until.UnixMilli(), 10), cfg.LockoutDuration)
+	_ = cache.GetCache().Delete(lockout.BuildFailedAttemptsKey(scope, subject))
+
+	metrics.AuthLockouts.WithLabelValues(string(scope)).Inc()
+
+	return lockout.Lockout{Scope: scope, Until: until}
+}
+
+// cachedTime reads a time stored in the cache as Unix milliseconds
+func cachedTime(key string) (time.Time, bool) {
+	value, err := cache.GetCache().Get(key)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	milliseconds, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.UnixMilli(milliseconds), true
+}
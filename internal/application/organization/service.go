@@ -8,9 +8,18 @@ import (
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
 )
 
-func NewOrganization(name string, key string, scope string, cfg *config.DatabaseConfig) (*organization.Organization, error) {
+// BuildOrganization prepares an organization with a new key. It is stored with its first account
+func BuildOrganization(name string, scope string, cfg *config.DatabaseConfig) (*organization.Organization, error) {
 	_organizationRepository := database.NewOrganizationRepository(cfg)
 
+	_scope, err := organization.NewOrgScope(scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := organization.GenerateOrganizationKey()
+
 	_organization, err := _organizationRepository.FindOne(key)
 
 	if err != nil {
@@ -21,13 +30,11 @@ func NewOrganization(name string, key string, scope string, cfg *config.Database
 		return nil, errors.New("Organization already exists.")
 	}
 
-	_organization = &organization.Organization{
+	return &organization.Organization{
 		Name:  name,
 		Key:   key,
-		Scope: organization.OrgScope(scope),
-	}
-
-	return _organizationRepository.Create(_organization)
+		Scope: _scope,
+	}, nil
 }
 
 func RetrieveOrganization(key string, cfg *config.DatabaseConfig) (*organization.Organization, error) {
@@ -36,6 +43,27 @@ func RetrieveOrganization(key string, cfg *config.DatabaseConfig) (*organization
 	return _organizationRepository.FindOne(key)
 }
 
+func RetrieveOrganizationByID(id uint, cfg *config.DatabaseConfig) (*organization.Organization, error) {
+	_organizationRepository := database.NewOrganizationRepository(cfg)
+
+	return _organizationRepository.FindOneByID(id)
+}
+
+// ResolveOrganization retrieves an organization by key, failing when it does not exist
+func ResolveOrganization(key string, cfg *config.DatabaseConfig) (*organization.Organization, error) {
+	_organization, err := RetrieveOrganization(key, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _organization == nil {
+		return nil, errors.New("Invalid organization.")
+	}
+
+	return _organization, nil
+}
+
 func UpdateOrganization (name *string, key string, scope *string, cfg *config.DatabaseConfig) (*organization.Organization, error) {
 	_organizationRepository := database.NewOrganizationRepository(cfg)
 
@@ -45,12 +73,18 @@ func UpdateOrganization (name *string, key string, scope *string, cfg *config.Da
 		return nil, errors.New("Failed to get organization.")
 	}
 
-	if name != nil {
+	if name != nil && *name != "" {
 		_organization.Name = *name
 	}
 
-	if scope != nil {
-		_organization.Scope = organization.OrgScope(*scope)
+	if scope != nil && *scope != "" {
+		_scope, err := organization.NewOrgScope(*scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		_organization.Scope = _scope
 	}
 
 	// Save updated project
@@ -59,4 +93,17 @@ func UpdateOrganization (name *string, key string, scope *string, cfg *config.Da
 	}
 
 	return _organization, nil
-}
\ No newline at end of file
+}
+func SetLanguage(key string, language string, cfg *config.DatabaseConfig) error {
+	_organizationRepository := database.NewOrganizationRepository(cfg)
+
+	_organization, err := _organizationRepository.FindOne(key)
+
+	if err != nil || _organization == nil {
+		return errors.New("Failed to get organization.")
+	}
+
+	_organization.Language = language
+
+	return _organizationRepository.Update(_organization)
+}
@@ -4,16 +4,23 @@ import (
 	"errors"
 
 	"github.com/darksuei/suei-intelligence/internal/application/account"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
 	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/darksuei/suei-intelligence/internal/domain/project"
 	projectDomain "github.com/darksuei/suei-intelligence/internal/domain/project"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
 )
 
-func NewProject(name string, key string,stage projectDomain.ProjectStage, businessDomain string, createdByEmail string, cfg *config.DatabaseConfig) (*project.Project, error) {
+func NewProject(name string, key string,stage projectDomain.ProjectStage, businessDomain string, createdByEmail string, organizationKey string, cfg *config.DatabaseConfig) (*project.Project, error) {
 	_projectRepository := database.NewProjectRepository(cfg)
 
-	_project, err := _projectRepository.FindOneByKey(key)
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_project, err := _projectRepository.FindOneByKey(key, _organization.ID)
 
 	if err != nil {
 		return nil, err
@@ -23,9 +30,9 @@ func NewProject(name string, key string,stage projectDomain.ProjectStage, busine
 		return nil, errors.New("Project already exists.")
 	}
 
-	createdByAccount, err := account.RetrieveAccount(createdByEmail, cfg)
+	createdByAccount, err := account.RetrieveOrganizationAccount(createdByEmail, organizationKey, cfg)
 
-	if err != nil {
+	if err != nil || createdByAccount == nil {
 		return nil, errors.New("Failed to get account")
 	}
 
@@ -41,25 +48,39 @@ func NewProject(name string, key string,stage projectDomain.ProjectStage, busine
 		Stage:   stage,
 		BusinessDomain: businessDomain,
 		CreatedBy: createdBy,
+		OrganizationID: _organization.ID,
 	}
 
 	return _projectRepository.Create(_project)
 }
 
-func RetrieveProject(key string, cfg *config.DatabaseConfig) (*project.Project, error) {
+func RetrieveProject(key string, organizationKey string, cfg *config.DatabaseConfig) (*project.Project, error) {
 	_projectRepository := database.NewProjectRepository(cfg)
 
-	return _projectRepository.FindOneByKey(key)
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _projectRepository.FindOneByKey(key, _organization.ID)
 }
 
-func RetrieveProjects(cfg *config.DatabaseConfig) (*[]project.Project, error) {
+func RetrieveProjects(organizationKey string, cfg *config.DatabaseConfig) (*[]project.Project, error) {
 	_projectRepository := database.NewProjectRepository(cfg)
 
-	return _projectRepository.Find()
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _projectRepository.Find(_organization.ID)
 }
 
 func UpdateProject(
     key string,
+    organizationKey string,
     name *string,
     newKey *string,
     stage *projectDomain.ProjectStage,
@@ -69,7 +90,7 @@ func UpdateProject(
     _projectRepository := database.NewProjectRepository(cfg)
 
     // Find existing project
-    _project, err := _projectRepository.FindOneByKey(key)
+    _project, err := RetrieveProject(key, organizationKey, cfg)
     if err != nil {
         return nil, err
     }
@@ -78,6 +99,10 @@ func UpdateProject(
         return nil, errors.New("Project not found")
     }
 
+    if err := _project.CheckWritable(); err != nil {
+        return nil, err
+    }
+
     // Update fields only if provided
     if name != nil {
         _project.Name = *name
@@ -98,4 +123,30 @@ func UpdateProject(
     }
 
     return _project, nil
-}
\ No newline at end of file
+}
+
+func TransitionProjectStatus(key string, organizationKey string, action projectDomain.ProjectStatusAction, cfg *config.DatabaseConfig) (*project.Project, error) {
+	_projectRepository := database.NewProjectRepository(cfg)
+
+	_project, err := RetrieveProject(key, organizationKey, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if _project == nil {
+		return nil, errors.New("Project not found")
+	}
+
+	status, err := projectDomain.NextStatus(_project.Status, action)
+	if err != nil {
+		return nil, err
+	}
+
+	_project.Status = status
+
+	if err := _projectRepository.Update(_project); err != nil {
+		return nil, err
+	}
+
+	return _project, nil
+}
@@ -0,0 +1,249 @@
+package role
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+var domains = []authorization.AuthorizationDomain{
+	authorization.AuthorizationDomainOrg,
+	authorization.AuthorizationDomainProject,
+}
+
+func NewRole(name string, description string, permissions []authorization.Permission, organizationKey string, cfg *config.DatabaseConfig) (*authorization.Role, error) {
+	_roleRepository := database.NewRoleRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.ValidatePermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	_role := &authorization.Role{
+		Key: fmt.Sprintf("custom-%s", strings.Split(uuid.New().String(), "-")[0]),
+		Name: name,
+		Description: description,
+		Permissions: permissions,
+		OrganizationID: _organization.ID,
+	}
+
+	_role, err = _roleRepository.Create(_role)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncRolePolicies(_role); err != nil {
+		return nil, err
+	}
+
+	return _role, nil
+}
+
+func RetrieveRoles(organizationKey string, cfg *config.DatabaseConfig) (*[]authorization.Role, error) {
+	_roleRepository := database.NewRoleRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _roleRepository.Find(_organization.ID)
+}
+
+func RetrieveRole(key string, organizationKey string, cfg *config.DatabaseConfig) (*authorization.Role, error) {
+	_roleRepository := database.NewRoleRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _roleRepository.FindOneByKey(key, _organization.ID)
+}
+
+func UpdateRole(key string, organizationKey string, name *string, description *string, permissions *[]authorization.Permission, cfg *config.DatabaseConfig) (*authorization.Role, error) {
+	_roleRepository := database.NewRoleRepository(cfg)
+
+	_role, err := RetrieveRole(key, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _role == nil {
+		return nil, errors.New("Role not found")
+	}
+
+	if name != nil {
+		_role.Name = *name
+	}
+
+	if description != nil {
+		_role.Description = *description
+	}
+
+	if permissions != nil {
+		if err := authorization.ValidatePermissions(*permissions); err != nil {
+			return nil, err
+		}
+		_role.Permissions = *permissions
+	}
+
+	if err := _roleRepository.Update(_role); err != nil {
+		return nil, err
+	}
+
+	if err := syncRolePolicies(_role); err != nil {
+		return nil, err
+	}
+
+	return _role, nil
+}
+
+func DeleteRole(key string, organizationKey string, cfg *config.DatabaseConfig) error {
+	_roleRepository := database.NewRoleRepository(cfg)
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_role, err := RetrieveRole(key, organizationKey, cfg)
+
+	if err != nil {
+		return err
+	}
+
+	if _role == nil {
+		return errors.New("Role not found")
+	}
+
+	// Remove the role from every account it is assigned to
+	_accounts, err := _accountRepository.Find(_role.OrganizationID)
+
+	if err != nil {
+		return err
+	}
+
+	if _accounts != nil {
+		for _, _account := range *_accounts {
+			changed := false
+			for k := range _account.InternalRoles {
+				if strings.HasSuffix(k, "/"+key) {
+					delete(_account.InternalRoles, k)
+					changed = true
+				}
+			}
+
+			if changed {
+				if err := _accountRepository.Update(&_account); err != nil {
+					return err
+				}
+
+				if err := accountService.RevokeAccessTokens(_account.ID, cfg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, domain := range domains {
+		if err := authorizationService.SetRolePolicies(authorization.BuildRoleSubject(domain, key), nil); err != nil {
+			return err
+		}
+	}
+
+	return _roleRepository.Delete(key)
+}
+
+// AssignRole assigns a custom role to an account on an org or a project
+func AssignRole(key string, email string, entityKey string, domain authorization.AuthorizationDomain, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_role, err := RetrieveRole(key, organizationKey, cfg)
+
+	if err != nil || _role == nil {
+		return nil, errors.New("Role not found")
+	}
+
+	_account, err := accountService.RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	if _account.InternalRoles == nil {
+		_account.InternalRoles = map[string]string{}
+	}
+
+	entryKey := account.BuildCustomRoleEntryKey(entityKey, domain, key)
+	_account.InternalRoles[entryKey] = account.BuildRoleKey(entityKey, domain, key)
+
+	if err := _accountRepository.Update(_account); err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before the change still carry the old roles
+	if err := accountService.RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// UnassignRole removes a custom role from an account on an org or a project
+func UnassignRole(key string, email string, entityKey string, domain authorization.AuthorizationDomain, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_accountRepository := database.NewAccountRepository(cfg)
+
+	_account, err := accountService.RetrieveOrganizationAccount(email, organizationKey, cfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	entryKey := account.BuildCustomRoleEntryKey(entityKey, domain, key)
+
+	if _, ok := _account.InternalRoles[entryKey]; !ok {
+		return nil, errors.New("Role is not assigned to this account.")
+	}
+
+	delete(_account.InternalRoles, entryKey)
+
+	if err := _accountRepository.Update(_account); err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before the change still carry the old roles
+	if err := accountService.RevokeAccessTokens(_account.ID, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// syncRolePolicies rewrites the Casbin policies of a role for both assignment domains
+func syncRolePolicies(_role *authorization.Role) error {
+	for _, domain := range domains {
+		subject := authorization.BuildRoleSubject(domain, _role.Key)
+
+		if err := authorizationService.SetRolePolicies(subject, authorization.BuildRolePolicies(subject, domain, _role.Permissions)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package role
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	databaseDomain "github.com/darksuei/suei-intelligence/internal/domain/database"
+	"github.com/darksuei/suei-intelligence/internal/domain/organization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+// roleKeys returns the role keys of an account as issued in its access tokens
+func roleKeys(t *testing.T, email string, cfg *config.DatabaseConfig) []string {
+	t.Helper()
+
+	_account, err := database.NewAccountRepository(cfg).FindOneByEmail(email)
+	if err != nil || _account == nil {
+		t.Fatalf("failed to retrieve account %s: %v", email, err)
+	}
+
+	var keys []string
+	for _, key := range _account.InternalRoles {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestCustomRoles(t *testing.T) {
+	// Assigning roles revokes access tokens through the cache, which is configured from the environment
+	for key, value := range map[string]string{
+		"AIRBYTECLOUD": "false",
+		"AIRBYTEENDPOINT": "http://127.0.0.1:1",
+		"AIRBYTECLIENTID": "client",
+		"AIRBYTECLIENTSECRET": "secret",
+		"AIRBYTEWORKSPACEID": "workspace",
+		"APPENV": "test",
+		"APPHOST": "localhost",
+		"APPPORT": "8080",
+		"BOOTSTRAPTOKEN": "bootstrap",
+	} {
+		t.Setenv(key, value)
+	}
+
+	config.Initialize()
+
+	cfg := &config.DatabaseConfig{
+		DatabaseType: databaseDomain.DatabaseTypeSqlite,
+		DatabasePath: filepath.Join(t.TempDir(), "test.db"),
+	}
+
+	database.Initialize(cfg)
+	database.Migrate(cfg)
+
+	authorizationService.Initialize(&config.CasbinConfig{
+		ModelConfPath: "../../../data/model.conf",
+		PolicyCsvPath: "../../../data/policy.csv",
+		PolicyReloadInterval: time.Hour,
+	}, cfg)
+
+	var organizationIds []uint
+	for _, key := range []string{"org-1", "org-2"} {
+		_organization, err := database.NewOrganizationRepository(cfg).Create(&organization.Organization{Name: key, Key: key, Scope: organization.Private})
+		if err != nil {
+			t.Fatalf("failed to create organization: %v", err)
+		}
+		organizationIds = append(organizationIds, _organization.ID)
+	}
+
+	if _, err := database.NewAccountRepository(cfg).Create(&account.Account{Name: "member", Email: "member@example.com", Role: account.Guest, MFASecret: "secret", OrganizationID: organizationIds[0]}); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	canWriteDatasources := func(projectKey string) bool {
+		allow, err := authorizationService.EnforceProjectRoles(roleKeys(t, "member@example.com", cfg), projectKey, authorization.Datasource, "write")
+		if err != nil {
+			t.Fatalf("EnforceProjectRoles() error = %v", err)
+		}
+		return allow
+	}
+
+	canReadAccounts := func() bool {
+		allow, err := authorizationService.EnforceRoles(roleKeys(t, "member@example.com", cfg), authorization.AuthorizationDomainOrg, authorization.Account, "read")
+		if err != nil {
+			t.Fatalf("EnforceRoles() error = %v", err)
+		}
+		return allow
+	}
+
+	if _, err := NewRole("invalid", "", []authorization.Permission{{Object: "instance", Action: "read"}}, "org-1", cfg); err == nil {
+		t.Fatal("NewRole() accepted an unknown object")
+	}
+
+	_role, err := NewRole("Datasource manager", "", []authorization.Permission{
+		{Object: authorization.Datasource, Action: "write"},
+		{Object: authorization.Account, Action: "read"},
+	}, "org-1", cfg)
+	if err != nil {
+		t.Fatalf("NewRole() error = %v", err)
+	}
+
+	t.Run("belongs to its organization", func(t *testing.T) {
+		if _role, _ := RetrieveRole(_role.Key, "org-2", cfg); _role != nil {
+			t.Fatal("role was retrieved through another organization")
+		}
+
+		if _, err := AssignRole(_role.Key, "member@example.com", "org-2", authorization.AuthorizationDomainOrg, "org-2", cfg); err == nil {
+			t.Fatal("role was assigned through another organization")
+		}
+	})
+
+	t.Run("grants nothing until assigned", func(t *testing.T) {
+		if canWriteDatasources("alpha") || canReadAccounts() {
+			t.Fatal("an unassigned role granted permissions")
+		}
+	})
+
+	t.Run("grants project permissions on the project it is assigned on", func(t *testing.T) {
+		if _, err := AssignRole(_role.Key, "member@example.com", "alpha", authorization.AuthorizationDomainProject, "org-1", cfg); err != nil {
+			t.Fatalf("AssignRole() error = %v", err)
+		}
+
+		if !canWriteDatasources("alpha") {
+			t.Fatal("role assigned on a project did not grant its permission there")
+		}
+		if canWriteDatasources("beta") {
+			t.Fatal("role assigned on a project granted its permission on another project")
+		}
+		if canReadAccounts() {
+			t.Fatal("role assigned on a project granted an org permission")
+		}
+
+		if _, err := UnassignRole(_role.Key, "member@example.com", "alpha", authorization.AuthorizationDomainProject, "org-1", cfg); err != nil {
+			t.Fatalf("UnassignRole() error = %v", err)
+		}
+
+		if canWriteDatasources("alpha") {
+			t.Fatal("unassigned role still grants its permission")
+		}
+	})
+
+	t.Run("grants every permission on every project when assigned on the org", func(t *testing.T) {
+		if _, err := AssignRole(_role.Key, "member@example.com", "org-1", authorization.AuthorizationDomainOrg, "org-1", cfg); err != nil {
+			t.Fatalf("AssignRole() error = %v", err)
+		}
+
+		if !canWriteDatasources("alpha") || !canWriteDatasources("beta") || !canReadAccounts() {
+			t.Fatal("role assigned on the org did not grant its permissions")
+		}
+	})
+
+	t.Run("applies permission changes to assigned accounts", func(t *testing.T) {
+		permissions := []authorization.Permission{{Object: authorization.Account, Action: "read"}}
+
+		if _, err := UpdateRole(_role.Key, "org-1", nil, nil, &permissions, cfg); err != nil {
+			t.Fatalf("UpdateRole() error = %v", err)
+		}
+
+		if canWriteDatasources("alpha") {
+			t.Fatal("removed permission is still granted")
+		}
+		if !canReadAccounts() {
+			t.Fatal("kept permission is no longer granted")
+		}
+	})
+
+	t.Run("removes deleted roles from accounts", func(t *testing.T) {
+		if err := DeleteRole(_role.Key, "org-1", cfg); err != nil {
+			t.Fatalf("DeleteRole() error = %v", err)
+		}
+
+		if keys := roleKeys(t, "member@example.com", cfg); len(keys) != 0 {
+			t.Fatalf("role keys = %v, want none", keys)
+		}
+
+		if _role, _ := RetrieveRole(_role.Key, "org-1", cfg); _role != nil {
+			t.Fatal("deleted role is still stored")
+		}
+	})
+}
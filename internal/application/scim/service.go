@@ -0,0 +1,977 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	projectService "github.com/darksuei/suei-intelligence/internal/application/project"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/domain/project"
+	"github.com/darksuei/suei-intelligence/internal/domain/scim"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+var ErrSuperAdminManaged = scim.NewError(http.StatusForbidden, "", "Superadmin accounts are not managed through SCIM.")
+
+func RetrieveTokens(organizationKey string, cfg *config.DatabaseConfig) (*[]scim.Token, error) {
+	_tokenRepository := database.NewSCIMTokenRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _tokenRepository.Find(_organization.ID)
+}
+
+// NewToken creates a bearer token for the organization's directory, returning the token itself,
+// which is not stored
+func NewToken(name string, organizationKey string, cfg *config.DatabaseConfig) (*scim.Token, string, error) {
+	_tokenRepository := database.NewSCIMTokenRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, hash, err := scim.GenerateToken()
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	_token, err := _tokenRepository.Create(&scim.Token{
+		OrganizationID: _organization.ID,
+		Name: name,
+		TokenHash: hash,
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return _token, token, nil
+}
+
+func DeleteToken(id uint, organizationKey string, cfg *config.DatabaseConfig) error {
+	_tokenRepository := database.NewSCIMTokenRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return err
+	}
+
+	if err := _tokenRepository.Delete(id, _organization.ID); err != nil {
+		return errors.New("Invalid token.")
+	}
+
+	return nil
+}
+
+// AuthenticateToken resolves the organization a bearer token provisions
+func AuthenticateToken(token string, cfg *config.DatabaseConfig) (string, *scim.Token, error) {
+	_tokenRepository := database.NewSCIMTokenRepository(cfg)
+
+	if !strings.HasPrefix(token, scim.TokenPrefix) {
+		return "", nil, errors.New("Invalid token.")
+	}
+
+	_token, err := _tokenRepository.FindOneByHash(scim.HashToken(token))
+
+	if err != nil || _token == nil {
+		return "", nil, errors.New("Invalid token.")
+	}
+
+	_organization, err := organizationService.RetrieveOrganizationByID(_token.OrganizationID, cfg)
+
+	if err != nil || _organization == nil {
+		return "", nil, errors.New("Invalid token.")
+	}
+
+	if err := _tokenRepository.RecordUse(_token.ID, time.Now()); err != nil {
+		log.Printf("Failed to record scim token use: %v", err)
+	}
+
+	return _organization.Key, _token, nil
+}
+
+// RetrieveUsers lists the organization's accounts matching a filter, one page at a time
+func RetrieveUsers(organizationKey string, filter string, startIndex int, count int, baseURL string, cfg *config.DatabaseConfig) (*scim.ListResponse, error) {
+	_accounts, err := accountService.RetrieveAccounts(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := retrieveGroups(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []interface{}{}
+
+	if _accounts != nil {
+		sort.Slice(*_accounts, func(i, j int) bool { return (*_accounts)[i].ID < (*_accounts)[j].ID })
+
+		for _, _account := range *_accounts {
+			resources = append(resources, toUserResource(&_account, groups, baseURL))
+		}
+	}
+
+	return buildListResponse(resources, filter, startIndex, count)
+}
+
+func RetrieveUser(id string, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.UserResource, error) {
+	_account, err := retrieveAccount(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := retrieveGroups(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserResource(_account, groups, baseURL), nil
+}
+
+// NewUser provisions an account, without a password: it signs in through SSO or after
+// resetting its password. Its roles are granted through groups
+func NewUser(payload *scim.UserResource, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.UserResource, error) {
+	email := strings.TrimSpace(payload.UserName)
+
+	if !strings.Contains(email, "@") {
+		return nil, scim.NewError(http.StatusBadRequest, "invalidValue", "userName must be an email address.")
+	}
+
+	existing, err := accountService.RetrieveAccount(email, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, scim.ErrUniqueness
+	}
+
+	internalRoleJson := map[string]string{
+		account.BuildRoleEntryKey(organizationKey, authorization.AuthorizationDomainOrg): account.BuildRoleKey(organizationKey, authorization.AuthorizationDomainOrg, string(account.Guest)),
+	}
+
+	name := resolveName(payload)
+	if name == "" {
+		name = email
+	}
+
+	_account, err := accountService.NewAccountWithoutPassword(name, email, account.Guest, internalRoleJson, organizationKey, cfg)
+
+	// Account names are unique, fall back to the email when the name is taken
+	if err != nil && name != email {
+		_account, err = accountService.NewAccountWithoutPassword(email, email, account.Guest, internalRoleJson, organizationKey, cfg)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.ExternalID != "" {
+		if _account, err = accountService.UpdateExternalID(_account.Email, organizationKey, payload.ExternalID, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if payload.Active != nil && !*payload.Active {
+		if _account, err = accountService.SetAccountDisabled(_account.Email, organizationKey, true, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return RetrieveUser(strconv.FormatUint(uint64(_account.ID), 10), organizationKey, baseURL, cfg)
+}
+
+// ReplaceUser replaces the attributes of an account. Deactivating it signs it out everywhere
+func ReplaceUser(id string, payload *scim.UserResource, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.UserResource, error) {
+	_account, err := retrieveManagedAccount(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateAccount(_account, payload, organizationKey, cfg); err != nil {
+		return nil, err
+	}
+
+	return RetrieveUser(id, organizationKey, baseURL, cfg)
+}
+
+// PatchUser applies PATCH operations to an account
+func PatchUser(id string, operations []scim.PatchOperation, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.UserResource, error) {
+	_account, err := retrieveManagedAccount(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := retrieveGroups(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var payload scim.UserResource
+
+	if err := patchResource(toUserResource(_account, groups, baseURL), operations, &payload); err != nil {
+		return nil, err
+	}
+
+	if err := updateAccount(_account, &payload, organizationKey, cfg); err != nil {
+		return nil, err
+	}
+
+	return RetrieveUser(id, organizationKey, baseURL, cfg)
+}
+
+// DeleteUser deprovisions an account: it is deactivated and removed from all groups. The
+// account itself is kept, so the resources it owns and its audit trail remain intact
+func DeleteUser(id string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_account, err := retrieveManagedAccount(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _account, err = accountService.SetAccountDisabled(_account.Email, organizationKey, true, cfg); err != nil {
+		return nil, err
+	}
+
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	groups, err := retrieveGroups(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if !group.HasMember(_account.ID) {
+			continue
+		}
+
+		group.Members = removeMember(group.Members, _account.ID)
+
+		if err := _groupRepository.Update(&group); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := syncRoles(organizationKey, []uint{_account.ID}, nil, cfg); err != nil {
+		return nil, err
+	}
+
+	return _account, nil
+}
+
+// RetrieveGroups lists the organization's groups matching a filter, one page at a time
+func RetrieveGroups(organizationKey string, filter string, startIndex int, count int, baseURL string, cfg *config.DatabaseConfig) (*scim.ListResponse, error) {
+	groups, err := retrieveGroups(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := retrieveMembers(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []interface{}{}
+
+	for _, group := range groups {
+		resources = append(resources, toGroupResource(&group, members, baseURL))
+	}
+
+	return buildListResponse(resources, filter, startIndex, count)
+}
+
+func RetrieveGroup(id string, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.GroupResource, error) {
+	group, err := retrieveGroup(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := retrieveMembers(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toGroupResource(group, members, baseURL), nil
+}
+
+// NewGroup provisions a group. It grants no roles until they are mapped by an administrator
+func NewGroup(payload *scim.GroupResource, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.GroupResource, error) {
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := strings.TrimSpace(payload.DisplayName)
+
+	if displayName == "" {
+		return nil, scim.NewError(http.StatusBadRequest, "invalidValue", "displayName is required.")
+	}
+
+	existing, err := _groupRepository.FindOneByDisplayName(displayName, _organization.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, scim.ErrUniqueness
+	}
+
+	memberIds, err := resolveMembers(payload.Members, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := _groupRepository.Create(&scim.Group{
+		OrganizationID: _organization.ID,
+		DisplayName: displayName,
+		ExternalID: payload.ExternalID,
+		Members: memberIds,
+		ProjectRoles: map[string]project.ProjectRole{},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return RetrieveGroup(strconv.FormatUint(uint64(group.ID), 10), organizationKey, baseURL, cfg)
+}
+
+// ReplaceGroup replaces the name and members of a group, granting and revoking the roles
+// of the members that joined or left
+func ReplaceGroup(id string, payload *scim.GroupResource, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.GroupResource, error) {
+	group, err := retrieveGroup(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateGroup(group, payload, organizationKey, cfg); err != nil {
+		return nil, err
+	}
+
+	return RetrieveGroup(id, organizationKey, baseURL, cfg)
+}
+
+// PatchGroup applies PATCH operations to a group, typically adding or removing members
+func PatchGroup(id string, operations []scim.PatchOperation, organizationKey string, baseURL string, cfg *config.DatabaseConfig) (*scim.GroupResource, error) {
+	group, err := retrieveGroup(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := retrieveMembers(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var payload scim.GroupResource
+
+	if err := patchResource(toGroupResource(group, members, baseURL), operations, &payload); err != nil {
+		return nil, err
+	}
+
+	if err := updateGroup(group, &payload, organizationKey, cfg); err != nil {
+		return nil, err
+	}
+
+	return RetrieveGroup(id, organizationKey, baseURL, cfg)
+}
+
+// DeleteGroup removes a group, revoking the roles it granted its members
+func DeleteGroup(id string, organizationKey string, cfg *config.DatabaseConfig) (*scim.Group, error) {
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	group, err := retrieveGroup(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := _groupRepository.Delete(group.ID); err != nil {
+		return nil, err
+	}
+
+	if err := syncRoles(organizationKey, group.Members, projectKeysOf(group), cfg); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// RetrieveDirectoryGroups lists the organization's groups with the roles they grant
+func RetrieveDirectoryGroups(organizationKey string, cfg *config.DatabaseConfig) ([]scim.Group, error) {
+	return retrieveGroups(organizationKey, cfg)
+}
+
+// UpdateGroupRoles replaces the roles a group grants its members. Superadmin cannot be
+// granted through a group
+func UpdateGroupRoles(id uint, organizationRole account.AccountRole, projectRoles map[string]project.ProjectRole, organizationKey string, cfg *config.DatabaseConfig) (*scim.Group, error) {
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := _groupRepository.FindOne(id, _organization.ID)
+
+	if err != nil || group == nil {
+		return nil, errors.New("Invalid group.")
+	}
+
+	if organizationRole != "" && organizationRole != account.Admin && organizationRole != account.Guest {
+		return nil, errors.New("Invalid organization role.")
+	}
+
+	for projectKey, role := range projectRoles {
+		if _, err := project.NewProjectRole(string(role)); err != nil {
+			return nil, fmt.Errorf("Invalid role for project %q.", projectKey)
+		}
+
+		_project, err := projectService.RetrieveProject(projectKey, organizationKey, cfg)
+
+		if err != nil || _project == nil {
+			return nil, fmt.Errorf("Invalid project %q.", projectKey)
+		}
+	}
+
+	// Projects the group no longer grants a role on are synced too
+	previousProjects := projectKeysOf(group)
+
+	group.OrganizationRole = organizationRole
+	group.ProjectRoles = projectRoles
+
+	if err := _groupRepository.Update(group); err != nil {
+		return nil, err
+	}
+
+	if err := syncRoles(organizationKey, group.Members, previousProjects, cfg); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func updateAccount(_account *account.Account, payload *scim.UserResource, organizationKey string, cfg *config.DatabaseConfig) error {
+	email := strings.TrimSpace(payload.UserName)
+
+	if !strings.Contains(email, "@") {
+		return scim.NewError(http.StatusBadRequest, "invalidValue", "userName must be an email address.")
+	}
+
+	var name, newEmail *string
+
+	if resolved := resolveName(payload); resolved != "" && resolved != _account.Name {
+		name = &resolved
+	}
+
+	if email != _account.Email {
+		existing, err := accountService.RetrieveAccount(email, cfg)
+
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			return scim.ErrUniqueness
+		}
+
+		newEmail = &email
+	}
+
+	if name != nil || newEmail != nil {
+		updated, err := accountService.UpdateAccount(_account.Email, organizationKey, name, newEmail, cfg)
+
+		if err != nil {
+			return err
+		}
+
+		_account = updated
+	}
+
+	if _, err := accountService.UpdateExternalID(_account.Email, organizationKey, payload.ExternalID, cfg); err != nil {
+		return err
+	}
+
+	if payload.Active != nil {
+		if _, err := accountService.SetAccountDisabled(_account.Email, organizationKey, !*payload.Active, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateGroup(group *scim.Group, payload *scim.GroupResource, organizationKey string, cfg *config.DatabaseConfig) error {
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	displayName := strings.TrimSpace(payload.DisplayName)
+
+	if displayName == "" {
+		return scim.NewError(http.StatusBadRequest, "invalidValue", "displayName is required.")
+	}
+
+	if displayName != group.DisplayName {
+		existing, err := _groupRepository.FindOneByDisplayName(displayName, group.OrganizationID)
+
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			return scim.ErrUniqueness
+		}
+	}
+
+	memberIds, err := resolveMembers(payload.Members, organizationKey, cfg)
+
+	if err != nil {
+		return err
+	}
+
+	// Members that left lose the group's roles, members that joined gain them
+	affected := append(append([]uint{}, group.Members...), memberIds...)
+
+	group.DisplayName = displayName
+	group.ExternalID = payload.ExternalID
+	group.Members = memberIds
+
+	if err := _groupRepository.Update(group); err != nil {
+		return err
+	}
+
+	return syncRoles(organizationKey, affected, nil, cfg)
+}
+
+// syncRoles grants the accounts the roles their groups resolve to. Superadmins are left as they are
+func syncRoles(organizationKey string, accountIds []uint, extraProjects []string, cfg *config.DatabaseConfig) error {
+	groups, err := retrieveGroups(organizationKey, cfg)
+
+	if err != nil {
+		return err
+	}
+
+	seen := map[uint]bool{}
+
+	for _, accountId := range accountIds {
+		if seen[accountId] {
+			continue
+		}
+		seen[accountId] = true
+
+		_account, err := accountService.RetrieveAccountByID(accountId, cfg)
+
+		if err != nil {
+			return err
+		}
+
+		if _account == nil || _account.Role == account.SuperAdmin {
+			continue
+		}
+
+		if _account.InternalRoles == nil {
+			_account.InternalRoles = map[string]string{}
+		}
+
+		resolved := scim.ResolveRoles(groups, accountId)
+
+		if resolved.OrganizationManaged && _account.Role != resolved.OrganizationRole {
+			if _, err := accountService.UpdateAccountRole(_account.Email, organizationKey, resolved.OrganizationRole, cfg); err != nil {
+				return err
+			}
+		}
+
+		for _, projectKey := range append(resolved.ManagedProjects, extraProjects...) {
+			current := _account.InternalRoles[account.BuildRoleEntryKey(projectKey, authorization.AuthorizationDomainProject)]
+			role, granted := resolved.ProjectRoles[projectKey]
+
+			switch {
+			case granted && current != account.BuildRoleKey(projectKey, authorization.AuthorizationDomainProject, string(role)):
+				if _, err := accountService.GrantProjectRole(_account.Email, projectKey, organizationKey, role, cfg); err != nil {
+					return err
+				}
+				_account.InternalRoles[account.BuildRoleEntryKey(projectKey, authorization.AuthorizationDomainProject)] = account.BuildRoleKey(projectKey, authorization.AuthorizationDomainProject, string(role))
+			case !granted && current != "":
+				if _, err := accountService.RevokeProjectRole(_account.Email, projectKey, organizationKey, cfg); err != nil {
+					return err
+				}
+				delete(_account.InternalRoles, account.BuildRoleEntryKey(projectKey, authorization.AuthorizationDomainProject))
+			}
+		}
+	}
+
+	return nil
+}
+
+func retrieveAccount(id string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	accountId, err := strconv.ParseUint(id, 10, 64)
+
+	if err != nil {
+		return nil, scim.ErrNotFound
+	}
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_account, err := accountService.RetrieveAccountByID(uint(accountId), cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _account == nil || _account.OrganizationID != _organization.ID {
+		return nil, scim.ErrNotFound
+	}
+
+	return _account, nil
+}
+
+// retrieveManagedAccount retrieves an account the directory may change
+func retrieveManagedAccount(id string, organizationKey string, cfg *config.DatabaseConfig) (*account.Account, error) {
+	_account, err := retrieveAccount(id, organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _account.Role == account.SuperAdmin {
+		return nil, ErrSuperAdminManaged
+	}
+
+	return _account, nil
+}
+
+func retrieveGroups(organizationKey string, cfg *config.DatabaseConfig) ([]scim.Group, error) {
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := _groupRepository.Find(_organization.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *groups, nil
+}
+
+func retrieveGroup(id string, organizationKey string, cfg *config.DatabaseConfig) (*scim.Group, error) {
+	_groupRepository := database.NewSCIMGroupRepository(cfg)
+
+	groupId, err := strconv.ParseUint(id, 10, 64)
+
+	if err != nil {
+		return nil, scim.ErrNotFound
+	}
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := _groupRepository.FindOne(uint(groupId), _organization.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if group == nil {
+		return nil, scim.ErrNotFound
+	}
+
+	return group, nil
+}
+
+// retrieveMembers indexes the organization's accounts by ID
+func retrieveMembers(organizationKey string, cfg *config.DatabaseConfig) (map[uint]account.Account, error) {
+	_accounts, err := accountService.RetrieveAccounts(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members := map[uint]account.Account{}
+
+	if _accounts != nil {
+		for _, _account := range *_accounts {
+			members[_account.ID] = _account
+		}
+	}
+
+	return members, nil
+}
+
+// resolveMembers resolves the account IDs of group members, which must belong to the organization
+func resolveMembers(references []scim.Reference, organizationKey string, cfg *config.DatabaseConfig) ([]uint, error) {
+	accounts, err := retrieveMembers(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	memberIds := []uint{}
+	seen := map[uint]bool{}
+
+	for _, reference := range references {
+		accountId, err := strconv.ParseUint(reference.Value, 10, 64)
+
+		if err != nil {
+			return nil, scim.NewError(http.StatusBadRequest, "invalidValue", fmt.Sprintf("Invalid member %q.", reference.Value))
+		}
+
+		if _, ok := accounts[uint(accountId)]; !ok {
+			return nil, scim.NewError(http.StatusBadRequest, "invalidValue", fmt.Sprintf("Invalid member %q.", reference.Value))
+		}
+
+		if !seen[uint(accountId)] {
+			seen[uint(accountId)] = true
+			memberIds = append(memberIds, uint(accountId))
+		}
+	}
+
+	return memberIds, nil
+}
+
+// patchResource applies PATCH operations to a resource, decoding the result into patched
+func patchResource(resource interface{}, operations []scim.PatchOperation, patched interface{}) error {
+	raw, err := json.Marshal(resource)
+
+	if err != nil {
+		return err
+	}
+
+	var document map[string]interface{}
+
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return err
+	}
+
+	if err := scim.ApplyPatch(document, operations); err != nil {
+		return err
+	}
+
+	// Some directories send booleans as strings, e.g. "False"
+	if active, ok := document["active"].(string); ok {
+		value, err := strconv.ParseBool(strings.ToLower(active))
+
+		if err != nil {
+			return scim.ErrInvalidValue
+		}
+
+		document["active"] = value
+	}
+
+	if raw, err = json.Marshal(document); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw, patched); err != nil {
+		return scim.ErrInvalidValue
+	}
+
+	return nil
+}
+
+func buildListResponse(resources []interface{}, filter string, startIndex int, count int) (*scim.ListResponse, error) {
+	if filter != "" {
+		parsed, err := scim.ParseFilter(filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		matched := []interface{}{}
+
+		for _, resource := range resources {
+			if parsed.Matches(scim.BuildAttributes(resource)) {
+				matched = append(matched, resource)
+			}
+		}
+
+		resources = matched
+	}
+
+	if startIndex < 1 {
+		startIndex = 1
+	}
+
+	if count < 0 {
+		count = 0
+	}
+
+	if count > scim.MaxPageSize {
+		count = scim.MaxPageSize
+	}
+
+	page := []interface{}{}
+
+	if startIndex <= len(resources) {
+		end := startIndex - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+
+		page = resources[startIndex-1 : end]
+	}
+
+	return &scim.ListResponse{
+		Schemas: []string{scim.SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex: startIndex,
+		ItemsPerPage: len(page),
+		Resources: page,
+	}, nil
+}
+
+func toUserResource(_account *account.Account, groups []scim.Group, baseURL string) *scim.UserResource {
+	id := strconv.FormatUint(uint64(_account.ID), 10)
+	active := !_account.IsDisabled()
+
+	resource := &scim.UserResource{
+		Schemas: []string{scim.SchemaUser},
+		ID: id,
+		ExternalID: _account.ExternalID,
+		UserName: _account.Email,
+		Name: &scim.Name{Formatted: _account.Name},
+		DisplayName: _account.Name,
+		Emails: []scim.Email{{Value: _account.Email, Type: "work", Primary: true}},
+		Active: &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created: _account.CreatedAt,
+			LastModified: _account.UpdatedAt,
+			Location: baseURL + "/Users/" + id,
+		},
+	}
+
+	for _, group := range groups {
+		if group.HasMember(_account.ID) {
+			groupId := strconv.FormatUint(uint64(group.ID), 10)
+
+			resource.Groups = append(resource.Groups, scim.Reference{
+				Value: groupId,
+				Display: group.DisplayName,
+				Ref: baseURL + "/Groups/" + groupId,
+			})
+		}
+	}
+
+	return resource
+}
+
+func toGroupResource(group *scim.Group, members map[uint]account.Account, baseURL string) *scim.GroupResource {
+	id := strconv.FormatUint(uint64(group.ID), 10)
+
+	resource := &scim.GroupResource{
+		Schemas: []string{scim.SchemaGroup},
+		ID: id,
+		ExternalID: group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members: []scim.Reference{},
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created: group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location: baseURL + "/Groups/" + id,
+		},
+	}
+
+	for _, memberId := range group.Members {
+		memberKey := strconv.FormatUint(uint64(memberId), 10)
+
+		resource.Members = append(resource.Members, scim.Reference{
+			Value: memberKey,
+			Display: members[memberId].Email,
+			Ref: baseURL + "/Users/" + memberKey,
+		})
+	}
+
+	return resource
+}
+
+// resolveName resolves an account name from a user's display name, or its formatted or given and
+// family names. It is empty when the user has none
+func resolveName(payload *scim.UserResource) string {
+	if name := strings.TrimSpace(payload.DisplayName); name != "" {
+		return name
+	}
+
+	if payload.Name != nil {
+		if name := strings.TrimSpace(payload.Name.Formatted); name != "" {
+			return name
+		}
+
+		if name := strings.TrimSpace(payload.Name.GivenName + " " + payload.Name.FamilyName); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func removeMember(members []uint, accountId uint) []uint {
+	kept := []uint{}
+	for _, member := range members {
+		if member != accountId {
+			kept = append(kept, member)
+		}
+	}
+	return kept
+}
+
+func projectKeysOf(group *scim.Group) []string {
+	keys := []string{}
+	for projectKey := range group.ProjectRoles {
+		keys = append(keys, projectKey)
+	}
+	return keys
+}
@@ -0,0 +1,364 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/secret"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+const (
+	// rotationCheckInterval is how often the data keys are reloaded, picking up keys rotated by
+	// other instances, and checked for rotation
+	rotationCheckInterval = time.Minute
+
+	// retirementGrace is how long a retired data key is kept before what it encrypted is
+	// re-encrypted, leaving every instance time to pick up the new key
+	retirementGrace = 10 * time.Minute
+)
+
+// Reencrypter re-encrypts every stored secret not encrypted with the active data key
+type Reencrypter func() error
+
+var (
+	databaseCfg *config.DatabaseConfig
+	secretsCfg  *config.SecretsConfig
+
+	masterKey    []byte
+	masterKeyId  string
+	reencrypters []Reencrypter
+
+	mu        sync.RWMutex
+	dataKeys  map[uint][]byte
+	activeKey *secret.DataKey
+)
+
+// Initialize loads the master key and the data keys, rewrapping data keys wrapped with a previous
+// master key. The reencrypters are run whenever data keys were retired
+func Initialize(sCfg *config.SecretsConfig, dbCfg *config.DatabaseConfig, reencrypt ...Reencrypter) {
+	databaseCfg = dbCfg
+	secretsCfg = sCfg
+	reencrypters = reencrypt
+
+	var err error
+
+	masterKey, err = loadMasterKey()
+	if err != nil {
+		log.Fatalf("failed to load master key: %v", err)
+	}
+
+	masterKeyId = secret.BuildMasterKeyID(masterKey)
+
+	if err := rewrapDataKeys(); err != nil {
+		log.Fatalf("failed to rewrap data keys: %v", err)
+	}
+
+	if err := loadDataKeys(); err != nil {
+		log.Fatalf("failed to load data keys: %v", err)
+	}
+
+	if err := rotateIfDue(); err != nil {
+		log.Fatalf("failed to generate data key: %v", err)
+	}
+
+	go watchDataKeys()
+
+	log.Print("Successfully initialized secrets encryption")
+}
+
+// Encrypt encrypts a secret with the active data key. The same additional data must be given
+// to decrypt it
+func Encrypt(plaintext []byte, additionalData string) (string, error) {
+	mu.RLock()
+	_key := activeKey
+	key := dataKeys[_key.ID]
+	mu.RUnlock()
+
+	sealed, err := secret.Seal(key, plaintext, additionalData)
+	if err != nil {
+		return "", err
+	}
+
+	return secret.BuildEnvelope(_key.ID, sealed), nil
+}
+
+func Decrypt(envelope string, additionalData string) ([]byte, error) {
+	dataKeyId, sealed, err := secret.ParseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := dataKey(dataKeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret.Open(key, sealed, additionalData)
+}
+
+// IsCurrent reports whether a secret is encrypted with the active data key
+func IsCurrent(envelope string) bool {
+	dataKeyId, _, err := secret.ParseEnvelope(envelope)
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return err == nil && dataKeyId == activeKey.ID
+}
+
+// RotateDataKey generates a new data key and retires the others. What they encrypted is
+// re-encrypted once they have been retired for a while
+func RotateDataKey() error {
+	_dataKeyRepository := database.NewDataKeyRepository(databaseCfg)
+
+	key, err := secret.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	wrapped, err := secret.WrapKey(masterKey, key)
+	if err != nil {
+		return err
+	}
+
+	_key, err := _dataKeyRepository.Create(&secret.DataKey{
+		MasterKeyID: masterKeyId,
+		WrappedKey: wrapped,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := _dataKeyRepository.RetireOthers(_key.ID, time.Now()); err != nil {
+		return err
+	}
+
+	log.Printf("Rotated data key, now encrypting with data key %d", _key.ID)
+
+	return loadDataKeys()
+}
+
+func watchDataKeys() {
+	ticker := time.NewTicker(rotationCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := loadDataKeys(); err != nil {
+			log.Printf("Error reloading data keys: %v", err)
+			continue
+		}
+
+		if err := rotateIfDue(); err != nil {
+			log.Printf("Error rotating data key: %v", err)
+		}
+
+		if err := reencryptRetired(); err != nil {
+			log.Printf("Error re-encrypting secrets: %v", err)
+		}
+	}
+}
+
+func rotateIfDue() error {
+	mu.RLock()
+	_key := activeKey
+	mu.RUnlock()
+
+	if _key != nil && time.Since(_key.CreatedAt) < secretsCfg.SecretsKeyRotationInterval {
+		return nil
+	}
+
+	return RotateDataKey()
+}
+
+// reencryptRetired re-encrypts what data keys retired past the grace period encrypted,
+// then deletes them
+func reencryptRetired() error {
+	_keys, err := database.NewDataKeyRepository(databaseCfg).Find()
+	if err != nil {
+		return err
+	}
+
+	retiredBefore := time.Now().Add(-retirementGrace)
+	due := false
+
+	for _, _key := range *_keys {
+		if _key.RetiredAt != nil && _key.RetiredAt.Before(retiredBefore) {
+			due = true
+			break
+		}
+	}
+
+	if !due {
+		return nil
+	}
+
+	for _, reencrypt := range reencrypters {
+		if err := reencrypt(); err != nil {
+			return err
+		}
+	}
+
+	if err := database.NewDataKeyRepository(databaseCfg).DeleteRetired(retiredBefore); err != nil {
+		return err
+	}
+
+	log.Print("Re-encrypted secrets and deleted retired data keys")
+
+	return loadDataKeys()
+}
+
+func dataKey(id uint) ([]byte, error) {
+	mu.RLock()
+	key, ok := dataKeys[id]
+	mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	// Another instance may have rotated the data key
+	if err := loadDataKeys(); err != nil {
+		return nil, err
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if key, ok := dataKeys[id]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown data key: %d", id)
+}
+
+func loadDataKeys() error {
+	_keys, err := database.NewDataKeyRepository(databaseCfg).Find()
+	if err != nil {
+		return err
+	}
+
+	loaded := map[uint][]byte{}
+	var active *secret.DataKey
+
+	for i := range *_keys {
+		_key := &(*_keys)[i]
+
+		key, err := secret.UnwrapKey(masterKey, _key.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("invalid data key %d: %w", _key.ID, err)
+		}
+
+		loaded[_key.ID] = key
+
+		// Keys are listed newest first
+		if active == nil && _key.RetiredAt == nil {
+			active = _key
+		}
+	}
+
+	mu.Lock()
+	dataKeys = loaded
+	if active != nil {
+		activeKey = active
+	}
+	mu.Unlock()
+
+	return nil
+}
+
+// rewrapDataKeys wraps data keys wrapped with a previous master key with the current one
+func rewrapDataKeys() error {
+	_dataKeyRepository := database.NewDataKeyRepository(databaseCfg)
+
+	_keys, err := _dataKeyRepository.Find()
+	if err != nil {
+		return err
+	}
+
+	previous := map[string][]byte{}
+
+	for _, encoded := range secretsCfg.SecretsPreviousMasterKeys {
+		key, err := secret.ParseKey(encoded)
+		if err != nil {
+			return fmt.Errorf("previous master key: %w", err)
+		}
+
+		previous[secret.BuildMasterKeyID(key)] = key
+	}
+
+	for i := range *_keys {
+		_key := &(*_keys)[i]
+
+		if _key.MasterKeyID == masterKeyId {
+			continue
+		}
+
+		previousKey, ok := previous[_key.MasterKeyID]
+		if !ok {
+			return fmt.Errorf("data key %d is wrapped with unknown master key %s", _key.ID, _key.MasterKeyID)
+		}
+
+		key, err := secret.UnwrapKey(previousKey, _key.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("invalid data key %d: %w", _key.ID, err)
+		}
+
+		wrapped, err := secret.WrapKey(masterKey, key)
+		if err != nil {
+			return err
+		}
+
+		if err := _dataKeyRepository.Rewrap(_key.ID, masterKeyId, wrapped); err != nil {
+			return err
+		}
+
+		log.Printf("Rewrapped data key %d with master key %s", _key.ID, masterKeyId)
+	}
+
+	return nil
+}
+
+// loadMasterKey reads the master key from the configuration, or from the master key file,
+// generating the file on first start
+func loadMasterKey() ([]byte, error) {
+	if secretsCfg.SecretsMasterKey != "" {
+		return secret.ParseKey(secretsCfg.SecretsMasterKey)
+	}
+
+	path := secretsCfg.SecretsMasterKeyFile
+
+	encoded, err := os.ReadFile(path)
+
+	if err == nil {
+		return secret.ParseKey(string(encoded))
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key, err := secret.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(path, []byte(secret.EncodeKey(key)), 0o600); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Generated master key at %s, back it up: stored secrets cannot be decrypted without it", path)
+
+	return key, nil
+}
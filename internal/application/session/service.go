@@ -0,0 +1,57 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/authentication"
+	"github.com/darksuei/suei-intelligence/internal/domain/session"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+func RetrieveSessions(accountId uint, cfg *config.DatabaseConfig) (*[]session.Session, error) {
+	_sessionRepository := database.NewSessionRepository(cfg)
+
+	return _sessionRepository.FindActive(accountId, time.Now())
+}
+
+// RevokeSession revokes one of the account's sessions, its refresh tokens stop working immediately
+func RevokeSession(id uint, accountId uint, cfg *config.DatabaseConfig) (*session.Session, error) {
+	_sessionRepository := database.NewSessionRepository(cfg)
+
+	_session, err := _sessionRepository.FindOne(id)
+
+	if err != nil || _session == nil || _session.AccountID != accountId || !_session.IsActive(time.Now()) {
+		return nil, errors.New("Session not found.")
+	}
+
+	if err := _sessionRepository.Revoke(_session.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := RevokeAccessTokens(_session.ID); err != nil {
+		return nil, err
+	}
+
+	return _session, nil
+}
+
+// RevokeSessions signs the account out everywhere
+func RevokeSessions(accountId uint, cfg *config.DatabaseConfig) error {
+	_sessionRepository := database.NewSessionRepository(cfg)
+
+	if err := _sessionRepository.RevokeAll(accountId, time.Now()); err != nil {
+		return err
+	}
+
+	return accountService.RevokeAccessTokens(accountId, cfg)
+}
+
+// RevokeAccessTokens invalidates the access tokens issued within a session. The revocation
+// is kept for as long as those tokens can still be valid
+func RevokeAccessTokens(sessionId uint) error {
+	return cache.GetCache().Set(authentication.BuildRevokedSessionKey(sessionId), "1", authentication.AccessTokenTTL)
+}
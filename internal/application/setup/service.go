@@ -0,0 +1,161 @@
+package setup
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/domain/metadata"
+	"github.com/darksuei/suei-intelligence/internal/domain/organization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+func IsSetupCompleted(cfg *config.DatabaseConfig) (bool, error) {
+	_metadataRepository := database.NewMetadataRepository(cfg)
+
+	_metadata, err := _metadataRepository.FindOne()
+
+	if err != nil {
+		return false, err
+	}
+
+	return _metadata != nil && _metadata.IsSetupCompleted(), nil
+}
+
+// ReconcileSetup locks setup on instances that already had an organization before setup was tracked
+func ReconcileSetup(cfg *config.DatabaseConfig) error {
+	_metadataRepository := database.NewMetadataRepository(cfg)
+	_organizationRepository := database.NewOrganizationRepository(cfg)
+
+	count, err := _organizationRepository.Count()
+
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	locked, err := _metadataRepository.LockSetup(time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if locked {
+		log.Print("Existing organization found, setup locked..")
+	}
+
+	return nil
+}
+
+// CompleteSetup creates the first organization and its SUPERADMIN account, who operates the instance.
+// It requires the bootstrap token and succeeds only once per instance
+func CompleteSetup(bootstrapToken string, name string, scope string, adminName string, adminEmail string, adminPassword string, cfg *config.DatabaseConfig) (*organization.Organization, *account.Account, error) {
+	_metadataRepository := database.NewMetadataRepository(cfg)
+
+	_metadata, err := _metadataRepository.FindOne()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _metadata == nil {
+		return nil, nil, errors.New("Bootstrap token has not been loaded.")
+	}
+
+	if _metadata.IsSetupCompleted() {
+		return nil, nil, metadata.ErrSetupCompleted
+	}
+
+	if err := _metadata.CheckBootstrapToken(bootstrapToken); err != nil {
+		return nil, nil, err
+	}
+
+	// Validate everything before locking so a bad request does not burn the setup
+	if err := validateOrganization(scope, adminEmail, adminPassword, cfg); err != nil {
+		return nil, nil, err
+	}
+
+	// Lock first so concurrent requests cannot both create an organization
+	locked, err := _metadataRepository.LockSetup(time.Now())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !locked {
+		return nil, nil, metadata.ErrSetupCompleted
+	}
+
+	// The account setting up the instance operates it
+	_organization, _account, err := createOrganization(name, scope, adminName, adminEmail, adminPassword, true, cfg)
+
+	if err != nil {
+		unlockSetup(_metadataRepository)
+		return nil, nil, err
+	}
+
+	return _organization, _account, nil
+}
+
+// NewOrganization creates a further organization and its first SUPERADMIN account. It is run by
+// instance operators once setup has completed
+func NewOrganization(name string, scope string, adminName string, adminEmail string, adminPassword string, cfg *config.DatabaseConfig) (*organization.Organization, *account.Account, error) {
+	if err := validateOrganization(scope, adminEmail, adminPassword, cfg); err != nil {
+		return nil, nil, err
+	}
+
+	return createOrganization(name, scope, adminName, adminEmail, adminPassword, false, cfg)
+}
+
+func validateOrganization(scope string, adminEmail string, adminPassword string, cfg *config.DatabaseConfig) error {
+	if _, err := organization.NewOrgScope(scope); err != nil {
+		return err
+	}
+
+	if err := account.CheckPassword(adminPassword); err != nil {
+		return err
+	}
+
+	if _existing, err := accountService.RetrieveAccount(adminEmail, cfg); err != nil || _existing != nil {
+		return errors.New("Email already registered.")
+	}
+
+	return nil
+}
+
+// createOrganization stores an organization and its first SUPERADMIN account in one transaction
+func createOrganization(name string, scope string, adminName string, adminEmail string, adminPassword string, instanceOperator bool, cfg *config.DatabaseConfig) (*organization.Organization, *account.Account, error) {
+	_organization, err := organizationService.BuildOrganization(name, scope, cfg)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	internalRoleJson := map[string]string{
+		account.BuildRoleEntryKey(_organization.Key, authorization.AuthorizationDomainOrg): account.BuildRoleKey(_organization.Key, authorization.AuthorizationDomainOrg, string(account.SuperAdmin)),
+	}
+
+	_account, err := accountService.BuildAccount(adminName, adminEmail, adminPassword, account.SuperAdmin, internalRoleJson, cfg)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_account.InstanceOperator = instanceOperator
+
+	return database.NewOrganizationRepository(cfg).CreateWithAdmin(_organization, _account)
+}
+
+func unlockSetup(_metadataRepository metadata.MetadataRepository) {
+	if err := _metadataRepository.UnlockSetup(); err != nil {
+		log.Printf("Failed to unlock setup: %v", err)
+	}
+}
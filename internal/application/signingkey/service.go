@@ -0,0 +1,313 @@
+package signingkey
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/application/secret"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/authentication"
+	"github.com/darksuei/suei-intelligence/internal/domain/signingkey"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+const (
+	// rotationCheckInterval is how often the keys are reloaded, picking up keys rotated by other
+	// instances, and checked for rotation
+	rotationCheckInterval = time.Minute
+
+	// reloadCooldown limits reloading the keys on unknown key IDs, which anyone can send
+	reloadCooldown = 10 * time.Second
+
+	// verificationLeeway keeps retired keys a little longer, covering clock skew between instances
+	verificationLeeway = time.Minute
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type loadedKey struct {
+	key        *signingkey.SigningKey
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+var (
+	databaseCfg *config.DatabaseConfig
+	jwtCfg      *config.JWTConfig
+
+	mu       sync.RWMutex
+	keys     []loadedKey // <- newest first
+	loadedAt time.Time
+)
+
+// Initialize loads the signing keys, generating the first one on first start, and rotates them
+// on schedule from then on. Private keys are encrypted, so secrets encryption must be
+// initialized first
+func Initialize(jCfg *config.JWTConfig, dbCfg *config.DatabaseConfig) {
+	databaseCfg = dbCfg
+	jwtCfg = jCfg
+
+	if !signingkey.IsAlgorithm(signingkey.Algorithm(jwtCfg.JWTAlgorithm)) {
+		log.Fatalf("unsupported jwt signing algorithm: %s", jwtCfg.JWTAlgorithm)
+	}
+
+	// Encrypt private keys stored before they were encrypted
+	if err := ReencryptKeys(databaseCfg); err != nil {
+		log.Fatalf("failed to encrypt signing keys: %v", err)
+	}
+
+	if err := loadKeys(); err != nil {
+		log.Fatalf("failed to load signing keys: %v", err)
+	}
+
+	if err := rotateIfDue(); err != nil {
+		log.Fatalf("failed to generate signing key: %v", err)
+	}
+
+	go watchKeys()
+
+	log.Print("Successfully initialized signing keys")
+}
+
+// SigningKey returns the active signing key, with its parsed private key
+func SigningKey() (*signingkey.SigningKey, crypto.Signer, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, k := range keys {
+		if k.key.IsActive() {
+			return k.key, k.privateKey, nil
+		}
+	}
+
+	return nil, nil, errors.New("no active signing key")
+}
+
+// VerificationKey returns the public key with the given ID, reloading the keys once when it is
+// unknown, as happens right after another instance rotated them
+func VerificationKey(keyId string) (*signingkey.SigningKey, crypto.PublicKey, error) {
+	if k := findKey(keyId); k != nil {
+		return k.key, k.publicKey, nil
+	}
+
+	mu.RLock()
+	stale := time.Since(loadedAt) >= reloadCooldown
+	mu.RUnlock()
+
+	if stale {
+		if err := loadKeys(); err != nil {
+			return nil, nil, err
+		}
+
+		if k := findKey(keyId); k != nil {
+			return k.key, k.publicKey, nil
+		}
+	}
+
+	return nil, nil, ErrUnknownKey
+}
+
+// RetrieveJWKS returns the public keys tokens can currently be verified with
+func RetrieveJWKS() (*signingkey.JWKS, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	jwks := &signingkey.JWKS{Keys: []signingkey.JWK{}}
+
+	for _, k := range keys {
+		jwk, err := k.key.JWK()
+		if err != nil {
+			return nil, err
+		}
+
+		jwks.Keys = append(jwks.Keys, *jwk)
+	}
+
+	return jwks, nil
+}
+
+// Rotate generates a new signing key and retires the others. Retired keys keep verifying the
+// tokens they signed until those have expired. When another instance rotated the key first,
+// its key is loaded instead
+func Rotate() error {
+	var activeId uint
+
+	if _active, _, err := SigningKey(); err == nil {
+		activeId = _active.ID
+	}
+
+	_new, err := signingkey.GenerateKey(signingkey.Algorithm(jwtCfg.JWTAlgorithm))
+
+	if err != nil {
+		return err
+	}
+
+	if err := sealPrivateKey(_new, []byte(_new.PrivateKey)); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	_new, err = database.NewSigningKeyRepository(databaseCfg).Rotate(_new, activeId, now, now.Add(authentication.AccessTokenTTL+verificationLeeway))
+
+	if errors.Is(err, signingkey.ErrKeyRotated) {
+		log.Print("Signing key was rotated by another instance, reloading")
+		return loadKeys()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Rotated signing key, now signing with %s", _new.KeyID)
+
+	return loadKeys()
+}
+
+// ReencryptKeys encrypts every private key not encrypted with the active data key, including
+// those stored before private keys were encrypted
+func ReencryptKeys(cfg *config.DatabaseConfig) error {
+	_signingKeyRepository := database.NewSigningKeyRepository(cfg)
+
+	_keys, err := _signingKeyRepository.Find()
+	if err != nil {
+		return err
+	}
+
+	for i := range *_keys {
+		_key := &(*_keys)[i]
+
+		if _key.IsSealed() && secret.IsCurrent(_key.PrivateKey) {
+			continue
+		}
+
+		privateKey, err := openPrivateKey(_key)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", _key.KeyID, err)
+		}
+
+		if err := sealPrivateKey(_key, privateKey); err != nil {
+			return err
+		}
+
+		if err := _signingKeyRepository.UpdatePrivateKey(_key.ID, _key.PrivateKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func watchKeys() {
+	ticker := time.NewTicker(rotationCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := database.NewSigningKeyRepository(databaseCfg).DeleteExpired(time.Now()); err != nil {
+			log.Printf("Error deleting expired signing keys: %v", err)
+		}
+
+		if err := loadKeys(); err != nil {
+			log.Printf("Error reloading signing keys: %v", err)
+			continue
+		}
+
+		if err := rotateIfDue(); err != nil {
+			log.Printf("Error rotating signing key: %v", err)
+		}
+	}
+}
+
+// rotateIfDue rotates the signing key when there is none, when it is older than the rotation
+// interval or when the configured algorithm changed
+func rotateIfDue() error {
+	_key, _, err := SigningKey()
+
+	if err == nil && time.Since(_key.CreatedAt) < jwtCfg.JWTKeyRotationInterval && string(_key.Algorithm) == jwtCfg.JWTAlgorithm {
+		return nil
+	}
+
+	return Rotate()
+}
+
+func loadKeys() error {
+	_keys, err := database.NewSigningKeyRepository(databaseCfg).Find()
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	loaded := []loadedKey{}
+
+	for i := range *_keys {
+		_key := &(*_keys)[i]
+
+		if !_key.IsVerifiable(now) {
+			continue
+		}
+
+		privateKeyPem, err := openPrivateKey(_key)
+		if err != nil {
+			return fmt.Errorf("invalid signing key %s: %w", _key.KeyID, err)
+		}
+
+		privateKey, err := signingkey.ParsePrivateKey(privateKeyPem)
+		if err != nil {
+			return fmt.Errorf("invalid signing key %s: %w", _key.KeyID, err)
+		}
+
+		publicKey, err := _key.ParsePublicKey()
+		if err != nil {
+			return fmt.Errorf("invalid signing key %s: %w", _key.KeyID, err)
+		}
+
+		loaded = append(loaded, loadedKey{key: _key, privateKey: privateKey, publicKey: publicKey})
+	}
+
+	mu.Lock()
+	keys = loaded
+	loadedAt = now
+	mu.Unlock()
+
+	return nil
+}
+
+func findKey(keyId string) *loadedKey {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	now := time.Now()
+
+	for i := range keys {
+		if keys[i].key.KeyID == keyId && keys[i].key.IsVerifiable(now) {
+			return &keys[i]
+		}
+	}
+
+	return nil
+}
+
+// openPrivateKey decrypts the PEM encoded private key of a signing key
+func openPrivateKey(_key *signingkey.SigningKey) ([]byte, error) {
+	if !_key.IsSealed() {
+		return []byte(_key.PrivateKey), nil
+	}
+
+	return secret.Decrypt(_key.PrivateKey, _key.BuildAdditionalData())
+}
+
+func sealPrivateKey(_key *signingkey.SigningKey, privateKey []byte) error {
+	envelope, err := secret.Encrypt(privateKey, _key.BuildAdditionalData())
+	if err != nil {
+		return err
+	}
+
+	_key.PrivateKey = envelope
+
+	return nil
+}
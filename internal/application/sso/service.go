@@ -0,0 +1,296 @@
+package sso
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/domain/sso"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/oidc"
+)
+
+func RetrieveProvider(organizationKey string, cfg *config.DatabaseConfig) (*sso.Provider, error) {
+	_providerRepository := database.NewProviderRepository(cfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return _providerRepository.FindOneByOrganization(_organization.ID)
+}
+
+// ConfigureProvider creates or replaces the organization's identity provider. The client
+// secret is kept when none is given. The issuer is checked by retrieving its configuration
+func ConfigureProvider(organizationKey string, payload *sso.Provider, databaseCfg *config.DatabaseConfig, ssoCfg *config.SSOConfig) (*sso.Provider, error) {
+	_providerRepository := database.NewProviderRepository(databaseCfg)
+
+	_organization, err := organizationService.ResolveOrganization(organizationKey, databaseCfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload.Issuer = strings.TrimRight(strings.TrimSpace(payload.Issuer), "/")
+
+	if _, err := url.ParseRequestURI(payload.Issuer); err != nil {
+		return nil, errors.New("Invalid issuer.")
+	}
+
+	for value, role := range payload.RoleMapping {
+		if _, err := account.NewAccountRole(role); err != nil {
+			return nil, fmt.Errorf("Invalid role mapped for %q.", value)
+		}
+	}
+
+	if payload.DefaultRole != "" {
+		if _, err := account.NewAccountRole(string(payload.DefaultRole)); err != nil {
+			return nil, errors.New("Invalid default role.")
+		}
+	}
+
+	if len(payload.RoleMapping) > 0 && payload.RoleClaim == "" {
+		return nil, errors.New("Role claim is required with a role mapping.")
+	}
+
+	if _, err := oidc.NewClient(ssoCfg).Discover(payload.Issuer); err != nil {
+		return nil, err
+	}
+
+	_provider, err := _providerRepository.FindOneByOrganization(_organization.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload.OrganizationID = _organization.ID
+
+	if _provider == nil {
+		if payload.ClientSecret == "" {
+			return nil, errors.New("Client secret is required.")
+		}
+
+		return _providerRepository.Create(payload)
+	}
+
+	if payload.ClientSecret == "" {
+		payload.ClientSecret = _provider.ClientSecret
+	}
+
+	payload.Model = _provider.Model
+
+	if err := _providerRepository.Update(payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func DeleteProvider(organizationKey string, cfg *config.DatabaseConfig) (*sso.Provider, error) {
+	_providerRepository := database.NewProviderRepository(cfg)
+
+	_provider, err := RetrieveProvider(organizationKey, cfg)
+
+	if err != nil || _provider == nil {
+		return nil, sso.ErrProviderNotConfigured
+	}
+
+	if err := _providerRepository.Delete(_provider.ID); err != nil {
+		return nil, err
+	}
+
+	return _provider, nil
+}
+
+// BeginLogin starts an authorization code flow with PKCE, returning the identity provider
+// URL to send the user to and the state, which the browser must present on the callback
+func BeginLogin(organizationKey string, databaseCfg *config.DatabaseConfig, ssoCfg *config.SSOConfig, commonCfg *config.CommonConfig) (string, string, error) {
+	_provider, err := RetrieveProvider(organizationKey, databaseCfg)
+
+	if err != nil || _provider == nil || !_provider.Enabled {
+		return "", "", sso.ErrProviderNotConfigured
+	}
+
+	discovery, err := oidc.NewClient(ssoCfg).Discover(_provider.Issuer)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := sso.GenerateRandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := sso.GenerateRandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	codeVerifier, err := sso.GenerateRandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	loginState, err := json.Marshal(sso.LoginState{
+		OrganizationKey: organizationKey,
+		CodeVerifier: codeVerifier,
+		Nonce: nonce,
+	})
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := cache.GetCache().Set(sso.BuildLoginStateKey(state), string(loginState), sso.LoginStateTTL); err != nil {
+		return "", "", err
+	}
+
+	authorizationURL, err := url.Parse(discovery.AuthorizationEndpoint)
+
+	if err != nil {
+		return "", "", errors.New("Invalid authorization endpoint.")
+	}
+
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", _provider.ClientID)
+	query.Set("redirect_uri", CallbackURL(ssoCfg, commonCfg))
+	query.Set("scope", strings.Join(append(append([]string{}, sso.DefaultScopes...), _provider.Scopes...), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", sso.BuildCodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), state, nil
+}
+
+// CompleteLogin redeems the authorization code of a callback and resolves the account it signs
+// in, provisioning it on first sign-in and syncing its role with the role mapping
+func CompleteLogin(state string, code string, databaseCfg *config.DatabaseConfig, ssoCfg *config.SSOConfig, commonCfg *config.CommonConfig) (*account.Account, bool, error) {
+	stateKey := sso.BuildLoginStateKey(state)
+
+	// A state is redeemed at most once
+	rawLoginState, err := cache.GetCache().Take(stateKey)
+
+	if err != nil {
+		return nil, false, errors.New("Invalid or expired sign-in. Please try again.")
+	}
+
+	var loginState sso.LoginState
+
+	if err := json.Unmarshal([]byte(rawLoginState), &loginState); err != nil {
+		return nil, false, errors.New("Invalid or expired sign-in. Please try again.")
+	}
+
+	_provider, err := RetrieveProvider(loginState.OrganizationKey, databaseCfg)
+
+	if err != nil || _provider == nil || !_provider.Enabled {
+		return nil, false, sso.ErrProviderNotConfigured
+	}
+
+	client := oidc.NewClient(ssoCfg)
+
+	discovery, err := client.Discover(_provider.Issuer)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	rawIDToken, err := client.ExchangeCode(discovery, _provider.ClientID, _provider.ClientSecret, code, loginState.CodeVerifier, CallbackURL(ssoCfg, commonCfg))
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	identity, err := client.VerifyIDToken(discovery, rawIDToken, _provider.ClientID, loginState.Nonce)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	role, mapped, err := sso.ResolveRole(_provider, identity.Claims)
+
+	_account, lookupErr := accountService.RetrieveAccount(identity.Email, databaseCfg)
+
+	if lookupErr != nil {
+		return nil, false, lookupErr
+	}
+
+	// Just-in-time provisioning
+	if _account == nil {
+		if err != nil {
+			return nil, false, err
+		}
+
+		internalRoleJson := map[string]string{
+			account.BuildRoleEntryKey(loginState.OrganizationKey, authorization.AuthorizationDomainOrg): account.BuildRoleKey(loginState.OrganizationKey, authorization.AuthorizationDomainOrg, string(role)),
+		}
+
+		name := identity.Name
+		if name == "" {
+			name = identity.Email
+		}
+
+		_account, err = accountService.NewAccountWithoutPassword(name, identity.Email, role, internalRoleJson, loginState.OrganizationKey, databaseCfg)
+
+		// Account names are unique, fall back to the email when the name is taken
+		if err != nil && name != identity.Email {
+			_account, err = accountService.NewAccountWithoutPassword(identity.Email, identity.Email, role, internalRoleJson, loginState.OrganizationKey, databaseCfg)
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return _account, true, nil
+	}
+
+	// Existing accounts must belong to the organization the provider is configured for
+	_account, err = accountService.RetrieveOrganizationAccount(identity.Email, loginState.OrganizationKey, databaseCfg)
+
+	if err != nil || _account == nil {
+		return nil, false, errors.New("Account belongs to another organization.")
+	}
+
+	// Roles granted by the identity provider are kept in sync, the default role is only
+	// ever given on provisioning
+	if mapped && _account.Role != role {
+		_updated, err := accountService.UpdateAccountRole(_account.Email, loginState.OrganizationKey, role, databaseCfg)
+
+		// The last superadmin keeps their role, rather than locking the organization out
+		if errors.Is(err, account.ErrLastSuperAdmin) {
+			log.Printf("Kept role of %s, the last superadmin of %s", _account.Email, loginState.OrganizationKey)
+			return _account, false, nil
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		_account = _updated
+	}
+
+	return _account, false, nil
+}
+
+// CallbackURL is the redirect URI registered at identity providers
+func CallbackURL(ssoCfg *config.SSOConfig, commonCfg *config.CommonConfig) string {
+	if ssoCfg.SSOCallbackURL != "" {
+		return ssoCfg.SSOCallbackURL
+	}
+
+	return fmt.Sprintf("http://%s:%s/auth/sso/callback", commonCfg.AppHost, commonCfg.AppPort)
+}
@@ -0,0 +1,238 @@
+package webauthn
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/webauthn"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+func RetrieveCredentials(accountId uint, cfg *config.DatabaseConfig) (*[]webauthn.Credential, error) {
+	_credentialRepository := database.NewCredentialRepository(cfg)
+
+	return _credentialRepository.Find(accountId)
+}
+
+// HasCredentials reports whether the account registered any WebAuthn credential
+func HasCredentials(accountId uint, cfg *config.DatabaseConfig) bool {
+	_credentialRepository := database.NewCredentialRepository(cfg)
+
+	count, err := _credentialRepository.Count(accountId)
+
+	return err == nil && count > 0
+}
+
+// BeginRegistration starts a registration ceremony, returning the options for navigator.credentials.create
+func BeginRegistration(accountId uint, databaseCfg *config.DatabaseConfig, webauthnCfg *config.WebAuthnConfig) (*webauthn.CreationOptions, error) {
+	_account, err := accountService.RetrieveAccountByID(accountId, databaseCfg)
+
+	if err != nil || _account == nil {
+		return nil, errors.New("Invalid account.")
+	}
+
+	challenge, err := webauthn.GenerateChallenge()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.GetCache().Set(webauthn.BuildRegistrationKey(_account.ID), challenge, webauthnCfg.WebAuthnTimeout); err != nil {
+		return nil, err
+	}
+
+	_credentials, err := RetrieveCredentials(_account.ID, databaseCfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Authenticators already registered must not be registered twice
+	excludeCredentials := []webauthn.CredentialDescriptor{}
+
+	for _, _credential := range *_credentials {
+		excludeCredentials = append(excludeCredentials, webauthn.CredentialDescriptor{Type: "public-key", ID: _credential.CredentialID})
+	}
+
+	pubKeyCredParams := []webauthn.CredentialParameter{}
+
+	for _, alg := range webauthn.SupportedAlgorithms {
+		pubKeyCredParams = append(pubKeyCredParams, webauthn.CredentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	return &webauthn.CreationOptions{
+		RP: webauthn.RelyingPartyEntity{ID: webauthnCfg.WebAuthnRPID, Name: webauthnCfg.WebAuthnRPName},
+		User: webauthn.UserEntity{
+			ID: webauthn.BuildUserHandle(_account.ID),
+			Name: _account.Email,
+			DisplayName: _account.Name,
+		},
+		Challenge: challenge,
+		PubKeyCredParams: pubKeyCredParams,
+		Timeout: webauthnCfg.WebAuthnTimeout.Milliseconds(),
+		ExcludeCredentials: excludeCredentials,
+		AuthenticatorSelection: webauthn.AuthenticatorSelection{ResidentKey: "discouraged", UserVerification: "preferred"},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration verifies the response to a registration ceremony and stores the new credential
+func FinishRegistration(accountId uint, name string, response webauthn.RegistrationResponse, databaseCfg *config.DatabaseConfig, webauthnCfg *config.WebAuthnConfig) (*webauthn.Credential, error) {
+	_credentialRepository := database.NewCredentialRepository(databaseCfg)
+
+	key := webauthn.BuildRegistrationKey(accountId)
+
+	// A challenge is answered at most once, taking it keeps parallel responses from sharing it
+	challenge, err := cache.GetCache().Take(key)
+
+	if err != nil {
+		return nil, errors.New("No pending registration. Please restart registration.")
+	}
+
+	_credential, err := webauthn.VerifyRegistration(relyingParty(webauthnCfg), challenge, response)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := _credentialRepository.FindOneByCredentialID(_credential.CredentialID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, errors.New("Credential already registered.")
+	}
+
+	_credential.AccountID = accountId
+	_credential.Name = strings.TrimSpace(name)
+
+	if _credential.Name == "" {
+		_credential.Name = "Security key"
+	}
+
+	return _credentialRepository.Create(_credential)
+}
+
+func RenameCredential(id uint, accountId uint, name string, cfg *config.DatabaseConfig) (*webauthn.Credential, error) {
+	_credentialRepository := database.NewCredentialRepository(cfg)
+
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return nil, errors.New("Name is required.")
+	}
+
+	_credential, err := _credentialRepository.FindOne(id, accountId)
+
+	if err != nil || _credential == nil {
+		return nil, errors.New("Credential not found.")
+	}
+
+	if err := _credentialRepository.Rename(_credential.ID, name); err != nil {
+		return nil, err
+	}
+
+	_credential.Name = name
+
+	return _credential, nil
+}
+
+func DeleteCredential(id uint, accountId uint, cfg *config.DatabaseConfig) (*webauthn.Credential, error) {
+	_credentialRepository := database.NewCredentialRepository(cfg)
+
+	_credential, err := _credentialRepository.FindOne(id, accountId)
+
+	if err != nil || _credential == nil {
+		return nil, errors.New("Credential not found.")
+	}
+
+	if err := _credentialRepository.Delete(_credential.ID); err != nil {
+		return nil, err
+	}
+
+	return _credential, nil
+}
+
+// BeginAssertion starts an assertion ceremony for a login challenge, returning the options
+// for navigator.credentials.get
+func BeginAssertion(accountId uint, challengeID string, databaseCfg *config.DatabaseConfig, webauthnCfg *config.WebAuthnConfig) (*webauthn.RequestOptions, error) {
+	_credentials, err := RetrieveCredentials(accountId, databaseCfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*_credentials) == 0 {
+		return nil, errors.New("No security keys registered.")
+	}
+
+	challenge, err := webauthn.GenerateChallenge()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.GetCache().Set(webauthn.BuildAssertionKey(challengeID), challenge, webauthnCfg.WebAuthnTimeout); err != nil {
+		return nil, err
+	}
+
+	allowCredentials := []webauthn.CredentialDescriptor{}
+
+	for _, _credential := range *_credentials {
+		allowCredentials = append(allowCredentials, webauthn.CredentialDescriptor{Type: "public-key", ID: _credential.CredentialID})
+	}
+
+	return &webauthn.RequestOptions{
+		RPID: webauthnCfg.WebAuthnRPID,
+		Challenge: challenge,
+		Timeout: webauthnCfg.WebAuthnTimeout.Milliseconds(),
+		AllowCredentials: allowCredentials,
+		UserVerification: "preferred",
+	}, nil
+}
+
+// FinishAssertion verifies the response to the assertion ceremony of a login challenge
+func FinishAssertion(accountId uint, challengeID string, response webauthn.AssertionResponse, databaseCfg *config.DatabaseConfig, webauthnCfg *config.WebAuthnConfig) (*webauthn.Credential, error) {
+	_credentialRepository := database.NewCredentialRepository(databaseCfg)
+
+	key := webauthn.BuildAssertionKey(challengeID)
+
+	// A challenge is answered at most once, taking it keeps parallel responses from sharing it
+	challenge, err := cache.GetCache().Take(key)
+
+	if err != nil {
+		return nil, errors.New("No pending security key challenge. Please request one.")
+	}
+
+	_credential, err := _credentialRepository.FindOneByCredentialID(strings.TrimRight(response.ID, "="))
+
+	if err != nil || _credential == nil || _credential.AccountID != accountId {
+		return nil, errors.New("Invalid security key.")
+	}
+
+	signCount, err := webauthn.VerifyAssertion(relyingParty(webauthnCfg), challenge, _credential, response)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := _credentialRepository.RecordUse(_credential.ID, signCount, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return _credential, nil
+}
+
+func relyingParty(cfg *config.WebAuthnConfig) webauthn.RelyingParty {
+	return webauthn.RelyingParty{
+		ID: cfg.WebAuthnRPID,
+		Name: cfg.WebAuthnRPName,
+		Origins: cfg.WebAuthnOrigins,
+	}
+}
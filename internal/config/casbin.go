@@ -1,6 +1,9 @@
 package config
 
+import "time"
+
 type CasbinConfig struct {
 	ModelConfPath string `default:"./data/model.conf"`
 	PolicyCsvPath string `default:"./data/policy.csv"`
+	PolicyReloadInterval time.Duration `default:"30s"`
 }
\ No newline at end of file
@@ -6,5 +6,5 @@ type CommonConfig struct {
 	AppPort string `required:"true"`
 	BootstrapToken string `required:"true"`
 	EnforceMfa bool `required:"false"`
-	JWTSecret string `required:"true"`
+	TrustedProxies []string `required:"false"` // <- proxies allowed to set the client IP through X-Forwarded-For, none by default
 }
\ No newline at end of file
@@ -11,7 +11,15 @@ var (
 	cache	 *CacheConfig
 	casbin   *CasbinConfig
     common   *CommonConfig
+	connector *ConnectorConfig
     database *DatabaseConfig
+	datasource *DatasourceConfig
+	jwt      *JWTConfig
+	lockout  *LockoutConfig
+	notifier *NotifierConfig
+	secrets  *SecretsConfig
+	sso      *SSOConfig
+	webauthn *WebAuthnConfig
 )
 
 func Initialize() {
@@ -31,14 +39,54 @@ func Initialize() {
     if err := envconfig.Process("", common); err != nil {
 		log.Fatalf("common config: %v", err)
     }
+	connector = &ConnectorConfig{}
+	if err := envconfig.Process("", connector); err != nil {
+		log.Fatalf("connector config: %v", err)
+	}
 	database = &DatabaseConfig{}
 	if err := envconfig.Process("", database); err != nil {
 		log.Fatalf("database config: %v", err)
 	}
+	datasource = &DatasourceConfig{}
+	if err := envconfig.Process("", datasource); err != nil {
+		log.Fatalf("datasource config: %v", err)
+	}
+	jwt = &JWTConfig{}
+	if err := envconfig.Process("", jwt); err != nil {
+		log.Fatalf("jwt config: %v", err)
+	}
+	lockout = &LockoutConfig{}
+	if err := envconfig.Process("", lockout); err != nil {
+		log.Fatalf("lockout config: %v", err)
+	}
+	notifier = &NotifierConfig{}
+	if err := envconfig.Process("", notifier); err != nil {
+		log.Fatalf("notifier config: %v", err)
+	}
+	secrets = &SecretsConfig{}
+	if err := envconfig.Process("", secrets); err != nil {
+		log.Fatalf("secrets config: %v", err)
+	}
+	sso = &SSOConfig{}
+	if err := envconfig.Process("", sso); err != nil {
+		log.Fatalf("sso config: %v", err)
+	}
+	webauthn = &WebAuthnConfig{}
+	if err := envconfig.Process("", webauthn); err != nil {
+		log.Fatalf("webauthn config: %v", err)
+	}
 }
 
 func Airbyte() *AirbyteConfig     { return airbyte }
 func Cache() *CacheConfig     { return cache }
 func Casbin() *CasbinConfig     { return casbin }
 func Common() *CommonConfig     { return common }
-func Database() *DatabaseConfig { return database }
\ No newline at end of file
+func Connector() *ConnectorConfig { return connector }
+func Database() *DatabaseConfig { return database }
+func Datasource() *DatasourceConfig { return datasource }
+func JWT() *JWTConfig           { return jwt }
+func Lockout() *LockoutConfig   { return lockout }
+func Notifier() *NotifierConfig { return notifier }
+func Secrets() *SecretsConfig   { return secrets }
+func SSO() *SSOConfig           { return sso }
+func WebAuthn() *WebAuthnConfig { return webauthn }
\ No newline at end of file
@@ -0,0 +1,5 @@
+package config
+
+type ConnectorConfig struct {
+	ConnectorPluginDir string `required:"false"` // <- directory of additional connector definitions (.yaml, .yml or .json), overriding built-in ones of the same source type
+}
@@ -0,0 +1,7 @@
+package config
+
+import "time"
+
+type DatasourceConfig struct {
+	DatasourceRetention time.Duration `default:"720h"` // <- how long deleted datasources can be restored before they are purged, 0 keeps them until purged explicitly
+}
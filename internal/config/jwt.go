@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type JWTConfig struct {
+	JWTAlgorithm           string        `default:"RS256"` // RS256 or EdDSA
+	JWTIssuer              string        `default:"https://intelligence.suei.io/"`
+	JWTAudience            string        `default:"suei-intelligence"` // <- downstream services verifying our tokens expect this audience
+	JWTKeyRotationInterval time.Duration `default:"720h"`
+}
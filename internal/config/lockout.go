@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type LockoutConfig struct {
+	LockoutMaxAttempts      int           `default:"5"`   // failed attempts per account before it is locked
+	LockoutIPMaxAttempts    int           `default:"20"`  // failed attempts per client IP before it is locked
+	LockoutWindow           time.Duration `default:"15m"` // window in which failed attempts are counted
+	LockoutDuration         time.Duration `default:"15m"`
+	LockoutDelayBase        time.Duration `default:"250ms"` // delay after the first failure, doubled on each further one
+	LockoutDelayMax         time.Duration `default:"4s"`
+	MFAChallengeMaxAttempts int           `default:"5"` // codes that may be tried against a single challenge
+}
@@ -0,0 +1,16 @@
+package config
+
+import (
+	domain "github.com/darksuei/suei-intelligence/internal/domain/notifier"
+)
+
+type NotifierConfig struct {
+	NotifierType     domain.NotifierType `default:"log"`
+	NotifierFilePath string              `default:"./data/notifications.log"`
+	SMTPAddr         string              `required:"false"`
+	SMTPUsername     string              `required:"false"`
+	SMTPPassword     string              `required:"false"`
+	SMTPFrom         string              `required:"false"`
+	InvitationURL    string              `required:"false"` // e.g. https://app.example.com/invitations/accept, the token is appended as ?token=
+	PasswordResetURL string              `required:"false"` // e.g. https://app.example.com/reset-password, the token is appended as ?token=
+}
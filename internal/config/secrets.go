@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+// The master key encrypts the keys that encrypt stored secrets. To rotate it, set the new key and
+// move the old one to SecretsPreviousMasterKeys until the next start has rewrapped every data key
+type SecretsConfig struct {
+	SecretsMasterKey           string        `required:"false"` // <- base64 encoded 32 bytes, takes precedence over the key file
+	SecretsMasterKeyFile       string        `default:"./data/master.key"` // <- generated on first start when no master key is set
+	SecretsPreviousMasterKeys  []string      `required:"false"` // comma separated
+	SecretsKeyRotationInterval time.Duration `default:"2160h"` // <- how often stored secrets are re-encrypted with a new data key
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type SSOConfig struct {
+	SSOCallbackURL string        `required:"false"` // e.g. https://api.example.com/auth/sso/callback, registered at the identity provider
+	SSOFrontendURL string        `required:"false"` // e.g. https://app.example.com/sso, receives the tokens in the URL fragment. Tokens are returned as JSON when empty
+	SSOHTTPTimeout time.Duration `default:"10s"`
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type WebAuthnConfig struct {
+	WebAuthnRPID    string        `default:"localhost"` // <- the registrable domain the app is served from
+	WebAuthnRPName  string        `default:"suei-intelligence"`
+	WebAuthnOrigins []string      `default:"http://localhost:3000"` // comma separated origins allowed to run ceremonies
+	WebAuthnTimeout time.Duration `default:"5m"`
+}
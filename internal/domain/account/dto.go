@@ -6,6 +6,8 @@ type AccountDTO struct {
 	Email      string     `json:"Email"`
 	Role       AccountRole `json:"Role"`
 	MFAEnabled bool       `json:"MFAEnabled"`
+	Disabled   bool       `json:"Disabled"`
+	InstanceOperator bool `json:"InstanceOperator"`
 	CreatedAt  string	  `json:"CreatedAt"`
 	UpdatedAt  string	  `json:"UpdatedAt"`
 }
@@ -17,6 +19,8 @@ func ToAccountDTO(acc *Account) *AccountDTO {
 		Email: acc.Email,
 		Role: acc.Role,
 		MFAEnabled: acc.MFAEnabled,
+		Disabled: acc.IsDisabled(),
+		InstanceOperator: acc.InstanceOperator,
 		CreatedAt: acc.CreatedAt.String(),
 		UpdatedAt: acc.UpdatedAt.String(),
 	}
@@ -35,6 +39,8 @@ func ToAccountDTOs(accounts *[]Account) *[]AccountDTO {
 			Email:      acc.Email,
 			Role:       acc.Role,
 			MFAEnabled: acc.MFAEnabled,
+			Disabled:   acc.IsDisabled(),
+			InstanceOperator: acc.InstanceOperator,
 			CreatedAt: acc.CreatedAt.String(),
 			UpdatedAt: acc.UpdatedAt.String(),
 		}
@@ -10,6 +10,18 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrAccountDisabled = errors.New("account is disabled")
+	ErrLastSuperAdmin  = errors.New("cannot remove the last superadmin of the organization")
+	ErrEmailInUse      = errors.New("email is already in use")
+	ErrInvalidRecovery = errors.New("recovery code is invalid or already used")
+	ErrWrongPassword   = errors.New("current password is incorrect")
+	ErrInvalidTOTP     = errors.New("totp code is invalid")
+)
+
+// DeletedAccountName replaces the creator of records created by a deleted account
+const DeletedAccountName = "Deleted account"
+
 func NewAccountRole(value string) (AccountRole, error) {
 	switch AccountRole(value) {
 	case SuperAdmin, Admin, Guest:
@@ -33,6 +45,75 @@ func BuildRoleKey(entityKey string, domain authorization.AuthorizationDomain, ro
 	return fmt.Sprintf("%s_%s__%s", domain, role, entityKey)
 }
 
+// BuildRoleEntryKey builds the InternalRoles key under which an entity's role key is stored.
+// Org roles are keyed by the bare org key, project roles are prefixed so a project key
+// can never shadow an org key
+//
+// Examples:
+//  BuildRoleEntryKey("org-1", AuthorizationDomainOrg)
+//   -> "org-1"
+//
+//  BuildRoleEntryKey("project-9", AuthorizationDomainProject)
+//   -> "project:project-9"
+func BuildRoleEntryKey(entityKey string, domain authorization.AuthorizationDomain) string {
+	if domain == authorization.AuthorizationDomainOrg {
+		return entityKey
+	}
+	return fmt.Sprintf("%s:%s", domain, entityKey)
+}
+
+// BuildCustomRoleEntryKey builds the InternalRoles key under which a custom role assigned
+// on an entity is stored, alongside the entity's built-in role
+//
+// Examples:
+//  BuildCustomRoleEntryKey("org-1", AuthorizationDomainOrg, "custom-1a2b3c4d")
+//   -> "org-1/custom-1a2b3c4d"
+//
+//  BuildCustomRoleEntryKey("project-9", AuthorizationDomainProject, "custom-1a2b3c4d")
+//   -> "project:project-9/custom-1a2b3c4d"
+func BuildCustomRoleEntryKey(entityKey string, domain authorization.AuthorizationDomain, roleKey string) string {
+	return fmt.Sprintf("%s/%s", BuildRoleEntryKey(entityKey, domain), roleKey)
+}
+
+// HasRoleEntryFor reports whether an InternalRoles key belongs to the given entity entry,
+// either as its built-in role or as one of its custom roles
+func HasRoleEntryFor(key string, entryKey string) bool {
+	return key == entryKey || strings.HasPrefix(key, entryKey+"/")
+}
+
+// RolePrivilege ranks account roles, higher grants more
+func RolePrivilege(role AccountRole) int {
+	switch role {
+	case SuperAdmin:
+		return 3
+	case Admin:
+		return 2
+	case Guest:
+		return 1
+	}
+	return 0
+}
+
+func (a *Account) IsDisabled() bool {
+	return a.DisabledAt != nil
+}
+
+// BuildCreatedBy builds the creator snapshot kept on the records an account creates,
+// e.g. projects and datasources. A nil account builds the snapshot of a deleted account
+func BuildCreatedBy(a *Account) map[string]string {
+	if a == nil {
+		return map[string]string{
+			"Email": "",
+			"Name": DeletedAccountName,
+		}
+	}
+
+	return map[string]string{
+		"Email": a.Email,
+		"Name": a.Name,
+	}
+}
+
 func CheckPassword(password string) error {
 	if password == "" {
 		return errors.New("password must not be empty")
@@ -0,0 +1,91 @@
+package account
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+)
+
+func TestCheckPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		wantErr  bool
+	}{
+		{password: "Passw0rd!"},
+		{password: "Pässw0rd€"},
+		{password: "", wantErr: true},
+		{password: "Pa0!", wantErr: true},
+		{password: "passw0rd!", wantErr: true},
+		{password: "PASSW0RD!", wantErr: true},
+		{password: "Password!", wantErr: true},
+		{password: "Passw0rdd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if err := CheckPassword(tt.password); (err != nil) != tt.wantErr {
+				t.Fatalf("CheckPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildCustomRoleEntryKey(t *testing.T) {
+	tests := []struct {
+		entityKey string
+		domain    authorization.AuthorizationDomain
+		want      string
+	}{
+		{entityKey: "org-1", domain: authorization.AuthorizationDomainOrg, want: "org-1/custom-1"},
+		{entityKey: "project-9", domain: authorization.AuthorizationDomainProject, want: "project:project-9/custom-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.domain), func(t *testing.T) {
+			if got := BuildCustomRoleEntryKey(tt.entityKey, tt.domain, "custom-1"); got != tt.want {
+				t.Fatalf("BuildCustomRoleEntryKey() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasRoleEntryFor(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "project:alpha", want: true},
+		{key: "project:alpha/custom-1", want: true},
+		{key: "project:alphabet"},
+		{key: "project:beta/custom-1"},
+		{key: "alpha"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := HasRoleEntryFor(tt.key, "project:alpha"); got != tt.want {
+				t.Fatalf("HasRoleEntryFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCreatedBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		account *Account
+		want    map[string]string
+	}{
+		{name: "account", account: &Account{Email: "ada@example.com", Name: "Ada"}, want: map[string]string{"Email": "ada@example.com", "Name": "Ada"}},
+		{name: "deleted account", want: map[string]string{"Email": "", "Name": DeletedAccountName}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildCreatedBy(tt.account); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BuildCreatedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
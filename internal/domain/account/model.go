@@ -1,6 +1,8 @@
 package account
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -10,9 +12,16 @@ type Account struct {
 	Name         string `gorm:"unique;not null"`
 	Email         string `gorm:"unique;not null"`
 	PasswordEnc		string
+	PasswordChangedAt *time.Time
+	TokenVersion  uint `gorm:"not null;default:0"` // <- access tokens carrying an older version are rejected
 	Role 		AccountRole `gorm:"type:text;not null"`
 	InternalRoles map[string]string `gorm:"type:jsonb;serializer:json;default:'{}'"`
+	OrganizationID uint `gorm:"not null;default:0;index"` // <- foreign key to Organization
+	ExternalID    string // <- identifier of the account in the organization's directory (SCIM)
+	DisabledAt    *time.Time // <- disabled accounts cannot sign in
+	InstanceOperator bool `gorm:"not null;default:false"` // <- manages the instance itself, outside any organization, e.g. authorization policies
 
 	MFAEnabled    bool
 	MFASecret     string `gorm:"unique;not null"`
+	MFARecoveryCodes []string `gorm:"type:jsonb;serializer:json;default:'[]'" json:"-"` // <- hashes of the unused recovery codes
 }
\ No newline at end of file
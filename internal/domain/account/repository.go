@@ -1,8 +1,20 @@
 package account
 
+import "time"
+
 type AccountRepository interface {
-	Find() (*[]Account, error)
+	Find(organizationId uint) (*[]Account, error)
+	FindOneByID(id uint) (*Account, error)
 	FindOneByEmail(email string) (*Account, error)
+	FindOneByEmailInOrganization(email string, organizationId uint) (*Account, error)
 	Create(payload *Account) (*Account, error)
 	Update(payload *Account) error
+	IncrementTokenVersion(id uint) error
+	UpdateRecoveryCodes(id uint, hashes []string) error
+	RemoveRecoveryCode(id uint, hash string) (int, error)
+	UpdateMFA(id uint, enabled bool, secret string, hashes []string) error
+	UpdateDisabledAt(id uint, disabledAt *time.Time) error
+	UpdateExternalID(id uint, externalId string) error
+	UpdateInstanceOperator(id uint, operator bool) error
+	Erase(id uint, erasedAt time.Time) error
 }
\ No newline at end of file
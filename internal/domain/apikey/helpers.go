@@ -0,0 +1,119 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+)
+
+// Keys look like "sk_1a2b3c4d_<secret>", the part before the secret is the key's prefix
+const KeyPrefix = "sk_"
+
+const (
+	DefaultTTL = 90 * 24 * time.Hour
+	MaxTTL     = 365 * 24 * time.Hour
+
+	// LastUsedInterval is how often the last use of a key is recorded at most
+	LastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrExpired    = errors.New("api key has expired")
+	ErrRevoked    = errors.New("api key has been revoked")
+)
+
+// GenerateKey returns a new key, its prefix and the hash it is stored under
+func GenerateKey() (string, string, string, error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix := KeyPrefix + hex.EncodeToString(id)
+	key := prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, prefix, HashKey(key), nil
+}
+
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyKey compares a key against the hash it is stored under in constant time
+func VerifyKey(key string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashKey(key)), []byte(hash)) == 1
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, KeyPrefix)
+}
+
+// ParsePrefix returns the prefix of a key, e.g. "sk_1a2b3c4d_<secret>" -> "sk_1a2b3c4d"
+func ParsePrefix(key string) (string, error) {
+	if !IsAPIKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(key, KeyPrefix), "_")
+
+	if !ok || prefix == "" {
+		return "", ErrInvalidKey
+	}
+
+	return KeyPrefix + prefix, nil
+}
+
+// BuildRoleSubject builds the Casbin subject holding a key's permissions, e.g. "project_apikey-12"
+func BuildRoleSubject(id uint) string {
+	return authorization.BuildRoleSubject(authorization.AuthorizationDomainProject, fmt.Sprintf("apikey-%d", id))
+}
+
+// BuildRoleKey builds the role a key authenticates with, which only applies to its project,
+// e.g. "project_apikey-12__project-9"
+func (k *APIKey) BuildRoleKey() string {
+	return fmt.Sprintf("%s__%s", BuildRoleSubject(k.ID), k.ProjectKey)
+}
+
+func (k *APIKey) Check(now time.Time) error {
+	if k.RevokedAt != nil {
+		return ErrRevoked
+	}
+
+	if !now.Before(k.ExpiresAt) {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// ValidatePermissions checks that permissions are valid project permissions, keys cannot be
+// granted organization permissions
+func ValidatePermissions(permissions []authorization.Permission) error {
+	if err := authorization.ValidatePermissions(permissions); err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if authorization.ObjectDomains[permission.Object] != authorization.AuthorizationDomainProject {
+			return fmt.Errorf("not a project permission: %s", permission.Object)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package apikey
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+)
+
+func TestGenerateKey(t *testing.T) {
+	key, prefix, hash, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	if !regexp.MustCompile(`^sk_[0-9a-f]{8}_[A-Za-z0-9_-]{43}$`).MatchString(key) {
+		t.Fatalf("key %q is not formatted as sk_<id>_<secret>", key)
+	}
+
+	if parsed, err := ParsePrefix(key); err != nil || parsed != prefix {
+		t.Fatalf("ParsePrefix() = %q, %v, want %q", parsed, err, prefix)
+	}
+
+	if !VerifyKey(key, hash) {
+		t.Fatal("VerifyKey() rejected the generated key")
+	}
+
+	if other, _, _, _ := GenerateKey(); other == key {
+		t.Fatal("GenerateKey() returned the same key twice")
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "sk_1a2b3c4d_secret", want: "sk_1a2b3c4d"},
+		{key: "sk_1a2b3c4d_secret_with_underscores", want: "sk_1a2b3c4d"},
+		{key: "sk_1a2b3c4d", wantErr: true},
+		{key: "sk__secret", wantErr: true},
+		{key: "pk_1a2b3c4d_secret", wantErr: true},
+		{key: "Bearer eyJhbGciOi", wantErr: true},
+		{key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := ParsePrefix(tt.key)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidKey) {
+					t.Fatalf("ParsePrefix() error = %v, want %v", err, ErrInvalidKey)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Fatalf("ParsePrefix() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyKey(t *testing.T) {
+	hash := HashKey("sk_1a2b3c4d_secret")
+
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{name: "the key", key: "sk_1a2b3c4d_secret", want: true},
+		{name: "another secret", key: "sk_1a2b3c4d_secreT"},
+		{name: "the prefix only", key: "sk_1a2b3c4d"},
+		{name: "the hash itself", key: hash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyKey(tt.key, hash); got != tt.want {
+				t.Fatalf("VerifyKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		key     APIKey
+		wantErr error
+	}{
+		{name: "valid", key: APIKey{ExpiresAt: now.Add(time.Hour)}},
+		{name: "expired", key: APIKey{ExpiresAt: now.Add(-time.Hour)}, wantErr: ErrExpired},
+		{name: "expiring now", key: APIKey{ExpiresAt: now}, wantErr: ErrExpired},
+		{name: "revoked", key: APIKey{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}, wantErr: ErrRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.Check(now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []authorization.Permission
+		wantErr     bool
+	}{
+		{name: "project permissions", permissions: []authorization.Permission{{Object: authorization.Datasource, Action: "write"}, {Object: authorization.Project, Action: "read"}}},
+		{name: "no permissions", permissions: []authorization.Permission{}, wantErr: true},
+		{name: "an organization permission", permissions: []authorization.Permission{{Object: authorization.Account, Action: "read"}}, wantErr: true},
+		{name: "an unknown action", permissions: []authorization.Permission{{Object: authorization.Datasource, Action: "delete"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePermissions(tt.permissions); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePermissions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package apikey
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+)
+
+// APIKey authenticates an integration within a single project, with a subset of the
+// permissions of the account that created it
+type APIKey struct {
+	gorm.Model
+
+	OrganizationID uint                       `gorm:"not null;index"` // <- foreign key to Organization
+	ProjectKey     string                     `gorm:"not null;index"`
+	Name           string                     `gorm:"not null"`
+	Prefix         string                     `gorm:"unique;not null"` // <- identifies the key, e.g. "sk_1a2b3c4d"
+	KeyHash        string                     `gorm:"not null" json:"-"` // <- SHA-256 of the key, which is only shown once
+	Permissions    []authorization.Permission `gorm:"type:jsonb;serializer:json;default:'[]'"`
+	CreatedBy      string                     `gorm:"not null"` // <- email of the creator
+	ExpiresAt      time.Time                  `gorm:"not null"`
+	LastUsedAt     *time.Time
+	RevokedAt      *time.Time
+}
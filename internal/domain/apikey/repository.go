@@ -0,0 +1,13 @@
+package apikey
+
+import "time"
+
+type APIKeyRepository interface {
+	Find(organizationId uint, projectKey string) (*[]APIKey, error)
+	FindOne(id uint, organizationId uint) (*APIKey, error)
+	FindOneByPrefix(prefix string) (*APIKey, error)
+	Create(payload *APIKey) (*APIKey, error)
+	Revoke(id uint, revokedAt time.Time) error
+	RecordUse(id uint, usedAt time.Time) error
+	ReassignCreatedBy(organizationId uint, email string, createdBy string) error
+}
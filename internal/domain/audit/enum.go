@@ -0,0 +1,110 @@
+package audit
+
+type AuditAction string
+
+const (
+	// Setup
+	SetupCompleted AuditAction = "setup.completed"
+
+	// Organization
+	OrganizationCreated AuditAction = "organization.created"
+	OrganizationUpdated AuditAction = "organization.updated"
+	LanguageUpdated     AuditAction = "organization.language_updated"
+	SSOConfigured       AuditAction = "organization.sso_configured"
+	SSODeleted          AuditAction = "organization.sso_deleted"
+	SCIMTokenCreated    AuditAction = "organization.scim_token_created"
+	SCIMTokenRevoked    AuditAction = "organization.scim_token_revoked"
+
+	// Account
+	AccountUpdated         AuditAction = "account.updated"
+	PasswordChanged        AuditAction = "account.password_changed"
+	PasswordResetRequested AuditAction = "account.password_reset_requested"
+	PasswordReset          AuditAction = "account.password_reset"
+	AccountUnlocked        AuditAction = "account.unlocked"
+	AccountProvisioned     AuditAction = "account.provisioned"
+	AccountDeprovisioned   AuditAction = "account.deprovisioned"
+	AccountDisabled        AuditAction = "account.disabled"
+	AccountEnabled         AuditAction = "account.enabled"
+	AccountDeleted         AuditAction = "account.deleted"
+	OwnershipTransferred   AuditAction = "account.ownership_transferred"
+
+	// Directory
+	GroupProvisioned  AuditAction = "directory.group_provisioned"
+	GroupUpdated      AuditAction = "directory.group_updated"
+	GroupDeleted      AuditAction = "directory.group_deleted"
+	GroupRolesUpdated AuditAction = "directory.group_roles_updated"
+
+	// Invitation
+	InvitationCreated  AuditAction = "invitation.created"
+	InvitationRevoked  AuditAction = "invitation.revoked"
+	InvitationAccepted AuditAction = "invitation.accepted"
+
+	// Project
+	ProjectCreated       AuditAction = "project.created"
+	ProjectUpdated       AuditAction = "project.updated"
+	ProjectStatusChanged AuditAction = "project.status_changed"
+	ProjectRoleGranted   AuditAction = "project.role_granted"
+	ProjectRoleRevoked   AuditAction = "project.role_revoked"
+	APIKeyCreated        AuditAction = "project.api_key_created"
+	APIKeyRevoked        AuditAction = "project.api_key_revoked"
+
+	// Datasource
+	DatasourceCreated       AuditAction = "datasource.created"
+	DatasourceUpdated       AuditAction = "datasource.updated"
+	DatasourceDeleted       AuditAction = "datasource.deleted"
+	DatasourceRestored      AuditAction = "datasource.restored"
+	DatasourcePurged        AuditAction = "datasource.purged"
+	SchemaMappingUpdated    AuditAction = "datasource.schema_mapping_updated"
+
+	// Authorization
+	PolicyAdded              AuditAction = "authorization.policy_added"
+	PolicyRemoved            AuditAction = "authorization.policy_removed"
+	RoleInheritanceAdded     AuditAction = "authorization.role_inheritance_added"
+	RoleInheritanceRemoved   AuditAction = "authorization.role_inheritance_removed"
+	RoleCreated              AuditAction = "authorization.role_created"
+	RoleUpdated              AuditAction = "authorization.role_updated"
+	RoleDeleted              AuditAction = "authorization.role_deleted"
+	RoleAssigned             AuditAction = "authorization.role_assigned"
+	RoleUnassigned           AuditAction = "authorization.role_unassigned"
+
+	// Authentication
+	LoginSucceeded       AuditAction = "auth.login_succeeded"
+	LoginFailed          AuditAction = "auth.login_failed"
+	MFASucceeded         AuditAction = "auth.mfa_succeeded"
+	MFAFailed            AuditAction = "auth.mfa_failed"
+	MFAEnabled           AuditAction = "auth.mfa_enabled"
+	TokenRefreshed       AuditAction = "auth.token_refreshed"
+	TokenRefreshFailed   AuditAction = "auth.token_refresh_failed"
+	TokenRevoked         AuditAction = "auth.token_revoked"
+	TokenReused          AuditAction = "auth.token_reused"
+	LoggedOut            AuditAction = "auth.logged_out"
+	SessionRevoked       AuditAction = "auth.session_revoked"
+	SessionsRevoked      AuditAction = "auth.sessions_revoked"
+	LockedOut            AuditAction = "auth.locked_out"
+	LockoutRejected      AuditAction = "auth.lockout_rejected"
+	MFAChallengeExhausted AuditAction = "auth.mfa_challenge_exhausted"
+	RecoveryCodeUsed     AuditAction = "auth.recovery_code_used"
+	RecoveryCodesRegenerated AuditAction = "auth.recovery_codes_regenerated"
+	MFAReEnrolled        AuditAction = "auth.mfa_reenrolled"
+	MFADisabled          AuditAction = "auth.mfa_disabled"
+	MFAReset             AuditAction = "auth.mfa_reset"
+	WebAuthnRegistered   AuditAction = "auth.webauthn_registered"
+	WebAuthnRenamed      AuditAction = "auth.webauthn_renamed"
+	WebAuthnRemoved      AuditAction = "auth.webauthn_removed"
+	SSOLoginSucceeded    AuditAction = "auth.sso_login_succeeded"
+	SSOLoginFailed       AuditAction = "auth.sso_login_failed"
+)
+
+type AuditOutcome string
+
+const (
+	Success AuditOutcome = "success"
+	Failure AuditOutcome = "failure"
+)
+
+type AuditExportFormat string
+
+const (
+	ExportCSV   AuditExportFormat = "csv"
+	ExportJSONL AuditExportFormat = "jsonl"
+)
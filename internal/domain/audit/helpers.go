@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ignoredFields are bookkeeping fields left out of diffs
+var ignoredFields = map[string]bool{
+	"CreatedAt": true,
+	"UpdatedAt": true,
+	"DeletedAt": true,
+}
+
+// redactedFields are secrets whose values must never be written to the audit log
+var redactedFields = map[string]bool{
+	"PasswordEnc": true,
+	"MFASecret":   true,
+}
+
+const redacted = "[REDACTED]"
+
+// BuildChanges diffs the JSON representation of before and after, field by field.
+// Either side may be nil, e.g. before is nil for a create and after is nil for a delete
+func BuildChanges(before interface{}, after interface{}) map[string]Change {
+	beforeFields := toFields(before)
+	afterFields := toFields(after)
+
+	changes := map[string]Change{}
+
+	for field := range mergeKeys(beforeFields, afterFields) {
+		if ignoredFields[field] {
+			continue
+		}
+
+		b, a := beforeFields[field], afterFields[field]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+
+		if redactedFields[field] {
+			b, a = redacted, redacted
+		}
+
+		changes[field] = Change{Before: b, After: a}
+	}
+
+	return changes
+}
+
+// CSVHeader lists the columns of a CSV export
+var CSVHeader = []string{"id", "created_at", "actor_id", "actor_email", "action", "outcome", "target_type", "target_id", "project_key", "changes", "ip", "user_agent"}
+
+// ToCSVRecord converts an event into a CSV export row matching CSVHeader
+func ToCSVRecord(e AuditEvent) []string {
+	changes, _ := json.Marshal(e.Changes)
+
+	return []string{
+		fmt.Sprintf("%d", e.ID),
+		e.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		e.ActorID,
+		e.ActorEmail,
+		string(e.Action),
+		string(e.Outcome),
+		e.TargetType,
+		e.TargetID,
+		e.ProjectKey,
+		string(changes),
+		e.IP,
+		e.UserAgent,
+	}
+}
+
+func NewExportFormat(value string) (AuditExportFormat, error) {
+	switch AuditExportFormat(strings.ToLower(value)) {
+	case ExportCSV, ExportJSONL:
+		return AuditExportFormat(strings.ToLower(value)), nil
+	default:
+		return "", fmt.Errorf("invalid export format: %s", value)
+	}
+}
+
+func toFields(v interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return map[string]interface{}{"value": v}
+	}
+
+	return fields
+}
+
+func mergeKeys(a map[string]interface{}, b map[string]interface{}) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
+
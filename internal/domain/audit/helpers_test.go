@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildChanges(t *testing.T) {
+	type record struct {
+		Name        string
+		PasswordEnc string
+		UpdatedAt   string
+	}
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   map[string]Change
+	}{
+		{
+			name: "a create",
+			after: &record{Name: "Ada"},
+			want: map[string]Change{
+				"Name": {After: "Ada"},
+				"PasswordEnc": {Before: redacted, After: redacted},
+			},
+		},
+		{
+			name: "an update",
+			before: &record{Name: "Ada", UpdatedAt: "monday"},
+			after: &record{Name: "Grace", UpdatedAt: "tuesday"},
+			want: map[string]Change{"Name": {Before: "Ada", After: "Grace"}},
+		},
+		{
+			name: "a changed secret",
+			before: &record{PasswordEnc: "old"},
+			after: &record{PasswordEnc: "new"},
+			want: map[string]Change{"PasswordEnc": {Before: redacted, After: redacted}},
+		},
+		{
+			name: "a delete of a nil pointer",
+			before: (*record)(nil),
+			want: map[string]Change{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildChanges(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BuildChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewExportFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    AuditExportFormat
+		wantErr bool
+	}{
+		{value: "csv", want: ExportCSV},
+		{value: "JSONL", want: ExportJSONL},
+		{value: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := NewExportFormat(tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("NewExportFormat() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"time"
+)
+
+// AuditEvent is an append-only record of a mutating action or an auth event
+type AuditEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	OrganizationKey string       `gorm:"index"`
+	ActorID    string            `gorm:"index"`
+	ActorEmail string            `gorm:"index"`
+	Action     AuditAction       `gorm:"type:text;not null;index"`
+	Outcome    AuditOutcome      `gorm:"type:text;not null"`
+	TargetType string            `gorm:"index"`
+	TargetID   string            `gorm:"index"`
+	ProjectKey string            `gorm:"index"`
+	Changes    map[string]Change `gorm:"type:jsonb;serializer:json;default:'{}'"`
+	IP         string
+	UserAgent  string
+}
+
+// Change holds the before and after value of a single changed field
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditFilter struct {
+	OrganizationKey string
+	ActorEmail string
+	Action     string
+	TargetType string
+	TargetID   string
+	ProjectKey string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,7 @@
+package audit
+
+// AuditRepository is append-only, events are never updated or deleted
+type AuditRepository interface {
+	Find(filter AuditFilter) (*[]AuditEvent, error)
+	Create(payload *AuditEvent) (*AuditEvent, error)
+}
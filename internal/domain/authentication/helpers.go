@@ -5,26 +5,76 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/darksuei/suei-intelligence/internal/domain/notifier"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 func GenerateJWT(p JWTParams) (string, error) {
 	now := time.Now()
 
 	claims := jwt.MapClaims{
+		"jti":   uuid.New().String(),
 		"sub":   p.Subject,
 		"email": p.Email,
+		"org":   p.Organization,
+		"sid":   p.Session,
+		"ver":   p.Version,
 		"roles": p.Roles,
-		"iss":   "https://intelligence.suei.io/",
+		"iss":   p.Issuer,
+		"aud":   p.Audience,
 		"iat":   now.Unix(),
 		"exp":   now.Add(p.TTL).Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(p.Method, claims)
+	token.Header["kid"] = p.KeyID
+
+	return token.SignedString(p.SigningKey)
+}
+
+// ParseAccessTokenClaims reads the claims used to check an access token for revocation.
+// Tokens issued before a claim existed report its zero value
+func ParseAccessTokenClaims(claims jwt.MapClaims) AccessTokenClaims {
+	// JWT numeric claims are decoded as float64
+	number := func(name string) uint {
+		if v, ok := claims[name].(float64); ok && v > 0 {
+			return uint(v)
+		}
+		return 0
+	}
+
+	id, _ := claims["jti"].(string)
 
-	return token.SignedString(p.SecretKey)
+	var expiresAt time.Time
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+
+	return AccessTokenClaims{
+		ID:        id,
+		Subject:   number("sub"),
+		Session:   number("sid"),
+		Version:   number("ver"),
+		ExpiresAt: expiresAt,
+	}
+}
+
+func BuildRevokedAccessTokenKey(id string) string {
+	return fmt.Sprintf("revoked-access-token-%s", id)
+}
+
+func BuildRevokedSessionKey(sessionId uint) string {
+	return fmt.Sprintf("revoked-session-%d", sessionId)
+}
+
+func BuildTokenVersionKey(accountId uint) string {
+	return fmt.Sprintf("token-version-%d", accountId)
 }
 
 func GenerateRefreshToken() (raw string, hash string, err error) {
@@ -43,4 +93,27 @@ func GenerateRefreshToken() (raw string, hash string, err error) {
 func HashRefreshToken(raw string) string {
 	sum := sha256.Sum256([]byte(raw))
 	return hex.EncodeToString(sum[:])
-}
\ No newline at end of file
+}
+
+// PasswordResetTTL is how long a password reset token can be used after it is sent
+const PasswordResetTTL = 30 * time.Minute
+
+// BuildPasswordResetMessage builds the notification that delivers a password reset token.
+// When resetURL is set the token is appended to it as a link
+func BuildPasswordResetMessage(email string, token string, resetURL string, expiresAt time.Time) notifier.Message {
+	reset := fmt.Sprintf("Reset token: %s", token)
+
+	if resetURL != "" {
+		reset = fmt.Sprintf("Reset your password: %s?token=%s", resetURL, url.QueryEscape(token))
+	}
+
+	return notifier.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"A password reset was requested for your account.\n\n%s\n\nIt expires on %s. If you did not request it, you can ignore this message.",
+			reset,
+			expiresAt.UTC().Format(time.RFC1123),
+		),
+	}
+}
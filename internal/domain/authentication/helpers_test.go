@@ -0,0 +1,56 @@
+package authentication
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseAccessTokenClaims(t *testing.T) {
+	expiresAt := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+
+	tests := []struct {
+		name   string
+		claims string
+		want   AccessTokenClaims
+	}{
+		{
+			name: "current token",
+			claims: `{"jti":"id","sub":7,"sid":3,"ver":2,"exp":` + strconv.FormatInt(expiresAt.Unix(), 10) + `}`,
+			want: AccessTokenClaims{ID: "id", Subject: 7, Session: 3, Version: 2, ExpiresAt: expiresAt},
+		},
+		{
+			name: "token issued before versions",
+			claims: `{"jti":"id","sub":7,"sid":3,"exp":` + strconv.FormatInt(expiresAt.Unix(), 10) + `}`,
+			want: AccessTokenClaims{ID: "id", Subject: 7, Session: 3, ExpiresAt: expiresAt},
+		},
+		{
+			name: "token issued before sessions and ids",
+			claims: `{"sub":7}`,
+			want: AccessTokenClaims{Subject: 7},
+		},
+		{
+			name: "claims of the wrong type",
+			claims: `{"jti":1,"sub":"7","sid":-3,"ver":"2"}`,
+			want: AccessTokenClaims{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{}
+			if err := json.Unmarshal([]byte(tt.claims), &claims); err != nil {
+				t.Fatalf("invalid claims: %v", err)
+			}
+
+			got := ParseAccessTokenClaims(claims)
+
+			if got.ID != tt.want.ID || got.Subject != tt.want.Subject || got.Session != tt.want.Session || got.Version != tt.want.Version || !got.ExpiresAt.Equal(tt.want.ExpiresAt) {
+				t.Fatalf("ParseAccessTokenClaims() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
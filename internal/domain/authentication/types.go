@@ -1,13 +1,34 @@
 package authentication
 
-import "time"
+import (
+	"crypto"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
 
 type JWTParams struct {
 	Subject   uint
 	Email     string
+	Organization string
+	Session   uint
+	Version   uint
 	Roles     []string
 	Issuer    string
 	Audience  string
 	TTL       time.Duration
-	SecretKey []byte
+	KeyID     string // <- "kid" header, identifies the key in the JWKS
+	Method    jwt.SigningMethod
+	SigningKey crypto.Signer
+}
+
+// AccessTokenTTL is how long an access token is valid, and so how long its revocation must be remembered
+const AccessTokenTTL = time.Hour
+
+type AccessTokenClaims struct {
+	ID        string
+	Subject   uint
+	Session   uint
+	Version   uint
+	ExpiresAt time.Time
 }
\ No newline at end of file
@@ -5,6 +5,12 @@ type AuthorizationObject string
 const (
 	Organization  AuthorizationObject = "organization"
 	Project  AuthorizationObject = "project"
+	Account  AuthorizationObject = "account"
+	Audit  AuthorizationObject = "audit"
+	Datasource  AuthorizationObject = "datasource"
+	SchemaMapping  AuthorizationObject = "schema-mapping"
+	Alert  AuthorizationObject = "alert"
+	Rule  AuthorizationObject = "rule"
 )
 
 type AuthorizationDomain string
@@ -12,4 +18,18 @@ type AuthorizationDomain string
 const (
 	AuthorizationDomainOrg     AuthorizationDomain = "org"
 	AuthorizationDomainProject AuthorizationDomain = "project"
-)
\ No newline at end of file
+)
+
+// ObjectDomains maps each object to the domain its policies live in
+var ObjectDomains = map[AuthorizationObject]AuthorizationDomain{
+	Organization:  AuthorizationDomainOrg,
+	Account:       AuthorizationDomainOrg,
+	Audit:         AuthorizationDomainOrg,
+	Project:       AuthorizationDomainProject,
+	Datasource:    AuthorizationDomainProject,
+	SchemaMapping: AuthorizationDomainProject,
+	Alert:         AuthorizationDomainProject,
+	Rule:          AuthorizationDomainProject,
+}
+
+var Actions = []string{"read", "write", "admin"}
\ No newline at end of file
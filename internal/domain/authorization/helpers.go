@@ -0,0 +1,110 @@
+package authorization
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NewPolicy builds a stored policy from a Casbin ptype and rule values
+func NewPolicy(ptype string, rule []string) Policy {
+	policy := Policy{Ptype: ptype}
+
+	values := []*string{&policy.V0, &policy.V1, &policy.V2, &policy.V3, &policy.V4, &policy.V5}
+	for i, v := range rule {
+		if i >= len(values) {
+			break
+		}
+		*values[i] = v
+	}
+
+	return policy
+}
+
+// ToRule converts a stored policy into Casbin rule values, dropping trailing empty values
+func (p *Policy) ToRule() []string {
+	rule := []string{p.V0, p.V1, p.V2, p.V3, p.V4, p.V5}
+
+	for len(rule) > 0 && rule[len(rule)-1] == "" {
+		rule = rule[:len(rule)-1]
+	}
+
+	return rule
+}
+
+func ValidatePolicyRule(rule PolicyRule) error {
+	switch AuthorizationDomain(rule.Domain) {
+	case AuthorizationDomainOrg, AuthorizationDomainProject:
+	default:
+		return fmt.Errorf("invalid domain: %s", rule.Domain)
+	}
+
+	if !isAction(rule.Action) {
+		return fmt.Errorf("invalid action: %s", rule.Action)
+	}
+
+	return nil
+}
+
+func ValidatePermissions(permissions []Permission) error {
+	if len(permissions) == 0 {
+		return errors.New("a role requires at least one permission")
+	}
+
+	for _, permission := range permissions {
+		if _, ok := ObjectDomains[permission.Object]; !ok {
+			return fmt.Errorf("invalid object: %s", permission.Object)
+		}
+
+		if !isAction(permission.Action) {
+			return fmt.Errorf("invalid action: %s", permission.Action)
+		}
+	}
+
+	return nil
+}
+
+// BuildRoleSubject builds the Casbin subject of a role in a domain, e.g. "project_custom-1a2b3c4d"
+func BuildRoleSubject(domain AuthorizationDomain, roleKey string) string {
+	return fmt.Sprintf("%s_%s", domain, roleKey)
+}
+
+// BuildRolePolicies builds the Casbin policies granting a custom role its permissions
+// when assigned in the given domain.
+// Roles assigned on an org apply to every project, so they carry both org and project
+// permissions. Roles assigned on a project only carry project permissions
+//
+// Examples:
+//  BuildRolePolicies("org_custom-1a2b3c4d", AuthorizationDomainOrg, [{datasource write}])
+//   -> [["org_custom-1a2b3c4d", "project", "datasource", "write"]]
+func BuildRolePolicies(subject string, domain AuthorizationDomain, permissions []Permission) [][]string {
+	policies := [][]string{}
+
+	for _, permission := range permissions {
+		objectDomain := ObjectDomains[permission.Object]
+
+		if domain == AuthorizationDomainProject && objectDomain != AuthorizationDomainProject {
+			continue
+		}
+
+		policies = append(policies, []string{subject, string(objectDomain), string(permission.Object), permission.Action})
+	}
+
+	return policies
+}
+
+func isAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateRoleInheritanceRule(rule RoleInheritanceRule) error {
+	if rule.Role == rule.InheritedRole {
+		return errors.New("a role cannot inherit itself")
+	}
+
+	return nil
+}
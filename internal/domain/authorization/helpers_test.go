@@ -0,0 +1,105 @@
+package authorization
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		rule []string
+	}{
+		{name: "a policy", rule: []string{"org_admin", "org", "organization", "read"}},
+		{name: "a role inheritance", rule: []string{"org_superadmin", "project_owner"}},
+		{name: "empty values", rule: []string{"org_admin", "", "organization"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewPolicy("p", tt.rule)
+
+			if got := policy.ToRule(); !reflect.DeepEqual(got, tt.rule) {
+				t.Fatalf("ToRule() = %v, want %v", got, tt.rule)
+			}
+		})
+	}
+}
+
+func TestValidatePolicyRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    PolicyRule
+		wantErr bool
+	}{
+		{name: "an org policy", rule: PolicyRule{Role: "org_admin", Domain: "org", Object: "organization", Action: "read"}},
+		{name: "a project policy", rule: PolicyRule{Role: "project_owner", Domain: "project", Object: "project", Action: "admin"}},
+		{name: "an unknown domain", rule: PolicyRule{Role: "org_admin", Domain: "instance", Object: "organization", Action: "read"}, wantErr: true},
+		{name: "an unknown action", rule: PolicyRule{Role: "org_admin", Domain: "org", Object: "organization", Action: "delete"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePolicyRule(tt.rule); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePolicyRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []Permission
+		wantErr     bool
+	}{
+		{name: "permissions", permissions: []Permission{{Object: Datasource, Action: "write"}, {Object: Account, Action: "read"}}},
+		{name: "no permissions", wantErr: true},
+		{name: "an unknown object", permissions: []Permission{{Object: "instance", Action: "read"}}, wantErr: true},
+		{name: "an unknown action", permissions: []Permission{{Object: Datasource, Action: "delete"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePermissions(tt.permissions); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePermissions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildRolePolicies(t *testing.T) {
+	permissions := []Permission{{Object: Datasource, Action: "write"}, {Object: Account, Action: "read"}}
+
+	tests := []struct {
+		name   string
+		domain AuthorizationDomain
+		want   [][]string
+	}{
+		{
+			name: "assigned on an org",
+			domain: AuthorizationDomainOrg,
+			want: [][]string{
+				{"org_custom-1", "project", "datasource", "write"},
+				{"org_custom-1", "org", "account", "read"},
+			},
+		},
+		{
+			name: "assigned on a project",
+			domain: AuthorizationDomainProject,
+			want: [][]string{
+				{"project_custom-1", "project", "datasource", "write"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject := BuildRoleSubject(tt.domain, "custom-1")
+
+			if got := BuildRolePolicies(subject, tt.domain, permissions); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BuildRolePolicies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
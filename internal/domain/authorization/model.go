@@ -0,0 +1,36 @@
+package authorization
+
+import (
+	"gorm.io/gorm"
+)
+
+// Policy is a single Casbin rule, e.g. "p, org_admin, org, organization, read"
+// or "g2, org_superadmin, project_owner"
+type Policy struct {
+	gorm.Model
+
+	Ptype string `gorm:"not null;index"`
+	V0    string `gorm:"not null;default:''"`
+	V1    string `gorm:"not null;default:''"`
+	V2    string `gorm:"not null;default:''"`
+	V3    string `gorm:"not null;default:''"`
+	V4    string `gorm:"not null;default:''"`
+	V5    string `gorm:"not null;default:''"`
+}
+
+// Role is a custom role, a named bundle of permissions that can be assigned
+// to accounts per org or per project
+type Role struct {
+	gorm.Model
+
+	Key         string       `gorm:"unique;not null"`
+	Name        string       `gorm:"not null;uniqueIndex:idx_roles_organization_name"`
+	OrganizationID uint      `gorm:"not null;default:0;uniqueIndex:idx_roles_organization_name"` // <- foreign key to Organization
+	Description string
+	Permissions []Permission `gorm:"type:jsonb;serializer:json;default:'[]'"`
+}
+
+type Permission struct {
+	Object AuthorizationObject `json:"object" binding:"required"`
+	Action string              `json:"action" binding:"required"`
+}
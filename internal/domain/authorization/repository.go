@@ -0,0 +1,19 @@
+package authorization
+
+type PolicyRepository interface {
+	Find() (*[]Policy, error)
+	Count() (int64, error)
+	Create(payload *Policy) (*Policy, error)
+	CreateMany(payload *[]Policy) error
+	Delete(payload *Policy) error
+	DeleteFiltered(ptype string, fieldIndex int, fieldValues ...string) error
+	DeleteAll() error
+}
+
+type RoleRepository interface {
+	Find(organizationId uint) (*[]Role, error)
+	FindOneByKey(key string, organizationId uint) (*Role, error)
+	Create(payload *Role) (*Role, error)
+	Update(payload *Role) error
+	Delete(key string) error
+}
@@ -0,0 +1,13 @@
+package authorization
+
+type PolicyRule struct {
+	Role   string `json:"role" binding:"required"`
+	Domain string `json:"domain" binding:"required"`
+	Object string `json:"object" binding:"required"`
+	Action string `json:"action" binding:"required"`
+}
+
+type RoleInheritanceRule struct {
+	Role          string `json:"role" binding:"required"`
+	InheritedRole string `json:"inheritedRole" binding:"required"`
+}
@@ -14,4 +14,8 @@ type Cache interface {
 	Set(key string, value string, ttl time.Duration) error
 	Get(key string) (string, error)
 	Delete(key string) error
+	// Take atomically gets and deletes a key, so a single-use value is only ever handed to one caller
+	Take(key string) (string, error)
+	// Increment atomically increments a counter, starting its ttl when the counter is created
+	Increment(key string, ttl time.Duration) (int64, error)
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package datasource
+
+// ConnectionFailure categorises why a connection test failed
+type ConnectionFailure string
+
+const (
+	FailureAuth        ConnectionFailure = "auth"
+	FailureNetwork     ConnectionFailure = "network"
+	FailureTLS         ConnectionFailure = "tls"
+	FailurePermissions ConnectionFailure = "permissions"
+	FailureUnknown     ConnectionFailure = "unknown"
+)
+
+type FieldType string
+
+const (
+	FieldString  FieldType = "string"
+	FieldInteger FieldType = "integer"
+	FieldBoolean FieldType = "boolean"
+	FieldArray   FieldType = "array"
+	FieldObject  FieldType = "object"
+	FieldConst   FieldType = "const"
+)
@@ -0,0 +1,195 @@
+package datasource
+
+import (
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/secret"
+)
+
+// connectionFailurePatterns match the errors connectors report, checked in order: TLS errors
+// often mention the handshake failing to connect, and auth errors often mention access denied
+var connectionFailurePatterns = []struct {
+	reason   ConnectionFailure
+	patterns []string
+}{
+	{FailureTLS, []string{"ssl", "tls", "certificate", "x509", "handshake"}},
+	{FailureAuth, []string{"password authentication failed", "authentication failed", "invalid password", "invalid username", "invalid credentials", "login failed", "access denied for user", "unauthorized"}},
+	{FailurePermissions, []string{"permission denied", "insufficient privilege", "not authorized", "access denied", "forbidden"}},
+	{FailureNetwork, []string{"connection refused", "no such host", "unknown host", "timed out", "timeout", "unreachable", "could not connect", "connection reset", "communications link failure", "name resolution"}},
+}
+
+// ParseConnector parses and validates a connector definition, written in YAML or JSON
+func ParseConnector(data []byte) (*Connector, error) {
+	var connector Connector
+
+	if err := yaml.UnmarshalWithOptions(data, &connector, yaml.DisallowUnknownField()); err != nil {
+		return nil, err
+	}
+
+	if err := connector.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &connector, nil
+}
+
+// ClassifyConnectionFailure categorises the error of a failed connection test
+func ClassifyConnectionFailure(err error) ConnectionFailure {
+	message := strings.ToLower(err.Error())
+
+	for _, p := range connectionFailurePatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(message, pattern) {
+				return p.reason
+			}
+		}
+	}
+
+	return FailureUnknown
+}
+
+// BuildAdditionalData binds an encrypted configuration to its datasource, so it cannot be
+// swapped with another datasource's
+func (d *Datasource) BuildAdditionalData() string {
+	return "datasource/" + d.SourceID
+}
+
+// MaskSecrets copies a configuration, replacing the values of its secret fields, including
+// those of the selected mode of oneOf fields, with secret.Mask. Every value is masked when the
+// source type is no longer supported, as its secret fields are unknown
+func MaskSecrets(sourceType string, configuration map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(configuration))
+	for k, v := range configuration {
+		masked[k] = v
+	}
+
+	connector := FindConnector(sourceType)
+
+	if connector == nil {
+		for k := range masked {
+			maskValue(masked, k)
+		}
+		return masked
+	}
+
+	for _, field := range connector.Form {
+		if field.Secret {
+			maskValue(masked, field.Title)
+			continue
+		}
+
+		value, ok := masked[field.Title].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		opt := field.selectedOption(value)
+		if opt == nil {
+			continue
+		}
+
+		maskedValue := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			maskedValue[k] = v
+		}
+
+		for _, f := range opt.Fields {
+			if f.Secret {
+				maskValue(maskedValue, f.Title)
+			}
+		}
+
+		masked[field.Title] = maskedValue
+	}
+
+	return masked
+}
+
+// maskValue masks a value when set, so an unset secret still reads as unset
+func maskValue(configuration map[string]interface{}, title string) {
+	if value, ok := configuration[title]; ok && value != nil && value != "" {
+		configuration[title] = secret.Mask
+	}
+}
+
+// UnmaskSecrets restores the stored values of secret fields left masked in an updated
+// configuration, so clients can send back the masked configuration they were given
+func UnmaskSecrets(sourceType string, configuration map[string]interface{}, stored map[string]interface{}) map[string]interface{} {
+	unmasked := make(map[string]interface{}, len(configuration))
+	for k, v := range configuration {
+		unmasked[k] = v
+	}
+
+	connector := FindConnector(sourceType)
+
+	if connector == nil {
+		return unmasked
+	}
+
+	for _, field := range connector.Form {
+		if field.Secret {
+			unmaskValue(unmasked, stored, field.Title)
+			continue
+		}
+
+		value, ok := unmasked[field.Title].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		storedValue, _ := stored[field.Title].(map[string]interface{})
+
+		// Secrets of another mode do not carry over
+		if storedValue == nil || storedValue["mode"] != value["mode"] {
+			continue
+		}
+
+		opt := field.selectedOption(value)
+		if opt == nil {
+			continue
+		}
+
+		unmaskedValue := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			unmaskedValue[k] = v
+		}
+
+		for _, f := range opt.Fields {
+			if f.Secret {
+				unmaskValue(unmaskedValue, storedValue, f.Title)
+			}
+		}
+
+		unmasked[field.Title] = unmaskedValue
+	}
+
+	return unmasked
+}
+
+// unmaskValue restores a masked value, dropping it when there is no stored value to restore
+func unmaskValue(configuration map[string]interface{}, stored map[string]interface{}, title string) {
+	if configuration[title] != secret.Mask {
+		return
+	}
+
+	if value, ok := stored[title]; ok {
+		configuration[title] = value
+	} else {
+		delete(configuration, title)
+	}
+}
+
+// selectedOption returns the mode of an object field selected by its value, nil when none is
+func (f *Field) selectedOption(value map[string]interface{}) *Option {
+	mode, _ := value["mode"].(string)
+
+	for i := range f.OneOf {
+		if f.OneOf[i].Value == mode {
+			return &f.OneOf[i]
+		}
+	}
+
+	return nil
+}
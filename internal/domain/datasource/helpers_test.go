@@ -0,0 +1,143 @@
+package datasource
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/secret"
+)
+
+// useConnectors replaces the connector catalog for the duration of a test
+func useConnectors(t *testing.T, connectors ...Connector) {
+	t.Helper()
+
+	previous := SupportedDatasources
+	SupportedDatasources = connectors
+
+	t.Cleanup(func() { SupportedDatasources = previous })
+}
+
+// testConnector has a secret field and a secret field in one of the modes of an object field
+var testConnector = Connector{
+	Version: ConnectorVersion,
+	SourceType: "test",
+	Name: "Test",
+	Form: []Field{
+		{Title: "host", Type: "string", Required: true},
+		{Title: "password", Type: "string", Secret: true},
+		{Title: "tunnel_method", Type: "object", OneOf: []Option{
+			{Title: "No Tunnel", Value: "NO_TUNNEL"},
+			{Title: "Password Authentication", Value: "SSH_PASSWORD_AUTH", Fields: []Field{
+				{Title: "tunnel_user", Type: "string", Required: true},
+				{Title: "tunnel_user_password", Type: "string", Secret: true},
+			}},
+		}},
+	},
+}
+
+func TestClassifyConnectionFailure(t *testing.T) {
+	tests := []struct {
+		err  string
+		want ConnectionFailure
+	}{
+		{err: `FATAL: password authentication failed for user "app"`, want: FailureAuth},
+		{err: "Access denied for user 'app'@'10.0.0.1' (using password: YES)", want: FailureAuth},
+		{err: "Login failed for user 'app'", want: FailureAuth},
+		{err: "permission denied for schema private", want: FailurePermissions},
+		{err: "Access denied; you need the REPLICATION CLIENT privilege", want: FailurePermissions},
+		{err: "dial tcp 10.0.0.1:5432: connect: connection refused", want: FailureNetwork},
+		{err: "dial tcp: lookup db.local: no such host", want: FailureNetwork},
+		{err: "Communications link failure", want: FailureNetwork},
+		{err: "x509: certificate signed by unknown authority", want: FailureTLS},
+		// TLS errors win over the network error they surface as
+		{err: "could not connect: SSL handshake failed", want: FailureTLS},
+		{err: "something else went wrong", want: FailureUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			if got := ClassifyConnectionFailure(errors.New(tt.err)); got != tt.want {
+				t.Fatalf("ClassifyConnectionFailure() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmaskSecrets(t *testing.T) {
+	useConnectors(t, testConnector)
+
+	stored := map[string]interface{}{
+		"host": "db.local",
+		"password": "hunter2",
+		"tunnel_method": map[string]interface{}{"mode": "SSH_PASSWORD_AUTH", "tunnel_user": "app", "tunnel_user_password": "tunnel2"},
+	}
+
+	tests := []struct {
+		name          string
+		sourceType    string
+		configuration map[string]interface{}
+		stored        map[string]interface{}
+		want          map[string]interface{}
+	}{
+		{
+			name: "restores masked secrets",
+			sourceType: "test",
+			configuration: MaskSecrets("test", stored),
+			stored: stored,
+			want: stored,
+		},
+		{
+			name: "keeps secrets that were changed",
+			sourceType: "test",
+			configuration: map[string]interface{}{
+				"host": "db2.local",
+				"password": "hunter3",
+				"tunnel_method": map[string]interface{}{"mode": "SSH_PASSWORD_AUTH", "tunnel_user": "app", "tunnel_user_password": secret.Mask},
+			},
+			stored: stored,
+			want: map[string]interface{}{
+				"host": "db2.local",
+				"password": "hunter3",
+				"tunnel_method": map[string]interface{}{"mode": "SSH_PASSWORD_AUTH", "tunnel_user": "app", "tunnel_user_password": "tunnel2"},
+			},
+		},
+		{
+			name: "does not carry secrets over to another mode",
+			sourceType: "test",
+			configuration: map[string]interface{}{
+				"host": "db.local",
+				"password": secret.Mask,
+				"tunnel_method": map[string]interface{}{"mode": "NO_TUNNEL", "tunnel_user_password": secret.Mask},
+			},
+			stored: stored,
+			want: map[string]interface{}{
+				"host": "db.local",
+				"password": "hunter2",
+				"tunnel_method": map[string]interface{}{"mode": "NO_TUNNEL", "tunnel_user_password": secret.Mask},
+			},
+		},
+		{
+			name: "drops masked secrets with no stored value",
+			sourceType: "test",
+			configuration: map[string]interface{}{"host": "db.local", "password": secret.Mask},
+			stored: map[string]interface{}{"host": "db.local"},
+			want: map[string]interface{}{"host": "db.local"},
+		},
+		{
+			name: "leaves unsupported source types as sent",
+			sourceType: "unsupported",
+			configuration: map[string]interface{}{"password": secret.Mask},
+			stored: stored,
+			want: map[string]interface{}{"password": secret.Mask},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnmaskSecrets(tt.sourceType, tt.configuration, tt.stored); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("UnmaskSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,4 +12,6 @@ type Datasource struct {
 	ProjectID		uint   `gorm:"not null;index"` // <- foreign key to Project
 	CreatedBy       map[string]string 		 `gorm:"type:jsonb;serializer:json;default:'{}'"`
 	SchemaMapping       map[string]interface{} 		 `gorm:"type:jsonb;serializer:json;default:'{}'"`
+	Configuration       string `json:"-"` // <- connection configuration, envelope-encrypted
+	MaskedConfiguration map[string]interface{} `gorm:"-" json:"Configuration,omitempty"` // <- configuration with its secret fields masked, for responses
 }
\ No newline at end of file
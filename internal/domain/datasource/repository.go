@@ -1,10 +1,20 @@
 package datasource
 
+import "time"
+
 type DatasourceRepository interface {
 	Find(projectId uint) (*[]Datasource, error)
 	FindOne(datasourceId uint, projectId uint) (*Datasource, error)
+	FindWithDeleted(projectId uint) (*[]Datasource, error)
+	FindAll() (*[]Datasource, error)
+	FindDeleted(projectId uint) (*[]Datasource, error)
+	FindOneDeleted(datasourceId uint, projectId uint) (*Datasource, error)
+	FindDeletedBefore(before time.Time) (*[]Datasource, error)
 	Create(payload *Datasource) (*Datasource, error)
 	Update(payload *Datasource) error
+	UpdateCreatedBy(datasourceId uint, createdBy map[string]string) error
+	UpdateConfiguration(datasourceId uint, configuration string) error
 	SoftDelete(datasourceId uint, projectId uint) error
+	Restore(datasourceId uint, projectId uint) error
 	HardDelete(datasourceId uint, projectId uint) error
 }
\ No newline at end of file
@@ -0,0 +1,516 @@
+package datasource
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+)
+
+// SupportedFormats are the formats fields may declare, with how they read in error messages.
+// host, port and pem are checked by this package, the others by the JSON Schema engine
+var SupportedFormats = map[string]string{
+	"host":      "hostname or IP address",
+	"hostname":  "hostname",
+	"ipv4":      "IPv4 address",
+	"ipv6":      "IPv6 address",
+	"port":      "port",
+	"pem":       "PEM encoded value",
+	"uri":       "URI",
+	"email":     "email address",
+	"uuid":      "UUID",
+	"date":      "date",
+	"date-time": "date-time",
+}
+
+var hostnamePattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*\.?$`)
+
+// formats leave empty strings to minLength, so empty required fields read as missing
+var formats = []*jsonschema.Format{
+	{Name: "host", Validate: validateHost},
+	{Name: "port", Validate: validatePort},
+	{Name: "pem", Validate: validatePEM},
+}
+
+// JSONSchema expresses the connector's form as a draft-07 JSON Schema, in the shape of the
+// connectionSpecification of an Airbyte connector specification
+func (c *Connector) JSONSchema() map[string]interface{} {
+	schema := objectSchema(c.Form)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = c.Name
+
+	return schema
+}
+
+// compile compiles the JSON Schema of the connector's form, validating patterns along the way
+func (c *Connector) compile() error {
+	raw, err := json.Marshal(c.JSONSchema())
+	if err != nil {
+		return err
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	url := "connector://" + c.SourceType
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft7)
+	compiler.AssertFormat()
+
+	for _, f := range formats {
+		compiler.RegisterFormat(f)
+	}
+
+	if err := compiler.AddResource(url, doc); err != nil {
+		return err
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return err
+	}
+
+	c.schema = schema
+
+	return nil
+}
+
+func objectSchema(fields []Field) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	dependencies := map[string]interface{}{}
+
+	for i, field := range fields {
+		properties[field.Title] = fieldSchema(field, i)
+
+		if field.Required && field.Type != FieldConst {
+			required = append(required, field.Title)
+		}
+
+		if len(field.Requires) > 0 {
+			dependencies[field.Title] = field.Requires
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	if len(dependencies) > 0 {
+		schema["dependencies"] = dependencies
+	}
+
+	return schema
+}
+
+func fieldSchema(field Field, order int) map[string]interface{} {
+	schema := map[string]interface{}{
+		"title": field.Title,
+		"order": order,
+	}
+
+	if field.Desc != "" {
+		schema["description"] = field.Desc
+	}
+	if field.Secret {
+		schema["airbyte_secret"] = true
+	}
+	if field.Hidden {
+		schema["airbyte_hidden"] = true
+	}
+	if field.Multiline {
+		schema["multiline"] = true
+	}
+	if field.Placeholder != "" {
+		schema["examples"] = []string{field.Placeholder}
+	}
+	if field.Default != nil {
+		schema["default"] = field.Default
+	}
+	if len(field.Enum) > 0 {
+		schema["enum"] = field.Enum
+	}
+	if field.Pattern != "" {
+		schema["pattern"] = field.Pattern
+	}
+	if field.Format != "" {
+		schema["format"] = field.Format
+	}
+
+	switch field.Type {
+	case FieldString:
+		schema["type"] = "string"
+
+		// Required strings must not be empty
+		if field.Required {
+			schema["minLength"] = 1
+		}
+
+	case FieldInteger:
+		schema["type"] = "integer"
+
+		if field.Min != nil {
+			schema["minimum"] = *field.Min
+		}
+		if field.Max != nil {
+			schema["maximum"] = *field.Max
+		}
+
+	case FieldBoolean:
+		schema["type"] = "boolean"
+
+	case FieldArray:
+		schema["type"] = "array"
+
+		if field.Items == string(FieldObject) {
+			schema["items"] = objectSchema(field.Fields)
+		} else if field.Items != "" {
+			schema["items"] = map[string]interface{}{"type": field.Items}
+		}
+
+		if field.MinLength != nil {
+			schema["minItems"] = *field.MinLength
+		}
+
+	case FieldObject:
+		schema["type"] = "object"
+
+		options := make([]interface{}, 0, len(field.OneOf))
+
+		for _, opt := range field.OneOf {
+			option := objectSchema(opt.Fields)
+			option["title"] = opt.Title
+			option["required"] = append([]string{"mode"}, requiredOf(option)...)
+			option["properties"].(map[string]interface{})["mode"] = map[string]interface{}{
+				"type":  "string",
+				"const": opt.Value,
+			}
+
+			options = append(options, option)
+		}
+
+		schema["oneOf"] = options
+
+	case FieldConst:
+		schema["type"] = "string"
+		schema["const"] = field.Value
+	}
+
+	return schema
+}
+
+func requiredOf(schema map[string]interface{}) []string {
+	required, _ := schema["required"].([]string)
+	return required
+}
+
+// schemaErrors converts a JSON Schema validation error into field errors, whose fields are JSON
+// pointers into the configuration
+func schemaErrors(err error, configuration map[string]interface{}) []FieldError {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []FieldError{{Field: "", Message: err.Error()}}
+	}
+
+	return collectErrors(validationErr, configuration, "")
+}
+
+// collectErrors walks the causes of an error down to the failed keywords. For oneOf, only the
+// mode selected by the object's "mode" is reported, so one typo does not yield an error per mode
+func collectErrors(e *jsonschema.ValidationError, configuration map[string]interface{}, mode string) []FieldError {
+	if k, ok := e.ErrorKind.(*kind.OneOf); ok && k.Subschemas == nil {
+		return oneOfErrors(e, configuration)
+	}
+
+	if len(e.Causes) > 0 {
+		var errs []FieldError
+		for _, cause := range e.Causes {
+			errs = append(errs, collectErrors(cause, configuration, mode)...)
+		}
+		return errs
+	}
+
+	return keywordErrors(e, mode)
+}
+
+func oneOfErrors(e *jsonschema.ValidationError, configuration map[string]interface{}) []FieldError {
+	location := e.InstanceLocation
+	parent := fieldTitle(location)
+
+	// Objects of another type are reported by the type keyword
+	value, ok := lookup(configuration, location).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	mode, hasMode := value["mode"].(string)
+
+	if !hasMode {
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s requires a 'mode' selection.", parent)}}
+	}
+
+	for _, cause := range e.Causes {
+		if modeConst(cause, location) == "" {
+			return collectErrors(cause, configuration, mode)
+		}
+	}
+
+	allowed := []string{}
+	for _, cause := range e.Causes {
+		if want := modeConst(cause, location); want != "" {
+			allowed = append(allowed, want)
+		}
+	}
+
+	return []FieldError{{
+		Field:   toPointer(childOf(location, "mode")),
+		Message: fmt.Sprintf("%s mode must be one of: %s.", parent, strings.Join(allowed, ", ")),
+	}}
+}
+
+// modeConst returns the mode of a oneOf branch that failed because it is for another mode
+func modeConst(e *jsonschema.ValidationError, location []string) string {
+	if k, ok := e.ErrorKind.(*kind.Const); ok && toPointer(e.InstanceLocation) == toPointer(childOf(location, "mode")) {
+		want, _ := k.Want.(string)
+		return want
+	}
+
+	for _, cause := range e.Causes {
+		if want := modeConst(cause, location); want != "" {
+			return want
+		}
+	}
+
+	return ""
+}
+
+func keywordErrors(e *jsonschema.ValidationError, mode string) []FieldError {
+	location := e.InstanceLocation
+	title := fieldTitle(location)
+
+	required := func(missing string) FieldError {
+		message := fmt.Sprintf("%s is required.", missing)
+		if mode != "" {
+			message = fmt.Sprintf("%s is required when mode is '%s'.", missing, mode)
+		}
+		return FieldError{Field: toPointer(childOf(location, missing)), Message: message}
+	}
+
+	switch k := e.ErrorKind.(type) {
+	case *kind.Required:
+		errs := make([]FieldError, 0, len(k.Missing))
+		for _, missing := range k.Missing {
+			errs = append(errs, required(missing))
+		}
+		return errs
+
+	case *kind.Dependency:
+		errs := make([]FieldError, 0, len(k.Missing))
+		for _, missing := range k.Missing {
+			errs = append(errs, FieldError{
+				Field:   toPointer(childOf(location, missing)),
+				Message: fmt.Sprintf("%s is required when %s is set.", missing, k.Prop),
+			})
+		}
+		return errs
+
+	case *kind.MinLength:
+		if k.Got == 0 {
+			message := fmt.Sprintf("%s is required.", title)
+			if mode != "" {
+				message = fmt.Sprintf("%s is required when mode is '%s'.", title, mode)
+			}
+			return []FieldError{{Field: toPointer(location), Message: message}}
+		}
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must be at least %d characters.", title, k.Want)}}
+
+	case *kind.Type:
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must be %s.", title, typeName(k.Want))}}
+
+	case *kind.Minimum:
+		want, _ := k.Want.Float64()
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must be at least %v.", title, want)}}
+
+	case *kind.Maximum:
+		want, _ := k.Want.Float64()
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must be at most %v.", title, want)}}
+
+	case *kind.MinItems:
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must have at least %d items.", title, k.Want)}}
+
+	case *kind.Enum:
+		allowed := make([]string, 0, len(k.Want))
+		for _, w := range k.Want {
+			allowed = append(allowed, fmt.Sprint(w))
+		}
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must be one of: %s.", title, strings.Join(allowed, ", "))}}
+
+	case *kind.Const:
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must be %v.", title, k.Want)}}
+
+	case *kind.Pattern:
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must match the pattern %s.", title, k.Want)}}
+
+	case *kind.Format:
+		name, ok := SupportedFormats[k.Want]
+		if !ok {
+			name = k.Want
+		}
+		return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s must be a valid %s.", title, name)}}
+	}
+
+	return []FieldError{{Field: toPointer(location), Message: fmt.Sprintf("%s is invalid.", title)}}
+}
+
+func typeName(want []string) string {
+	names := make([]string, 0, len(want))
+
+	for _, w := range want {
+		switch w {
+		case "integer", "object", "array":
+			names = append(names, "an "+w)
+		default:
+			names = append(names, "a "+w)
+		}
+	}
+
+	return strings.Join(names, " or ")
+}
+
+// fieldTitle names the field at a location in error messages
+func fieldTitle(location []string) string {
+	if len(location) == 0 {
+		return "configuration"
+	}
+
+	return location[len(location)-1]
+}
+
+// toPointer builds the JSON pointer of a location, e.g. /ssl_mode/ca_certificate
+func toPointer(location []string) string {
+	var b strings.Builder
+
+	for _, token := range location {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		b.WriteString("/" + token)
+	}
+
+	return b.String()
+}
+
+// childOf returns the location of a child, without sharing the parent's backing array
+func childOf(location []string, token string) []string {
+	return append(append([]string{}, location...), token)
+}
+
+func lookup(value interface{}, location []string) interface{} {
+	for _, token := range location {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[token]
+	}
+
+	return value
+}
+
+// withoutNulls drops null values, which read as unset like they did before schema validation
+func withoutNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			if item != nil {
+				out[k] = withoutNulls(item)
+			}
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = withoutNulls(item)
+		}
+		return out
+	}
+
+	return value
+}
+
+func validateHost(v any) error {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return nil
+	}
+
+	if net.ParseIP(s) != nil || (len(s) <= 253 && hostnamePattern.MatchString(s)) {
+		return nil
+	}
+
+	return errors.New("not a hostname or IP address")
+}
+
+func validatePort(v any) error {
+	port, ok := toFloat64(v)
+	if !ok {
+		if n, isNumber := v.(json.Number); isNumber {
+			f, err := n.Float64()
+			port, ok = f, err == nil
+		}
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if port < 1 || port > 65535 || port != float64(int(port)) {
+		return errors.New("not a port between 1 and 65535")
+	}
+
+	return nil
+}
+
+func validatePEM(v any) error {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return nil
+	}
+
+	rest := []byte(strings.TrimSpace(s))
+	blocks := 0
+
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+
+		if block == nil {
+			return errors.New("not PEM encoded")
+		}
+
+		blocks++
+		rest = bytes.TrimSpace(rest)
+	}
+
+	if blocks == 0 {
+		return errors.New("not PEM encoded")
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package datasource
+
+import (
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// ConnectionTest is the outcome of testing a datasource's connection
+type ConnectionTest struct {
+	Succeeded bool              `json:"succeeded"`
+	LatencyMs int64             `json:"latencyMs"`
+	Reason    ConnectionFailure `json:"reason,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Connector defines a type of datasource and the form of its configuration. Connectors are
+// loaded from definition files, see the built-in ones in connectors/
+type Connector struct {
+	Version    int     `json:"version"` // <- version of the definition format
+	SourceType string  `json:"sourceType"`
+	Name       string  `json:"name"`
+	Logo       string  `json:"logo"`
+	Desc       string  `json:"desc"`
+	Form       []Field `json:"form"`
+
+	schema *jsonschema.Schema // <- compiled JSON Schema of the form
+}
+
+// Field is a configuration field of a connector
+type Field struct {
+	Title       string      `json:"title"`
+	Desc        string      `json:"desc,omitempty"`
+	Type        FieldType   `json:"type"`
+	Required    bool        `json:"required,omitempty"`
+	Secret      bool        `json:"secret,omitempty"` // <- encrypted at rest and masked in responses
+	Multiline   bool        `json:"multiline,omitempty"`
+	Hidden      bool        `json:"hidden,omitempty"`
+	Placeholder string      `json:"placeholder,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Min         *float64    `json:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty"`
+	MinLength   *int        `json:"minLength,omitempty"` // <- arrays only
+	Items       string      `json:"items,omitempty"`     // <- type of array items
+	Fields      []Field     `json:"fields,omitempty"`    // <- fields of array items of type object
+	Enum        []string    `json:"enum,omitempty"`
+	Pattern     string      `json:"pattern,omitempty"`  // <- regular expression string values must match
+	Format      string      `json:"format,omitempty"`   // <- see SupportedFormats
+	Requires    []string    `json:"requires,omitempty"` // <- sibling fields required when this one is set
+	Value       string      `json:"value,omitempty"`    // <- value of const fields
+	OneOf       []Option    `json:"oneOf,omitempty"`    // <- modes of object fields, selected by the object's "mode"
+}
+
+// Option is a mode of an object field, with the fields that mode takes
+type Option struct {
+	Title  string  `json:"title"`
+	Value  string  `json:"value"`
+	Fields []Field `json:"fields,omitempty"`
+}
@@ -3,9 +3,13 @@ package datasource
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 )
 
+var sourceTypePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 type FieldError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
@@ -13,153 +17,228 @@ type FieldError struct {
 
 // Validate datasource
 // - sourceType must be supported
-// - configuration params must be valid for the given sourceType
+// - configuration params must be valid against the JSON Schema of the connector's form
 func ValidateInput(sourceType string, configuration map[string]interface{}) ([]FieldError, error) {
-	var formDef []map[string]interface{}
-	for _, datasource := range SupportedDatasources {
-		if datasource["sourceType"] == sourceType {
-			formDef = datasource["form"].([]map[string]interface{})
-			break
-		}
-	}
+	connector := FindConnector(sourceType)
 
-	if formDef == nil {
+	if connector == nil {
 		return nil, errors.New("Unsupported datasource.")
 	}
 
-	var errs []FieldError
+	if err := connector.schema.Validate(withoutNulls(configuration)); err != nil {
+		return schemaErrors(err, configuration), nil
+	}
 
-	for _, field := range formDef {
-		title := field["title"].(string)
-		fieldType, _ := field["type"].(string)
-		required, _ := field["required"].(bool)
+	return nil, nil
+}
 
-		if fieldType == "const" {
-			continue
-		}
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
 
-		value, exists := configuration[title]
 
-		if required && (!exists || value == nil || value == "") {
-			errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s is required.", title)})
-			continue
-		}
+// Validate a connector definition
+// - version must be the supported definition format version
+// - sourceType and name are required, sourceType is lowercase letters, digits, "_" and "-"
+// - fields must be well formed for their type, see validateFields
+// - the form must compile to a JSON Schema
+func (c *Connector) Validate() error {
+	var problems []string
 
-		if !exists || value == nil {
-			continue
-		}
+	if c.Version != ConnectorVersion {
+		problems = append(problems, fmt.Sprintf("unsupported version %d, expected %d", c.Version, ConnectorVersion))
+	}
 
-		switch fieldType {
-		case "string":
-			if _, ok := value.(string); !ok {
-				errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must be a string.", title)})
-			}
+	if !sourceTypePattern.MatchString(c.SourceType) {
+		problems = append(problems, fmt.Sprintf("invalid sourceType %q", c.SourceType))
+	}
 
-		case "integer":
-			num, ok := toFloat64(value)
-			if !ok {
-				errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must be an integer.", title)})
-			} else {
-				if min, hasMin := field["min"]; hasMin {
-					if num < toFloat64Must(min) {
-						errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must be at least %v.", title, min)})
-					}
-				}
-				if max, hasMax := field["max"]; hasMax {
-					if num > toFloat64Must(max) {
-						errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must be at most %v.", title, max)})
-					}
-				}
-			}
+	if c.Name == "" {
+		problems = append(problems, "name is required")
+	}
 
-		case "boolean":
-			if _, ok := value.(bool); !ok {
-				errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must be a boolean.", title)})
-			}
+	if len(c.Form) == 0 {
+		problems = append(problems, "form requires at least one field")
+	}
 
-		case "array":
-			arr, ok := value.([]interface{})
-			if !ok {
-				errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must be an array.", title)})
-			} else if minLen, has := field["minLength"]; has {
-				if float64(len(arr)) < toFloat64Must(minLen) {
-					errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must have at least %v items.", title, minLen)})
-				}
-			}
+	problems = append(problems, validateFields("form", c.Form, true)...)
 
-		case "object":
-			obj, ok := value.(map[string]interface{})
-			if !ok {
-				errs = append(errs, FieldError{Field: title, Message: fmt.Sprintf("%s must be an object.", title)})
-			} else if oneOf, has := field["oneOf"]; has {
-				errs = append(errs, validateOneOf(title, obj, oneOf.([]map[string]interface{}))...)
-			}
+	for _, field := range c.Form {
+		if field.Title == "sourceType" && field.Type == FieldConst && field.Value != c.SourceType {
+			problems = append(problems, fmt.Sprintf("form.sourceType: value must be %q", c.SourceType))
 		}
 	}
 
-	if len(errs) > 0 {
-		return errs, nil
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
 	}
 
-	return nil, nil
+	if err := c.compile(); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	return nil
 }
 
-func validateOneOf(parent string, value map[string]interface{}, options []map[string]interface{}) []FieldError {
-	var errs []FieldError
+// validateFields checks the fields of a form or of an object mode. Object fields are only
+// supported at the top level of the form
+func validateFields(path string, fields []Field, allowObjects bool) []string {
+	var problems []string
+	titles := map[string]bool{}
 
-	mode, hasMode := value["mode"].(string)
-	if !hasMode {
-		return []FieldError{{Field: parent, Message: fmt.Sprintf("%s requires a 'mode' selection.", parent)}}
+	siblings := map[string]bool{}
+	for _, field := range fields {
+		siblings[field.Title] = true
 	}
 
-	var matched map[string]interface{}
-	for _, opt := range options {
-		if opt["value"] == mode {
-			matched = opt
-			break
+	for i, field := range fields {
+		name := fmt.Sprintf("%s[%d]", path, i)
+		if field.Title != "" {
+			name = path + "." + field.Title
 		}
-	}
 
-	if matched == nil {
-		allowed := make([]string, 0, len(options))
-		for _, opt := range options {
-			allowed = append(allowed, opt["value"].(string))
+		if field.Title == "" {
+			problems = append(problems, name+": title is required")
+		} else if titles[field.Title] {
+			problems = append(problems, name+": duplicate title")
+		}
+		titles[field.Title] = true
+
+		switch field.Type {
+		case FieldString, FieldInteger, FieldBoolean, FieldArray, FieldConst:
+		case FieldObject:
+			if !allowObjects {
+				problems = append(problems, name+": object fields are only supported at the top level")
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unsupported type %q", name, field.Type))
+		}
+
+		if field.Type == FieldObject && len(field.OneOf) == 0 {
+			problems = append(problems, name+": object fields require oneOf")
+		}
+		if field.Type != FieldObject && len(field.OneOf) > 0 {
+			problems = append(problems, name+": oneOf is only supported on object fields")
+		}
+
+		if field.Type == FieldConst && field.Value == "" {
+			problems = append(problems, name+": const fields require a value")
+		}
+
+		if (field.Min != nil || field.Max != nil) && field.Type != FieldInteger {
+			problems = append(problems, name+": min and max are only supported on integer fields")
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			problems = append(problems, name+": min must not exceed max")
+		}
+
+		if (field.MinLength != nil || field.Items != "") && field.Type != FieldArray {
+			problems = append(problems, name+": minLength and items are only supported on array fields")
+		}
+		if field.MinLength != nil && *field.MinLength < 0 {
+			problems = append(problems, name+": minLength must not be negative")
+		}
+
+		if len(field.Enum) > 0 && field.Type != FieldString && field.Type != FieldConst {
+			problems = append(problems, name+": enum is only supported on string fields")
+		}
+
+		switch FieldType(field.Items) {
+		case "", FieldString, FieldInteger, FieldBoolean:
+		case FieldObject:
+			if len(field.Fields) == 0 {
+				problems = append(problems, name+": array items of type object require fields")
+			}
+			problems = append(problems, validateFields(name+"[]", field.Fields, false)...)
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unsupported items type %q", name, field.Items))
+		}
+		if len(field.Fields) > 0 && FieldType(field.Items) != FieldObject {
+			problems = append(problems, name+": fields are only supported on arrays of objects")
+		}
+
+		if field.Pattern != "" {
+			if field.Type != FieldString {
+				problems = append(problems, name+": pattern is only supported on string fields")
+			} else if _, err := regexp.Compile(field.Pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: invalid pattern: %v", name, err))
+			}
+		}
+
+		if _, ok := SupportedFormats[field.Format]; field.Format != "" && !ok {
+			problems = append(problems, fmt.Sprintf("%s: unsupported format %q", name, field.Format))
+		}
+
+		for _, required := range field.Requires {
+			if !siblings[required] || required == field.Title {
+				problems = append(problems, fmt.Sprintf("%s: requires unknown field %q", name, required))
+			}
+		}
+
+		if field.Default != nil && !defaultMatches(field) {
+			problems = append(problems, fmt.Sprintf("%s: default must be a %s", name, field.Type))
+		} else if value, ok := field.Default.(string); ok && len(field.Enum) > 0 && !slices.Contains(field.Enum, value) {
+			problems = append(problems, name+": default must be one of enum")
 		}
-		return []FieldError{{Field: parent, Message: fmt.Sprintf("%s mode must be one of: %s.", parent, strings.Join(allowed, ", "))}}
-	}
 
-	if fields, has := matched["fields"].([]map[string]interface{}); has {
-		for _, f := range fields {
-			fTitle := f["title"].(string)
-			fRequired, _ := f["required"].(bool)
-			fVal, fExists := value[fTitle]
-
-			if fRequired && (!fExists || fVal == nil || fVal == "") {
-				errs = append(errs, FieldError{
-					Field:   parent + "." + fTitle,
-					Message: fmt.Sprintf("%s is required when mode is '%s'.", fTitle, mode),
-				})
+		values := map[string]bool{}
+
+		for j, opt := range field.OneOf {
+			optName := fmt.Sprintf("%s.oneOf[%d]", name, j)
+
+			if opt.Title == "" {
+				problems = append(problems, optName+": title is required")
+			}
+
+			if opt.Value == "" {
+				problems = append(problems, optName+": value is required")
+			} else if values[opt.Value] {
+				problems = append(problems, fmt.Sprintf("%s: duplicate value %q", optName, opt.Value))
+			}
+			values[opt.Value] = true
+
+			for _, f := range opt.Fields {
+				if f.Title == "mode" {
+					problems = append(problems, optName+": mode is reserved for selecting the mode")
+				}
 			}
+
+			problems = append(problems, validateFields(name+"."+opt.Value, opt.Fields, false)...)
 		}
 	}
 
-	return errs
+	return problems
 }
 
-func toFloat64(v interface{}) (float64, bool) {
-	switch n := v.(type) {
-	case float64:
-		return n, true
-	case int:
-		return float64(n), true
-	case int64:
-		return float64(n), true
-	default:
-		return 0, false
+func defaultMatches(field Field) bool {
+	switch field.Type {
+	case FieldString:
+		_, ok := field.Default.(string)
+		return ok
+
+	case FieldInteger:
+		num, ok := toFloat64(field.Default)
+		return ok && num == float64(int64(num))
+
+	case FieldBoolean:
+		_, ok := field.Default.(bool)
+		return ok
+
+	case FieldArray:
+		_, ok := field.Default.([]interface{})
+		return ok
 	}
-}
 
-func toFloat64Must(v interface{}) float64 {
-	f, _ := toFloat64(v)
-	return f
-}
\ No newline at end of file
+	return false
+}
@@ -0,0 +1,211 @@
+package datasource
+
+import (
+	"encoding/pem"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// builtinConnector parses a built-in connector definition
+func builtinConnector(t *testing.T, sourceType string) Connector {
+	t.Helper()
+
+	data, err := BuiltinConnectors.ReadFile("connectors/" + sourceType + ".yaml")
+	if err != nil {
+		t.Fatalf("failed to read connector %s: %v", sourceType, err)
+	}
+
+	connector, err := ParseConnector(data)
+	if err != nil {
+		t.Fatalf("ParseConnector() error = %v", err)
+	}
+
+	return *connector
+}
+
+// postgresConfiguration returns a valid postgres configuration with some values replaced and
+// some removed
+func postgresConfiguration(values map[string]interface{}, without ...string) map[string]interface{} {
+	configuration := map[string]interface{}{
+		"host": "db.local",
+		"port": float64(5432),
+		"database": "app",
+		"username": "user",
+		"password": "hunter2",
+		"ssl_mode": map[string]interface{}{"mode": "disable"},
+		"replication_method": map[string]interface{}{"mode": "Xmin"},
+		"tunnel_method": map[string]interface{}{"mode": "NO_TUNNEL"},
+	}
+
+	for k, v := range values {
+		configuration[k] = v
+	}
+	for _, k := range without {
+		delete(configuration, k)
+	}
+
+	return configuration
+}
+
+func TestBuiltinConnectors(t *testing.T) {
+	files, err := fs.Glob(BuiltinConnectors, "connectors/*.yaml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no built-in connectors: %v", err)
+	}
+
+	for _, file := range files {
+		sourceType := strings.TrimSuffix(strings.TrimPrefix(file, "connectors/"), ".yaml")
+
+		t.Run(sourceType, func(t *testing.T) {
+			if connector := builtinConnector(t, sourceType); connector.SourceType != sourceType {
+				t.Fatalf("sourceType = %s, want %s", connector.SourceType, sourceType)
+			}
+		})
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	useConnectors(t, builtinConnector(t, "postgres"))
+
+	certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("certificate")}))
+
+	tests := []struct {
+		name          string
+		configuration map[string]interface{}
+		want          []FieldError
+	}{
+		{name: "a valid configuration", configuration: postgresConfiguration(nil)},
+		{name: "null values read as unset", configuration: postgresConfiguration(map[string]interface{}{"jdbc_url_params": nil, "password": nil})},
+		{
+			name: "a missing required field",
+			configuration: postgresConfiguration(nil, "host"),
+			want: []FieldError{{Field: "/host", Message: "host is required."}},
+		},
+		{
+			name: "an empty required field",
+			configuration: postgresConfiguration(map[string]interface{}{"host": ""}),
+			want: []FieldError{{Field: "/host", Message: "host is required."}},
+		},
+		{
+			name: "an invalid host",
+			configuration: postgresConfiguration(map[string]interface{}{"host": "db local"}),
+			want: []FieldError{{Field: "/host", Message: "host must be a valid hostname or IP address."}},
+		},
+		{
+			name: "a port of another type",
+			configuration: postgresConfiguration(map[string]interface{}{"port": "5432"}),
+			want: []FieldError{{Field: "/port", Message: "port must be an integer."}},
+		},
+		{
+			name: "a port out of range",
+			configuration: postgresConfiguration(map[string]interface{}{"port": float64(70000)}),
+			want: []FieldError{{Field: "/port", Message: "port must be a valid port."}},
+		},
+		{
+			name: "a value not matching the pattern",
+			configuration: postgresConfiguration(map[string]interface{}{"jdbc_url_params": "ssl"}),
+			want: []FieldError{{Field: "/jdbc_url_params", Message: "jdbc_url_params must match the pattern ^[^=&]+=[^&]*(&[^=&]+=[^&]*)*$."}},
+		},
+		{
+			name: "no mode selected",
+			configuration: postgresConfiguration(map[string]interface{}{"ssl_mode": map[string]interface{}{}}),
+			want: []FieldError{{Field: "/ssl_mode", Message: "ssl_mode requires a 'mode' selection."}},
+		},
+		{
+			name: "a field required by the selected mode",
+			configuration: postgresConfiguration(map[string]interface{}{"ssl_mode": map[string]interface{}{"mode": "verify-ca"}}),
+			want: []FieldError{{Field: "/ssl_mode/ca_certificate", Message: "ca_certificate is required when mode is 'verify-ca'."}},
+		},
+		{
+			name: "a value not PEM encoded",
+			configuration: postgresConfiguration(map[string]interface{}{"ssl_mode": map[string]interface{}{"mode": "verify-ca", "ca_certificate": "certificate"}}),
+			want: []FieldError{{Field: "/ssl_mode/ca_certificate", Message: "ca_certificate must be a valid PEM encoded value."}},
+		},
+		{
+			name: "a field required by another",
+			configuration: postgresConfiguration(map[string]interface{}{"ssl_mode": map[string]interface{}{"mode": "verify-ca", "ca_certificate": certificate, "client_key_password": "secret"}}),
+			want: []FieldError{{Field: "/ssl_mode/client_key", Message: "client_key is required when client_key_password is set."}},
+		},
+		{
+			name: "several invalid fields",
+			configuration: postgresConfiguration(map[string]interface{}{"port": float64(0)}, "database"),
+			want: []FieldError{{Field: "/database", Message: "database is required."}, {Field: "/port", Message: "port must be a valid port."}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateInput("postgres", tt.configuration)
+			if err != nil {
+				t.Fatalf("ValidateInput() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ValidateInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("an unsupported source type", func(t *testing.T) {
+		if _, err := ValidateInput("unsupported", postgresConfiguration(nil)); err == nil {
+			t.Fatal("ValidateInput() accepted an unsupported source type")
+		}
+	})
+}
+
+func TestConnectorValidate(t *testing.T) {
+	min, max := float64(10), float64(1)
+
+	tests := []struct {
+		name    string
+		form    []Field
+		wantErr string
+	}{
+		{name: "a valid form", form: []Field{{Title: "host", Type: FieldString, Format: "host"}}},
+		{name: "no fields", wantErr: "form requires at least one field"},
+		{name: "a field without title", form: []Field{{Type: FieldString}}, wantErr: "form[0]: title is required"},
+		{name: "duplicate titles", form: []Field{{Title: "host", Type: FieldString}, {Title: "host", Type: FieldString}}, wantErr: "form.host: duplicate title"},
+		{name: "an unsupported type", form: []Field{{Title: "host", Type: "uri"}}, wantErr: `form.host: unsupported type "uri"`},
+		{name: "an object without modes", form: []Field{{Title: "ssl", Type: FieldObject}}, wantErr: "form.ssl: object fields require oneOf"},
+		{name: "nested objects", form: []Field{{Title: "ssl", Type: FieldObject, OneOf: []Option{
+			{Title: "On", Value: "on", Fields: []Field{{Title: "ca", Type: FieldObject, OneOf: []Option{{Title: "A", Value: "a"}}}}},
+		}}}, wantErr: "form.ssl.on.ca: object fields are only supported at the top level"},
+		{name: "a mode field in a mode", form: []Field{{Title: "ssl", Type: FieldObject, OneOf: []Option{
+			{Title: "On", Value: "on", Fields: []Field{{Title: "mode", Type: FieldString}}},
+		}}}, wantErr: "form.ssl.oneOf[0]: mode is reserved"},
+		{name: "min above max", form: []Field{{Title: "port", Type: FieldInteger, Min: &min, Max: &max}}, wantErr: "form.port: min must not exceed max"},
+		{name: "an invalid pattern", form: []Field{{Title: "host", Type: FieldString, Pattern: "("}}, wantErr: "form.host: invalid pattern"},
+		{name: "an unsupported format", form: []Field{{Title: "host", Type: FieldString, Format: "url"}}, wantErr: `form.host: unsupported format "url"`},
+		{name: "an unknown required field", form: []Field{{Title: "key_password", Type: FieldString, Requires: []string{"key"}}}, wantErr: `form.key_password: requires unknown field "key"`},
+		{name: "a default of another type", form: []Field{{Title: "port", Type: FieldInteger, Default: "5432"}}, wantErr: "form.port: default must be a integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := Connector{Version: ConnectorVersion, SourceType: "test", Name: "Test", Form: tt.form}
+
+			err := connector.Validate()
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("an unsupported version", func(t *testing.T) {
+		connector := Connector{Version: ConnectorVersion + 1, SourceType: "test", Name: "Test", Form: []Field{{Title: "host", Type: FieldString}}}
+
+		if err := connector.Validate(); err == nil || !strings.Contains(err.Error(), "unsupported version") {
+			t.Fatalf("Validate() error = %v, want an unsupported version", err)
+		}
+	})
+}
@@ -9,6 +9,7 @@ const (
 // Minimal ETL operations
 type ETL interface {
 	CreateSourceConnection(name string, configuration map[string]interface{}) (*string, error)
+	UpdateSourceConnection(sourceId string, configuration map[string]interface{}) error
 	DeleteSourceConnection(sourceId string) error
 	TestSourceConnection(sourceId string) error
 	RetrieveSourceSchemas(sourceId string) ([]SourceSchema, error)
@@ -0,0 +1,92 @@
+package invitation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/notifier"
+)
+
+// TTL is how long an invitation can be accepted after it is sent
+const TTL = 7 * 24 * time.Hour
+
+var (
+	ErrInvitationExpired  = errors.New("invitation has expired")
+	ErrInvitationAccepted = errors.New("invitation has already been accepted")
+)
+
+type InvitationStatus string
+
+const (
+	Pending  InvitationStatus = "PENDING"
+	Accepted InvitationStatus = "ACCEPTED"
+	Expired  InvitationStatus = "EXPIRED"
+)
+
+func (i *Invitation) Status(now time.Time) InvitationStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return Accepted
+	case now.After(i.ExpiresAt):
+		return Expired
+	default:
+		return Pending
+	}
+}
+
+// CheckAcceptable rejects invitations that were already used or have expired
+func (i *Invitation) CheckAcceptable(now time.Time) error {
+	switch i.Status(now) {
+	case Accepted:
+		return ErrInvitationAccepted
+	case Expired:
+		return ErrInvitationExpired
+	default:
+		return nil
+	}
+}
+
+func GenerateToken() (raw string, hash string, err error) {
+	b := make([]byte, 32)
+
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	raw = base64.RawURLEncoding.EncodeToString(b)
+
+	return raw, HashToken(raw), nil
+}
+
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// BuildMessage builds the notification that delivers an invite token to the invitee.
+// When invitationURL is set the token is appended to it as a link
+func BuildMessage(i *Invitation, organizationName string, token string, invitationURL string) notifier.Message {
+	accept := fmt.Sprintf("Invite token: %s", token)
+
+	if invitationURL != "" {
+		accept = fmt.Sprintf("Accept the invitation: %s?token=%s", invitationURL, url.QueryEscape(token))
+	}
+
+	return notifier.Message{
+		To:      i.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s", organizationName),
+		Body: fmt.Sprintf(
+			"You have been invited to join %s as %s.\n\n%s\n\nThis invitation expires on %s.",
+			organizationName,
+			i.Role,
+			accept,
+			i.ExpiresAt.UTC().Format(time.RFC1123),
+		),
+	}
+}
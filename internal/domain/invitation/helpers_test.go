@@ -0,0 +1,82 @@
+package invitation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckAcceptable(t *testing.T) {
+	now := time.Now()
+	acceptedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		invitation Invitation
+		wantStatus InvitationStatus
+		wantErr    error
+	}{
+		{name: "a pending invitation", invitation: Invitation{ExpiresAt: now.Add(time.Hour)}, wantStatus: Pending},
+		{name: "an expired invitation", invitation: Invitation{ExpiresAt: now.Add(-time.Hour)}, wantStatus: Expired, wantErr: ErrInvitationExpired},
+		{name: "an accepted invitation", invitation: Invitation{ExpiresAt: now.Add(time.Hour), AcceptedAt: &acceptedAt}, wantStatus: Accepted, wantErr: ErrInvitationAccepted},
+		{name: "an invitation accepted before it expired", invitation: Invitation{ExpiresAt: now.Add(-time.Minute), AcceptedAt: &acceptedAt}, wantStatus: Accepted, wantErr: ErrInvitationAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.invitation.Status(now); got != tt.wantStatus {
+				t.Fatalf("Status() = %v, want %v", got, tt.wantStatus)
+			}
+
+			if err := tt.invitation.CheckAcceptable(now); err != tt.wantErr {
+				t.Fatalf("CheckAcceptable() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	raw, hash, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	if hash != HashToken(raw) {
+		t.Fatalf("GenerateToken() hash = %s, want %s", hash, HashToken(raw))
+	}
+
+	if strings.Contains(hash, raw) {
+		t.Fatal("GenerateToken() hash contains the token")
+	}
+
+	if other, _, _ := GenerateToken(); other == raw {
+		t.Fatal("GenerateToken() returned the same token twice")
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	_invitation := &Invitation{Email: "invitee@example.com", Role: "GUEST", ExpiresAt: time.Now().Add(TTL)}
+
+	tests := []struct {
+		name          string
+		invitationURL string
+		want          string
+	}{
+		{name: "without an invitation URL", want: "Invite token: a+b"},
+		{name: "with an invitation URL", invitationURL: "https://app.example.com/invitations/accept", want: "https://app.example.com/invitations/accept?token=a%2Bb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := BuildMessage(_invitation, "Acme", "a+b", tt.invitationURL)
+
+			if message.To != _invitation.Email {
+				t.Fatalf("BuildMessage() To = %s, want %s", message.To, _invitation.Email)
+			}
+
+			if !strings.Contains(message.Body, tt.want) {
+				t.Fatalf("BuildMessage() Body = %q, want it to contain %q", message.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package invitation
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+)
+
+// Invitation is a single-use, expiring invite for an email to join an organization.
+// Only the hash of the invite token is stored
+type Invitation struct {
+	gorm.Model
+
+	Email          string              `gorm:"not null;index"`
+	Role           account.AccountRole `gorm:"type:text;not null"`
+	ProjectRoles   map[string]string   `gorm:"type:jsonb;serializer:json;default:'{}'"` // project key -> project role
+	TokenHash      string              `gorm:"unique;not null" json:"-"`
+	ExpiresAt      time.Time           `gorm:"not null"`
+	AcceptedAt     *time.Time
+	InvitedBy      map[string]string   `gorm:"type:jsonb;serializer:json;default:'{}'"`
+	OrganizationID uint                `gorm:"not null;index"` // <- foreign key to Organization
+}
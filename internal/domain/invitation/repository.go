@@ -0,0 +1,11 @@
+package invitation
+
+type InvitationRepository interface {
+	Find(organizationId uint) (*[]Invitation, error)
+	FindOne(id uint, organizationId uint) (*Invitation, error)
+	FindOneByTokenHash(tokenHash string) (*Invitation, error)
+	Create(payload *Invitation) (*Invitation, error)
+	Update(payload *Invitation) error
+	Delete(id uint, organizationId uint) error
+	DeletePending(email string, organizationId uint) error
+}
@@ -0,0 +1,61 @@
+package lockout
+
+import (
+	"fmt"
+	"time"
+)
+
+type LockoutScope string
+
+const (
+	ScopeAccount LockoutScope = "account"
+	ScopeIP      LockoutScope = "ip"
+)
+
+type AttemptKind string
+
+const (
+	AttemptPassword AttemptKind = "password"
+	AttemptMFA      AttemptKind = "mfa"
+)
+
+func BuildFailedAttemptsKey(scope LockoutScope, subject string) string {
+	return fmt.Sprintf("failed-attempts-%s-%s", scope, subject)
+}
+
+func BuildLockoutKey(scope LockoutScope, subject string) string {
+	return fmt.Sprintf("lockout-%s-%s", scope, subject)
+}
+
+func BuildNotBeforeKey(email string) string {
+	return fmt.Sprintf("not-before-%s", email)
+}
+
+func BuildChallengeAttemptsKey(challengeID string) string {
+	return fmt.Sprintf("challenge-attempts-%s", challengeID)
+}
+
+// ProgressiveDelay returns the delay applied after the given number of consecutive failures,
+// doubling from base and capped at max
+func ProgressiveDelay(failures int64, base time.Duration, max time.Duration) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	delay := base
+	for i := int64(1); i < failures && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+
+	return delay
+}
+
+// Lockout is an active temporary lockout of an account or client IP
+type Lockout struct {
+	Scope LockoutScope
+	Until time.Time
+}
@@ -0,0 +1,28 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressiveDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int64
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: 0},
+		{name: "first failure", failures: 1, want: 250 * time.Millisecond},
+		{name: "doubles on each failure", failures: 3, want: time.Second},
+		{name: "reaches the cap", failures: 5, want: 4 * time.Second},
+		{name: "stays at the cap", failures: 40, want: 4 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProgressiveDelay(tt.failures, 250*time.Millisecond, 4*time.Second); got != tt.want {
+				t.Fatalf("ProgressiveDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
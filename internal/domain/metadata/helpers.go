@@ -0,0 +1,24 @@
+package metadata
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+var (
+	ErrSetupCompleted        = errors.New("setup has already been completed")
+	ErrInvalidBootstrapToken = errors.New("invalid bootstrap token")
+)
+
+func (m *Metadata) IsSetupCompleted() bool {
+	return m.SetupCompletedAt != nil
+}
+
+// CheckBootstrapToken compares the given token against the stored one in constant time
+func (m *Metadata) CheckBootstrapToken(token string) error {
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(m.BootstrapToken)) != 1 {
+		return ErrInvalidBootstrapToken
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckBootstrapToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  string
+		token   string
+		wantErr error
+	}{
+		{name: "the stored token", stored: "bootstrap", token: "bootstrap"},
+		{name: "another token", stored: "bootstrap", token: "bootstrap2", wantErr: ErrInvalidBootstrapToken},
+		{name: "an empty token", stored: "bootstrap", wantErr: ErrInvalidBootstrapToken},
+		{name: "an empty token without a stored token", wantErr: ErrInvalidBootstrapToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_metadata := &Metadata{BootstrapToken: tt.stored}
+
+			if err := _metadata.CheckBootstrapToken(tt.token); err != tt.wantErr {
+				t.Fatalf("CheckBootstrapToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsSetupCompleted(t *testing.T) {
+	completedAt := time.Now()
+
+	if (&Metadata{}).IsSetupCompleted() {
+		t.Fatal("IsSetupCompleted() = true without SetupCompletedAt")
+	}
+
+	if !(&Metadata{SetupCompletedAt: &completedAt}).IsSetupCompleted() {
+		t.Fatal("IsSetupCompleted() = false with SetupCompletedAt")
+	}
+}
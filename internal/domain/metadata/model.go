@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -9,4 +11,6 @@ type Metadata struct {
 
 	BootstrapToken         string `gorm:"unique;not null"`
 	Language         string `gorm:"not null"`
+	PolicyVersion    uint   `gorm:"not null;default:0"`
+	SetupCompletedAt *time.Time
 }
\ No newline at end of file
@@ -1,7 +1,12 @@
 package metadata
 
+import "time"
+
 type MetadataRepository interface {
 	FindOne() (*Metadata, error)
 	Create(payload *Metadata) (*Metadata, error)
 	Update(payload *Metadata) error
+	IncrementPolicyVersion() error
+	LockSetup(at time.Time) (bool, error)
+	UnlockSetup() error
 }
\ No newline at end of file
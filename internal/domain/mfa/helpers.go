@@ -4,11 +4,30 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base32"
 	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
+const (
+	RecoveryCodeCount            = 10
+	RecoveryCodeWarningThreshold = 3 // remaining codes at which users are warned to regenerate
+)
+
+// EnrollmentTTL is how long a rotated secret waits to be confirmed before it is discarded
+const EnrollmentTTL = 10 * time.Minute
+
+var ErrEnrollmentRequired = errors.New("mfa enrollment is required")
+
+func BuildEnrollmentKey(accountId uint) string {
+	return fmt.Sprintf("mfa-enrollment-%d", accountId)
+}
+
 func ConstantTimeCompare(a, b uint32) bool {
 	var r uint32
 	r |= a ^ b
@@ -53,4 +72,32 @@ func GenerateTOTP(secret string, t time.Time) (uint32, error) {
 
 	// 6-digit code
 	return code % 1_000_000, nil
+}
+
+// GenerateRecoveryCodes returns a fresh set of single-use recovery codes, formatted as
+// "xxxxx-xxxxx", along with the hashes to store
+func GenerateRecoveryCodes() (codes []string, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
 }
\ No newline at end of file
@@ -0,0 +1,93 @@
+package mfa
+
+import (
+	"encoding/base32"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestGenerateTOTP(t *testing.T) {
+	// The SHA-1 test vectors of RFC 6238, truncated to six digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		time int64
+		want uint32
+	}{
+		{time: 59, want: 287082},
+		{time: 1111111109, want: 81804},
+		{time: 1111111111, want: 50471},
+		{time: 1234567890, want: 5924},
+		{time: 2000000000, want: 279037},
+		{time: 20000000000, want: 353130},
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.time, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := GenerateTOTP(secret, time.Unix(tt.time, 0))
+
+			if err != nil {
+				t.Fatalf("GenerateTOTP() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("GenerateTOTP() = %06d, want %06d", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := GenerateTOTP("not base32!", time.Now()); err == nil {
+		t.Fatal("GenerateTOTP() accepted an invalid secret")
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := HashRecoveryCode("abcde-fghij")
+
+	tests := []struct {
+		name string
+		code string
+		same bool
+	}{
+		{name: "as issued", code: "abcde-fghij", same: true},
+		{name: "upper case", code: "ABCDE-FGHIJ", same: true},
+		{name: "without the dash", code: "abcdefghij", same: true},
+		{name: "with spaces", code: " abcde fghij ", same: true},
+		{name: "another code", code: "abcde-fghik"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashRecoveryCode(tt.code); (got == want) != tt.same {
+				t.Fatalf("HashRecoveryCode(%q) matches = %v, want %v", tt.code, got == want, tt.same)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes and %d hashes, want %d", len(codes), len(hashes), RecoveryCodeCount)
+	}
+
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Fatalf("code %q is not formatted as xxxxx-xxxxx", code)
+		}
+		if hashes[i] != HashRecoveryCode(code) {
+			t.Fatalf("hash of code %q does not match", code)
+		}
+		if seen[code] {
+			t.Fatalf("code %q was issued twice", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,21 @@
+package notifier
+
+type NotifierType string
+
+const (
+	NotifierTypeLog  NotifierType = "log"
+	NotifierTypeFile NotifierType = "file"
+	NotifierTypeSMTP NotifierType = "smtp"
+)
+
+// Message is a notification addressed to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to recipients out of band, e.g. invitation links
+type Notifier interface {
+	Send(message Message) error
+}
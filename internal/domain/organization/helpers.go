@@ -0,0 +1,27 @@
+package organization
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DefaultKey is the key of the organization that existed before multi-organization tenancy.
+// Records created before then are bound to it on migration
+const DefaultKey = "default"
+
+func NewOrgScope(value string) (OrgScope, error) {
+	switch OrgScope(value) {
+	case Public, Private:
+		return OrgScope(value), nil
+	default:
+		return "", errors.New("invalid organization scope")
+	}
+}
+
+// GenerateOrganizationKey generates a random organization key, e.g. "org-1a2b3c4d"
+func GenerateOrganizationKey() string {
+	return fmt.Sprintf("org-%s", strings.Split(uuid.New().String(), "-")[0])
+}
@@ -10,4 +10,5 @@ type Organization struct {
 	Name         string `gorm:"unique;not null"`
 	Key         string `gorm:"unique;not null"`
 	Scope		 OrgScope `gorm:"type:text;not null"`
+	Language     string // <- empty until the organization picks one
 }
\ No newline at end of file
@@ -1,7 +1,12 @@
 package organization
 
+import "github.com/darksuei/suei-intelligence/internal/domain/account"
+
 type OrganizationRepository interface {
 	FindOne(key string) (*Organization, error)
+	FindOneByID(id uint) (*Organization, error)
+	Count() (int64, error)
 	Create(payload *Organization) (*Organization, error)
+	CreateWithAdmin(payload *Organization, admin *account.Account) (*Organization, *account.Account, error)
 	Update(payload *Organization) error
 }
\ No newline at end of file
@@ -13,4 +13,12 @@ type ProjectStage string
 const (
 	Sandbox ProjectStage = "SANDBOX"
 	Production ProjectStage = "PRODUCTION"
+)
+
+type ProjectRole string
+
+const (
+	Owner  ProjectRole = "OWNER"
+	Editor ProjectRole = "EDITOR"
+	Viewer ProjectRole = "VIEWER"
 )
\ No newline at end of file
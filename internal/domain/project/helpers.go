@@ -0,0 +1,96 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrProjectArchived = errors.New("Project is archived and read-only.")
+var ErrProjectPaused = errors.New("Project is paused and does not sync datasources.")
+
+func NewProjectRole(value string) (ProjectRole, error) {
+	switch ProjectRole(value) {
+	case Owner, Editor, Viewer:
+		return ProjectRole(value), nil
+	default:
+		return "", errors.New("invalid project role")
+	}
+}
+
+// RolePrivilege ranks project roles, higher grants more
+func RolePrivilege(role ProjectRole) int {
+	switch role {
+	case Owner:
+		return 3
+	case Editor:
+		return 2
+	case Viewer:
+		return 1
+	}
+	return 0
+}
+
+type ProjectStatusAction string
+
+const (
+	Pause   ProjectStatusAction = "pause"
+	Resume  ProjectStatusAction = "resume"
+	Archive ProjectStatusAction = "archive"
+	Restore ProjectStatusAction = "restore"
+)
+
+type statusTransition struct {
+	from []ProjectStatus
+	to   ProjectStatus
+}
+
+// statusTransitions defines the allowed project lifecycle:
+//
+//	ACTIVE   --pause-->   PAUSED
+//	PAUSED   --resume-->  ACTIVE
+//	ACTIVE   --archive--> ARCHIVED
+//	PAUSED   --archive--> ARCHIVED
+//	ARCHIVED --restore--> ACTIVE
+var statusTransitions = map[ProjectStatusAction]statusTransition{
+	Pause:   {from: []ProjectStatus{Active}, to: Paused},
+	Resume:  {from: []ProjectStatus{Paused}, to: Active},
+	Archive: {from: []ProjectStatus{Active, Paused}, to: Archived},
+	Restore: {from: []ProjectStatus{Archived}, to: Active},
+}
+
+// NextStatus returns the status a project moves to when the given action
+// is applied, or an error if the transition is not allowed
+func NextStatus(current ProjectStatus, action ProjectStatusAction) (ProjectStatus, error) {
+	transition, ok := statusTransitions[action]
+	if !ok {
+		return "", fmt.Errorf("unknown project status action: %s", action)
+	}
+
+	for _, from := range transition.from {
+		if from == current {
+			return transition.to, nil
+		}
+	}
+
+	return "", fmt.Errorf("Cannot %s a project that is %s.", action, current)
+}
+
+// CheckWritable fails for archived projects, which are read-only
+func (p *Project) CheckWritable() error {
+	if p.Status == Archived {
+		return ErrProjectArchived
+	}
+	return nil
+}
+
+// CheckSyncable fails for projects whose datasources must not reach the ETL,
+// archived projects are read-only and paused projects suspend syncs
+func (p *Project) CheckSyncable() error {
+	if err := p.CheckWritable(); err != nil {
+		return err
+	}
+	if p.Status == Paused {
+		return ErrProjectPaused
+	}
+	return nil
+}
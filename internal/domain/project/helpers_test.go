@@ -0,0 +1,81 @@
+package project
+
+import "testing"
+
+func TestNextStatus(t *testing.T) {
+	tests := []struct {
+		current ProjectStatus
+		action  ProjectStatusAction
+		want    ProjectStatus
+		wantErr bool
+	}{
+		{current: Active, action: Pause, want: Paused},
+		{current: Paused, action: Pause, wantErr: true},
+		{current: Archived, action: Pause, wantErr: true},
+
+		{current: Paused, action: Resume, want: Active},
+		{current: Active, action: Resume, wantErr: true},
+		{current: Archived, action: Resume, wantErr: true},
+
+		{current: Active, action: Archive, want: Archived},
+		{current: Paused, action: Archive, want: Archived},
+		{current: Archived, action: Archive, wantErr: true},
+
+		{current: Archived, action: Restore, want: Active},
+		{current: Active, action: Restore, wantErr: true},
+		{current: Paused, action: Restore, wantErr: true},
+
+		{current: Active, action: "delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action)+" "+string(tt.current), func(t *testing.T) {
+			got, err := NextStatus(tt.current, tt.action)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NextStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NextStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	tests := []struct {
+		status  ProjectStatus
+		wantErr error
+	}{
+		{status: Active},
+		{status: Paused},
+		{status: Archived, wantErr: ErrProjectArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if err := (&Project{Status: tt.status}).CheckWritable(); err != tt.wantErr {
+				t.Fatalf("CheckWritable() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSyncable(t *testing.T) {
+	tests := []struct {
+		status  ProjectStatus
+		wantErr error
+	}{
+		{status: Active},
+		{status: Paused, wantErr: ErrProjectPaused},
+		{status: Archived, wantErr: ErrProjectArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if err := (&Project{Status: tt.status}).CheckSyncable(); err != tt.wantErr {
+				t.Fatalf("CheckSyncable() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -9,8 +9,9 @@ import (
 type Project struct {
 	gorm.Model
 
-	Name            string                   `gorm:"unique;not null"`
-	Key             string                   `gorm:"unique;not null"`
+	Name            string                   `gorm:"not null;uniqueIndex:idx_projects_organization_name"`
+	Key             string                   `gorm:"not null;uniqueIndex:idx_projects_organization_key"`
+	OrganizationID  uint                     `gorm:"not null;default:0;uniqueIndex:idx_projects_organization_name;uniqueIndex:idx_projects_organization_key"` // <- foreign key to Organization
 	Status          ProjectStatus            `gorm:"type:text;not null"`
 	Stage           ProjectStage             `gorm:"type:text;not null"`
 	BusinessDomain  string    				 `gorm:"not null"`
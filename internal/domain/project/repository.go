@@ -1,8 +1,9 @@
 package project
 
 type ProjectRepository interface {
-	Find() (*[]Project, error)
-	FindOneByKey(key string) (*Project, error)
+	Find(organizationId uint) (*[]Project, error)
+	FindOneByKey(key string, organizationId uint) (*Project, error)
+	FindOneByID(id uint) (*Project, error)
 	Create(payload *Project) (*Project, error)
 	Update(payload *Project) error
 }
\ No newline at end of file
@@ -0,0 +1,377 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Attributes are the values of a resource by lowercased attribute path, e.g. "name.givenname"
+// or "emails.value". The values of a multi-valued attribute with a "value" sub-attribute are
+// also listed under the attribute itself, so "emails eq ..." matches any email
+type Attributes map[string][]string
+
+// Filter is a parsed SCIM filter expression (RFC 7644, section 3.4.2.2)
+type Filter interface {
+	Matches(attributes Attributes) bool
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right Filter
+}
+
+func (f *logicalFilter) Matches(attributes Attributes) bool {
+	if f.and {
+		return f.left.Matches(attributes) && f.right.Matches(attributes)
+	}
+	return f.left.Matches(attributes) || f.right.Matches(attributes)
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f *notFilter) Matches(attributes Attributes) bool {
+	return !f.filter.Matches(attributes)
+}
+
+type attributeFilter struct {
+	path     string
+	operator string
+	value    *string // <- nil for null and the pr operator
+}
+
+func (f *attributeFilter) Matches(attributes Attributes) bool {
+	values := attributes[f.path]
+
+	switch f.operator {
+	case "pr":
+		return len(values) > 0
+	case "ne":
+		if f.value == nil {
+			return len(values) > 0
+		}
+		for _, value := range values {
+			if strings.EqualFold(value, *f.value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if f.value == nil {
+		return f.operator == "eq" && len(values) == 0
+	}
+
+	expected := strings.ToLower(*f.value)
+
+	for _, value := range values {
+		value = strings.ToLower(value)
+
+		var matched bool
+
+		switch f.operator {
+		case "eq":
+			matched = value == expected
+		case "co":
+			matched = strings.Contains(value, expected)
+		case "sw":
+			matched = strings.HasPrefix(value, expected)
+		case "ew":
+			matched = strings.HasSuffix(value, expected)
+		case "gt":
+			matched = value > expected
+		case "ge":
+			matched = value >= expected
+		case "lt":
+			matched = value < expected
+		case "le":
+			matched = value <= expected
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+var filterOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true, "pr": true,
+}
+
+// ParseFilter parses a filter expression. Attribute paths are matched case-insensitively and
+// may be qualified with their schema. Value path filters such as emails[type eq "work"] are
+// not supported in filters, use emails.type instead
+func ParseFilter(expression string) (Filter, error) {
+	tokens, err := tokenizeFilter(expression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+
+	filter, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.position != len(p.tokens) {
+		return nil, ErrInvalidFilter
+	}
+
+	return filter, nil
+}
+
+type filterToken struct {
+	value  string
+	quoted bool
+}
+
+type filterParser struct {
+	tokens   []filterToken
+	position int
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.position >= len(p.tokens) {
+		return filterToken{}, false
+	}
+	return p.tokens[p.position], true
+}
+
+func (p *filterParser) next() (filterToken, bool) {
+	token, ok := p.peek()
+	if ok {
+		p.position++
+	}
+	return token, ok
+}
+
+func (p *filterParser) keyword(keyword string) bool {
+	token, ok := p.peek()
+	if ok && !token.quoted && strings.EqualFold(token.value, keyword) {
+		p.position++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = &logicalFilter{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.keyword("not") {
+		if !p.keyword("(") {
+			return nil, ErrInvalidFilter
+		}
+
+		filter, err := p.parseGroup()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &notFilter{filter: filter}, nil
+	}
+
+	if p.keyword("(") {
+		return p.parseGroup()
+	}
+
+	return p.parseAttribute()
+}
+
+func (p *filterParser) parseGroup() (Filter, error) {
+	filter, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.keyword(")") {
+		return nil, ErrInvalidFilter
+	}
+
+	return filter, nil
+}
+
+func (p *filterParser) parseAttribute() (Filter, error) {
+	path, ok := p.next()
+
+	if !ok || path.quoted || path.value == "(" || path.value == ")" || strings.ContainsAny(path.value, "[]") {
+		return nil, ErrInvalidFilter
+	}
+
+	operator, ok := p.next()
+
+	if !ok || operator.quoted || !filterOperators[strings.ToLower(operator.value)] {
+		return nil, ErrInvalidFilter
+	}
+
+	filter := &attributeFilter{
+		path: strings.ToLower(stripSchema(path.value)),
+		operator: strings.ToLower(operator.value),
+	}
+
+	if filter.operator == "pr" {
+		return filter, nil
+	}
+
+	value, ok := p.next()
+
+	if !ok {
+		return nil, ErrInvalidFilter
+	}
+
+	if value.quoted {
+		filter.value = &value.value
+		return filter, nil
+	}
+
+	switch literal := strings.ToLower(value.value); {
+	case literal == "null":
+	case literal == "true" || literal == "false":
+		filter.value = &literal
+	default:
+		if _, err := strconv.ParseFloat(literal, 64); err != nil {
+			return nil, ErrInvalidFilter
+		}
+		filter.value = &literal
+	}
+
+	return filter, nil
+}
+
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	tokens := []filterToken{}
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, filterToken{value: string(r)})
+			i++
+		case r == '"':
+			// JSON string, including its escapes
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				if runes[end] == '\\' {
+					end++
+				}
+			}
+
+			if end >= len(runes) {
+				return nil, ErrInvalidFilter
+			}
+
+			var value string
+			if err := json.Unmarshal([]byte(string(runes[i:end+1])), &value); err != nil {
+				return nil, ErrInvalidFilter
+			}
+
+			tokens = append(tokens, filterToken{value: value, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"'; end++ {
+			}
+
+			tokens = append(tokens, filterToken{value: string(runes[i:end])})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// BuildAttributes flattens a resource, as it is represented in JSON, into its attributes
+func BuildAttributes(resource interface{}) Attributes {
+	var decoded interface{}
+
+	if raw, err := json.Marshal(resource); err == nil {
+		_ = json.Unmarshal(raw, &decoded)
+	}
+
+	attributes := Attributes{}
+	flattenAttributes(attributes, "", decoded)
+
+	return attributes
+}
+
+func flattenAttributes(attributes Attributes, path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := strings.ToLower(key)
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+
+			flattenAttributes(attributes, childPath, child)
+		}
+
+		if path != "" {
+			if primary, ok := v["value"]; ok {
+				flattenAttributes(attributes, path, primary)
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			flattenAttributes(attributes, path, child)
+		}
+	case string:
+		attributes[path] = append(attributes[path], v)
+	case bool:
+		attributes[path] = append(attributes[path], strconv.FormatBool(v))
+	case float64:
+		attributes[path] = append(attributes[path], strconv.FormatFloat(v, 'f', -1, 64))
+	}
+}
@@ -0,0 +1,87 @@
+package scim
+
+import (
+	"errors"
+	"testing"
+)
+
+var testUser = UserResource{
+	Schemas: []string{SchemaUser},
+	ID: "42",
+	UserName: "Ada@Example.com",
+	Name: &Name{GivenName: "Ada", FamilyName: "Lovelace"},
+	Emails: []Email{{Value: "ada@example.com", Type: "work", Primary: true}, {Value: "ada@home.example", Type: "home"}},
+	Active: func() *bool { active := true; return &active }(),
+}
+
+func TestParseFilterMatches(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{expression: `userName eq "ada@example.com"`, want: true},
+		{expression: `USERNAME EQ "ADA@EXAMPLE.COM"`, want: true},
+		{expression: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "ada@example.com"`, want: true},
+		{expression: `userName ne "ada@example.com"`, want: false},
+		{expression: `userName co "example"`, want: true},
+		{expression: `userName sw "ada@"`, want: true},
+		{expression: `userName ew ".org"`, want: false},
+		{expression: `name.familyName eq "Lovelace"`, want: true},
+		{expression: `name.formatted pr`, want: false},
+		{expression: `name.givenName pr`, want: true},
+		{expression: `externalId eq null`, want: true},
+		{expression: `externalId ne null`, want: false},
+		{expression: `emails eq "ada@home.example"`, want: true},
+		{expression: `emails.type eq "home"`, want: true},
+		{expression: `active eq true`, want: true},
+		{expression: `active eq false`, want: false},
+		{expression: `id gt "41"`, want: true},
+		{expression: `id le "41"`, want: false},
+		{expression: `userName eq "ada@example.com" and name.givenName eq "Grace"`, want: false},
+		{expression: `userName eq "grace@example.com" or name.givenName eq "Ada"`, want: true},
+		{expression: `not (name.givenName eq "Ada")`, want: false},
+		{expression: `name.givenName eq "Grace" or name.givenName eq "Ada" and name.familyName eq "Hopper"`, want: false},
+		{expression: `(name.givenName eq "Grace" or name.givenName eq "Ada") and name.familyName eq "Lovelace"`, want: true},
+	}
+
+	attributes := BuildAttributes(testUser)
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+
+			if got := filter.Matches(attributes); got != tt.want {
+				t.Fatalf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterInvalid(t *testing.T) {
+	tests := []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName is "ada"`,
+		`userName eq ada`,
+		`userName eq "ada`,
+		`"userName" eq "ada"`,
+		`emails[type eq "work"] pr`,
+		`(userName eq "ada"`,
+		`userName eq "ada")`,
+		`not userName eq "ada"`,
+		`userName eq "ada" and`,
+		`userName eq "ada" userName eq "ada"`,
+	}
+
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := ParseFilter(expression); !errors.Is(err, ErrInvalidFilter) {
+				t.Fatalf("ParseFilter() error = %v, want %v", err, ErrInvalidFilter)
+			}
+		})
+	}
+}
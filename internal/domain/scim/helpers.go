@@ -0,0 +1,131 @@
+package scim
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/project"
+)
+
+const (
+	TokenPrefix     = "scim_"
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// Error is a SCIM error, rendered with its HTTP status and, for 400 and 409, a scimType
+type Error struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func NewError(status int, scimType string, detail string) *Error {
+	return &Error{Status: status, ScimType: scimType, Detail: detail}
+}
+
+var (
+	ErrNotFound      = NewError(http.StatusNotFound, "", "Resource not found.")
+	ErrUniqueness    = NewError(http.StatusConflict, "uniqueness", "A resource with this identifier already exists.")
+	ErrInvalidFilter = NewError(http.StatusBadRequest, "invalidFilter", "The filter is invalid or not supported.")
+	ErrInvalidPath   = NewError(http.StatusBadRequest, "invalidPath", "The path attribute is invalid or not supported.")
+	ErrInvalidValue  = NewError(http.StatusBadRequest, "invalidValue", "A required value was missing or invalid.")
+	ErrMutability    = NewError(http.StatusBadRequest, "mutability", "The attribute is read-only.")
+)
+
+// GenerateToken returns a new bearer token and the hash it is stored under
+func GenerateToken() (string, string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ResolvedRoles are the roles an account is granted by the groups of its organization
+type ResolvedRoles struct {
+	OrganizationRole    account.AccountRole
+	OrganizationManaged bool // <- the organization role is managed when any group grants one
+	ProjectRoles        map[string]project.ProjectRole
+	ManagedProjects     []string // <- projects any group grants a role on
+}
+
+// ResolveRoles resolves the roles of an account from the groups of its organization. The
+// highest role granted by any of its groups wins. Roles that are managed through groups but
+// granted by none of the account's groups fall back to guest, or are removed for projects
+func ResolveRoles(groups []Group, accountId uint) ResolvedRoles {
+	resolved := ResolvedRoles{
+		OrganizationRole: account.Guest,
+		ProjectRoles: map[string]project.ProjectRole{},
+		ManagedProjects: []string{},
+	}
+
+	managedProjects := map[string]bool{}
+
+	for _, group := range groups {
+		if group.OrganizationRole != "" {
+			resolved.OrganizationManaged = true
+		}
+
+		for projectKey := range group.ProjectRoles {
+			if !managedProjects[projectKey] {
+				managedProjects[projectKey] = true
+				resolved.ManagedProjects = append(resolved.ManagedProjects, projectKey)
+			}
+		}
+
+		if !group.HasMember(accountId) {
+			continue
+		}
+
+		if account.RolePrivilege(group.OrganizationRole) > account.RolePrivilege(resolved.OrganizationRole) {
+			resolved.OrganizationRole = group.OrganizationRole
+		}
+
+		for projectKey, role := range group.ProjectRoles {
+			if project.RolePrivilege(role) > project.RolePrivilege(resolved.ProjectRoles[projectKey]) {
+				resolved.ProjectRoles[projectKey] = role
+			}
+		}
+	}
+
+	return resolved
+}
+
+func (g *Group) HasMember(accountId uint) bool {
+	for _, member := range g.Members {
+		if member == accountId {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSchema removes the schema URN an attribute path may be qualified with, e.g.
+// "urn:ietf:params:scim:schemas:core:2.0:User:userName" -> "userName"
+func stripSchema(path string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(path) > len(schema) && strings.EqualFold(path[:len(schema)+1], schema+":") {
+			return path[len(schema)+1:]
+		}
+	}
+	return path
+}
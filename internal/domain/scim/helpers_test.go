@@ -0,0 +1,81 @@
+package scim
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/project"
+)
+
+func TestResolveRoles(t *testing.T) {
+	groups := []Group{
+		{DisplayName: "Admins", Members: []uint{1}, OrganizationRole: account.Admin},
+		{DisplayName: "Engineering", Members: []uint{1, 2}, ProjectRoles: map[string]project.ProjectRole{"core": project.Viewer, "web": project.Editor}},
+		{DisplayName: "Core", Members: []uint{2}, ProjectRoles: map[string]project.ProjectRole{"core": project.Owner}},
+	}
+
+	tests := []struct {
+		name      string
+		groups    []Group
+		accountId uint
+		want      ResolvedRoles
+	}{
+		{
+			name: "grants the roles of the account's groups",
+			groups: groups,
+			accountId: 1,
+			want: ResolvedRoles{
+				OrganizationRole: account.Admin,
+				OrganizationManaged: true,
+				ProjectRoles: map[string]project.ProjectRole{"core": project.Viewer, "web": project.Editor},
+				ManagedProjects: []string{"core", "web"},
+			},
+		},
+		{
+			name: "grants the highest role of any group",
+			groups: groups,
+			accountId: 2,
+			want: ResolvedRoles{
+				OrganizationRole: account.Guest,
+				OrganizationManaged: true,
+				ProjectRoles: map[string]project.ProjectRole{"core": project.Owner, "web": project.Editor},
+				ManagedProjects: []string{"core", "web"},
+			},
+		},
+		{
+			name: "falls back to guest for accounts in no group",
+			groups: groups,
+			accountId: 3,
+			want: ResolvedRoles{
+				OrganizationRole: account.Guest,
+				OrganizationManaged: true,
+				ProjectRoles: map[string]project.ProjectRole{},
+				ManagedProjects: []string{"core", "web"},
+			},
+		},
+		{
+			name: "manages no role without groups",
+			accountId: 1,
+			want: ResolvedRoles{
+				OrganizationRole: account.Guest,
+				ProjectRoles: map[string]project.ProjectRole{},
+				ManagedProjects: []string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveRoles(tt.groups, tt.accountId)
+
+			// Projects of a single group are listed in map order
+			sort.Strings(got.ManagedProjects)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ResolveRoles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package scim
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/project"
+)
+
+// Token is a bearer token a directory provisions an organization's accounts with
+type Token struct {
+	gorm.Model
+
+	OrganizationID uint   `gorm:"not null;index"` // <- foreign key to Organization
+	Name           string `gorm:"not null"`
+	TokenHash      string `gorm:"unique;not null" json:"-"` // <- SHA-256 of the token, which is only shown once
+	LastUsedAt     *time.Time
+}
+
+// Group is a directory group. Its members are granted the group's roles
+type Group struct {
+	gorm.Model
+
+	OrganizationID   uint                           `gorm:"not null;uniqueIndex:idx_scim_groups_organization_name"` // <- foreign key to Organization
+	DisplayName      string                         `gorm:"not null;uniqueIndex:idx_scim_groups_organization_name"`
+	ExternalID       string
+	Members          []uint                         `gorm:"type:jsonb;serializer:json;default:'[]'"` // <- account IDs
+	OrganizationRole account.AccountRole            `gorm:"type:text"` // <- none granted when empty
+	ProjectRoles     map[string]project.ProjectRole `gorm:"type:jsonb;serializer:json;default:'{}'"` // project key -> project role
+}
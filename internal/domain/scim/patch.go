@@ -0,0 +1,280 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrInvalidPatchOp = NewError(http.StatusBadRequest, "invalidSyntax", "The patch operation is invalid or not supported.")
+	ErrNoTarget       = NewError(http.StatusBadRequest, "noTarget", "The path did not match any value.")
+)
+
+// ApplyPatch applies PATCH operations (RFC 7644, section 3.5.2) to a resource as it is
+// represented in JSON. Operations without a path apply each attribute of their value, which
+// may itself be a path, as sent by some directories
+func ApplyPatch(resource map[string]interface{}, operations []PatchOperation) error {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+
+		if op != "add" && op != "replace" && op != "remove" {
+			return ErrInvalidPatchOp
+		}
+
+		var value interface{}
+
+		if len(operation.Value) > 0 {
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				return ErrInvalidValue
+			}
+		}
+
+		if operation.Path != "" {
+			if err := applyPatchPath(resource, op, operation.Path, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if op == "remove" {
+			return ErrNoTarget
+		}
+
+		attributes, ok := value.(map[string]interface{})
+
+		if !ok {
+			return ErrInvalidValue
+		}
+
+		for path, attributeValue := range attributes {
+			if err := applyPatchPath(resource, op, path, attributeValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyPatchPath(resource map[string]interface{}, op string, path string, value interface{}) error {
+	attribute, filter, subAttribute, err := parsePatchPath(path)
+
+	if err != nil {
+		return err
+	}
+
+	key := findAttributeKey(resource, attribute)
+
+	// Whole attribute, or a sub-attribute of a complex attribute
+	if filter == nil {
+		if subAttribute != "" {
+			complexValue, _ := resource[key].(map[string]interface{})
+
+			if op == "remove" {
+				if complexValue != nil {
+					delete(complexValue, findAttributeKey(complexValue, subAttribute))
+				}
+				return nil
+			}
+
+			if complexValue == nil {
+				complexValue = map[string]interface{}{}
+				resource[key] = complexValue
+			}
+
+			setPatchValue(complexValue, op, subAttribute, value)
+			return nil
+		}
+
+		if op == "remove" {
+			// Members listed in the value are removed from a multi-valued attribute
+			if items, ok := resource[key].([]interface{}); ok && value != nil {
+				resource[key] = removeItems(items, value)
+				return nil
+			}
+
+			delete(resource, key)
+			return nil
+		}
+
+		setPatchValue(resource, op, key, value)
+		return nil
+	}
+
+	// Values of a multi-valued attribute selected by a filter, e.g. members[value eq "2"]
+	items, _ := resource[key].([]interface{})
+	kept := []interface{}{}
+	matched := false
+
+	for _, item := range items {
+		itemValue, ok := item.(map[string]interface{})
+
+		if !ok || !filter.Matches(BuildAttributes(itemValue)) {
+			kept = append(kept, item)
+			continue
+		}
+
+		matched = true
+
+		switch {
+		case op == "remove" && subAttribute == "":
+			continue
+		case op == "remove":
+			delete(itemValue, findAttributeKey(itemValue, subAttribute))
+		case subAttribute == "":
+			if object, ok := value.(map[string]interface{}); ok {
+				for k, v := range object {
+					itemValue[findAttributeKey(itemValue, k)] = v
+				}
+			}
+		default:
+			itemValue[findAttributeKey(itemValue, subAttribute)] = value
+		}
+
+		kept = append(kept, itemValue)
+	}
+
+	// Setting a sub-attribute of a value that does not exist yet adds it, e.g.
+	// emails[type eq "work"].value adds a work email
+	if !matched && op != "remove" {
+		equality, ok := filter.(*attributeFilter)
+
+		if !ok || equality.operator != "eq" || equality.value == nil || subAttribute == "" {
+			return ErrNoTarget
+		}
+
+		kept = append(kept, map[string]interface{}{
+			equality.path: *equality.value,
+			subAttribute: value,
+		})
+	}
+
+	resource[key] = kept
+
+	return nil
+}
+
+// parsePatchPath splits a path into its attribute, value filter and sub-attribute, e.g.
+// emails[type eq "work"].value
+func parsePatchPath(path string) (string, Filter, string, error) {
+	path = stripSchema(strings.TrimSpace(path))
+
+	open := strings.Index(path, "[")
+
+	if open < 0 {
+		attribute, subAttribute, _ := strings.Cut(path, ".")
+
+		if attribute == "" {
+			return "", nil, "", ErrInvalidPath
+		}
+
+		return attribute, nil, subAttribute, nil
+	}
+
+	closing := strings.LastIndex(path, "]")
+
+	if open == 0 || closing < open {
+		return "", nil, "", ErrInvalidPath
+	}
+
+	filter, err := ParseFilter(path[open+1 : closing])
+
+	if err != nil {
+		return "", nil, "", ErrInvalidPath
+	}
+
+	rest := path[closing+1:]
+
+	if rest != "" && !strings.HasPrefix(rest, ".") {
+		return "", nil, "", ErrInvalidPath
+	}
+
+	return path[:open], filter, strings.TrimPrefix(rest, "."), nil
+}
+
+// setPatchValue sets an attribute. Adding to a multi-valued attribute appends the new values
+// and adding to a complex attribute, like replacing it, only sets the given sub-attributes
+func setPatchValue(resource map[string]interface{}, op string, attribute string, value interface{}) {
+	key := findAttributeKey(resource, attribute)
+
+	switch existing := resource[key].(type) {
+	case []interface{}:
+		if op != "add" {
+			break
+		}
+
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+
+		for _, v := range values {
+			if !containsItem(existing, v) {
+				existing = append(existing, v)
+			}
+		}
+
+		resource[key] = existing
+		return
+	case map[string]interface{}:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		for k, v := range object {
+			existing[findAttributeKey(existing, k)] = v
+		}
+		return
+	}
+
+	resource[key] = value
+}
+
+// removeItems removes the values of a multi-valued attribute with the same "value" as any of
+// the given ones
+func removeItems(items []interface{}, value interface{}) []interface{} {
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	kept := []interface{}{}
+
+	for _, item := range items {
+		if !containsItem(values, item) {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
+
+func containsItem(items []interface{}, item interface{}) bool {
+	for _, existing := range items {
+		if itemValue(existing) == itemValue(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func itemValue(item interface{}) string {
+	if object, ok := item.(map[string]interface{}); ok {
+		item = object["value"]
+	}
+
+	raw, _ := json.Marshal(item)
+	return string(raw)
+}
+
+// findAttributeKey returns the key an attribute is stored under, attribute names are case-insensitive
+func findAttributeKey(resource map[string]interface{}, attribute string) string {
+	for key := range resource {
+		if strings.EqualFold(key, attribute) {
+			return key
+		}
+	}
+	return attribute
+}
@@ -0,0 +1,120 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	user := `{
+		"userName": "ada@example.com",
+		"active": true,
+		"name": {"givenName": "Ada", "familyName": "Lovelace"},
+		"emails": [{"value": "ada@example.com", "type": "work"}]
+	}`
+
+	group := `{"displayName": "Engineering", "members": [{"value": "1"}, {"value": "2"}]}`
+
+	tests := []struct {
+		name       string
+		resource   string
+		operations string
+		want       string
+		wantErr    error
+	}{
+		{
+			name: "replaces an attribute",
+			resource: user,
+			operations: `[{"op": "replace", "path": "active", "value": false}]`,
+			want: `{"userName": "ada@example.com", "active": false, "name": {"givenName": "Ada", "familyName": "Lovelace"}, "emails": [{"value": "ada@example.com", "type": "work"}]}`,
+		},
+		{
+			name: "replaces attributes without a path",
+			resource: user,
+			operations: `[{"op": "Replace", "value": {"active": false, "name.givenName": "Augusta"}}]`,
+			want: `{"userName": "ada@example.com", "active": false, "name": {"givenName": "Augusta", "familyName": "Lovelace"}, "emails": [{"value": "ada@example.com", "type": "work"}]}`,
+		},
+		{
+			name: "matches attribute names case-insensitively",
+			resource: user,
+			operations: `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:USERNAME", "value": "augusta@example.com"}]`,
+			want: `{"userName": "augusta@example.com", "active": true, "name": {"givenName": "Ada", "familyName": "Lovelace"}, "emails": [{"value": "ada@example.com", "type": "work"}]}`,
+		},
+		{
+			name: "replaces a value selected by a filter",
+			resource: user,
+			operations: `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "augusta@example.com"}]`,
+			want: `{"userName": "ada@example.com", "active": true, "name": {"givenName": "Ada", "familyName": "Lovelace"}, "emails": [{"value": "augusta@example.com", "type": "work"}]}`,
+		},
+		{
+			name: "adds a value a filter selects none of",
+			resource: user,
+			operations: `[{"op": "add", "path": "emails[type eq \"home\"].value", "value": "ada@home.example"}]`,
+			want: `{"userName": "ada@example.com", "active": true, "name": {"givenName": "Ada", "familyName": "Lovelace"}, "emails": [{"value": "ada@example.com", "type": "work"}, {"value": "ada@home.example", "type": "home"}]}`,
+		},
+		{
+			name: "adds members once",
+			resource: group,
+			operations: `[{"op": "add", "path": "members", "value": [{"value": "2"}, {"value": "3"}]}]`,
+			want: `{"displayName": "Engineering", "members": [{"value": "1"}, {"value": "2"}, {"value": "3"}]}`,
+		},
+		{
+			name: "removes a member selected by a filter",
+			resource: group,
+			operations: `[{"op": "remove", "path": "members[value eq \"1\"]"}]`,
+			want: `{"displayName": "Engineering", "members": [{"value": "2"}]}`,
+		},
+		{
+			name: "removes the members listed in the value",
+			resource: group,
+			operations: `[{"op": "remove", "path": "members", "value": [{"value": "2"}]}]`,
+			want: `{"displayName": "Engineering", "members": [{"value": "1"}]}`,
+		},
+		{
+			name: "removes an attribute",
+			resource: group,
+			operations: `[{"op": "remove", "path": "members"}]`,
+			want: `{"displayName": "Engineering"}`,
+		},
+		{name: "rejects an unknown operation", resource: user, operations: `[{"op": "move", "path": "active"}]`, wantErr: ErrInvalidPatchOp},
+		{name: "rejects a remove without a path", resource: user, operations: `[{"op": "remove"}]`, wantErr: ErrNoTarget},
+		{name: "rejects a value that is not an object without a path", resource: user, operations: `[{"op": "replace", "value": false}]`, wantErr: ErrInvalidValue},
+		{name: "rejects an invalid filter", resource: user, operations: `[{"op": "replace", "path": "emails[type].value", "value": "x"}]`, wantErr: ErrInvalidPath},
+		{name: "rejects a filter selecting nothing that cannot be added", resource: user, operations: `[{"op": "replace", "path": "emails[type ne \"work\"].value", "value": "x"}]`, wantErr: ErrNoTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource map[string]interface{}
+			var operations []PatchOperation
+
+			if err := json.Unmarshal([]byte(tt.resource), &resource); err != nil {
+				t.Fatalf("invalid resource: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.operations), &operations); err != nil {
+				t.Fatalf("invalid operations: %v", err)
+			}
+
+			err := ApplyPatch(resource, operations)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyPatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			var want map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("invalid want: %v", err)
+			}
+
+			if !reflect.DeepEqual(resource, want) {
+				got, _ := json.Marshal(resource)
+				t.Fatalf("ApplyPatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package scim
+
+import "time"
+
+type TokenRepository interface {
+	Find(organizationId uint) (*[]Token, error)
+	FindOneByHash(hash string) (*Token, error)
+	Create(payload *Token) (*Token, error)
+	RecordUse(id uint, usedAt time.Time) error
+	Delete(id uint, organizationId uint) error
+}
+
+type GroupRepository interface {
+	Find(organizationId uint) (*[]Group, error)
+	FindOne(id uint, organizationId uint) (*Group, error)
+	FindOneByDisplayName(displayName string, organizationId uint) (*Group, error)
+	Create(payload *Group) (*Group, error)
+	Update(payload *Group) error
+	Delete(id uint) error
+}
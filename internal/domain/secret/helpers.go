@@ -0,0 +1,151 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeySize is the size of master and data keys, for AES-256
+const KeySize = 32
+
+// Sealed secrets look like "v1:<data key id>:<base64 nonce and ciphertext>"
+const envelopeVersion = "v1"
+
+// Mask replaces secret values in API responses
+const Mask = "********"
+
+// wrappedKeyAdditionalData binds wrapped data keys to their purpose
+const wrappedKeyAdditionalData = "data-key"
+
+var (
+	ErrInvalidKey      = errors.New("invalid encryption key, expected 32 base64 encoded bytes")
+	ErrInvalidEnvelope = errors.New("invalid encrypted secret")
+)
+
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ParseKey decodes a base64 encoded key, e.g. a master key from the configuration
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+
+	if err != nil || len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return key, nil
+}
+
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// BuildMasterKeyID identifies a master key without revealing it
+func BuildMasterKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// WrapKey encrypts a data key with the master key
+func WrapKey(masterKey []byte, key []byte) (string, error) {
+	wrapped, err := Seal(masterKey, key, wrappedKeyAdditionalData)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+func UnwrapKey(masterKey []byte, wrappedKey string) ([]byte, error) {
+	wrapped, err := base64.StdEncoding.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+
+	return Open(masterKey, wrapped, wrappedKeyAdditionalData)
+}
+
+// Seal encrypts plaintext with AES-GCM. The additional data is authenticated but not
+// encrypted, it binds the ciphertext to what it belongs to, e.g. a datasource
+func Seal(key []byte, plaintext []byte, additionalData string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, []byte(additionalData)), nil
+}
+
+func Open(key []byte, sealed []byte, additionalData string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(additionalData))
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+
+	return plaintext, nil
+}
+
+func BuildEnvelope(dataKeyId uint, sealed []byte) string {
+	return fmt.Sprintf("%s:%d:%s", envelopeVersion, dataKeyId, base64.StdEncoding.EncodeToString(sealed))
+}
+
+// ParseEnvelope returns the data key ID and the sealed secret of an envelope
+func ParseEnvelope(envelope string) (uint, []byte, error) {
+	parts := strings.SplitN(envelope, ":", 3)
+
+	if len(parts) != 3 || parts[0] != envelopeVersion {
+		return 0, nil, ErrInvalidEnvelope
+	}
+
+	dataKeyId, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, nil, ErrInvalidEnvelope
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return 0, nil, ErrInvalidEnvelope
+	}
+
+	return uint(dataKeyId), sealed, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
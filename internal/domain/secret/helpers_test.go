@@ -0,0 +1,164 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustGenerateKey(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	return key
+}
+
+func TestParseKey(t *testing.T) {
+	key := mustGenerateKey(t)
+
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{name: "an encoded key", encoded: EncodeKey(key)},
+		{name: "surrounding whitespace", encoded: "  " + EncodeKey(key) + "\n"},
+		{name: "a short key", encoded: EncodeKey(key[:16]), wantErr: true},
+		{name: "not base64", encoded: strings.Repeat("!", 44), wantErr: true},
+		{name: "empty", encoded: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKey(tt.encoded)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidKey) {
+					t.Fatalf("ParseKey() error = %v, want %v", err, ErrInvalidKey)
+				}
+				return
+			}
+
+			if err != nil || !bytes.Equal(got, key) {
+				t.Fatalf("ParseKey() = %x, %v, want %x", got, err, key)
+			}
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	key := mustGenerateKey(t)
+	plaintext := []byte(`{"password":"hunter2"}`)
+
+	sealed, err := Seal(key, plaintext, "datasource:1")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	if bytes.Contains(sealed, plaintext) {
+		t.Fatal("Seal() left the plaintext readable")
+	}
+
+	if again, _ := Seal(key, plaintext, "datasource:1"); bytes.Equal(again, sealed) {
+		t.Fatal("Seal() reused a nonce")
+	}
+
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name           string
+		key            []byte
+		sealed         []byte
+		additionalData string
+		wantErr        bool
+	}{
+		{name: "opens with the key and additional data", key: key, sealed: sealed, additionalData: "datasource:1"},
+		{name: "another key", key: mustGenerateKey(t), sealed: sealed, additionalData: "datasource:1", wantErr: true},
+		{name: "other additional data", key: key, sealed: sealed, additionalData: "datasource:2", wantErr: true},
+		{name: "tampered ciphertext", key: key, sealed: tampered, additionalData: "datasource:1", wantErr: true},
+		{name: "shorter than a nonce", key: key, sealed: sealed[:4], additionalData: "datasource:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(tt.key, tt.sealed, tt.additionalData)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEnvelope) {
+					t.Fatalf("Open() error = %v, want %v", err, ErrInvalidEnvelope)
+				}
+				return
+			}
+
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Fatalf("Open() = %q, %v, want %q", got, err, plaintext)
+			}
+		})
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	masterKey := mustGenerateKey(t)
+	dataKey := mustGenerateKey(t)
+
+	wrapped, err := WrapKey(masterKey, dataKey)
+	if err != nil {
+		t.Fatalf("WrapKey() error = %v", err)
+	}
+
+	if got, err := UnwrapKey(masterKey, wrapped); err != nil || !bytes.Equal(got, dataKey) {
+		t.Fatalf("UnwrapKey() = %x, %v, want %x", got, err, dataKey)
+	}
+
+	if _, err := UnwrapKey(mustGenerateKey(t), wrapped); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("UnwrapKey() with another master key error = %v, want %v", err, ErrInvalidEnvelope)
+	}
+
+	// A wrapped data key is not a secret sealed with the master key, and vice versa
+	sealed, _ := Seal(masterKey, dataKey, "datasource:1")
+	if _, err := Open(masterKey, sealed, wrappedKeyAdditionalData); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("Open() of a secret as a data key error = %v, want %v", err, ErrInvalidEnvelope)
+	}
+}
+
+func TestParseEnvelope(t *testing.T) {
+	sealed := []byte("nonce and ciphertext")
+
+	tests := []struct {
+		name          string
+		envelope      string
+		wantDataKeyId uint
+		wantErr       bool
+	}{
+		{name: "a built envelope", envelope: BuildEnvelope(42, sealed), wantDataKeyId: 42},
+		{name: "another version", envelope: strings.Replace(BuildEnvelope(42, sealed), "v1:", "v2:", 1), wantErr: true},
+		{name: "no data key", envelope: "v1::bm9uY2U=", wantErr: true},
+		{name: "a negative data key", envelope: "v1:-1:bm9uY2U=", wantErr: true},
+		{name: "not base64", envelope: "v1:42:!!!", wantErr: true},
+		{name: "missing parts", envelope: "v1:42", wantErr: true},
+		{name: "plaintext", envelope: `{"password":"hunter2"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataKeyId, got, err := ParseEnvelope(tt.envelope)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEnvelope) {
+					t.Fatalf("ParseEnvelope() error = %v, want %v", err, ErrInvalidEnvelope)
+				}
+				return
+			}
+
+			if err != nil || dataKeyId != tt.wantDataKeyId || !bytes.Equal(got, sealed) {
+				t.Fatalf("ParseEnvelope() = %d, %q, %v, want %d, %q", dataKeyId, got, err, tt.wantDataKeyId, sealed)
+			}
+		})
+	}
+}
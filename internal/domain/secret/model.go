@@ -0,0 +1,17 @@
+package secret
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataKey encrypts secrets stored in the database. It is itself stored encrypted
+// ("wrapped") with the master key, which never leaves the configuration
+type DataKey struct {
+	gorm.Model
+
+	MasterKeyID string     `gorm:"not null;index"` // <- identifies the master key the data key is wrapped with
+	WrappedKey  string     `gorm:"not null" json:"-"`
+	RetiredAt   *time.Time // <- retired keys only decrypt, until what they encrypted is re-encrypted
+}
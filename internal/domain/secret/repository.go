@@ -0,0 +1,12 @@
+package secret
+
+import "time"
+
+type DataKeyRepository interface {
+	Find() (*[]DataKey, error)
+	FindOne(id uint) (*DataKey, error)
+	Create(payload *DataKey) (*DataKey, error)
+	Rewrap(id uint, masterKeyId string, wrappedKey string) error
+	RetireOthers(id uint, retiredAt time.Time) error
+	DeleteRetired(retiredBefore time.Time) error
+}
@@ -0,0 +1,8 @@
+package session
+
+type AuthMethod string
+
+const (
+	AuthMethodPassword AuthMethod = "password"
+	AuthMethodSSO      AuthMethod = "sso" // <- second factors are up to the identity provider
+)
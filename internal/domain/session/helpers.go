@@ -0,0 +1,20 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+// TTL is how long a session stays valid after its refresh token was last rotated
+const TTL = 7 * 24 * time.Hour
+
+var ErrTokenReused = errors.New("refresh token has already been used")
+
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
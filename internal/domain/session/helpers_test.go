@@ -0,0 +1,30 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsActive(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		session Session
+		want    bool
+	}{
+		{name: "active", session: Session{ExpiresAt: now.Add(time.Hour)}, want: true},
+		{name: "expired", session: Session{ExpiresAt: now.Add(-time.Hour)}},
+		{name: "expiring now", session: Session{ExpiresAt: now}},
+		{name: "revoked", session: Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.IsActive(now); got != tt.want {
+				t.Fatalf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
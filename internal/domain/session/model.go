@@ -0,0 +1,30 @@
+package session
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is a refresh token family: every refresh token rotated from a single sign-in
+type Session struct {
+	gorm.Model
+
+	AccountID  uint      `gorm:"not null;index"` // <- foreign key to Account
+	IP         string
+	UserAgent  string
+	AuthMethod AuthMethod `gorm:"type:text;not null;default:'password'"`
+	LastUsedAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	RevokedAt  *time.Time
+}
+
+// RefreshToken is one refresh token of a session. Rotated tokens are kept so a replay
+// of a rotated-out token can be detected. Only the hash of the token is stored
+type RefreshToken struct {
+	gorm.Model
+
+	SessionID uint   `gorm:"not null;index"` // <- foreign key to Session
+	TokenHash string `gorm:"unique;not null" json:"-"`
+	RotatedAt *time.Time
+}
@@ -0,0 +1,16 @@
+package session
+
+import "time"
+
+type SessionRepository interface {
+	FindActive(accountId uint, now time.Time) (*[]Session, error)
+	FindOne(id uint) (*Session, error)
+	Create(payload *Session) (*Session, error)
+	Update(payload *Session) error
+	Revoke(id uint, at time.Time) error
+	RevokeAll(accountId uint, at time.Time) error
+	DeleteAll(accountId uint) error
+	FindOneToken(tokenHash string) (*RefreshToken, error)
+	CreateToken(payload *RefreshToken) (*RefreshToken, error)
+	RotateToken(id uint, at time.Time) (bool, error)
+}
@@ -0,0 +1,8 @@
+package signingkey
+
+type Algorithm string
+
+const (
+	AlgorithmRS256 Algorithm = "RS256"
+	AlgorithmEdDSA Algorithm = "EdDSA"
+)
@@ -0,0 +1,153 @@
+package signingkey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const rsaKeyBits = 2048
+
+// ErrKeyRotated is returned when the active key was rotated concurrently, e.g. by another instance
+var ErrKeyRotated = errors.New("signing key was rotated concurrently")
+
+func IsAlgorithm(algorithm Algorithm) bool {
+	return algorithm == AlgorithmRS256 || algorithm == AlgorithmEdDSA
+}
+
+// GenerateKey generates a new signing key for the algorithm
+func GenerateKey(algorithm Algorithm) (*SigningKey, error) {
+	var privateKey crypto.Signer
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	publicDer, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		KeyID: base64.RawURLEncoding.EncodeToString(id),
+		Algorithm: algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer})),
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})),
+	}, nil
+}
+
+func (k *SigningKey) IsActive() bool {
+	return k.RetiredAt == nil
+}
+
+func (k *SigningKey) IsVerifiable(now time.Time) bool {
+	return k.VerifiableUntil == nil || now.Before(*k.VerifiableUntil)
+}
+
+func (k *SigningKey) SigningMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// IsSealed reports whether the private key is encrypted. Keys generated before private keys
+// were encrypted are stored as plain PEM
+func (k *SigningKey) IsSealed() bool {
+	return !strings.HasPrefix(k.PrivateKey, "-----BEGIN")
+}
+
+// BuildAdditionalData binds an encrypted private key to its key ID, so it cannot be swapped
+// with another key's
+func (k *SigningKey) BuildAdditionalData() string {
+	return "signingkey/" + k.KeyID
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS #8 private key
+func ParsePrivateKey(privateKey []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("invalid private key")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("invalid private key")
+	}
+
+	return signer, nil
+}
+
+func (k *SigningKey) ParsePublicKey() (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(k.PublicKey))
+	if block == nil {
+		return nil, errors.New("invalid public key")
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// JWK builds the public JWK of the key
+func (k *SigningKey) JWK() (*JWK, error) {
+	publicKey, err := k.ParsePublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	jwk := &JWK{
+		Use: "sig",
+		Alg: string(k.Algorithm),
+		Kid: k.KeyID,
+	}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	default:
+		return nil, errors.New("unsupported public key type")
+	}
+
+	return jwk, nil
+}
@@ -0,0 +1,20 @@
+package signingkey
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SigningKey signs access tokens. Keys are rotated: a retired key no longer signs, but still
+// verifies the tokens it signed until they have expired
+type SigningKey struct {
+	gorm.Model
+
+	KeyID           string    `gorm:"unique;not null"` // <- the "kid" header of the tokens it signs
+	Algorithm       Algorithm `gorm:"type:text;not null"`
+	PrivateKey      string    `gorm:"not null" json:"-"` // <- PKCS #8, PEM encoded, then sealed with the secrets encryption
+	PublicKey       string    `gorm:"not null"`          // <- PKIX, PEM encoded
+	RetiredAt       *time.Time
+	VerifiableUntil *time.Time // <- set when retired, the key is removed after
+}
@@ -0,0 +1,10 @@
+package signingkey
+
+import "time"
+
+type SigningKeyRepository interface {
+	Find() (*[]SigningKey, error)
+	Rotate(payload *SigningKey, activeId uint, retiredAt time.Time, verifiableUntil time.Time) (*SigningKey, error)
+	UpdatePrivateKey(id uint, privateKey string) error
+	DeleteExpired(now time.Time) error
+}
@@ -0,0 +1,98 @@
+package sso
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+)
+
+// LoginStateTTL is how long a sign-in can take at the identity provider
+const LoginStateTTL = 10 * time.Minute
+
+// StateCookieName is the cookie binding a sign-in to the browser that started it
+const StateCookieName = "sso_state"
+
+// DefaultScopes are always requested
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+var (
+	ErrProviderNotConfigured = errors.New("sso is not configured for this organization")
+	ErrNoRoleMapped          = errors.New("no account role is mapped for this identity")
+)
+
+func BuildLoginStateKey(state string) string {
+	return fmt.Sprintf("sso-state-%s", state)
+}
+
+func BuildDiscoveryKey(issuer string) string {
+	return fmt.Sprintf("sso-discovery-%s", issuer)
+}
+
+// GenerateRandomString returns a random base64url string, used for state, nonce and PKCE verifiers
+func GenerateRandomString() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// BuildCodeChallenge derives the S256 PKCE code challenge of a code verifier
+func BuildCodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ResolveRole maps the role claim of an identity to an account role. When several values
+// are mapped the most privileged role wins. mapped reports whether the role came from the
+// mapping rather than the default
+func ResolveRole(p *Provider, claims map[string]interface{}) (role account.AccountRole, mapped bool, err error) {
+	values := []string{}
+
+	switch claim := claims[p.RoleClaim].(type) {
+	case string:
+		values = append(values, claim)
+	case []interface{}:
+		for _, v := range claim {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	for _, value := range values {
+		mappedRole, ok := p.RoleMapping[value]
+
+		if !ok {
+			continue
+		}
+
+		_role, err := account.NewAccountRole(mappedRole)
+
+		if err != nil {
+			continue
+		}
+
+		if !mapped || account.RolePrivilege(_role) > account.RolePrivilege(role) {
+			role = _role
+			mapped = true
+		}
+	}
+
+	if mapped {
+		return role, true, nil
+	}
+
+	if p.DefaultRole != "" {
+		return p.DefaultRole, false, nil
+	}
+
+	return "", false, ErrNoRoleMapped
+}
@@ -0,0 +1,22 @@
+package sso
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+)
+
+// Provider is the OpenID Connect identity provider an organization signs in with
+type Provider struct {
+	gorm.Model
+
+	OrganizationID uint                `gorm:"not null;uniqueIndex"` // <- foreign key to Organization
+	Issuer         string              `gorm:"not null"`
+	ClientID       string              `gorm:"not null"`
+	ClientSecret   string              `gorm:"not null" json:"-"`
+	Scopes         []string            `gorm:"type:jsonb;serializer:json;default:'[]'"` // <- requested in addition to openid, email and profile
+	RoleClaim      string              // <- ID token claim holding the user's groups or roles, e.g. "groups"
+	RoleMapping    map[string]string   `gorm:"type:jsonb;serializer:json;default:'{}'"` // claim value -> account role
+	DefaultRole    account.AccountRole `gorm:"type:text"` // <- role of accounts no mapping matches, none are provisioned when empty
+	Enabled        bool                `gorm:"not null;default:true"`
+}
@@ -0,0 +1,8 @@
+package sso
+
+type ProviderRepository interface {
+	FindOneByOrganization(organizationId uint) (*Provider, error)
+	Create(payload *Provider) (*Provider, error)
+	Update(payload *Provider) error
+	Delete(id uint) error
+}
@@ -0,0 +1,24 @@
+package sso
+
+// Discovery is the part of an OpenID provider's configuration document used for sign-in
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// LoginState is kept between redirecting to the identity provider and its callback
+type LoginState struct {
+	OrganizationKey string `json:"organization_key"`
+	CodeVerifier    string `json:"code_verifier"`
+	Nonce           string `json:"nonce"`
+}
+
+// Identity is the verified identity an ID token asserts
+type Identity struct {
+	Subject string
+	Email   string
+	Name    string
+	Claims  map[string]interface{}
+}
@@ -0,0 +1,370 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// COSE algorithm identifiers of the supported public keys
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+var (
+	ErrInvalidClientData   = errors.New("invalid webauthn client data")
+	ErrInvalidAuthData     = errors.New("invalid webauthn authenticator data")
+	ErrInvalidPublicKey    = errors.New("invalid or unsupported webauthn public key")
+	ErrInvalidSignature    = errors.New("invalid webauthn signature")
+	ErrCredentialMismatch  = errors.New("webauthn credential does not match")
+	ErrSignCountRegression = errors.New("webauthn signature counter did not increase, the authenticator may be cloned")
+)
+
+const (
+	flagUserPresent            = 0x01
+	flagAttestedCredentialData = 0x40
+)
+
+func GenerateChallenge() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func BuildRegistrationKey(accountId uint) string {
+	return fmt.Sprintf("webauthn-registration-%d", accountId)
+}
+
+func BuildAssertionKey(challengeID string) string {
+	return fmt.Sprintf("webauthn-assertion-%s", challengeID)
+}
+
+// BuildUserHandle returns the opaque user handle an account is registered under
+func BuildUserHandle(accountId uint) string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(accountId))
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeBase64URL decodes base64url, with or without padding, as sent by browsers and libraries
+func DecodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// VerifyRegistration verifies the response to a registration (navigator.credentials.create)
+// ceremony and returns the credential to store. Attestation statements are not verified,
+// registration options request "none" attestation
+func VerifyRegistration(rp RelyingParty, challenge string, response RegistrationResponse) (*Credential, error) {
+	clientDataJSON, err := DecodeBase64URL(response.ClientDataJSON)
+
+	if err != nil {
+		return nil, ErrInvalidClientData
+	}
+
+	if err := verifyClientData(rp, "webauthn.create", challenge, clientDataJSON); err != nil {
+		return nil, err
+	}
+
+	attestationObject, err := DecodeBase64URL(response.AttestationObject)
+
+	if err != nil {
+		return nil, ErrInvalidAuthData
+	}
+
+	attestation, _, err := decodeCBOR(attestationObject)
+
+	if err != nil {
+		return nil, ErrInvalidAuthData
+	}
+
+	attestationMap, ok := attestation.(map[interface{}]interface{})
+
+	if !ok {
+		return nil, ErrInvalidAuthData
+	}
+
+	rawAuthData, ok := attestationMap["authData"].([]byte)
+
+	if !ok {
+		return nil, ErrInvalidAuthData
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyAuthenticatorData(rp, authData); err != nil {
+		return nil, err
+	}
+
+	if authData.Flags&flagAttestedCredentialData == 0 {
+		return nil, ErrInvalidAuthData
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(authData.CredentialID)
+
+	if response.ID != "" && strings.TrimRight(response.ID, "=") != credentialID {
+		return nil, ErrCredentialMismatch
+	}
+
+	algorithm, _, err := ParsePublicKey(authData.PublicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		CredentialID: credentialID,
+		PublicKey: authData.PublicKey,
+		Algorithm: algorithm,
+		SignCount: authData.SignCount,
+	}, nil
+}
+
+// VerifyAssertion verifies the response to an assertion (navigator.credentials.get) ceremony
+// against a registered credential and returns the authenticator's new signature counter
+func VerifyAssertion(rp RelyingParty, challenge string, credential *Credential, response AssertionResponse) (uint32, error) {
+	if strings.TrimRight(response.ID, "=") != credential.CredentialID {
+		return 0, ErrCredentialMismatch
+	}
+
+	clientDataJSON, err := DecodeBase64URL(response.ClientDataJSON)
+
+	if err != nil {
+		return 0, ErrInvalidClientData
+	}
+
+	if err := verifyClientData(rp, "webauthn.get", challenge, clientDataJSON); err != nil {
+		return 0, err
+	}
+
+	rawAuthData, err := DecodeBase64URL(response.AuthenticatorData)
+
+	if err != nil {
+		return 0, ErrInvalidAuthData
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := verifyAuthenticatorData(rp, authData); err != nil {
+		return 0, err
+	}
+
+	signature, err := DecodeBase64URL(response.Signature)
+
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+
+	// The signature covers the authenticator data followed by the client data hash
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+
+	if err := VerifySignature(credential.PublicKey, signed, signature); err != nil {
+		return 0, err
+	}
+
+	// Authenticators without a counter always report zero
+	if (authData.SignCount != 0 || credential.SignCount != 0) && authData.SignCount <= credential.SignCount {
+		return 0, ErrSignCountRegression
+	}
+
+	return authData.SignCount, nil
+}
+
+// ParseAuthenticatorData parses authenticator data: the RP ID hash, flags, signature counter
+// and, when present, the attested credential
+func ParseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrInvalidAuthData
+	}
+
+	authData := &AuthenticatorData{
+		RPIDHash: data[:32],
+		Flags: data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if authData.Flags&flagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	// AAGUID (16 bytes), credential ID length (2 bytes), credential ID, COSE public key
+	rest := data[37:]
+
+	if len(rest) < 18 {
+		return nil, ErrInvalidAuthData
+	}
+
+	length := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+
+	if length == 0 || len(rest) < length {
+		return nil, ErrInvalidAuthData
+	}
+
+	authData.CredentialID = rest[:length]
+	rest = rest[length:]
+
+	// The public key is followed by extension data, if any
+	_, extensions, err := decodeCBOR(rest)
+
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	authData.PublicKey = append([]byte{}, rest[:len(rest)-len(extensions)]...)
+
+	return authData, nil
+}
+
+// ParsePublicKey parses a COSE encoded public key, returning its algorithm
+func ParsePublicKey(cose []byte) (int, crypto.PublicKey, error) {
+	decoded, _, err := decodeCBOR(cose)
+
+	if err != nil {
+		return 0, nil, ErrInvalidPublicKey
+	}
+
+	key, ok := decoded.(map[interface{}]interface{})
+
+	if !ok {
+		return 0, nil, ErrInvalidPublicKey
+	}
+
+	kty, _ := key[int64(1)].(int64)
+	alg, _ := key[int64(3)].(int64)
+
+	switch {
+	case kty == 2 && alg == AlgES256:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return 0, nil, ErrInvalidPublicKey
+		}
+
+		// Reject points that are not on the curve
+		point := append(append([]byte{0x04}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return 0, nil, ErrInvalidPublicKey
+		}
+
+		return AlgES256, &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case kty == 1 && alg == AlgEdDSA:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return 0, nil, ErrInvalidPublicKey
+		}
+
+		return AlgEdDSA, ed25519.PublicKey(x), nil
+	case kty == 3 && alg == AlgRS256:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return 0, nil, ErrInvalidPublicKey
+		}
+
+		return AlgRS256, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+
+	return 0, nil, ErrInvalidPublicKey
+}
+
+// VerifySignature verifies a signature made with the private key of a COSE encoded public key
+func VerifySignature(cose []byte, data []byte, signature []byte) error {
+	_, publicKey, err := ParsePublicKey(cose)
+
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(key, digest[:], signature) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(key, data, signature) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func verifyClientData(rp RelyingParty, ceremony string, challenge string, clientDataJSON []byte) error {
+	var clientData ClientData
+
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return ErrInvalidClientData
+	}
+
+	if clientData.Type != ceremony {
+		return ErrInvalidClientData
+	}
+
+	if subtle.ConstantTimeCompare([]byte(strings.TrimRight(clientData.Challenge, "=")), []byte(challenge)) != 1 {
+		return ErrInvalidClientData
+	}
+
+	for _, origin := range rp.Origins {
+		if clientData.Origin == origin {
+			return nil
+		}
+	}
+
+	return ErrInvalidClientData
+}
+
+func verifyAuthenticatorData(rp RelyingParty, authData *AuthenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) {
+		return ErrInvalidAuthData
+	}
+
+	if authData.Flags&flagUserPresent == 0 {
+		return ErrInvalidAuthData
+	}
+
+	return nil
+}
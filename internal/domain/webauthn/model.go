@@ -0,0 +1,21 @@
+package webauthn
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Credential is a WebAuthn public key credential (security key or passkey) registered
+// as a second factor
+type Credential struct {
+	gorm.Model
+
+	AccountID    uint   `gorm:"not null;index"` // <- foreign key to Account
+	Name         string `gorm:"not null"`
+	CredentialID string `gorm:"unique;not null"` // <- base64url credential ID chosen by the authenticator
+	PublicKey    []byte `gorm:"not null" json:"-"` // <- COSE encoded public key
+	Algorithm    int    `gorm:"not null"`
+	SignCount    uint32 `gorm:"not null;default:0"`
+	LastUsedAt   *time.Time
+}
@@ -0,0 +1,15 @@
+package webauthn
+
+import "time"
+
+type CredentialRepository interface {
+	Find(accountId uint) (*[]Credential, error)
+	FindOne(id uint, accountId uint) (*Credential, error)
+	FindOneByCredentialID(credentialId string) (*Credential, error)
+	Count(accountId uint) (int64, error)
+	Create(payload *Credential) (*Credential, error)
+	Rename(id uint, name string) error
+	RecordUse(id uint, signCount uint32, at time.Time) error
+	Delete(id uint) error
+	DeleteAll(accountId uint) error
+}
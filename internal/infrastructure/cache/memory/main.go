@@ -2,6 +2,7 @@ package memory
 
 import (
 	"errors"
+	"strconv"
 	"sync"
 	"time"
 
@@ -59,4 +60,46 @@ func (m *MemoryCache) Delete(key string) error {
 
 	delete(m.data, key)
 	return nil
+}
+
+func (m *MemoryCache) Take(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	it, ok := m.data[key]
+	if !ok {
+		return "", errors.New("key not found")
+	}
+
+	delete(m.data, key)
+
+	if time.Now().After(it.expiration) {
+		return "", errors.New("key not found")
+	}
+
+	return it.value, nil
+}
+
+func (m *MemoryCache) Increment(key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	it, ok := m.data[key]
+	if !ok || time.Now().After(it.expiration) {
+		it = item{
+			value:      "0",
+			expiration: time.Now().Add(ttl),
+		}
+	}
+
+	count, err := strconv.ParseInt(it.value, 10, 64)
+	if err != nil {
+		return 0, errors.New("value is not a counter")
+	}
+
+	count++
+	it.value = strconv.FormatInt(count, 10)
+	m.data[key] = it
+
+	return count, nil
 }
\ No newline at end of file
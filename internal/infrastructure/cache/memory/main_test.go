@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		set     bool
+		want    string
+		wantErr bool
+	}{
+		{name: "present", ttl: time.Minute, set: true, want: "value"},
+		{name: "missing", wantErr: true},
+		{name: "expired", ttl: -time.Second, set: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCache()
+
+			if tt.set {
+				_ = c.Set("key", "value", tt.ttl)
+			}
+
+			got, err := c.Take("key")
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Take() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Take() = %q, want %q", got, tt.want)
+			}
+
+			if _, err := c.Get("key"); err == nil {
+				t.Fatal("key still present after Take()")
+			}
+		})
+	}
+}
+
+func TestTakeIsSingleUse(t *testing.T) {
+	c := NewCache()
+	_ = c.Set("token", "value", time.Minute)
+
+	var wg sync.WaitGroup
+	var taken atomic.Int32
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Take("token"); err == nil {
+				taken.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := taken.Load(); got != 1 {
+		t.Fatalf("token taken %d times, want 1", got)
+	}
+}
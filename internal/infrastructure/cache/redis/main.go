@@ -37,4 +37,23 @@ func (c *CacheType) Get(key string) (string, error) {
 
 func (c *CacheType) Delete(key string) error {
 	return c.client.Del(c.ctx, key).Err()
+}
+
+func (c *CacheType) Take(key string) (string, error) {
+	return c.client.GetDel(c.ctx, key).Result()
+}
+
+func (c *CacheType) Increment(key string, ttl time.Duration) (int64, error) {
+	count, err := c.client.Incr(c.ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 {
+		if err := c.client.Expire(c.ctx, key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
 }
\ No newline at end of file
@@ -3,11 +3,21 @@ package database
 import (
 	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/apikey"
+	"github.com/darksuei/suei-intelligence/internal/domain/audit"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
 	databaseDomain "github.com/darksuei/suei-intelligence/internal/domain/database"
 	"github.com/darksuei/suei-intelligence/internal/domain/datasource"
+	"github.com/darksuei/suei-intelligence/internal/domain/invitation"
 	"github.com/darksuei/suei-intelligence/internal/domain/metadata"
 	"github.com/darksuei/suei-intelligence/internal/domain/organization"
 	"github.com/darksuei/suei-intelligence/internal/domain/project"
+	"github.com/darksuei/suei-intelligence/internal/domain/session"
+	"github.com/darksuei/suei-intelligence/internal/domain/scim"
+	"github.com/darksuei/suei-intelligence/internal/domain/secret"
+	"github.com/darksuei/suei-intelligence/internal/domain/signingkey"
+	"github.com/darksuei/suei-intelligence/internal/domain/sso"
+	"github.com/darksuei/suei-intelligence/internal/domain/webauthn"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/database/postgres"
 	postgresRepository "github.com/darksuei/suei-intelligence/internal/infrastructure/database/postgres/repositories"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/database/sqlite"
@@ -80,4 +90,52 @@ func NewProjectRepository(config *config.DatabaseConfig) project.ProjectReposito
 
 func NewDatasourceRepository(config *config.DatabaseConfig) datasource.DatasourceRepository {
 	return newRepository(config, postgresRepository.NewDatasourceRepository, sqliteRepository.NewDatasourceRepository)
-}
\ No newline at end of file
+}
+
+func NewPolicyRepository(config *config.DatabaseConfig) authorization.PolicyRepository {
+	return newRepository(config, postgresRepository.NewPolicyRepository, sqliteRepository.NewPolicyRepository)
+}
+
+func NewRoleRepository(config *config.DatabaseConfig) authorization.RoleRepository {
+	return newRepository(config, postgresRepository.NewRoleRepository, sqliteRepository.NewRoleRepository)
+}
+
+func NewAuditRepository(config *config.DatabaseConfig) audit.AuditRepository {
+	return newRepository(config, postgresRepository.NewAuditRepository, sqliteRepository.NewAuditRepository)
+}
+
+func NewInvitationRepository(config *config.DatabaseConfig) invitation.InvitationRepository {
+	return newRepository(config, postgresRepository.NewInvitationRepository, sqliteRepository.NewInvitationRepository)
+}
+
+func NewSessionRepository(config *config.DatabaseConfig) session.SessionRepository {
+	return newRepository(config, postgresRepository.NewSessionRepository, sqliteRepository.NewSessionRepository)
+}
+
+func NewCredentialRepository(config *config.DatabaseConfig) webauthn.CredentialRepository {
+	return newRepository(config, postgresRepository.NewCredentialRepository, sqliteRepository.NewCredentialRepository)
+}
+
+func NewProviderRepository(config *config.DatabaseConfig) sso.ProviderRepository {
+	return newRepository(config, postgresRepository.NewProviderRepository, sqliteRepository.NewProviderRepository)
+}
+
+func NewSCIMTokenRepository(config *config.DatabaseConfig) scim.TokenRepository {
+	return newRepository(config, postgresRepository.NewSCIMTokenRepository, sqliteRepository.NewSCIMTokenRepository)
+}
+
+func NewSCIMGroupRepository(config *config.DatabaseConfig) scim.GroupRepository {
+	return newRepository(config, postgresRepository.NewSCIMGroupRepository, sqliteRepository.NewSCIMGroupRepository)
+}
+
+func NewAPIKeyRepository(config *config.DatabaseConfig) apikey.APIKeyRepository {
+	return newRepository(config, postgresRepository.NewAPIKeyRepository, sqliteRepository.NewAPIKeyRepository)
+}
+
+func NewSigningKeyRepository(config *config.DatabaseConfig) signingkey.SigningKeyRepository {
+	return newRepository(config, postgresRepository.NewSigningKeyRepository, sqliteRepository.NewSigningKeyRepository)
+}
+
+func NewDataKeyRepository(config *config.DatabaseConfig) secret.DataKeyRepository {
+	return newRepository(config, postgresRepository.NewDataKeyRepository, sqliteRepository.NewDataKeyRepository)
+}
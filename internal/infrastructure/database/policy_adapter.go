@@ -0,0 +1,93 @@
+package database
+
+import (
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/persist"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+)
+
+// policyAdapter is a Casbin adapter persisting policies through the PolicyRepository
+type policyAdapter struct {
+	repository authorization.PolicyRepository
+}
+
+func NewPolicyAdapter(config *config.DatabaseConfig) persist.Adapter {
+	return &policyAdapter{repository: NewPolicyRepository(config)}
+}
+
+func (a *policyAdapter) LoadPolicy(m model.Model) error {
+	_policies, err := a.repository.Find()
+
+	if err != nil || _policies == nil {
+		return err
+	}
+
+	for _, _policy := range *_policies {
+		rule := append([]string{_policy.Ptype}, _policy.ToRule()...)
+
+		if err := persist.LoadPolicyArray(rule, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *policyAdapter) SavePolicy(m model.Model) error {
+	var _policies []authorization.Policy
+
+	for _, sec := range []string{"p", "g"} {
+		for ptype, assertion := range m[sec] {
+			for _, rule := range assertion.Policy {
+				_policies = append(_policies, authorization.NewPolicy(ptype, rule))
+			}
+		}
+	}
+
+	if err := a.repository.DeleteAll(); err != nil {
+		return err
+	}
+
+	return a.repository.CreateMany(&_policies)
+}
+
+func (a *policyAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	_policy := authorization.NewPolicy(ptype, rule)
+
+	_, err := a.repository.Create(&_policy)
+
+	return err
+}
+
+func (a *policyAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	_policy := authorization.NewPolicy(ptype, rule)
+
+	return a.repository.Delete(&_policy)
+}
+
+func (a *policyAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return a.repository.DeleteFiltered(ptype, fieldIndex, fieldValues...)
+}
+
+func (a *policyAdapter) AddPolicies(sec string, ptype string, rules [][]string) error {
+	_policies := make([]authorization.Policy, 0, len(rules))
+	for _, rule := range rules {
+		_policies = append(_policies, authorization.NewPolicy(ptype, rule))
+	}
+
+	return a.repository.CreateMany(&_policies)
+}
+
+func (a *policyAdapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	for _, rule := range rules {
+		_policy := authorization.NewPolicy(ptype, rule)
+
+		if err := a.repository.Delete(&_policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,9 +1,13 @@
 package repositories
 
 import (
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/darksuei/suei-intelligence/internal/domain/account"
 )
@@ -12,10 +16,14 @@ type accountRepository struct {
 	db *gorm.DB
 }
 
-func (r *accountRepository) Find() (*[]account.Account, error) {
+func (r *accountRepository) Find(organizationId uint) (*[]account.Account, error) {
 	var _accounts []account.Account
 
-	if err := r.db.Unscoped().Find(&_accounts).Error; err != nil {
+	query := map[string]interface{}{
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).Find(&_accounts).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -25,6 +33,19 @@ func (r *accountRepository) Find() (*[]account.Account, error) {
 	return &_accounts, nil
 }
 
+func (r *accountRepository) FindOneByID(id uint) (*account.Account, error) {
+	var _account account.Account
+
+	if err := r.db.Where(&account.Account{Model: gorm.Model{ID: id}}).First(&_account).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_account, nil
+}
+
 func (r *accountRepository) FindOneByEmail(email string) (*account.Account, error) {
 	var _account account.Account
 
@@ -32,7 +53,25 @@ func (r *accountRepository) FindOneByEmail(email string) (*account.Account, erro
 		"email": email,
 	}
 
-	if err := r.db.Unscoped().Where(query).First(&_account).Error; err != nil {
+	if err := r.db.Where(query).First(&_account).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_account, nil
+}
+
+func (r *accountRepository) FindOneByEmailInOrganization(email string, organizationId uint) (*account.Account, error) {
+	var _account account.Account
+
+	query := map[string]interface{}{
+		"email": email,
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).First(&_account).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -48,6 +87,8 @@ func (r *accountRepository) Create(payload *account.Account) (*account.Account,
 		Email: payload.Email, 
 		Role: payload.Role,
 		InternalRoles: payload.InternalRoles,
+		OrganizationID: payload.OrganizationID,
+		ExternalID: payload.ExternalID,
 		PasswordEnc: payload.PasswordEnc, 
 		MFAEnabled: payload.MFAEnabled, 
 		MFASecret: payload.MFASecret,
@@ -63,7 +104,8 @@ func (r *accountRepository) Create(payload *account.Account) (*account.Account,
 }
 
 func (r *accountRepository) Update(payload *account.Account) error {
-	err := r.db.Updates(payload).Error
+	// The token version, recovery codes and status have dedicated updates, never written back from a stale copy
+	err := r.db.Omit("token_version", "mfa_recovery_codes", "disabled_at").Updates(payload).Error
 
 	if err != nil {
 		return errors.New("failed to update account: " + err.Error())
@@ -72,6 +114,160 @@ func (r *accountRepository) Update(payload *account.Account) error {
 	return nil
 }
 
+func (r *accountRepository) IncrementTokenVersion(id uint) error {
+	err := r.db.Model(&account.Account{}).
+		Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + ?", 1)).
+		Error
+
+	if err != nil {
+		return errors.New("failed to increment token version: " + err.Error())
+	}
+
+	return nil
+}
+
+// UpdateRecoveryCodes replaces the stored recovery code hashes, including with an empty set
+func (r *accountRepository) UpdateRecoveryCodes(id uint, hashes []string) error {
+	err := r.db.Model(&account.Account{Model: gorm.Model{ID: id}}).
+		Select("mfa_recovery_codes").
+		Updates(&account.Account{MFARecoveryCodes: hashes}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update recovery codes: " + err.Error())
+	}
+
+	return nil
+}
+
+// RemoveRecoveryCode spends one recovery code hash and returns how many remain. The account
+// row is locked for the read and the write, so a code can only be spent once
+func (r *accountRepository) RemoveRecoveryCode(id uint, hash string) (int, error) {
+	var remaining []string
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var _account account.Account
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "mfa_recovery_codes").First(&_account, id).Error; err != nil {
+			return err
+		}
+
+		for i, _hash := range _account.MFARecoveryCodes {
+			if subtle.ConstantTimeCompare([]byte(_hash), []byte(hash)) != 1 {
+				continue
+			}
+
+			remaining = append(append([]string{}, _account.MFARecoveryCodes[:i]...), _account.MFARecoveryCodes[i+1:]...)
+
+			return tx.Model(&account.Account{Model: gorm.Model{ID: id}}).
+				Select("mfa_recovery_codes").
+				Updates(&account.Account{MFARecoveryCodes: remaining}).
+				Error
+		}
+
+		return account.ErrInvalidRecovery
+	})
+
+	if errors.Is(err, account.ErrInvalidRecovery) {
+		return 0, err
+	}
+
+	if err != nil {
+		return 0, errors.New("failed to remove recovery code: " + err.Error())
+	}
+
+	return len(remaining), nil
+}
+
+// UpdateMFA replaces the MFA state of an account, including disabling it
+func (r *accountRepository) UpdateMFA(id uint, enabled bool, secret string, hashes []string) error {
+	err := r.db.Model(&account.Account{Model: gorm.Model{ID: id}}).
+		Select("mfa_enabled", "mfa_secret", "mfa_recovery_codes").
+		Updates(&account.Account{MFAEnabled: enabled, MFASecret: secret, MFARecoveryCodes: hashes}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update mfa: " + err.Error())
+	}
+
+	return nil
+}
+
+// UpdateDisabledAt disables an account, or enables it again when disabledAt is nil
+func (r *accountRepository) UpdateDisabledAt(id uint, disabledAt *time.Time) error {
+	err := r.db.Model(&account.Account{Model: gorm.Model{ID: id}}).
+		Select("disabled_at").
+		Updates(&account.Account{DisabledAt: disabledAt}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update account status: " + err.Error())
+	}
+
+	return nil
+}
+
+// UpdateInstanceOperator grants or revokes operating the instance, including revoking it
+func (r *accountRepository) UpdateInstanceOperator(id uint, operator bool) error {
+	err := r.db.Model(&account.Account{Model: gorm.Model{ID: id}}).
+		Select("instance_operator").
+		Updates(&account.Account{InstanceOperator: operator}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update account: " + err.Error())
+	}
+
+	return nil
+}
+
+// UpdateExternalID replaces the directory identifier of an account, including clearing it
+func (r *accountRepository) UpdateExternalID(id uint, externalId string) error {
+	err := r.db.Model(&account.Account{Model: gorm.Model{ID: id}}).
+		Select("external_id").
+		Updates(&account.Account{ExternalID: externalId}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update account external id: " + err.Error())
+	}
+
+	return nil
+}
+
+// Erase removes the personal data of an account and deletes it. The record itself is kept,
+// so that its ID is never given to another account
+func (r *accountRepository) Erase(id uint, erasedAt time.Time) error {
+	tombstone := fmt.Sprintf("deleted-%d", id)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&account.Account{Model: gorm.Model{ID: id}}).
+			Select("name", "email", "password_enc", "password_changed_at", "internal_roles", "external_id", "disabled_at", "mfa_enabled", "mfa_secret", "mfa_recovery_codes").
+			Updates(&account.Account{
+				Name: tombstone,
+				Email: tombstone,
+				InternalRoles: map[string]string{},
+				DisabledAt: &erasedAt,
+				MFASecret: tombstone,
+				MFARecoveryCodes: []string{},
+			}).
+			Error
+
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&account.Account{}, id).Error
+	})
+
+	if err != nil {
+		return errors.New("failed to erase account: " + err.Error())
+	}
+
+	return nil
+}
+
 func NewAccountRepository(db *gorm.DB) account.AccountRepository {
 	return &accountRepository{db: db}
 }
\ No newline at end of file
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/audit"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func (r *auditRepository) Find(filter audit.AuditFilter) (*[]audit.AuditEvent, error) {
+	var _events []audit.AuditEvent
+
+	query := r.db.Model(&audit.AuditEvent{})
+
+	// Events never cross organizations
+	query = query.Where("organization_key = ?", filter.OrganizationKey)
+
+	if filter.ActorEmail != "" {
+		query = query.Where("actor_email = ?", filter.ActorEmail)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.ProjectKey != "" {
+		query = query.Where("project_key = ?", filter.ProjectKey)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	if err := query.Order("id desc").Find(&_events).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_events, nil
+}
+
+func (r *auditRepository) Create(payload *audit.AuditEvent) (*audit.AuditEvent, error) {
+	_event := *payload
+	_event.ID = 0
+
+	err := r.db.Create(&_event).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create audit event: " + err.Error())
+	}
+
+	return &_event, nil
+}
+
+func NewAuditRepository(db *gorm.DB) audit.AuditRepository {
+	return &auditRepository{db: db}
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -38,12 +39,78 @@ func (r *datasourceRepository) Find(projectId uint) (*[]datasource.Datasource, e
 	return &_datasources, nil
 }
 
+// FindWithDeleted lists every datasource of a project, including deleted ones
+func (r *datasourceRepository) FindWithDeleted(projectId uint) (*[]datasource.Datasource, error) {
+	var _datasources []datasource.Datasource
+
+	if err := r.db.Unscoped().Where(&datasource.Datasource{ProjectID: projectId}).Find(&_datasources).Error; err != nil {
+		return nil, err
+	}
+
+	return &_datasources, nil
+}
+
+// FindAll lists the datasources of every project, including deleted ones
+func (r *datasourceRepository) FindAll() (*[]datasource.Datasource, error) {
+	var _datasources []datasource.Datasource
+
+	if err := r.db.Unscoped().Find(&_datasources).Error; err != nil {
+		return nil, err
+	}
+
+	return &_datasources, nil
+}
+
+// FindDeleted lists the deleted datasources of a project, most recently deleted first
+func (r *datasourceRepository) FindDeleted(projectId uint) (*[]datasource.Datasource, error) {
+	var _datasources []datasource.Datasource
+
+	if err := r.db.Unscoped().
+		Where(&datasource.Datasource{ProjectID: projectId}).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&_datasources).Error; err != nil {
+		return nil, err
+	}
+
+	return &_datasources, nil
+}
+
+// FindOneDeleted finds a deleted datasource of a project
+func (r *datasourceRepository) FindOneDeleted(datasourceID uint, projectId uint) (*datasource.Datasource, error) {
+	var _datasource datasource.Datasource
+
+	if err := r.db.Unscoped().
+		Where(&datasource.Datasource{ProjectID: projectId, Model: gorm.Model{ID: datasourceID}}).
+		Where("deleted_at IS NOT NULL").
+		First(&_datasource).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_datasource, nil
+}
+
+// FindDeletedBefore lists the datasources of every project deleted before the given time
+func (r *datasourceRepository) FindDeletedBefore(before time.Time) (*[]datasource.Datasource, error) {
+	var _datasources []datasource.Datasource
+
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&_datasources).Error; err != nil {
+		return nil, err
+	}
+
+	return &_datasources, nil
+}
+
 func (r *datasourceRepository) Create(payload *datasource.Datasource) (*datasource.Datasource, error) {
 	_datasource := datasource.Datasource{
 		SourceType: payload.SourceType,
 		SourceID: payload.SourceID,
 		ProjectID: payload.ProjectID,
 		CreatedBy: payload.CreatedBy,
+		Configuration: payload.Configuration,
 	}
 
 	err := r.db.Create(&_datasource).Error
@@ -65,6 +132,35 @@ func (r *datasourceRepository) Update(payload *datasource.Datasource) error {
 	return nil
 }
 
+// UpdateCreatedBy replaces the creator of a datasource, including deleted ones
+func (r *datasourceRepository) UpdateCreatedBy(datasourceId uint, createdBy map[string]string) error {
+	err := r.db.Unscoped().
+		Model(&datasource.Datasource{Model: gorm.Model{ID: datasourceId}}).
+		Select("created_by").
+		Updates(&datasource.Datasource{CreatedBy: createdBy}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update datasource: " + err.Error())
+	}
+
+	return nil
+}
+
+// UpdateConfiguration replaces the encrypted configuration of a datasource, including deleted ones
+func (r *datasourceRepository) UpdateConfiguration(datasourceId uint, configuration string) error {
+	err := r.db.Unscoped().
+		Model(&datasource.Datasource{Model: gorm.Model{ID: datasourceId}}).
+		Update("configuration", configuration).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update datasource: " + err.Error())
+	}
+
+	return nil
+}
+
 func (r *datasourceRepository) SoftDelete(datasourceID, projectID uint) error {
 	return r.db.
 		Where(&datasource.Datasource{
@@ -75,6 +171,18 @@ func (r *datasourceRepository) SoftDelete(datasourceID, projectID uint) error {
 		Error
 }
 
+// Restore undoes the soft delete of a datasource
+func (r *datasourceRepository) Restore(datasourceID, projectID uint) error {
+	return r.db.Unscoped().
+		Model(&datasource.Datasource{}).
+		Where(&datasource.Datasource{
+			Model: gorm.Model{ID: datasourceID},
+			ProjectID: projectID,
+		}).
+		Update("deleted_at", nil).
+		Error
+}
+
 func (r *datasourceRepository) HardDelete(datasourceID, projectID uint) error {
 	return r.db.Unscoped().
 		Where(&datasource.Datasource{
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/invitation"
+)
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func (r *invitationRepository) Find(organizationId uint) (*[]invitation.Invitation, error) {
+	var _invitations []invitation.Invitation
+
+	if err := r.db.Where(&invitation.Invitation{OrganizationID: organizationId}).Order("id desc").Find(&_invitations).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_invitations, nil
+}
+
+func (r *invitationRepository) FindOne(id uint, organizationId uint) (*invitation.Invitation, error) {
+	var _invitation invitation.Invitation
+
+	if err := r.db.Where(&invitation.Invitation{OrganizationID: organizationId, Model: gorm.Model{ID: id}}).First(&_invitation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_invitation, nil
+}
+
+func (r *invitationRepository) FindOneByTokenHash(tokenHash string) (*invitation.Invitation, error) {
+	var _invitation invitation.Invitation
+
+	query := map[string]interface{}{
+		"token_hash": tokenHash,
+	}
+
+	if err := r.db.Where(query).First(&_invitation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_invitation, nil
+}
+
+func (r *invitationRepository) Create(payload *invitation.Invitation) (*invitation.Invitation, error) {
+	_invitation := invitation.Invitation{
+		Email: payload.Email,
+		Role: payload.Role,
+		ProjectRoles: payload.ProjectRoles,
+		TokenHash: payload.TokenHash,
+		ExpiresAt: payload.ExpiresAt,
+		InvitedBy: payload.InvitedBy,
+		OrganizationID: payload.OrganizationID,
+	}
+
+	err := r.db.Create(&_invitation).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create invitation: " + err.Error())
+	}
+
+	return &_invitation, nil
+}
+
+func (r *invitationRepository) Update(payload *invitation.Invitation) error {
+	err := r.db.Updates(payload).Error
+
+	if err != nil {
+		return errors.New("failed to update invitation: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *invitationRepository) Delete(id uint, organizationId uint) error {
+	return r.db.Unscoped().Where(&invitation.Invitation{OrganizationID: organizationId, Model: gorm.Model{ID: id}}).Delete(&invitation.Invitation{}).Error
+}
+
+// DeletePending removes the unaccepted invitations of an email, so only the latest invite token is valid
+func (r *invitationRepository) DeletePending(email string, organizationId uint) error {
+	return r.db.Unscoped().Where("email = ? AND organization_id = ? AND accepted_at IS NULL", email, organizationId).Delete(&invitation.Invitation{}).Error
+}
+
+func NewInvitationRepository(db *gorm.DB) invitation.InvitationRepository {
+	return &invitationRepository{db: db}
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -47,6 +48,45 @@ func (r *metadataRepository) Update(payload *metadata.Metadata) error {
 	return nil
 }
 
+func (r *metadataRepository) IncrementPolicyVersion() error {
+	err := r.db.Model(&metadata.Metadata{}).
+		Where("1 = 1").
+		UpdateColumn("policy_version", gorm.Expr("policy_version + ?", 1)).
+		Error
+
+	if err != nil {
+		return errors.New("failed to increment policy version: " + err.Error())
+	}
+
+	return nil
+}
+
+// LockSetup marks setup as completed, reporting false when another request already did
+func (r *metadataRepository) LockSetup(at time.Time) (bool, error) {
+	result := r.db.Model(&metadata.Metadata{}).
+		Where("setup_completed_at IS NULL").
+		UpdateColumn("setup_completed_at", at)
+
+	if result.Error != nil {
+		return false, errors.New("failed to lock setup: " + result.Error.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *metadataRepository) UnlockSetup() error {
+	err := r.db.Model(&metadata.Metadata{}).
+		Where("1 = 1").
+		UpdateColumn("setup_completed_at", nil).
+		Error
+
+	if err != nil {
+		return errors.New("failed to unlock setup: " + err.Error())
+	}
+
+	return nil
+}
+
 func NewMetadataRepository(db *gorm.DB) metadata.MetadataRepository {
 	return &metadataRepository{db: db}
 }
@@ -5,6 +5,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
 	"github.com/darksuei/suei-intelligence/internal/domain/organization"
 )
 
@@ -29,6 +30,29 @@ func (r *organizationRepository) FindOne(key string) (*organization.Organization
 	return &_organization, nil
 }
 
+func (r *organizationRepository) Count() (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&organization.Organization{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *organizationRepository) FindOneByID(id uint) (*organization.Organization, error) {
+	var _organization organization.Organization
+
+	if err := r.db.Where(&organization.Organization{Model: gorm.Model{ID: id}}).First(&_organization).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_organization, nil
+}
+
 func (r *organizationRepository) Create(payload *organization.Organization) (*organization.Organization, error) {
 	_organization := organization.Organization{Name: payload.Name, Key: payload.Key, Scope: payload.Scope}
 
@@ -41,6 +65,43 @@ func (r *organizationRepository) Create(payload *organization.Organization) (*or
 	return &_organization, nil
 }
 
+// CreateWithAdmin stores an organization together with its first account, so that
+// neither is kept when the other cannot be stored
+func (r *organizationRepository) CreateWithAdmin(payload *organization.Organization, admin *account.Account) (*organization.Organization, *account.Account, error) {
+	var _organization *organization.Organization
+	var _account *account.Account
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		_organization, err = NewOrganizationRepository(tx).Create(payload)
+		if err != nil {
+			return err
+		}
+
+		admin.OrganizationID = _organization.ID
+
+		_account, err = NewAccountRepository(tx).Create(admin)
+		if err != nil {
+			return err
+		}
+
+		if !admin.InstanceOperator {
+			return nil
+		}
+
+		_account.InstanceOperator = true
+
+		return NewAccountRepository(tx).UpdateInstanceOperator(_account.ID, true)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return _organization, _account, nil
+}
+
 func (r *organizationRepository) Update(payload *organization.Organization) error {
 	err := r.db.Updates(payload).Error
 
@@ -9,10 +9,20 @@ import (
 
 	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/apikey"
+	"github.com/darksuei/suei-intelligence/internal/domain/audit"
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
 	"github.com/darksuei/suei-intelligence/internal/domain/datasource"
+	"github.com/darksuei/suei-intelligence/internal/domain/invitation"
 	"github.com/darksuei/suei-intelligence/internal/domain/metadata"
 	"github.com/darksuei/suei-intelligence/internal/domain/organization"
 	"github.com/darksuei/suei-intelligence/internal/domain/project"
+	"github.com/darksuei/suei-intelligence/internal/domain/session"
+	"github.com/darksuei/suei-intelligence/internal/domain/scim"
+	"github.com/darksuei/suei-intelligence/internal/domain/secret"
+	"github.com/darksuei/suei-intelligence/internal/domain/signingkey"
+	"github.com/darksuei/suei-intelligence/internal/domain/sso"
+	"github.com/darksuei/suei-intelligence/internal/domain/webauthn"
 )
 
 var DB *gorm.DB
@@ -64,4 +74,133 @@ func Migrate() {
 	if err != nil {
 		log.Fatalf("failed to migrate sqlite database (datasource): %v", err)
 	}
+
+	err = DB.AutoMigrate(&authorization.Policy{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (policy): %v", err)
+	}
+
+	err = DB.AutoMigrate(&authorization.Role{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (role): %v", err)
+	}
+
+	err = DB.AutoMigrate(&audit.AuditEvent{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (audit): %v", err)
+	}
+
+	err = DB.AutoMigrate(&invitation.Invitation{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (invitation): %v", err)
+	}
+
+	err = DB.AutoMigrate(&session.Session{}, &session.RefreshToken{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (session): %v", err)
+	}
+
+	err = DB.AutoMigrate(&webauthn.Credential{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (webauthn): %v", err)
+	}
+
+	err = DB.AutoMigrate(&sso.Provider{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (sso): %v", err)
+	}
+
+	err = DB.AutoMigrate(&scim.Token{}, &scim.Group{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (scim): %v", err)
+	}
+
+	err = DB.AutoMigrate(&apikey.APIKey{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (api keys): %v", err)
+	}
+
+	err = DB.AutoMigrate(&signingkey.SigningKey{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (signing keys): %v", err)
+	}
+
+	err = DB.AutoMigrate(&secret.DataKey{})
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (secrets): %v", err)
+	}
+
+	err = backfillOrganization()
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (organization backfill): %v", err)
+	}
+
+	err = backfillInstanceOperator()
+	if err != nil {
+		log.Fatalf("failed to migrate sqlite database (instance operator backfill): %v", err)
+	}
+}
+
+// backfillOrganization binds records created before multi-organization tenancy
+// to the original default organization
+func backfillOrganization() error {
+	var _organization organization.Organization
+
+	query := map[string]interface{}{
+		"key": organization.DefaultKey,
+	}
+
+	if err := DB.Where(query).First(&_organization).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	for _, model := range []interface{}{&account.Account{}, &project.Project{}, &authorization.Role{}} {
+		if err := DB.Unscoped().Model(model).Where("organization_id = ?", 0).Update("organization_id", _organization.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	return DB.Model(&audit.AuditEvent{}).Where("organization_key IS NULL OR organization_key = ?", "").Update("organization_key", _organization.Key).Error
+}
+
+// backfillInstanceOperator makes the oldest active SUPERADMIN of the first organization the
+// instance operator of instances set up before instance operators existed
+func backfillInstanceOperator() error {
+	var count int64
+
+	if err := DB.Model(&account.Account{}).Where("instance_operator = ?", true).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	var _organization organization.Organization
+
+	if err := DB.Order("id").First(&_organization).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	var _account account.Account
+
+	query := map[string]interface{}{
+		"organization_id": _organization.ID,
+		"role": account.SuperAdmin,
+	}
+
+	if err := DB.Where(query).Where("disabled_at IS NULL").Order("id").First(&_account).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	return DB.Model(&_account).Update("instance_operator", true).Error
 }
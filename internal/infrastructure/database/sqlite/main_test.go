@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/organization"
+)
+
+func TestBackfillInstanceOperator(t *testing.T) {
+	Connect(&config.DatabaseConfig{DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	Migrate()
+
+	first := &organization.Organization{Name: "first", Key: "first", Scope: organization.Private}
+	second := &organization.Organization{Name: "second", Key: "second", Scope: organization.Private}
+
+	for _, _organization := range []*organization.Organization{first, second} {
+		if err := DB.Create(_organization).Error; err != nil {
+			t.Fatalf("failed to create organization: %v", err)
+		}
+	}
+
+	create := func(name string, role account.AccountRole, organizationId uint) *account.Account {
+		_account := &account.Account{Name: name, Email: name + "@example.com", Role: role, MFASecret: name, OrganizationID: organizationId}
+		if err := DB.Create(_account).Error; err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+		return _account
+	}
+
+	operators := func() []string {
+		var names []string
+		DB.Model(&account.Account{}).Where("instance_operator = ?", true).Order("id").Pluck("name", &names)
+		return names
+	}
+
+	if err := backfillInstanceOperator(); err != nil {
+		t.Fatalf("backfillInstanceOperator() without accounts error = %v", err)
+	}
+
+	create("tenant", account.SuperAdmin, second.ID)
+	create("admin", account.Admin, first.ID)
+
+	disabled := create("disabled", account.SuperAdmin, first.ID)
+	DB.Model(disabled).Update("disabled_at", time.Now())
+
+	create("first", account.SuperAdmin, first.ID)
+	create("second", account.SuperAdmin, first.ID)
+
+	t.Run("promotes the oldest active SUPERADMIN of the first organization", func(t *testing.T) {
+		if err := backfillInstanceOperator(); err != nil {
+			t.Fatalf("backfillInstanceOperator() error = %v", err)
+		}
+
+		if got := operators(); len(got) != 1 || got[0] != "first" {
+			t.Fatalf("instance operators = %v, want [first]", got)
+		}
+	})
+
+	t.Run("keeps an existing instance operator", func(t *testing.T) {
+		DB.Model(&account.Account{}).Where("name = ?", "first").Update("instance_operator", false)
+		DB.Model(&account.Account{}).Where("name = ?", "second").Update("instance_operator", true)
+
+		if err := backfillInstanceOperator(); err != nil {
+			t.Fatalf("backfillInstanceOperator() error = %v", err)
+		}
+
+		if got := operators(); len(got) != 1 || got[0] != "second" {
+			t.Fatalf("instance operators = %v, want [second]", got)
+		}
+	})
+}
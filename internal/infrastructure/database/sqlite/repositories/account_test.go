@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+)
+
+func newTestAccount(t *testing.T, hashes []string) (account.AccountRepository, uint) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&account.Account{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	// SQLite has no row locks, a single connection serializes the transactions instead
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	repository := NewAccountRepository(db)
+
+	_account, err := repository.Create(&account.Account{
+		Name: "test",
+		Email: "test@example.com",
+		Role: account.SuperAdmin,
+		MFASecret: "secret",
+	})
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	if err := repository.UpdateRecoveryCodes(_account.ID, hashes); err != nil {
+		t.Fatalf("failed to store recovery codes: %v", err)
+	}
+
+	return repository, _account.ID
+}
+
+func TestRemoveRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		hashes        []string
+		spend         []string
+		wantRemaining int
+		wantErr       error
+	}{
+		{name: "spends a code", hashes: []string{"a", "b", "c"}, spend: []string{"b"}, wantRemaining: 2},
+		{name: "spends the last code", hashes: []string{"a"}, spend: []string{"a"}, wantRemaining: 0},
+		{name: "rejects an unknown code", hashes: []string{"a", "b"}, spend: []string{"x"}, wantErr: account.ErrInvalidRecovery},
+		{name: "rejects a spent code", hashes: []string{"a", "b"}, spend: []string{"a", "a"}, wantErr: account.ErrInvalidRecovery},
+		{name: "rejects any code once none remain", hashes: []string{}, spend: []string{"a"}, wantErr: account.ErrInvalidRecovery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository, id := newTestAccount(t, tt.hashes)
+
+			var remaining int
+			var err error
+
+			for _, hash := range tt.spend {
+				remaining, err = repository.RemoveRecoveryCode(id, hash)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveRecoveryCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && remaining != tt.wantRemaining {
+				t.Fatalf("RemoveRecoveryCode() = %d, want %d", remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestRemoveRecoveryCodeIsSingleUse(t *testing.T) {
+	repository, id := newTestAccount(t, []string{"a", "b", "c"})
+
+	var wg sync.WaitGroup
+	var spent atomic.Int32
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repository.RemoveRecoveryCode(id, "b"); err == nil {
+				spent.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := spent.Load(); got != 1 {
+		t.Fatalf("recovery code spent %d times, want 1", got)
+	}
+
+	_account, err := repository.FindOneByID(id)
+	if err != nil {
+		t.Fatalf("failed to find account: %v", err)
+	}
+	if len(_account.MFARecoveryCodes) != 2 {
+		t.Fatalf("%d recovery codes remain, want 2", len(_account.MFARecoveryCodes))
+	}
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/apikey"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *apiKeyRepository) Find(organizationId uint, projectKey string) (*[]apikey.APIKey, error) {
+	var _keys []apikey.APIKey
+
+	query := map[string]interface{}{
+		"organization_id": organizationId,
+		"project_key": projectKey,
+	}
+
+	if err := r.db.Where(query).Order("created_at desc").Find(&_keys).Error; err != nil {
+		return nil, err
+	}
+
+	return &_keys, nil
+}
+
+func (r *apiKeyRepository) FindOne(id uint, organizationId uint) (*apikey.APIKey, error) {
+	var _key apikey.APIKey
+
+	query := map[string]interface{}{
+		"id": id,
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).First(&_key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_key, nil
+}
+
+func (r *apiKeyRepository) FindOneByPrefix(prefix string) (*apikey.APIKey, error) {
+	var _key apikey.APIKey
+
+	query := map[string]interface{}{
+		"prefix": prefix,
+	}
+
+	if err := r.db.Where(query).First(&_key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_key, nil
+}
+
+func (r *apiKeyRepository) Create(payload *apikey.APIKey) (*apikey.APIKey, error) {
+	_key := apikey.APIKey{
+		OrganizationID: payload.OrganizationID,
+		ProjectKey: payload.ProjectKey,
+		Name: payload.Name,
+		Prefix: payload.Prefix,
+		KeyHash: payload.KeyHash,
+		Permissions: payload.Permissions,
+		CreatedBy: payload.CreatedBy,
+		ExpiresAt: payload.ExpiresAt,
+	}
+
+	err := r.db.Create(&_key).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create api key: " + err.Error())
+	}
+
+	return &_key, nil
+}
+
+// Revoke keeps the key, so that it remains identifiable in the audit log
+func (r *apiKeyRepository) Revoke(id uint, revokedAt time.Time) error {
+	err := r.db.Model(&apikey.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("revoked_at", revokedAt).
+		Error
+
+	if err != nil {
+		return errors.New("failed to revoke api key: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) RecordUse(id uint, usedAt time.Time) error {
+	err := r.db.Model(&apikey.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).
+		Error
+
+	if err != nil {
+		return errors.New("failed to record api key use: " + err.Error())
+	}
+
+	return nil
+}
+
+// ReassignCreatedBy replaces the creator of every key an account created in the organization
+func (r *apiKeyRepository) ReassignCreatedBy(organizationId uint, email string, createdBy string) error {
+	err := r.db.Model(&apikey.APIKey{}).
+		Where("organization_id = ? AND created_by = ?", organizationId, email).
+		UpdateColumn("created_by", createdBy).
+		Error
+
+	if err != nil {
+		return errors.New("failed to reassign api keys: " + err.Error())
+	}
+
+	return nil
+}
+
+func NewAPIKeyRepository(db *gorm.DB) apikey.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/datasource"
+)
+
+func newTestDatasourceRepository(t *testing.T) datasource.DatasourceRepository {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&datasource.Datasource{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	return NewDatasourceRepository(db)
+}
+
+// datasourceIDs returns the IDs of datasources
+func datasourceIDs(_datasources *[]datasource.Datasource) []uint {
+	if _datasources == nil {
+		return nil
+	}
+
+	var ids []uint
+	for _, _datasource := range *_datasources {
+		ids = append(ids, _datasource.ID)
+	}
+	return ids
+}
+
+func TestDatasourceTrash(t *testing.T) {
+	repository := newTestDatasourceRepository(t)
+
+	create := func(projectId uint) uint {
+		_datasource, err := repository.Create(&datasource.Datasource{SourceType: "postgres", SourceID: "source", ProjectID: projectId})
+		if err != nil {
+			t.Fatalf("failed to create datasource: %v", err)
+		}
+		return _datasource.ID
+	}
+
+	live := create(1)
+	deleted := create(1)
+	other := create(2)
+
+	if err := repository.SoftDelete(deleted, 1); err != nil {
+		t.Fatalf("failed to delete datasource: %v", err)
+	}
+	if err := repository.SoftDelete(other, 2); err != nil {
+		t.Fatalf("failed to delete datasource: %v", err)
+	}
+
+	t.Run("lists deleted datasources apart", func(t *testing.T) {
+		if _datasources, err := repository.Find(1); err != nil || len(*_datasources) != 1 || (*_datasources)[0].ID != live {
+			t.Fatalf("Find() = %v, %v, want [%d]", datasourceIDs(_datasources), err, live)
+		}
+
+		if _datasources, err := repository.FindDeleted(1); err != nil || len(*_datasources) != 1 || (*_datasources)[0].ID != deleted {
+			t.Fatalf("FindDeleted() = %v, %v, want [%d]", datasourceIDs(_datasources), err, deleted)
+		}
+	})
+
+	t.Run("finds a deleted datasource of its project only", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			id        uint
+			projectId uint
+			want      bool
+		}{
+			{name: "a deleted datasource", id: deleted, projectId: 1, want: true},
+			{name: "a datasource not deleted", id: live, projectId: 1},
+			{name: "a deleted datasource of another project", id: other, projectId: 1},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_datasource, err := repository.FindOneDeleted(tt.id, tt.projectId)
+				if err != nil || (_datasource != nil) != tt.want {
+					t.Fatalf("FindOneDeleted() = %v, %v, want found %v", _datasource, err, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("finds datasources deleted before a time across projects", func(t *testing.T) {
+		if _datasources, err := repository.FindDeletedBefore(time.Now().Add(-time.Minute)); err != nil || len(*_datasources) != 0 {
+			t.Fatalf("FindDeletedBefore() a minute ago = %v, %v, want none", datasourceIDs(_datasources), err)
+		}
+
+		if _datasources, err := repository.FindDeletedBefore(time.Now().Add(time.Minute)); err != nil || len(*_datasources) != 2 {
+			t.Fatalf("FindDeletedBefore() in a minute = %v, %v, want [%d %d]", datasourceIDs(_datasources), err, deleted, other)
+		}
+	})
+
+	t.Run("restores a deleted datasource", func(t *testing.T) {
+		if err := repository.Restore(deleted, 1); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+
+		if _datasource, err := repository.FindOne(deleted, 1); err != nil || _datasource == nil {
+			t.Fatalf("FindOne() of the restored datasource = %v, %v", _datasource, err)
+		}
+
+		if _datasources, _ := repository.FindDeleted(1); len(*_datasources) != 0 {
+			t.Fatalf("FindDeleted() = %v, want none", datasourceIDs(_datasources))
+		}
+	})
+
+	t.Run("does not restore datasources of another project", func(t *testing.T) {
+		if err := repository.Restore(other, 1); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+
+		if _datasource, _ := repository.FindOneDeleted(other, 2); _datasource == nil {
+			t.Fatal("datasource of another project was restored")
+		}
+	})
+
+	t.Run("purges a deleted datasource", func(t *testing.T) {
+		if err := repository.HardDelete(other, 2); err != nil {
+			t.Fatalf("HardDelete() error = %v", err)
+		}
+
+		if _datasources, _ := repository.FindWithDeleted(2); len(*_datasources) != 0 {
+			t.Fatalf("FindWithDeleted() = %v, want none", datasourceIDs(_datasources))
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/account"
+	"github.com/darksuei/suei-intelligence/internal/domain/organization"
+)
+
+func TestCreateWithAdmin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&organization.Organization{}, &account.Account{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	repository := NewOrganizationRepository(db)
+
+	if _, err := NewAccountRepository(db).Create(&account.Account{Name: "taken", Email: "taken@example.com", Role: account.Guest, MFASecret: "taken"}); err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		key              string
+		email            string
+		instanceOperator bool
+		wantErr          bool
+	}{
+		{name: "stores the organization and its admin", key: "first", email: "first@example.com"},
+		{name: "grants operating the instance", key: "operated", email: "operator@example.com", instanceOperator: true},
+		{name: "keeps no organization when the admin cannot be stored", key: "orphan", email: "taken@example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_organization, _account, err := repository.CreateWithAdmin(
+				&organization.Organization{Name: tt.key, Key: tt.key, Scope: organization.Private},
+				&account.Account{Name: tt.key, Email: tt.email, Role: account.SuperAdmin, MFASecret: tt.key, InstanceOperator: tt.instanceOperator},
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateWithAdmin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			stored, _ := repository.FindOne(tt.key)
+
+			if tt.wantErr {
+				if stored != nil {
+					t.Fatalf("organization %s was stored without its admin", tt.key)
+				}
+				return
+			}
+
+			if stored == nil || _account.OrganizationID != _organization.ID {
+				t.Fatalf("account organization = %d, want %v", _account.OrganizationID, stored)
+			}
+
+			_stored, _ := NewAccountRepository(db).FindOneByID(_account.ID)
+			if _stored == nil || _stored.InstanceOperator != tt.instanceOperator {
+				t.Fatalf("stored account = %v, want instance operator %v", _stored, tt.instanceOperator)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+)
+
+type policyRepository struct {
+	db *gorm.DB
+}
+
+func (r *policyRepository) Find() (*[]authorization.Policy, error) {
+	var _policies []authorization.Policy
+
+	if err := r.db.Order("id").Find(&_policies).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_policies, nil
+}
+
+func (r *policyRepository) Count() (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&authorization.Policy{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *policyRepository) Create(payload *authorization.Policy) (*authorization.Policy, error) {
+	_policy := authorization.NewPolicy(payload.Ptype, payload.ToRule())
+
+	err := r.db.Create(&_policy).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create policy: " + err.Error())
+	}
+
+	return &_policy, nil
+}
+
+func (r *policyRepository) CreateMany(payload *[]authorization.Policy) error {
+	if payload == nil || len(*payload) == 0 {
+		return nil
+	}
+
+	err := r.db.Create(payload).Error
+
+	if err != nil {
+		return errors.New("failed to create policies: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *policyRepository) Delete(payload *authorization.Policy) error {
+	query := map[string]interface{}{
+		"ptype": payload.Ptype,
+		"v0":    payload.V0,
+		"v1":    payload.V1,
+		"v2":    payload.V2,
+		"v3":    payload.V3,
+		"v4":    payload.V4,
+		"v5":    payload.V5,
+	}
+
+	return r.db.Unscoped().Where(query).Delete(&authorization.Policy{}).Error
+}
+
+func (r *policyRepository) DeleteFiltered(ptype string, fieldIndex int, fieldValues ...string) error {
+	query := map[string]interface{}{
+		"ptype": ptype,
+	}
+
+	for i, v := range fieldValues {
+		// Empty values are wildcards
+		if v == "" {
+			continue
+		}
+		query[fmt.Sprintf("v%d", fieldIndex+i)] = v
+	}
+
+	return r.db.Unscoped().Where(query).Delete(&authorization.Policy{}).Error
+}
+
+func (r *policyRepository) DeleteAll() error {
+	return r.db.Unscoped().Where("1 = 1").Delete(&authorization.Policy{}).Error
+}
+
+func NewPolicyRepository(db *gorm.DB) authorization.PolicyRepository {
+	return &policyRepository{db: db}
+}
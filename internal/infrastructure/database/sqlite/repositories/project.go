@@ -12,10 +12,14 @@ type projectRepository struct {
 	db *gorm.DB
 }
 
-func (r *projectRepository) Find() (*[]project.Project, error) {
+func (r *projectRepository) Find(organizationId uint) (*[]project.Project, error) {
 	var _projects []project.Project
 
-	if err := r.db.Unscoped().Find(&_projects).Error; err != nil {
+	query := map[string]interface{}{
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Unscoped().Where(query).Find(&_projects).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -25,11 +29,12 @@ func (r *projectRepository) Find() (*[]project.Project, error) {
 	return &_projects, nil
 }
 
-func (r *projectRepository) FindOneByKey(key string) (*project.Project, error) {
+func (r *projectRepository) FindOneByKey(key string, organizationId uint) (*project.Project, error) {
 	var _project project.Project
 
 	query := map[string]interface{}{
 		"key": key,
+		"organization_id": organizationId,
 	}
 
 	if err := r.db.Unscoped().Where(query).First(&_project).Error; err != nil {
@@ -42,6 +47,19 @@ func (r *projectRepository) FindOneByKey(key string) (*project.Project, error) {
 	return &_project, nil
 }
 
+func (r *projectRepository) FindOneByID(id uint) (*project.Project, error) {
+	var _project project.Project
+
+	if err := r.db.Unscoped().Where(&project.Project{Model: gorm.Model{ID: id}}).First(&_project).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_project, nil
+}
+
 func (r *projectRepository) Create(payload *project.Project) (*project.Project, error) {
 	_project := project.Project{
 		Name: payload.Name,
@@ -50,6 +68,7 @@ func (r *projectRepository) Create(payload *project.Project) (*project.Project,
 		Stage: payload.Stage,
 		BusinessDomain: payload.BusinessDomain,
 		CreatedBy: payload.CreatedBy,
+		OrganizationID: payload.OrganizationID,
 	}
 
 	err := r.db.Create(&_project).Error
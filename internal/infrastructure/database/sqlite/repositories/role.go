@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/authorization"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func (r *roleRepository) Find(organizationId uint) (*[]authorization.Role, error) {
+	var _roles []authorization.Role
+
+	query := map[string]interface{}{
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).Find(&_roles).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_roles, nil
+}
+
+func (r *roleRepository) FindOneByKey(key string, organizationId uint) (*authorization.Role, error) {
+	var _role authorization.Role
+
+	query := map[string]interface{}{
+		"key": key,
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).First(&_role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_role, nil
+}
+
+func (r *roleRepository) Create(payload *authorization.Role) (*authorization.Role, error) {
+	_role := authorization.Role{
+		Key: payload.Key,
+		Name: payload.Name,
+		Description: payload.Description,
+		Permissions: payload.Permissions,
+		OrganizationID: payload.OrganizationID,
+	}
+
+	err := r.db.Create(&_role).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create role: " + err.Error())
+	}
+
+	return &_role, nil
+}
+
+func (r *roleRepository) Update(payload *authorization.Role) error {
+	err := r.db.Updates(payload).Error
+
+	if err != nil {
+		return errors.New("failed to update role: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *roleRepository) Delete(key string) error {
+	query := map[string]interface{}{
+		"key": key,
+	}
+
+	return r.db.Unscoped().Where(query).Delete(&authorization.Role{}).Error
+}
+
+func NewRoleRepository(db *gorm.DB) authorization.RoleRepository {
+	return &roleRepository{db: db}
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/scim"
+)
+
+type scimTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *scimTokenRepository) Find(organizationId uint) (*[]scim.Token, error) {
+	var _tokens []scim.Token
+
+	query := map[string]interface{}{
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).Order("created_at desc").Find(&_tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return &_tokens, nil
+}
+
+func (r *scimTokenRepository) FindOneByHash(hash string) (*scim.Token, error) {
+	var _token scim.Token
+
+	query := map[string]interface{}{
+		"token_hash": hash,
+	}
+
+	if err := r.db.Where(query).First(&_token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_token, nil
+}
+
+func (r *scimTokenRepository) Create(payload *scim.Token) (*scim.Token, error) {
+	_token := scim.Token{
+		OrganizationID: payload.OrganizationID,
+		Name: payload.Name,
+		TokenHash: payload.TokenHash,
+	}
+
+	err := r.db.Create(&_token).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create scim token: " + err.Error())
+	}
+
+	return &_token, nil
+}
+
+func (r *scimTokenRepository) RecordUse(id uint, usedAt time.Time) error {
+	err := r.db.Model(&scim.Token{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).
+		Error
+
+	if err != nil {
+		return errors.New("failed to record scim token use: " + err.Error())
+	}
+
+	return nil
+}
+
+// Delete removes a token for good, it can never be used again
+func (r *scimTokenRepository) Delete(id uint, organizationId uint) error {
+	result := r.db.Unscoped().
+		Where("id = ? AND organization_id = ?", id, organizationId).
+		Delete(&scim.Token{})
+
+	if result.Error != nil {
+		return errors.New("failed to delete scim token: " + result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("scim token not found")
+	}
+
+	return nil
+}
+
+type scimGroupRepository struct {
+	db *gorm.DB
+}
+
+func (r *scimGroupRepository) Find(organizationId uint) (*[]scim.Group, error) {
+	var _groups []scim.Group
+
+	query := map[string]interface{}{
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).Order("id").Find(&_groups).Error; err != nil {
+		return nil, err
+	}
+
+	return &_groups, nil
+}
+
+func (r *scimGroupRepository) FindOne(id uint, organizationId uint) (*scim.Group, error) {
+	var _group scim.Group
+
+	query := map[string]interface{}{
+		"id": id,
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).First(&_group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_group, nil
+}
+
+func (r *scimGroupRepository) FindOneByDisplayName(displayName string, organizationId uint) (*scim.Group, error) {
+	var _group scim.Group
+
+	query := map[string]interface{}{
+		"display_name": displayName,
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).First(&_group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_group, nil
+}
+
+func (r *scimGroupRepository) Create(payload *scim.Group) (*scim.Group, error) {
+	_group := scim.Group{
+		OrganizationID: payload.OrganizationID,
+		DisplayName: payload.DisplayName,
+		ExternalID: payload.ExternalID,
+		Members: payload.Members,
+		OrganizationRole: payload.OrganizationRole,
+		ProjectRoles: payload.ProjectRoles,
+	}
+
+	err := r.db.Create(&_group).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create scim group: " + err.Error())
+	}
+
+	return &_group, nil
+}
+
+func (r *scimGroupRepository) Update(payload *scim.Group) error {
+	// Save writes zero values too, so members and roles can be cleared
+	err := r.db.Save(payload).Error
+
+	if err != nil {
+		return errors.New("failed to update scim group: " + err.Error())
+	}
+
+	return nil
+}
+
+// Delete removes a group for good, so a group with the same name can be provisioned again
+func (r *scimGroupRepository) Delete(id uint) error {
+	err := r.db.Unscoped().Delete(&scim.Group{}, id).Error
+
+	if err != nil {
+		return errors.New("failed to delete scim group: " + err.Error())
+	}
+
+	return nil
+}
+
+func NewSCIMTokenRepository(db *gorm.DB) scim.TokenRepository {
+	return &scimTokenRepository{db: db}
+}
+
+func NewSCIMGroupRepository(db *gorm.DB) scim.GroupRepository {
+	return &scimGroupRepository{db: db}
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/secret"
+)
+
+type dataKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *dataKeyRepository) Find() (*[]secret.DataKey, error) {
+	var _keys []secret.DataKey
+
+	if err := r.db.Order("id desc").Find(&_keys).Error; err != nil {
+		return nil, err
+	}
+
+	return &_keys, nil
+}
+
+func (r *dataKeyRepository) FindOne(id uint) (*secret.DataKey, error) {
+	var _key secret.DataKey
+
+	if err := r.db.First(&_key, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_key, nil
+}
+
+func (r *dataKeyRepository) Create(payload *secret.DataKey) (*secret.DataKey, error) {
+	_key := secret.DataKey{
+		MasterKeyID: payload.MasterKeyID,
+		WrappedKey: payload.WrappedKey,
+	}
+
+	err := r.db.Create(&_key).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create data key: " + err.Error())
+	}
+
+	return &_key, nil
+}
+
+// Rewrap replaces the wrapped data key, after the master key was rotated
+func (r *dataKeyRepository) Rewrap(id uint, masterKeyId string, wrappedKey string) error {
+	err := r.db.Model(&secret.DataKey{Model: gorm.Model{ID: id}}).
+		Updates(map[string]interface{}{
+			"master_key_id": masterKeyId,
+			"wrapped_key": wrappedKey,
+		}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to rewrap data key: " + err.Error())
+	}
+
+	return nil
+}
+
+// RetireOthers retires every active key but the given one
+func (r *dataKeyRepository) RetireOthers(id uint, retiredAt time.Time) error {
+	err := r.db.Model(&secret.DataKey{}).
+		Where("id <> ? AND retired_at IS NULL", id).
+		UpdateColumn("retired_at", retiredAt).
+		Error
+
+	if err != nil {
+		return errors.New("failed to retire data keys: " + err.Error())
+	}
+
+	return nil
+}
+
+// DeleteRetired removes keys retired before the given time for good, once nothing is encrypted
+// with them anymore
+func (r *dataKeyRepository) DeleteRetired(retiredBefore time.Time) error {
+	err := r.db.Unscoped().
+		Where("retired_at IS NOT NULL AND retired_at < ?", retiredBefore).
+		Delete(&secret.DataKey{}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to delete retired data keys: " + err.Error())
+	}
+
+	return nil
+}
+
+func NewDataKeyRepository(db *gorm.DB) secret.DataKeyRepository {
+	return &dataKeyRepository{db: db}
+}
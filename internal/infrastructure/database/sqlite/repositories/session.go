@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/session"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func (r *sessionRepository) FindActive(accountId uint, now time.Time) (*[]session.Session, error) {
+	var _sessions []session.Session
+
+	if err := r.db.Where("account_id = ? AND revoked_at IS NULL AND expires_at > ?", accountId, now).Order("last_used_at desc").Find(&_sessions).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_sessions, nil
+}
+
+func (r *sessionRepository) FindOne(id uint) (*session.Session, error) {
+	var _session session.Session
+
+	if err := r.db.Where(&session.Session{Model: gorm.Model{ID: id}}).First(&_session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_session, nil
+}
+
+func (r *sessionRepository) Create(payload *session.Session) (*session.Session, error) {
+	_session := session.Session{
+		AccountID: payload.AccountID,
+		IP: payload.IP,
+		UserAgent: payload.UserAgent,
+		AuthMethod: payload.AuthMethod,
+		LastUsedAt: payload.LastUsedAt,
+		ExpiresAt: payload.ExpiresAt,
+	}
+
+	err := r.db.Create(&_session).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create session: " + err.Error())
+	}
+
+	return &_session, nil
+}
+
+func (r *sessionRepository) Update(payload *session.Session) error {
+	err := r.db.Updates(payload).Error
+
+	if err != nil {
+		return errors.New("failed to update session: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) Revoke(id uint, at time.Time) error {
+	err := r.db.Model(&session.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", at).
+		Error
+
+	if err != nil {
+		return errors.New("failed to revoke session: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeAll(accountId uint, at time.Time) error {
+	err := r.db.Model(&session.Session{}).
+		Where("account_id = ? AND revoked_at IS NULL", accountId).
+		UpdateColumn("revoked_at", at).
+		Error
+
+	if err != nil {
+		return errors.New("failed to revoke sessions: " + err.Error())
+	}
+
+	return nil
+}
+
+// DeleteAll removes every session of an account for good, with their refresh tokens
+func (r *sessionRepository) DeleteAll(accountId uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		sessionIds := tx.Unscoped().Model(&session.Session{}).Select("id").Where("account_id = ?", accountId)
+
+		if err := tx.Unscoped().Where("session_id IN (?)", sessionIds).Delete(&session.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("account_id = ?", accountId).Delete(&session.Session{}).Error
+	})
+
+	if err != nil {
+		return errors.New("failed to delete sessions: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) FindOneToken(tokenHash string) (*session.RefreshToken, error) {
+	var _token session.RefreshToken
+
+	query := map[string]interface{}{
+		"token_hash": tokenHash,
+	}
+
+	if err := r.db.Where(query).First(&_token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_token, nil
+}
+
+func (r *sessionRepository) CreateToken(payload *session.RefreshToken) (*session.RefreshToken, error) {
+	_token := session.RefreshToken{
+		SessionID: payload.SessionID,
+		TokenHash: payload.TokenHash,
+	}
+
+	err := r.db.Create(&_token).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create refresh token: " + err.Error())
+	}
+
+	return &_token, nil
+}
+
+// RotateToken marks a refresh token as rotated, reporting false when it already was
+func (r *sessionRepository) RotateToken(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&session.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL", id).
+		UpdateColumn("rotated_at", at)
+
+	if result.Error != nil {
+		return false, errors.New("failed to rotate refresh token: " + result.Error.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func NewSessionRepository(db *gorm.DB) session.SessionRepository {
+	return &sessionRepository{db: db}
+}
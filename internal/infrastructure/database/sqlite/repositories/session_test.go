@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/session"
+)
+
+func newTestRefreshToken(t *testing.T) (session.SessionRepository, uint) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&session.Session{}, &session.RefreshToken{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	repository := NewSessionRepository(db)
+
+	_session, err := repository.Create(&session.Session{AccountID: 1, LastUsedAt: time.Now(), ExpiresAt: time.Now().Add(session.TTL)})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	_token, err := repository.CreateToken(&session.RefreshToken{SessionID: _session.ID, TokenHash: "hash"})
+	if err != nil {
+		t.Fatalf("failed to create refresh token: %v", err)
+	}
+
+	return repository, _token.ID
+}
+
+func TestRotateToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		rotate int
+		want   bool
+	}{
+		{name: "rotates a token", rotate: 1, want: true},
+		{name: "detects a token rotated already", rotate: 2, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository, id := newTestRefreshToken(t)
+
+			var rotated bool
+			var err error
+
+			for i := 0; i < tt.rotate; i++ {
+				rotated, err = repository.RotateToken(id, time.Now())
+			}
+
+			if err != nil {
+				t.Fatalf("RotateToken() error = %v", err)
+			}
+			if rotated != tt.want {
+				t.Fatalf("RotateToken() = %v, want %v", rotated, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateTokenConcurrently(t *testing.T) {
+	repository, id := newTestRefreshToken(t)
+
+	var wg sync.WaitGroup
+	var rotated atomic.Int32
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := repository.RotateToken(id, time.Now()); err == nil && ok {
+				rotated.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := rotated.Load(); got != 1 {
+		t.Fatalf("refresh token rotated %d times, want 1", got)
+	}
+}
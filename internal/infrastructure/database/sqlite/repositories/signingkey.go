@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/signingkey"
+)
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *signingKeyRepository) Find() (*[]signingkey.SigningKey, error) {
+	var _keys []signingkey.SigningKey
+
+	if err := r.db.Order("id desc").Find(&_keys).Error; err != nil {
+		return nil, err
+	}
+
+	return &_keys, nil
+}
+
+// Rotate stores a new signing key and retires the active ones, as long as activeId is still
+// active, or 0 when there was no active key. Otherwise another instance rotated it first and
+// ErrKeyRotated is returned
+func (r *signingKeyRepository) Rotate(payload *signingkey.SigningKey, activeId uint, retiredAt time.Time, verifiableUntil time.Time) (*signingkey.SigningKey, error) {
+	_key := signingkey.SigningKey{
+		KeyID: payload.KeyID,
+		Algorithm: payload.Algorithm,
+		PrivateKey: payload.PrivateKey,
+		PublicKey: payload.PublicKey,
+	}
+
+	retire := map[string]interface{}{
+		"retired_at": retiredAt,
+		"verifiable_until": verifiableUntil,
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if activeId != 0 {
+			result := tx.Model(&signingkey.SigningKey{}).
+				Where("id = ? AND retired_at IS NULL", activeId).
+				Updates(retire)
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected != 1 {
+				return signingkey.ErrKeyRotated
+			}
+		}
+
+		// Retire whatever else is still active, e.g. keys generated by instances starting together
+		if err := tx.Model(&signingkey.SigningKey{}).Where("retired_at IS NULL").Updates(retire).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&_key).Error
+	})
+
+	if errors.Is(err, signingkey.ErrKeyRotated) {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, errors.New("failed to rotate signing key: " + err.Error())
+	}
+
+	return &_key, nil
+}
+
+// UpdatePrivateKey replaces the stored private key, e.g. once re-encrypted
+func (r *signingKeyRepository) UpdatePrivateKey(id uint, privateKey string) error {
+	err := r.db.Model(&signingkey.SigningKey{Model: gorm.Model{ID: id}}).
+		Update("private_key", privateKey).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update signing key: " + err.Error())
+	}
+
+	return nil
+}
+
+// DeleteExpired removes retired keys for good once they no longer verify any token
+func (r *signingKeyRepository) DeleteExpired(now time.Time) error {
+	err := r.db.Unscoped().
+		Where("verifiable_until IS NOT NULL AND verifiable_until < ?", now).
+		Delete(&signingkey.SigningKey{}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to delete expired signing keys: " + err.Error())
+	}
+
+	return nil
+}
+
+func NewSigningKeyRepository(db *gorm.DB) signingkey.SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/signingkey"
+)
+
+func newTestSigningKeyRepository(t *testing.T) signingkey.SigningKeyRepository {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&signingkey.SigningKey{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	// SQLite has no row locks, a single connection serializes the transactions instead
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	return NewSigningKeyRepository(db)
+}
+
+func rotateTestKey(repository signingkey.SigningKeyRepository, keyId string, activeId uint) (*signingkey.SigningKey, error) {
+	now := time.Now()
+
+	return repository.Rotate(&signingkey.SigningKey{
+		KeyID: keyId,
+		Algorithm: signingkey.AlgorithmEdDSA,
+		PrivateKey: "sealed",
+		PublicKey: "public",
+	}, activeId, now, now.Add(time.Hour))
+}
+
+func activeKeys(t *testing.T, repository signingkey.SigningKeyRepository) []string {
+	t.Helper()
+
+	_keys, err := repository.Find()
+	if err != nil {
+		t.Fatalf("failed to find signing keys: %v", err)
+	}
+
+	active := []string{}
+
+	for _, _key := range *_keys {
+		if _key.IsActive() {
+			active = append(active, _key.KeyID)
+		}
+	}
+
+	return active
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name       string
+		activeId   func(first *signingkey.SigningKey) uint
+		wantErr    error
+		wantActive string
+	}{
+		{name: "rotates the active key", activeId: func(first *signingkey.SigningKey) uint { return first.ID }, wantActive: "second"},
+		{name: "rejects a key that is not active", activeId: func(first *signingkey.SigningKey) uint { return first.ID + 100 }, wantErr: signingkey.ErrKeyRotated, wantActive: "first"},
+		{name: "retires keys left active by instances starting together", activeId: func(first *signingkey.SigningKey) uint { return 0 }, wantActive: "second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newTestSigningKeyRepository(t)
+
+			first, err := rotateTestKey(repository, "first", 0)
+			if err != nil {
+				t.Fatalf("failed to create the first key: %v", err)
+			}
+
+			_, err = rotateTestKey(repository, "second", tt.activeId(first))
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rotate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			active := activeKeys(t, repository)
+
+			if len(active) != 1 || active[0] != tt.wantActive {
+				t.Fatalf("active keys = %v, want [%s]", active, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestRotateConcurrently(t *testing.T) {
+	repository := newTestSigningKeyRepository(t)
+
+	first, err := rotateTestKey(repository, "first", 0)
+	if err != nil {
+		t.Fatalf("failed to create the first key: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var rotated atomic.Int32
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := rotateTestKey(repository, fmt.Sprintf("key-%d", i), first.ID); err == nil {
+				rotated.Add(1)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if got := rotated.Load(); got != 1 {
+		t.Fatalf("key rotated %d times, want 1", got)
+	}
+
+	if active := activeKeys(t, repository); len(active) != 1 {
+		t.Fatalf("active keys = %v, want exactly one", active)
+	}
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/sso"
+)
+
+type providerRepository struct {
+	db *gorm.DB
+}
+
+func (r *providerRepository) FindOneByOrganization(organizationId uint) (*sso.Provider, error) {
+	var _provider sso.Provider
+
+	query := map[string]interface{}{
+		"organization_id": organizationId,
+	}
+
+	if err := r.db.Where(query).First(&_provider).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_provider, nil
+}
+
+func (r *providerRepository) Create(payload *sso.Provider) (*sso.Provider, error) {
+	_provider := sso.Provider{
+		OrganizationID: payload.OrganizationID,
+		Issuer: payload.Issuer,
+		ClientID: payload.ClientID,
+		ClientSecret: payload.ClientSecret,
+		Scopes: payload.Scopes,
+		RoleClaim: payload.RoleClaim,
+		RoleMapping: payload.RoleMapping,
+		DefaultRole: payload.DefaultRole,
+		Enabled: payload.Enabled,
+	}
+
+	err := r.db.Create(&_provider).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create sso provider: " + err.Error())
+	}
+
+	return &_provider, nil
+}
+
+func (r *providerRepository) Update(payload *sso.Provider) error {
+	// Save writes zero values too, so the provider can be disabled and its mapping cleared
+	err := r.db.Save(payload).Error
+
+	if err != nil {
+		return errors.New("failed to update sso provider: " + err.Error())
+	}
+
+	return nil
+}
+
+// Delete removes the provider for good, so the organization can configure a new one
+func (r *providerRepository) Delete(id uint) error {
+	err := r.db.Unscoped().Delete(&sso.Provider{}, id).Error
+
+	if err != nil {
+		return errors.New("failed to delete sso provider: " + err.Error())
+	}
+
+	return nil
+}
+
+func NewProviderRepository(db *gorm.DB) sso.ProviderRepository {
+	return &providerRepository{db: db}
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/webauthn"
+)
+
+type credentialRepository struct {
+	db *gorm.DB
+}
+
+func (r *credentialRepository) Find(accountId uint) (*[]webauthn.Credential, error) {
+	var _credentials []webauthn.Credential
+
+	if err := r.db.Where("account_id = ?", accountId).Order("created_at asc").Find(&_credentials).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_credentials, nil
+}
+
+func (r *credentialRepository) FindOne(id uint, accountId uint) (*webauthn.Credential, error) {
+	var _credential webauthn.Credential
+
+	if err := r.db.Where("id = ? AND account_id = ?", id, accountId).First(&_credential).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_credential, nil
+}
+
+func (r *credentialRepository) FindOneByCredentialID(credentialId string) (*webauthn.Credential, error) {
+	var _credential webauthn.Credential
+
+	query := map[string]interface{}{
+		"credential_id": credentialId,
+	}
+
+	if err := r.db.Where(query).First(&_credential).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &_credential, nil
+}
+
+func (r *credentialRepository) Count(accountId uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&webauthn.Credential{}).Where("account_id = ?", accountId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *credentialRepository) Create(payload *webauthn.Credential) (*webauthn.Credential, error) {
+	_credential := webauthn.Credential{
+		AccountID: payload.AccountID,
+		Name: payload.Name,
+		CredentialID: payload.CredentialID,
+		PublicKey: payload.PublicKey,
+		Algorithm: payload.Algorithm,
+		SignCount: payload.SignCount,
+	}
+
+	err := r.db.Create(&_credential).Error
+
+	if err != nil {
+		return nil, errors.New("failed to create webauthn credential: " + err.Error())
+	}
+
+	return &_credential, nil
+}
+
+func (r *credentialRepository) Rename(id uint, name string) error {
+	err := r.db.Model(&webauthn.Credential{Model: gorm.Model{ID: id}}).
+		Update("name", name).
+		Error
+
+	if err != nil {
+		return errors.New("failed to rename webauthn credential: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *credentialRepository) RecordUse(id uint, signCount uint32, at time.Time) error {
+	err := r.db.Model(&webauthn.Credential{Model: gorm.Model{ID: id}}).
+		Updates(map[string]interface{}{"sign_count": signCount, "last_used_at": at}).
+		Error
+
+	if err != nil {
+		return errors.New("failed to update webauthn credential: " + err.Error())
+	}
+
+	return nil
+}
+
+// Delete removes a credential for good, so the authenticator can be registered again
+func (r *credentialRepository) Delete(id uint) error {
+	err := r.db.Unscoped().Delete(&webauthn.Credential{}, id).Error
+
+	if err != nil {
+		return errors.New("failed to delete webauthn credential: " + err.Error())
+	}
+
+	return nil
+}
+
+func (r *credentialRepository) DeleteAll(accountId uint) error {
+	err := r.db.Unscoped().Where("account_id = ?", accountId).Delete(&webauthn.Credential{}).Error
+
+	if err != nil {
+		return errors.New("failed to delete webauthn credentials: " + err.Error())
+	}
+
+	return nil
+}
+
+func NewCredentialRepository(db *gorm.DB) webauthn.CredentialRepository {
+	return &credentialRepository{db: db}
+}
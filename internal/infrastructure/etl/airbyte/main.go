@@ -149,6 +149,53 @@ func (c *AirbyteContext) CreateSourceConnection(name string, configuration map[s
 	return &result.SourceId, nil
 }
 
+func (c *AirbyteContext) UpdateSourceConnection(sourceId string, configuration map[string]interface{}) error {
+	token, err := retrieveAccessToken(c.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve access token: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"configuration": configuration,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	url := c.cfg.AirbyteEndpoint
+
+	if c.cfg.AirbyteCloud {
+		url += fmt.Sprintf("/v1/sources/%s", sourceId)
+	} else {
+		url += fmt.Sprintf("/api/public/v1/sources/%s", sourceId)
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update source connection: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update source connection (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	log.Printf("Successfully updated source - %s", sourceId)
+
+	return nil
+}
+
 func (c *AirbyteContext) DeleteSourceConnection(sourceId string) error {
 	token, err := retrieveAccessToken(c.cfg)
 	if err != nil {
@@ -175,8 +222,9 @@ func (c *AirbyteContext) DeleteSourceConnection(sourceId string) error {
 		return fmt.Errorf("failed to delete source connection: %w", err)
 	}
 	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
+
+	// A source that no longer exists is already deleted, so deletes can be retried
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
 		respBody, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("failed to delete source connection (status %d): %s", resp.StatusCode, string(respBody))
 	}
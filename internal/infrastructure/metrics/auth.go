@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	FailedAuthAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_failed_attempts_total",
+		Help: "Failed authentication attempts by kind (password, mfa).",
+	}, []string{"kind"})
+
+	AuthLockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_lockouts_total",
+		Help: "Temporary lockouts by scope (account, ip).",
+	}, []string{"scope"})
+
+	AuthThrottledRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_throttled_requests_total",
+		Help: "Authentication requests rejected while locked out, by scope (account, ip).",
+	}, []string{"scope"})
+
+	MFAChallengesExhausted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_mfa_challenges_exhausted_total",
+		Help: "MFA challenges discarded after too many failed codes.",
+	})
+)
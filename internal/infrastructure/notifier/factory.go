@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"sync"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	domain "github.com/darksuei/suei-intelligence/internal/domain/notifier"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/notifier/file"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/notifier/log"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/notifier/smtp"
+)
+
+var (
+	instance domain.Notifier
+	once     sync.Once
+)
+
+// GetNotifier returns a singleton notifier instance
+func GetNotifier() domain.Notifier {
+
+	once.Do(func() {
+		switch config.Notifier().NotifierType {
+			case domain.NotifierTypeSMTP:
+				instance = smtp.NewNotifier(config.Notifier())
+			case domain.NotifierTypeFile:
+				instance = file.NewNotifier(config.Notifier().NotifierFilePath)
+			case domain.NotifierTypeLog:
+				instance = log.NewNotifier()
+			default:
+				instance = log.NewNotifier()
+		}
+	})
+	return instance
+}
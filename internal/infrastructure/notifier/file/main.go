@@ -0,0 +1,38 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	domain "github.com/darksuei/suei-intelligence/internal/domain/notifier"
+)
+
+// FileNotifier appends messages as JSON lines to a local file, for testing
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewNotifier(path string) domain.Notifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) Send(message domain.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(map[string]interface{}{
+		"sentAt":  time.Now().UTC(),
+		"to":      message.To,
+		"subject": message.Subject,
+		"body":    message.Body,
+	})
+}
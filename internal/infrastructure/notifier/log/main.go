@@ -0,0 +1,19 @@
+package log
+
+import (
+	"log"
+
+	domain "github.com/darksuei/suei-intelligence/internal/domain/notifier"
+)
+
+// LogNotifier writes messages to the application log, for local development and testing
+type LogNotifier struct{}
+
+func NewNotifier() domain.Notifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(message domain.Message) error {
+	log.Printf("notification to=%s subject=%q\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
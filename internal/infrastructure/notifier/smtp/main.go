@@ -0,0 +1,50 @@
+package smtp
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	domain "github.com/darksuei/suei-intelligence/internal/domain/notifier"
+)
+
+// SMTPNotifier delivers messages as plain text emails
+type SMTPNotifier struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewNotifier(cfg *config.NotifierConfig) domain.Notifier {
+	var auth smtp.Auth
+
+	if cfg.SMTPUsername != "" {
+		host, _, _ := net.SplitHostPort(cfg.SMTPAddr)
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+	}
+
+	return &SMTPNotifier{
+		addr: cfg.SMTPAddr,
+		from: cfg.SMTPFrom,
+		auth: auth,
+	}
+}
+
+func (n *SMTPNotifier) Send(message domain.Message) error {
+	// Reject header injection through the recipient or subject
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return fmt.Errorf("invalid message headers")
+	}
+
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		n.from,
+		message.To,
+		message.Subject,
+		message.Body,
+	)
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{message.To}, []byte(msg))
+}
@@ -0,0 +1,262 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/sso"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
+)
+
+// discoveryTTL is how long provider configuration and signing keys are cached
+const discoveryTTL = time.Hour
+
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(cfg *config.SSOConfig) *Client {
+	return &Client{http: &http.Client{Timeout: cfg.SSOHTTPTimeout}}
+}
+
+// Discover retrieves the configuration document of an OpenID provider
+func (c *Client) Discover(issuer string) (*sso.Discovery, error) {
+	issuer = strings.TrimRight(issuer, "/")
+	cacheKey := sso.BuildDiscoveryKey(issuer)
+
+	var discovery sso.Discovery
+
+	if cached, err := cache.GetCache().Get(cacheKey); err == nil {
+		if err := json.Unmarshal([]byte(cached), &discovery); err == nil {
+			return &discovery, nil
+		}
+	}
+
+	body, err := c.get(issuer + "/.well-known/openid-configuration")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover identity provider: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to decode identity provider configuration: %w", err)
+	}
+
+	// The document must be about the issuer it was retrieved from
+	if strings.TrimRight(discovery.Issuer, "/") != issuer || discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("invalid identity provider configuration")
+	}
+
+	_ = cache.GetCache().Set(cacheKey, string(body), discoveryTTL)
+
+	return &discovery, nil
+}
+
+// ExchangeCode redeems an authorization code with its PKCE verifier, returning the raw ID token
+func (c *Client) ExchangeCode(discovery *sso.Discovery, clientID string, clientSecret string, code string, codeVerifier string, redirectURI string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	if result.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+
+	return result.IDToken, nil
+}
+
+// VerifyIDToken verifies an ID token's signature, issuer, audience, expiry and nonce
+func (c *Client) VerifyIDToken(discovery *sso.Discovery, rawIDToken string, clientID string, nonce string) (*sso.Identity, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.signingKey(discovery, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+
+	if subject == "" || email == "" {
+		return nil, errors.New("id token has no subject or email")
+	}
+
+	// Accounts are matched by email, an unverified or unconfirmed email could take one over
+	if verified, _ := claims["email_verified"].(bool); !verified {
+		return nil, errors.New("email is not verified by the identity provider")
+	}
+
+	return &sso.Identity{
+		Subject: subject,
+		Email: strings.ToLower(email),
+		Name: name,
+		Claims: claims,
+	}, nil
+}
+
+// signingKey returns the provider's key with the given ID, refreshing the cached key set
+// once when the key is unknown, as happens after key rotation
+func (c *Client) signingKey(discovery *sso.Discovery, kid string) (interface{}, error) {
+	cacheKey := "sso-jwks-" + discovery.JWKSURI
+
+	for attempt := 0; attempt < 2; attempt++ {
+		body, err := cache.GetCache().Get(cacheKey)
+
+		if err != nil || attempt > 0 {
+			raw, err := c.get(discovery.JWKSURI)
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve signing keys: %w", err)
+			}
+
+			body = string(raw)
+			_ = cache.GetCache().Set(cacheKey, body, discoveryTTL)
+		}
+
+		var jwks struct {
+			Keys []jwk `json:"keys"`
+		}
+
+		if err := json.Unmarshal([]byte(body), &jwks); err != nil {
+			return nil, fmt.Errorf("failed to decode signing keys: %w", err)
+		}
+
+		for _, key := range jwks.Keys {
+			if (kid == "" || key.Kid == kid) && (key.Use == "" || key.Use == "sig") {
+				if publicKey, err := key.publicKey(); err == nil {
+					return publicKey, nil
+				}
+			}
+		}
+	}
+
+	return nil, errors.New("no signing key found for id token")
+}
+
+func (c *Client) get(endpoint string) ([]byte, error) {
+	resp, err := c.http.Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	decode := func(value string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+		if err != nil || len(b) == 0 {
+			return nil, errors.New("invalid key parameter")
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, errors.New("unsupported curve")
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, errors.New("unsupported key type")
+}
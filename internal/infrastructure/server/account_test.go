@@ -0,0 +1,235 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+)
+
+// projectCreator returns the creator snapshot of a project
+func projectCreator(t *testing.T, key string) map[string]interface{} {
+	t.Helper()
+
+	status, body := request("GET", "/project/"+key, login(t, rootEmail, rootPassword), nil)
+	if status != http.StatusOK {
+		t.Fatalf("failed to retrieve project (%d): %s", status, body)
+	}
+
+	createdBy, _ := body["project"].(map[string]interface{})["CreatedBy"].(map[string]interface{})
+	return createdBy
+}
+
+// createOwnProject creates a project as the given account
+func createOwnProject(t *testing.T, headers map[string]string, key string) {
+	t.Helper()
+
+	status, body := request("POST", "/project", headers, map[string]string{
+		"name": key,
+		"key": key,
+		"description": "test project",
+		"stage": "SANDBOX",
+		"businessDomain": "test",
+	})
+	if status != http.StatusCreated && status != http.StatusOK {
+		t.Fatalf("failed to create project (%d): %s", status, body)
+	}
+}
+
+func TestDisableAccount(t *testing.T) {
+	const password = "Passw0rd!disable"
+
+	email := "disable@example.com"
+	guest := "disable-guest@example.com"
+
+	newAccount(t, email, "GUEST", password)
+	newAccount(t, guest, "GUEST", password)
+
+	headers := login(t, email, password)
+
+	tests := []struct {
+		name       string
+		caller     map[string]string
+		path       string
+		wantStatus int
+		wantLogin  int
+	}{
+		{name: "guests cannot disable accounts", caller: login(t, guest, password), path: "/account/disable?email=" + email, wantStatus: http.StatusForbidden, wantLogin: http.StatusOK},
+		{name: "accounts cannot disable themselves", caller: login(t, rootEmail, rootPassword), path: "/account/disable?email=" + rootEmail, wantStatus: http.StatusBadRequest},
+		{name: "disables an account", caller: login(t, rootEmail, rootPassword), path: "/account/disable?email=" + email, wantStatus: http.StatusOK, wantLogin: http.StatusForbidden},
+		{name: "enables an account", caller: login(t, rootEmail, rootPassword), path: "/account/enable?email=" + email, wantStatus: http.StatusOK, wantLogin: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("POST", tt.path, tt.caller, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if tt.wantLogin == 0 {
+				return
+			}
+
+			status, body = request("POST", "/auth/login", nil, map[string]string{"email": email, "password": password})
+			if status != tt.wantLogin {
+				t.Fatalf("login: status = %d, want %d: %s", status, tt.wantLogin, body)
+			}
+		})
+	}
+
+	// Disabling signed the account out, enabling it again does not revive old tokens
+	if status, body := request("GET", "/auth/sessions", headers, nil); status != http.StatusUnauthorized {
+		t.Fatalf("access token issued before the account was disabled: status = %d, want %d: %s", status, http.StatusUnauthorized, body)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	const password = "Passw0rd!delete"
+
+	tests := []struct {
+		name          string
+		email         string
+		projectKey    string
+		self          bool
+		transferTo    string
+		wantCreatedBy map[string]interface{}
+	}{
+		{name: "transfers what the account created", email: "delete-transfer@example.com", projectKey: "deletetransfer", transferTo: rootEmail, wantCreatedBy: map[string]interface{}{"Email": rootEmail, "Name": "Root"}},
+		{name: "attributes what the account created to a deleted account", email: "delete-self@example.com", projectKey: "deleteself", self: true, wantCreatedBy: map[string]interface{}{"Email": "", "Name": accountDomain.DeletedAccountName}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newAccount(t, tt.email, "ADMIN", password)
+
+			headers := login(t, tt.email, password)
+
+			createOwnProject(t, headers, tt.projectKey)
+
+			caller := login(t, rootEmail, rootPassword)
+			if tt.self {
+				caller = headers
+			}
+
+			path := "/account?email=" + tt.email
+			if tt.transferTo != "" {
+				path += "&transferTo=" + tt.transferTo
+			}
+
+			status, body := request("DELETE", path, caller, nil)
+			if status != http.StatusOK {
+				t.Fatalf("failed to delete account (%d): %s", status, body)
+			}
+
+			if createdBy := projectCreator(t, tt.projectKey); createdBy["Email"] != tt.wantCreatedBy["Email"] || createdBy["Name"] != tt.wantCreatedBy["Name"] {
+				t.Fatalf("CreatedBy = %v, want %v", createdBy, tt.wantCreatedBy)
+			}
+
+			if status, _ := request("GET", "/auth/sessions", headers, nil); status != http.StatusUnauthorized {
+				t.Fatalf("access token of the deleted account: status = %d, want %d", status, http.StatusUnauthorized)
+			}
+
+			if status, _ := request("POST", "/auth/login", nil, map[string]string{"email": tt.email, "password": password}); status != http.StatusBadRequest {
+				t.Fatalf("login of the deleted account: status = %d, want %d", status, http.StatusBadRequest)
+			}
+		})
+	}
+
+	t.Run("keeps the last superadmin", func(t *testing.T) {
+		status, body := request("DELETE", "/account?email="+rootEmail, login(t, rootEmail, rootPassword), nil)
+		if status != http.StatusConflict {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusConflict, body)
+		}
+	})
+}
+
+func TestUpdateAccount(t *testing.T) {
+	const password = "Passw0rd!update"
+
+	admin := "update-admin@example.com"
+	guest := "update-guest@example.com"
+	other := "update-other@example.com"
+
+	newAccount(t, admin, "ADMIN", password)
+	newAccount(t, guest, "GUEST", password)
+	newAccount(t, other, "GUEST", password)
+
+	tests := []struct {
+		name       string
+		caller     string
+		email      string
+		body       map[string]string
+		wantStatus int
+		wantName   string
+		wantEmail  string
+	}{
+		{name: "an admin cannot update a superadmin", caller: admin, email: rootEmail, body: map[string]string{"email": "takeover@example.com"}, wantStatus: http.StatusForbidden},
+		{name: "a guest cannot update another account", caller: guest, email: other, body: map[string]string{"name": "renamed"}, wantStatus: http.StatusForbidden},
+		{name: "rejects an email in use", caller: admin, email: guest, body: map[string]string{"email": other}, wantStatus: http.StatusConflict},
+		{name: "rejects an invalid email", caller: admin, email: guest, body: map[string]string{"email": "not-an-email"}, wantStatus: http.StatusBadRequest},
+		{name: "an admin updates a guest", caller: admin, email: guest, body: map[string]string{"name": "renamed-guest"}, wantStatus: http.StatusOK, wantName: "renamed-guest", wantEmail: guest},
+		{name: "an account updates itself", caller: other, email: other, body: map[string]string{"email": "update-self@example.com"}, wantStatus: http.StatusOK, wantName: "update-other", wantEmail: "update-self@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("PUT", "/account?email="+tt.email, login(t, tt.caller, password), tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			// Fields left out of the request are kept
+			_account, _ := body["account"].(map[string]interface{})
+			if _account["Name"] != tt.wantName || _account["Email"] != tt.wantEmail {
+				t.Fatalf("account = %v, want name %s and email %s", _account, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+
+	t.Run("keeps accounts that were not updated", func(t *testing.T) {
+		login(t, rootEmail, rootPassword)
+		login(t, guest, password)
+	})
+}
+
+func TestTransferOwnership(t *testing.T) {
+	const password = "Passw0rd!transfer"
+
+	from := "transfer-from@example.com"
+	to := "transfer-to@example.com"
+
+	newAccount(t, from, "ADMIN", password)
+	newAccount(t, to, "GUEST", password)
+
+	createOwnProject(t, login(t, from, password), "transfer")
+
+	tests := []struct {
+		name       string
+		from       string
+		to         string
+		wantStatus int
+		wantEmail  string
+	}{
+		{name: "rejects an unknown account", from: from, to: "nobody@example.com", wantStatus: http.StatusBadRequest, wantEmail: from},
+		{name: "rejects a transfer to the same account", from: from, to: from, wantStatus: http.StatusBadRequest, wantEmail: from},
+		{name: "transfers what the account created", from: from, to: to, wantStatus: http.StatusOK, wantEmail: to},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("POST", "/account/transfer", login(t, rootEmail, rootPassword), map[string]string{"from": tt.from, "to": tt.to})
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if createdBy := projectCreator(t, "transfer"); createdBy["Email"] != tt.wantEmail {
+				t.Fatalf("CreatedBy = %v, want %s", createdBy, tt.wantEmail)
+			}
+		})
+	}
+}
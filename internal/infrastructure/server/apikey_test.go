@@ -0,0 +1,44 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	createProject(t, "apikeys")
+	createProject(t, "others")
+
+	headers, _, id := newAPIKey(t, "apikeys", map[string]string{"object": "datasource", "action": "read"})
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		path       string
+		revoke     bool
+		wantStatus int
+	}{
+		{name: "authenticates the key on its project", headers: headers, path: "/project/apikeys/datasources", wantStatus: http.StatusOK},
+		{name: "confines the key to its project", headers: headers, path: "/project/others/datasources", wantStatus: http.StatusForbidden},
+		{name: "rejects a key with an unknown secret", headers: map[string]string{"X-API-Key": headers["X-API-Key"] + "x"}, path: "/project/apikeys/datasources", wantStatus: http.StatusUnauthorized},
+		{name: "rejects a malformed key", headers: map[string]string{"X-API-Key": "sk_malformed"}, path: "/project/apikeys/datasources", wantStatus: http.StatusUnauthorized},
+		{name: "rejects a revoked key", headers: headers, path: "/project/apikeys/datasources", revoke: true, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.revoke {
+				status, body := request("DELETE", fmt.Sprintf("/project/apikeys/api-keys/%d", int(id)), login(t, rootEmail, rootPassword), nil)
+				if status != http.StatusOK {
+					t.Fatalf("failed to revoke api key (%d): %s", status, body)
+				}
+			}
+
+			status, body := request("GET", tt.path, tt.headers, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+		})
+	}
+}
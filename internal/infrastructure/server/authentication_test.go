@@ -0,0 +1,84 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAccessTokenRevocation(t *testing.T) {
+	const password = "Passw0rd!revoke"
+
+	createProject(t, "tokens")
+
+	bystander := "bystander@example.com"
+	newAccount(t, bystander, "GUEST", password)
+
+	tests := []struct {
+		name  string
+		email string
+		// revoke runs the change that must invalidate the account's access token. It returns
+		// the Authorization header of a token issued with the change, if any
+		revoke func(t *testing.T, email string, headers map[string]string) map[string]string
+	}{
+		{
+			name: "logout",
+			email: "revoke-logout@example.com",
+			revoke: func(t *testing.T, email string, headers map[string]string) map[string]string {
+				status, body := request("POST", "/auth/logout", headers, nil)
+				if status != http.StatusOK {
+					t.Fatalf("logout failed (%d): %s", status, body)
+				}
+				return nil
+			},
+		},
+		{
+			name: "password change",
+			email: "revoke-password@example.com",
+			revoke: func(t *testing.T, email string, headers map[string]string) map[string]string {
+				status, body := request("PUT", "/account/password", headers, map[string]string{"currentPassword": password, "newPassword": "Passw0rd!changed"})
+				if status != http.StatusOK {
+					t.Fatalf("password change failed (%d): %s", status, body)
+				}
+
+				token, _ := body["access_token"].(string)
+				return map[string]string{"Authorization": "Bearer " + token}
+			},
+		},
+		{
+			name: "project role grant",
+			email: "revoke-grant@example.com",
+			revoke: func(t *testing.T, email string, headers map[string]string) map[string]string {
+				status, body := request("PUT", "/project/tokens/members", login(t, rootEmail, rootPassword), map[string]string{"email": email, "role": "VIEWER"})
+				if status != http.StatusOK && status != http.StatusCreated {
+					t.Fatalf("project role grant failed (%d): %s", status, body)
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newAccount(t, tt.email, "GUEST", password)
+
+			headers := login(t, tt.email, password)
+			bystanderHeaders := login(t, bystander, password)
+
+			reissued := tt.revoke(t, tt.email, headers)
+
+			if status, body := request("GET", "/auth/sessions", headers, nil); status != http.StatusUnauthorized {
+				t.Fatalf("revoked access token: status = %d, want %d: %s", status, http.StatusUnauthorized, body)
+			}
+
+			if reissued != nil {
+				if status, body := request("GET", "/auth/sessions", reissued, nil); status != http.StatusOK {
+					t.Fatalf("access token issued with the change: status = %d, want %d: %s", status, http.StatusOK, body)
+				}
+			}
+
+			if status, body := request("GET", "/auth/sessions", bystanderHeaders, nil); status != http.StatusOK {
+				t.Fatalf("access token of another account: status = %d, want %d: %s", status, http.StatusOK, body)
+			}
+		})
+	}
+}
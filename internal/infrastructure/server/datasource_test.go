@@ -0,0 +1,318 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	datasourceService "github.com/darksuei/suei-intelligence/internal/application/datasource"
+)
+
+var postgresDatasource = map[string]interface{}{
+	"sourceType": "postgres",
+	"configuration": map[string]interface{}{
+		"host": "db.local",
+		"port": 5432,
+		"database": "app",
+		"username": "user",
+		"password": "hunter2",
+		"ssl_mode": map[string]interface{}{"mode": "disable"},
+		"replication_method": map[string]interface{}{"mode": "Xmin"},
+		"tunnel_method": map[string]interface{}{"mode": "NO_TUNNEL"},
+	},
+}
+
+// newAPIKey creates an API key for a project as root and returns its header, prefix and id
+func newAPIKey(t *testing.T, projectKey string, permissions ...map[string]string) (map[string]string, string, float64) {
+	t.Helper()
+
+	status, body := request("POST", "/project/"+projectKey+"/api-keys", login(t, rootEmail, rootPassword), map[string]interface{}{
+		"name": "ci",
+		"permissions": permissions,
+		"expiresInDays": 30,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create api key (%d): %s", status, body)
+	}
+
+	key, _ := body["key"].(string)
+	prefix, _ := body["apiKey"].(map[string]interface{})["Prefix"].(string)
+	id, _ := body["apiKey"].(map[string]interface{})["ID"].(float64)
+
+	return map[string]string{"X-API-Key": key}, prefix, id
+}
+
+func TestNewDatasourceWithAPIKey(t *testing.T) {
+	createProject(t, "apikeys")
+
+	tests := []struct {
+		name        string
+		permissions []map[string]string
+		wantStatus  int
+	}{
+		{name: "key with write permission", permissions: []map[string]string{{"object": "datasource", "action": "write"}}, wantStatus: http.StatusCreated},
+		{name: "key with read permission", permissions: []map[string]string{{"object": "datasource", "action": "read"}}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, prefix, _ := newAPIKey(t, "apikeys", tt.permissions...)
+
+			createdBefore, _ := airbyte.counts()
+
+			status, body := request("POST", "/project/apikeys/datasources", headers, postgresDatasource)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			createdAfter, _ := airbyte.counts()
+
+			if tt.wantStatus != http.StatusCreated {
+				if createdAfter != createdBefore {
+					t.Fatalf("an ETL source was created for a rejected request")
+				}
+				return
+			}
+
+			createdBy, _ := body["datasource"].(map[string]interface{})["CreatedBy"].(map[string]interface{})
+
+			if createdBy["APIKey"] != prefix {
+				t.Fatalf("CreatedBy.APIKey = %v, want %s", createdBy["APIKey"], prefix)
+			}
+			if createdBy["Email"] != rootEmail {
+				t.Fatalf("CreatedBy.Email = %v, want the key creator %s", createdBy["Email"], rootEmail)
+			}
+		})
+	}
+}
+
+func TestUpdateDatasource(t *testing.T) {
+	createProject(t, "updates")
+
+	root := login(t, rootEmail, rootPassword)
+
+	status, body := request("POST", "/project/updates/datasources", root, postgresDatasource)
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create datasource (%d): %s", status, body)
+	}
+
+	created, _ := body["datasource"].(map[string]interface{})
+	id, _ := created["ID"].(float64)
+	sourceId, _ := created["SourceID"].(string)
+	path := fmt.Sprintf("/project/updates/datasources/%v", id)
+
+	// with returns the masked configuration of the datasource with some values replaced
+	masked, _ := created["Configuration"].(map[string]interface{})
+	with := func(values map[string]interface{}) map[string]interface{} {
+		configuration := map[string]interface{}{}
+		for k, v := range masked {
+			configuration[k] = v
+		}
+		for k, v := range values {
+			configuration[k] = v
+		}
+		return configuration
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		configuration map[string]interface{}
+		wantStatus    int
+		wantReason    string
+		// Configuration of the source and of the datasource once the request is handled
+		wantHost     string
+		wantPassword string
+	}{
+		{name: "keeps masked secrets", path: path, configuration: with(map[string]interface{}{"host": "db2.local"}), wantStatus: http.StatusOK, wantHost: "db2.local", wantPassword: "hunter2"},
+		{name: "rolls back a configuration that fails to connect", path: path, configuration: with(map[string]interface{}{"host": "db3.local", "password": wrongPassword}), wantStatus: http.StatusBadRequest, wantReason: "auth", wantHost: "db2.local", wantPassword: "hunter2"},
+		{name: "rejects an invalid configuration", path: path, configuration: with(map[string]interface{}{"port": 70000}), wantStatus: http.StatusUnprocessableEntity, wantHost: "db2.local", wantPassword: "hunter2"},
+		{name: "changes secrets", path: path, configuration: with(map[string]interface{}{"host": "db2.local", "password": "hunter3"}), wantStatus: http.StatusOK, wantHost: "db2.local", wantPassword: "hunter3"},
+		{name: "rejects an unknown datasource", path: "/project/updates/datasources/999999", configuration: with(nil), wantStatus: http.StatusNotFound, wantHost: "db2.local", wantPassword: "hunter3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("PUT", tt.path, root, map[string]interface{}{"configuration": tt.configuration})
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if tt.wantReason != "" {
+				if reason := body["test"].(map[string]interface{})["reason"]; reason != tt.wantReason {
+					t.Fatalf("reason = %v, want %s", reason, tt.wantReason)
+				}
+			}
+
+			source := airbyte.configuration(sourceId)
+			if source["host"] != tt.wantHost || source["password"] != tt.wantPassword {
+				t.Fatalf("source configuration = %v, want host %s and password %s", source, tt.wantHost, tt.wantPassword)
+			}
+
+			_datasource := storedDatasource(t, id)
+			expectPassword(t, _datasource, tt.wantPassword)
+
+			if configuration, _ := datasourceService.RetrieveConfiguration(_datasource); configuration["host"] != tt.wantHost {
+				t.Fatalf("host = %v, want %s", configuration["host"], tt.wantHost)
+			}
+		})
+	}
+
+	t.Run("tests the connection on demand", func(t *testing.T) {
+		status, body := request("POST", path+"/test", root, nil)
+		if status != http.StatusOK {
+			t.Fatalf("failed to test connection (%d): %s", status, body)
+		}
+
+		if test, _ := body["test"].(map[string]interface{}); test["succeeded"] != true || test["latencyMs"] == nil {
+			t.Fatalf("test = %v, want a succeeded test with its latency", test)
+		}
+	})
+
+	t.Run("rejects schema mappings of an unknown datasource", func(t *testing.T) {
+		status, body := request("PUT", "/project/updates/datasources/999999/schema-mapping", root, map[string]interface{}{"schemaMapping": map[string]interface{}{}})
+		if status != http.StatusBadRequest || body["error"] != "Invalid datasource" {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+	})
+
+	t.Run("does not return the error of the ETL", func(t *testing.T) {
+		status, body := request("PUT", path, root, map[string]interface{}{"configuration": with(map[string]interface{}{"host": rejectedHost})})
+		if status != http.StatusInternalServerError || body["reason"] == nil {
+			t.Fatalf("status = %d, want %d with a reason: %s", status, http.StatusInternalServerError, body)
+		}
+
+		if strings.Contains(body.String(), "hunter3") {
+			t.Fatalf("response quotes the configuration: %s", body)
+		}
+	})
+
+	t.Run("reports a configuration that could not be restored", func(t *testing.T) {
+		status, body := request("PUT", path, root, map[string]interface{}{"configuration": with(map[string]interface{}{"host": stuckHost, "password": wrongPassword})})
+		if status != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusInternalServerError, body)
+		}
+
+		if message, _ := body["error"].(string); !strings.Contains(message, "configuration not restored") {
+			t.Fatalf("error = %v, want the configuration reported as not restored", body["error"])
+		}
+
+		expectPassword(t, storedDatasource(t, id), "hunter3")
+	})
+}
+
+// listsDatasource reports whether a list of datasources includes one
+func listsDatasource(t *testing.T, path string, headers map[string]string, id float64) bool {
+	t.Helper()
+
+	status, body := request("GET", path, headers, nil)
+	if status != http.StatusOK {
+		t.Fatalf("failed to list datasources (%d): %s", status, body)
+	}
+
+	datasources, _ := body["datasources"].([]interface{})
+
+	for _, _datasource := range datasources {
+		if _datasource.(map[string]interface{})["ID"] == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestDatasourceTrash(t *testing.T) {
+	const (
+		editor   = "trash-editor@example.com"
+		password = "Passw0rd!trash"
+	)
+
+	createProject(t, "trash")
+	newAccount(t, editor, "GUEST", password)
+
+	root := login(t, rootEmail, rootPassword)
+
+	status, body := request("PUT", "/project/trash/members", root, map[string]string{"email": editor, "role": "EDITOR"})
+	if status != http.StatusOK && status != http.StatusCreated {
+		t.Fatalf("failed to grant project role (%d): %s", status, body)
+	}
+
+	headers := login(t, editor, password)
+
+	status, body = request("POST", "/project/trash/datasources", root, postgresDatasource)
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create datasource (%d): %s", status, body)
+	}
+
+	created, _ := body["datasource"].(map[string]interface{})
+	id, _ := created["ID"].(float64)
+	sourceId, _ := created["SourceID"].(string)
+	path := fmt.Sprintf("/project/trash/datasources/%v", id)
+
+	trash := func(t *testing.T) {
+		t.Helper()
+
+		status, body := request("DELETE", path, headers, nil)
+		expectStatus(t, "delete: "+body.String(), status, http.StatusOK)
+	}
+
+	t.Run("moves deleted datasources to the trash", func(t *testing.T) {
+		trash(t)
+
+		if listsDatasource(t, "/project/trash/datasources", headers, id) {
+			t.Fatal("deleted datasource is still listed")
+		}
+		if !listsDatasource(t, "/project/trash/datasources/trash", headers, id) {
+			t.Fatal("deleted datasource is not in the trash")
+		}
+	})
+
+	t.Run("rejects schema requests for a deleted datasource", func(t *testing.T) {
+		status, body := request("PUT", path+"/schema-mapping", headers, map[string]interface{}{"schemaMapping": map[string]interface{}{}})
+		if status != http.StatusBadRequest || body["error"] != "Invalid datasource" {
+			t.Fatalf("schema mapping: status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+
+		status, _ = request("GET", path+"/source-schema-definition", headers, nil)
+		expectStatus(t, "source schema", status, http.StatusBadRequest)
+	})
+
+	t.Run("restores a deleted datasource", func(t *testing.T) {
+		status, body := request("POST", path+"/restore", headers, nil)
+		expectStatus(t, "restore: "+body.String(), status, http.StatusOK)
+
+		if !listsDatasource(t, "/project/trash/datasources", headers, id) {
+			t.Fatal("restored datasource is not listed")
+		}
+		if listsDatasource(t, "/project/trash/datasources/trash", headers, id) {
+			t.Fatal("restored datasource is still in the trash")
+		}
+
+		status, _ = request("POST", path+"/restore", headers, nil)
+		expectStatus(t, "restore of a datasource not deleted", status, http.StatusNotFound)
+	})
+
+	t.Run("purges a deleted datasource", func(t *testing.T) {
+		status, _ := request("DELETE", path+"/purge", root, nil)
+		expectStatus(t, "purge of a datasource not deleted", status, http.StatusNotFound)
+
+		trash(t)
+
+		status, _ = request("DELETE", path+"/purge", headers, nil)
+		expectStatus(t, "purge by a project editor", status, http.StatusForbidden)
+
+		status, body := request("DELETE", path+"/purge", root, nil)
+		expectStatus(t, "purge: "+body.String(), status, http.StatusOK)
+
+		if airbyte.configuration(sourceId) != nil {
+			t.Fatal("ETL source of the purged datasource still exists")
+		}
+		if listsDatasource(t, "/project/trash/datasources/trash", headers, id) {
+			t.Fatal("purged datasource is still in the trash")
+		}
+
+		status, _ = request("POST", path+"/restore", headers, nil)
+		expectStatus(t, "restore of a purged datasource", status, http.StatusNotFound)
+	})
+}
@@ -1,26 +1,114 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	"github.com/darksuei/suei-intelligence/internal/application/authentication"
 	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	lockoutService "github.com/darksuei/suei-intelligence/internal/application/lockout"
 	"github.com/darksuei/suei-intelligence/internal/config"
 	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
 	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	lockoutDomain "github.com/darksuei/suei-intelligence/internal/domain/lockout"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
 )
 
-func NewAccount(c *gin.Context) {
-	// Parse the request body
+func RetrieveAccounts(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve accounts
+	_accounts, err := accountService.RetrieveAccounts(organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"accounts": accountDomain.ToAccountDTOs(_accounts),
+	})
+}
+
+func RetrieveAccountByEmail(c *gin.Context) {
+	// Get email from query params
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameter: email",
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization - callers may always read their own account
+	if !authorizeAccountAccess(c, email, "read") {
+		return
+	}
+
+	// Retrieve account
+	_account, err := accountService.RetrieveOrganizationAccount(email, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _account == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"account": accountDomain.ToAccountDTO(_account),
+		"security_level": accountDomain.GetSecurityLevel(*_account),
+	})
+}
+
+func UpdateAccount(c *gin.Context) {
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameter: email",
+		})
+		return
+	}
+
 	var req struct {
-		Name string `json:"name" binding:"required"`
-		Email string `json:"email" binding:"required"`
-		Password string `json:"password" binding:"required"`
-		Role string `json:"role" binding:"required"`
+		Name  *string `json:"name,omitempty" binding:"omitempty,min=1"` // <- fields left out are kept
+		Email *string `json:"email,omitempty" binding:"omitempty,email"`
 	}
 
 	if err := c.BindJSON(&req); err != nil {
@@ -31,39 +119,165 @@ func NewAccount(c *gin.Context) {
 		return
 	}
 
-	role, err := accountDomain.NewAccountRole(req.Role)
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization - callers may always update their own account. Updating another account
+	// administers it, as changing its email hands over password resets
+	if callerEmail, err := utils.GetUserEmailFromContext(c); err != nil || *callerEmail != email {
+		if !authorizeAccountAdministration(c, email, organizationKey) {
+			return
+		}
+	}
+
+	// Snapshot before update for the audit log
+	_before, _ := accountService.RetrieveOrganizationAccount(email, organizationKey, config.Database())
+
+	_account, err := accountService.UpdateAccount(email, organizationKey, req.Name, req.Email, config.Database())
+
+	if errors.Is(err, accountDomain.ErrEmailInUse) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Email already registered.",
+		})
+		return
+	}
+
 	if err != nil {
+		log.Printf("Error updating account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var _beforeDTO interface{}
+	if _before != nil {
+		_beforeDTO = accountDomain.ToAccountDTO(_before)
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.AccountUpdated,
+		TargetType: "account",
+		TargetID: email,
+		Changes: auditDomain.BuildChanges(_beforeDTO, accountDomain.ToAccountDTO(_account)),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"account": accountDomain.ToAccountDTO(_account),
+		"security_level": accountDomain.GetSecurityLevel(*_account),
+	})
+}
+
+// ChangePassword changes the caller's own password. The TOTP code is required when MFA is enabled.
+// Every session of the account is revoked and a fresh token pair is returned
+func ChangePassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"currentPassword" binding:"required"`
+		NewPassword string `json:"newPassword" binding:"required"`
+		Code string `json:"code,omitempty"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"error": "Invalid role.",
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
 		})
 		return
 	}
 
-	internalRoleKey := accountDomain.BuildRoleKey("default", authorizationDomain.AuthorizationDomainOrg, req.Role)
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
 
-	internalRoleJson := map[string]string{
-		"default": internalRoleKey,
+	if err != nil || email == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	var code *uint32
+
+	if req.Code != "" {
+		codeUint64, err := strconv.ParseUint(req.Code, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code format"})
+			return
+		}
+
+		_code := uint32(codeUint64)
+		code = &_code
+	}
+
+	if !enforceLockout(c, *email) {
+		return
+	}
+
+	_account, err := accountService.ChangePassword(*email, organizationKey, req.CurrentPassword, req.NewPassword, code, config.Database())
+
+	// Guessing the current password or code counts towards the lockout like a failed sign-in
+	if errors.Is(err, accountDomain.ErrWrongPassword) {
+		recordFailedAttempt(c, *email, lockoutDomain.AttemptPassword)
+	}
+
+	if errors.Is(err, accountDomain.ErrInvalidTOTP) {
+		recordFailedAttempt(c, *email, lockoutDomain.AttemptMFA)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
 
-	// Create account
-	_account, err := accountService.NewAccount(req.Name, req.Email, req.Password, role, internalRoleJson, config.Database())
+	lockoutService.ClearFailedAttempts(_account.Email)
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.PasswordChanged,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
+	auth, err := authentication.LoginWithoutPassword(_account.Email, sessionClientOf(c), config.Common(), config.JWT(), config.Database())
 
 	if err != nil {
-		log.Printf("Error creating account: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
-		"account": accountDomain.ToAccountDTO(_account),
-	  })
+		"access_token": auth.AccessToken,
+		"refresh_token": auth.RefreshToken,
+	})
 }
 
-func RetrieveAccounts(c *gin.Context) {
-	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "read")
+// UnlockAccount lifts a temporary lockout caused by failed sign-in attempts
+func UnlockAccount(c *gin.Context) {
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameter: email",
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "write")
 
 	if err != nil || !allow {
 		c.JSON(http.StatusForbidden, gin.H{
@@ -72,25 +286,36 @@ func RetrieveAccounts(c *gin.Context) {
 		return
 	}
 
-	// Retrieve accounts
-	_accounts, err := accountService.RetrieveAccounts(config.Database())
+	_account, err := accountService.RetrieveOrganizationAccount(email, organizationKey, config.Database())
 
-	if err != nil {
-		log.Printf("Error retrieving account: %v", err)
+	if err != nil || _account == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	if err := lockoutService.UnlockAccount(_account.Email); err != nil {
+		log.Printf("Error unlocking account: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.AccountUnlocked,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
-		"accounts": accountDomain.ToAccountDTOs(_accounts),
 	})
 }
 
-func RetrieveAccountByEmail(c *gin.Context) {
-	// Get email from query params
+// Force an MFA reset for another account, which has to enroll again on its next sign-in
+func ResetMFA(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -99,32 +324,148 @@ func RetrieveAccountByEmail(c *gin.Context) {
 		return
 	}
 
-	// Retrieve account
-	_account, err := accountService.RetrieveAccount(email, config.Database())
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
 
-	if err != nil {
-		log.Printf("Error retrieving account: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
 		})
 		return
 	}
 
-	if _account == nil {
+	_account, err := accountService.RetrieveOrganizationAccount(email, organizationKey, config.Database())
+
+	if err != nil || _account == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Not Found.",
 		})
 		return
 	}
 
+	// Resetting admins requires account admin
+	if _account.Role == accountDomain.SuperAdmin || _account.Role == accountDomain.Admin {
+		allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "admin")
+
+		if err != nil || !allow {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "forbidden",
+			})
+			return
+		}
+	}
+
+	mfaEnabled := _account.MFAEnabled
+
+	if _, err := accountService.ResetMFA(_account.Email, organizationKey, config.Database()); err != nil {
+		log.Printf("Error resetting mfa: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.MFAReset,
+		TargetType: "account",
+		TargetID: _account.Email,
+		Changes: map[string]auditDomain.Change{
+			"MFAEnabled": {Before: mfaEnabled, After: false},
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// authorizeAccountAccess allows callers to act on their own account, or on any account
+// of their organization with the given account permission
+func authorizeAccountAccess(c *gin.Context, email string, action string) bool {
+	if callerEmail, err := utils.GetUserEmailFromContext(c); err == nil && *callerEmail == email {
+		return true
+	}
+
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, action)
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return false
+	}
+
+	return true
+}
+
+func DisableAccount(c *gin.Context) {
+	setAccountDisabled(c, true)
+}
+
+func EnableAccount(c *gin.Context) {
+	setAccountDisabled(c, false)
+}
+
+// Disable or enable another account of the organization. A disabled account is signed out
+// everywhere and cannot sign in until it is enabled again
+func setAccountDisabled(c *gin.Context, disabled bool) {
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameter: email",
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	if !authorizeAccountAdministration(c, email, organizationKey) {
+		return
+	}
+
+	if callerEmail, err := utils.GetUserEmailFromContext(c); err == nil && *callerEmail == email {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "You cannot disable or enable your own account.",
+		})
+		return
+	}
+
+	_account, err := accountService.SetAccountDisabled(email, organizationKey, disabled, config.Database())
+
+	if err != nil {
+		accountLifecycleFailed(c, err)
+		return
+	}
+
+	action := auditDomain.AccountEnabled
+	if disabled {
+		action = auditDomain.AccountDisabled
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: action,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
 		"account": accountDomain.ToAccountDTO(_account),
-		"security_level": accountDomain.GetSecurityLevel(*_account),
 	})
 }
 
-func UpdateAccount(c *gin.Context) {
+// Delete an account and erase its personal data. Accounts can delete themselves. What the
+// account created is transferred to the account given in transferTo, or attributed to a
+// deleted account
+func DeleteAccount(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -133,9 +474,52 @@ func UpdateAccount(c *gin.Context) {
 		return
 	}
 
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	callerEmail, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || *callerEmail != email {
+		if !authorizeAccountAdministration(c, email, organizationKey) {
+			return
+		}
+	}
+
+	transferTo := c.Query("transferTo")
+
+	_account, err := accountService.DeleteAccount(email, organizationKey, transferTo, config.Database())
+
+	if err != nil {
+		accountLifecycleFailed(c, err)
+		return
+	}
+
+	event := auditDomain.AuditEvent{
+		Action: auditDomain.AccountDeleted,
+		TargetType: "account",
+		TargetID: strconv.FormatUint(uint64(_account.ID), 10), // <- the email is erased
+	}
+
+	if transferTo != "" {
+		event.Changes = map[string]auditDomain.Change{
+			"Owner": {Before: event.TargetID, After: transferTo},
+		}
+	}
+
+	recordAudit(c, event)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// Make another account the creator of everything an account created, e.g. before it leaves
+func TransferOwnership(c *gin.Context) {
 	var req struct {
-		Name  string `json:"name,omitempty"`
-		Email string `json:"email,omitempty"`
+		From string `json:"from" binding:"required,email"`
+		To string `json:"to" binding:"required,email"`
 	}
 
 	if err := c.BindJSON(&req); err != nil {
@@ -146,19 +530,88 @@ func UpdateAccount(c *gin.Context) {
 		return
 	}
 
-	_account, err := accountService.UpdateAccount(email, &req.Name, &req.Email, config.Database())
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
 
-	if err != nil {
-		log.Printf("Error updating account: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
 		})
 		return
 	}
 
+	_target, err := accountService.TransferOwnership(req.From, req.To, organizationKey, config.Database())
+
+	if err != nil {
+		accountLifecycleFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.OwnershipTransferred,
+		TargetType: "account",
+		TargetID: req.From,
+		Changes: map[string]auditDomain.Change{
+			"Owner": {Before: req.From, After: _target.Email},
+		},
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
-		"account": _account,
-		"security_level": accountDomain.GetSecurityLevel(*_account),
 	})
-}
\ No newline at end of file
+}
+
+// authorizeAccountAdministration allows account writers to administer accounts of the
+// organization, and account admins to administer admins too
+func authorizeAccountAdministration(c *gin.Context, email string, organizationKey string) bool {
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return false
+	}
+
+	_account, err := accountService.RetrieveOrganizationAccount(email, organizationKey, config.Database())
+
+	if err != nil || _account == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return false
+	}
+
+	if _account.Role == accountDomain.SuperAdmin || _account.Role == accountDomain.Admin {
+		allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "admin")
+
+		if err != nil || !allow {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "forbidden",
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+func accountLifecycleFailed(c *gin.Context, err error) {
+	if errors.Is(err, accountDomain.ErrLastSuperAdmin) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Cannot remove the last superadmin of the organization.",
+		})
+		return
+	}
+
+	log.Printf("Error updating account lifecycle: %v", err)
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": err.Error(),
+	})
+}
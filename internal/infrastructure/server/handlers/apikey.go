@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apiKeyService "github.com/darksuei/suei-intelligence/internal/application/apikey"
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+func RetrieveAPIKeys(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /project/:key/api-keys
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	_keys, err := apiKeyService.RetrieveKeys(key, organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"apiKeys": _keys,
+	})
+}
+
+// Create an API key for the project, with a subset of the caller's permissions on it.
+// Keys are created by accounts only, so that a key cannot outlive its revocation through another key
+func NewAPIKey(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+		Permissions []authorizationDomain.Permission `json:"permissions" binding:"required"`
+		ExpiresInDays int `json:"expiresInDays,omitempty" binding:"omitempty,min=1"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := requireAccountId(c); !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Failed to get email",
+		})
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /project/:key/api-keys
+
+	// Authorization
+	roles := utils.GetUserRolesFromContext(c)
+
+	allow, err := authorizationService.EnforceProjectRoles(roles, key, authorizationDomain.Project, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	expiresIn := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+
+	_key, apiKey, err := apiKeyService.NewKey(req.Name, req.Permissions, expiresIn, key, organizationKey, *email, roles, config.Database())
+
+	if err != nil {
+		log.Printf("Error creating api key: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.APIKeyCreated,
+		TargetType: "api_key",
+		TargetID: _key.Prefix,
+		ProjectKey: key,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"key": apiKey, // <- only shown once
+		"apiKey": _key,
+	})
+}
+
+func RevokeAPIKey(c *gin.Context) {
+	keyId, err := strconv.ParseUint(c.Param("id"), 10, 64) // assumes route is like /project/:key/api-keys/:id
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid api key id",
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key")
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	_key, err := apiKeyService.RevokeKey(uint(keyId), key, organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.APIKeyRevoked,
+		TargetType: "api_key",
+		TargetID: _key.Prefix,
+		ProjectKey: key,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"apiKey": _key,
+	})
+}
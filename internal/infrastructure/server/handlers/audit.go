@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	auditService "github.com/darksuei/suei-intelligence/internal/application/audit"
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+	maxAuditExport    = 100000
+)
+
+func RetrieveAuditEvents(c *gin.Context) {
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Audit, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	filter, err := parseAuditFilter(c, defaultAuditLimit, maxAuditLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Retrieve events
+	_events, err := auditService.RetrieveEvents(*filter, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving audit events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"events": _events,
+		"limit": filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+func ExportAuditEvents(c *gin.Context) {
+	format, err := auditDomain.NewExportFormat(c.DefaultQuery("format", string(auditDomain.ExportJSONL)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Audit, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	filter, err := parseAuditFilter(c, maxAuditExport, maxAuditExport)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Retrieve events
+	_events, err := auditService.RetrieveEvents(*filter, config.Database())
+
+	if err != nil {
+		log.Printf("Error exporting audit events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-events-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	switch format {
+	case auditDomain.ExportCSV:
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write(auditDomain.CSVHeader)
+		for _, e := range *_events {
+			_ = w.Write(auditDomain.ToCSVRecord(e))
+		}
+		w.Flush()
+
+	case auditDomain.ExportJSONL:
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+
+		enc := json.NewEncoder(c.Writer)
+		for _, e := range *_events {
+			_ = enc.Encode(e)
+		}
+	}
+}
+
+// recordAudit appends an event to the audit log, attributing it to the authenticated caller
+// unless the event already names its actor (e.g. login attempts)
+func recordAudit(c *gin.Context, event auditDomain.AuditEvent) {
+	if event.OrganizationKey == "" {
+		if organizationKey, err := utils.GetOrganizationFromContext(c); err == nil {
+			event.OrganizationKey = *organizationKey
+		}
+	}
+
+	if event.ActorID == "" {
+		if userID, err := utils.GetUserIdFromContext(c); err == nil {
+			event.ActorID = *userID
+		}
+	}
+
+	if event.ActorEmail == "" {
+		if email, err := utils.GetUserEmailFromContext(c); err == nil {
+			event.ActorEmail = *email
+		}
+	}
+
+	event.IP = c.ClientIP()
+	event.UserAgent = c.Request.UserAgent()
+
+	auditService.Record(event, config.Database())
+}
+
+func parseAuditFilter(c *gin.Context, defaultLimit int, maxLimit int) (*auditDomain.AuditFilter, error) {
+	organizationKey, err := utils.GetOrganizationFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &auditDomain.AuditFilter{
+		OrganizationKey: *organizationKey,
+		ActorEmail: c.Query("actor"),
+		Action: c.Query("action"),
+		TargetType: c.Query("targetType"),
+		TargetID: c.Query("targetId"),
+		ProjectKey: c.Query("projectKey"),
+		Limit: defaultLimit,
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from timestamp, expected RFC3339")
+		}
+		filter.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to timestamp, expected RFC3339")
+		}
+		filter.To = &t
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = n
+	}
+
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+
+	if offset := c.Query("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid offset")
+		}
+		filter.Offset = n
+	}
+
+	return filter, nil
+}
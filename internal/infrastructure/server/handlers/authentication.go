@@ -1,17 +1,25 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/darksuei/suei-intelligence/internal/application/account"
 	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	lockoutService "github.com/darksuei/suei-intelligence/internal/application/lockout"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
 	"github.com/darksuei/suei-intelligence/internal/application/authentication"
 	"github.com/darksuei/suei-intelligence/internal/application/mfa"
+	webauthnService "github.com/darksuei/suei-intelligence/internal/application/webauthn"
 	"github.com/darksuei/suei-intelligence/internal/config"
-	authenticationDomain "github.com/darksuei/suei-intelligence/internal/domain/authentication"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	lockoutDomain "github.com/darksuei/suei-intelligence/internal/domain/lockout"
+	mfaDomain "github.com/darksuei/suei-intelligence/internal/domain/mfa"
+	sessionDomain "github.com/darksuei/suei-intelligence/internal/domain/session"
+	webauthnDomain "github.com/darksuei/suei-intelligence/internal/domain/webauthn"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
 	"github.com/gin-gonic/gin"
@@ -33,16 +41,47 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	_account, err := account.RetrieveAccountWithPassword(req.Email, req.Password, config.Database())
+	if !enforceLockout(c, req.Email) {
+		return
+	}
+
+	_account, err := accountService.RetrieveAccountWithPassword(req.Email, req.Password, config.Database())
 
 	if err != nil || _account == nil {
+		recordAudit(c, auditDomain.AuditEvent{
+			OrganizationKey: organizationKeyOf(req.Email),
+			ActorEmail: req.Email,
+			Action: auditDomain.LoginFailed,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: req.Email,
+		})
+
+		recordFailedAttempt(c, req.Email, lockoutDomain.AttemptPassword)
+
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid email or password",
 		})
 		return
 	}
 
-	if _account.MFAEnabled {
+	if _account.IsDisabled() {
+		recordAudit(c, auditDomain.AuditEvent{
+			OrganizationKey: organizationKeyOf(req.Email),
+			ActorEmail: req.Email,
+			Action: auditDomain.LoginFailed,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: req.Email,
+		})
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Account is disabled.",
+		})
+		return
+	}
+
+	if authentication.IsMFAEnrolled(_account, config.Database()) {
 		challengeID := uuid.New().String()
 
 		challengeKey := fmt.Sprintf("challenge-id-%s", challengeID)
@@ -56,15 +95,35 @@ func Login(c *gin.Context) {
 			return
 		}
 	
+		methods := []string{}
+		if _account.MFAEnabled {
+			methods = append(methods, "totp")
+		}
+		if webauthnService.HasCredentials(_account.ID, config.Database()) {
+			methods = append(methods, "webauthn")
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": "success",
-			"mfa_required": _account.MFAEnabled,
+			"mfa_required": true,
+			"mfa_methods": methods,
 			"challenge_id": challengeID,
 		})
 		return
 	}
 
-	auth, err := authentication.Login(req.Email, req.Password, config.Common(), config.Database())
+	auth, err := authentication.Login(req.Email, req.Password, sessionClientOf(c), config.Common(), config.JWT(), config.Database())
+
+	// The password was valid, but MFA is enforced and the account has not enrolled yet
+	if errors.Is(err, mfaDomain.ErrEnrollmentRequired) {
+		lockoutService.ClearFailedAttempts(_account.Email)
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "MFA enrollment is required. Please enroll via /mfa/totp-uri and /mfa/confirm.",
+			"mfa_enrollment_required": true,
+		})
+		return
+	}
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -73,6 +132,17 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	lockoutService.ClearFailedAttempts(_account.Email)
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(_account.Email),
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.LoginSucceeded,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
 		"access_token": auth.AccessToken,
@@ -85,7 +155,9 @@ func MFA(c *gin.Context) {
 	// Parse the request body
 	var req struct {
 		ChallengeID string `json:"challenge_id" binding:"required"`
-		Code string `json:"code" binding:"required"`
+		Code string `json:"code,omitempty"`
+		RecoveryCode string `json:"recovery_code,omitempty"` // <- accepted in place of the TOTP code
+		WebAuthn *webauthnDomain.AssertionResponse `json:"webauthn,omitempty"` // <- security key assertion, see /auth/mfa/webauthn/options
 	}
 
 	if err := c.BindJSON(&req); err != nil {
@@ -96,6 +168,13 @@ func MFA(c *gin.Context) {
 		return
 	}
 
+	if req.Code == "" && req.RecoveryCode == "" && req.WebAuthn == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "TOTP code, recovery code or security key assertion is required.",
+		})
+		return
+	}
+
 	challengeKey := fmt.Sprintf("challenge-id-%s", req.ChallengeID)
 
 	email, err := cache.GetCache().Get(challengeKey)
@@ -107,6 +186,10 @@ func MFA(c *gin.Context) {
 		return
 	}
 
+	if !enforceLockout(c, email) {
+		return
+	}
+
 	// Retrieve account
 	_account, err := accountService.RetrieveAccount(email, config.Database())
 
@@ -124,29 +207,94 @@ func MFA(c *gin.Context) {
 		return
 	}
 
-	codeUint64, err := strconv.ParseUint(req.Code, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code format"})
-		return
-	}
+	var isCodeValid bool
+	remainingRecoveryCodes := -1
+
+	if req.WebAuthn != nil {
+		_, err := webauthnService.FinishAssertion(_account.ID, req.ChallengeID, *req.WebAuthn, config.Database(), config.WebAuthn())
+
+		isCodeValid = err == nil
+	} else if req.RecoveryCode != "" {
+		// Recovery codes are single-use, consumed on success
+		remaining, err := accountService.UseRecoveryCode(_account.Email, req.RecoveryCode, config.Database())
 
-	code := uint32(codeUint64)
+		isCodeValid = err == nil
+		remainingRecoveryCodes = remaining
+	} else {
+		codeUint64, err := strconv.ParseUint(req.Code, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code format"})
+			return
+		}
 
-	// Confirm and enable MFA
-	isCodeValid := mfa.VerifyTOTP(_account.MFASecret, code, time.Now())
+		code := uint32(codeUint64)
+
+		// Accounts enrolled with security keys only have an unconfirmed secret
+		isCodeValid = _account.MFAEnabled && mfa.VerifyTOTP(_account.MFASecret, code, time.Now())
+	}
 
 	if !isCodeValid {
+		recordAudit(c, auditDomain.AuditEvent{
+			OrganizationKey: organizationKeyOf(_account.Email),
+			ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+			ActorEmail: _account.Email,
+			Action: auditDomain.MFAFailed,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: _account.Email,
+		})
+
+		recordFailedAttempt(c, _account.Email, lockoutDomain.AttemptMFA)
+
+		// Discard the challenge once it has run out of attempts
+		if lockoutService.RecordChallengeAttempt(req.ChallengeID, config.Lockout()) {
+			_ = cache.GetCache().Delete(challengeKey)
+
+			recordAudit(c, auditDomain.AuditEvent{
+				OrganizationKey: organizationKeyOf(_account.Email),
+				ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+				ActorEmail: _account.Email,
+				Action: auditDomain.MFAChallengeExhausted,
+				Outcome: auditDomain.Failure,
+				TargetType: "account",
+				TargetID: _account.Email,
+			})
+
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Too many invalid codes. Please restart login flow.",
+			})
+			return
+		}
+
+		if req.WebAuthn != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid security key assertion.",
+			})
+			return
+		}
+
+		if req.RecoveryCode != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid recovery code.",
+			})
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid TOTP code.",
 		})
 		return
 	}
 
-	defer func() {
-		_ = cache.GetCache().Delete(challengeKey)
-	}()
+	// Consume the challenge, so that parallel requests cannot both complete it
+	if _, err := cache.GetCache().Take(challengeKey); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Please restart login flow.",
+		})
+		return
+	}
 
-	auth, err := authentication.LoginWithoutPassword(email, config.Common(), config.Database())
+	auth, err := authentication.LoginWithoutPassword(email, sessionClientOf(c), config.Common(), config.JWT(), config.Database())
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -155,11 +303,50 @@ func MFA(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	lockoutService.ClearFailedAttempts(_account.Email)
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(_account.Email),
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.MFASucceeded,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
+	if remainingRecoveryCodes < 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "success",
+			"access_token": auth.AccessToken,
+			"refresh_token": auth.RefreshToken,
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(_account.Email),
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.RecoveryCodeUsed,
+		TargetType: "account",
+		TargetID: _account.Email,
+		Changes: map[string]auditDomain.Change{
+			"RecoveryCodesRemaining": {Before: remainingRecoveryCodes + 1, After: remainingRecoveryCodes},
+		},
+	})
+
+	response := gin.H{
 		"message": "success",
 		"access_token": auth.AccessToken,
 		"refresh_token": auth.RefreshToken,
-	})
+		"recovery_codes_remaining": remainingRecoveryCodes,
+	}
+
+	if remainingRecoveryCodes <= mfaDomain.RecoveryCodeWarningThreshold {
+		response["warning"] = fmt.Sprintf("Only %d recovery codes remain. Please regenerate them.", remainingRecoveryCodes)
+	}
+
+	c.JSON(http.StatusOK, response)
 	return
 }
 
@@ -176,21 +363,67 @@ func RevokeToken(c *gin.Context) {
 		return
 	}
 
-	err := cache.GetCache().Delete(fmt.Sprintf("refresh-token-%s", authenticationDomain.HashRefreshToken(req.RefreshToken)))
+	// Revoke the session the token belongs to
+	email, err := authentication.RevokeRefreshToken(req.RefreshToken, config.Database())
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(email),
+		ActorEmail: email,
+		Action: auditDomain.TokenRevoked,
+		TargetType: "account",
+		TargetID: email,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
 	})
 	return
 }
 
+// Logout revokes the caller's access token and its session
+func Logout(c *gin.Context) {
+	accessToken, err := utils.GetAccessTokenFromContext(c)
+
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Failed to get access token",
+		})
+		return
+	}
+
+	if err := authentication.Logout(*accessToken, config.Database()); err != nil {
+		log.Printf("Error logging out: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	email, _ := utils.GetUserEmailFromContext(c)
+
+	targetId := ""
+	if email != nil {
+		targetId = *email
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.LoggedOut,
+		TargetType: "account",
+		TargetID: targetId,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
 func RefreshToken(c *gin.Context) {
 	var req struct {
 		RefreshToken string `json:"refresh_token" binding:"required"`
@@ -204,17 +437,129 @@ func RefreshToken(c *gin.Context) {
 		return
 	}
 
-	authTokens, err := authentication.Refresh(req.RefreshToken, config.Common(), config.Database())
+	// Resolve the token owner for the audit log before it is rotated
+	email := authentication.RetrieveRefreshTokenOwner(req.RefreshToken, config.Database())
+
+	authTokens, err := authentication.Refresh(req.RefreshToken, sessionClientOf(c), config.Common(), config.JWT(), config.Database())
 	if err != nil {
+		action := auditDomain.TokenRefreshFailed
+
+		// A replayed token revokes its whole session
+		if errors.Is(err, sessionDomain.ErrTokenReused) {
+			action = auditDomain.TokenReused
+		}
+
+		recordAudit(c, auditDomain.AuditEvent{
+			OrganizationKey: organizationKeyOf(email),
+			ActorEmail: email,
+			Action: action,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: email,
+		})
+
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "invalid or expired refresh token",
 		})
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(email),
+		ActorEmail: email,
+		Action: auditDomain.TokenRefreshed,
+		TargetType: "account",
+		TargetID: email,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
 		"access_token":  authTokens.AccessToken,
 		"refresh_token": authTokens.RefreshToken,
 	})
 }
+
+func ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	// Always succeed so the response does not reveal whether the account exists
+	if err := authentication.RequestPasswordReset(req.Email, config.Database(), config.Notifier()); err != nil {
+		log.Printf("Error requesting password reset: %v", err)
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(req.Email),
+		ActorEmail: req.Email,
+		Action: auditDomain.PasswordResetRequested,
+		TargetType: "account",
+		TargetID: req.Email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+func ResetPassword(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	_account, err := authentication.ResetPassword(req.Token, req.Password, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(_account.Email),
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.PasswordReset,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// organizationKeyOf resolves the organization of an account for the audit log, empty when unknown
+func organizationKeyOf(email string) string {
+	_account, err := accountService.RetrieveAccount(email, config.Database())
+
+	if err != nil || _account == nil {
+		return ""
+	}
+
+	_organization, err := organizationService.RetrieveOrganizationByID(_account.OrganizationID, config.Database())
+
+	if err != nil || _organization == nil {
+		return ""
+	}
+
+	return _organization.Key
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+func RetrievePolicies(c *gin.Context) {
+	// Authorization - policies apply to every organization, so only instance operators manage them
+	if !requireInstanceOperator(c) {
+		return
+	}
+
+	policies, err := authorizationService.RetrievePolicies()
+
+	if err != nil {
+		log.Printf("Error retrieving policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"policies": policies,
+	})
+}
+
+func AddPolicy(c *gin.Context) {
+	var req authorizationDomain.PolicyRule
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	if err := authorizationDomain.ValidatePolicyRule(req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Authorization - policies apply to every organization, so only instance operators manage them
+	if !requireInstanceOperator(c) {
+		return
+	}
+
+	added, err := authorizationService.AddPolicy(req)
+
+	if err != nil {
+		log.Printf("Error adding policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !added {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Policy already exists.",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.PolicyAdded,
+		TargetType: "policy",
+		TargetID: req.Role,
+		Changes: auditDomain.BuildChanges(nil, req),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"policy": req,
+	})
+}
+
+func RemovePolicy(c *gin.Context) {
+	req := authorizationDomain.PolicyRule{
+		Role: c.Query("role"),
+		Domain: c.Query("domain"),
+		Object: c.Query("object"),
+		Action: c.Query("action"),
+	}
+
+	if req.Role == "" || req.Domain == "" || req.Object == "" || req.Action == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameters: role, domain, object, action",
+		})
+		return
+	}
+
+	// Authorization - policies apply to every organization, so only instance operators manage them
+	if !requireInstanceOperator(c) {
+		return
+	}
+
+	removed, err := authorizationService.RemovePolicy(req)
+
+	if err != nil {
+		log.Printf("Error removing policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.PolicyRemoved,
+		TargetType: "policy",
+		TargetID: req.Role,
+		Changes: auditDomain.BuildChanges(req, nil),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+func RetrieveRoleInheritance(c *gin.Context) {
+	// Authorization - policies apply to every organization, so only instance operators manage them
+	if !requireInstanceOperator(c) {
+		return
+	}
+
+	rules, err := authorizationService.RetrieveRoleInheritance()
+
+	if err != nil {
+		log.Printf("Error retrieving role inheritance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"roleInheritance": rules,
+	})
+}
+
+func AddRoleInheritance(c *gin.Context) {
+	var req authorizationDomain.RoleInheritanceRule
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	if err := authorizationDomain.ValidateRoleInheritanceRule(req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Authorization - policies apply to every organization, so only instance operators manage them
+	if !requireInstanceOperator(c) {
+		return
+	}
+
+	added, err := authorizationService.AddRoleInheritance(req)
+
+	if err != nil {
+		log.Printf("Error adding role inheritance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !added {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Role inheritance already exists.",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RoleInheritanceAdded,
+		TargetType: "role-inheritance",
+		TargetID: req.Role,
+		Changes: auditDomain.BuildChanges(nil, req),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"roleInheritance": req,
+	})
+}
+
+func RemoveRoleInheritance(c *gin.Context) {
+	req := authorizationDomain.RoleInheritanceRule{
+		Role: c.Query("role"),
+		InheritedRole: c.Query("inheritedRole"),
+	}
+
+	if req.Role == "" || req.InheritedRole == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameters: role, inheritedRole",
+		})
+		return
+	}
+
+	// Authorization - policies apply to every organization, so only instance operators manage them
+	if !requireInstanceOperator(c) {
+		return
+	}
+
+	removed, err := authorizationService.RemoveRoleInheritance(req)
+
+	if err != nil {
+		log.Printf("Error removing role inheritance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RoleInheritanceRemoved,
+		TargetType: "role-inheritance",
+		TargetID: req.Role,
+		Changes: auditDomain.BuildChanges(req, nil),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// requireInstanceOperator aborts the request unless the caller operates the instance. Operating
+// the instance is not a Casbin role, so no organization can grant it to itself by editing policies
+func requireInstanceOperator(c *gin.Context) bool {
+	if email, err := utils.GetUserEmailFromContext(c); err == nil && email != nil {
+		_account, err := accountService.RetrieveAccount(*email, config.Database())
+
+		if err == nil && _account != nil && _account.InstanceOperator && !_account.IsDisabled() {
+			return true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": "forbidden",
+	})
+	return false
+}
@@ -1,15 +1,28 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 
+	setupService "github.com/darksuei/suei-intelligence/internal/application/setup"
 	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/gin-gonic/gin"
 )
 
 func RetrieveConfig(c *gin.Context) {
+	setupCompleted, err := setupService.IsSetupCompleted(config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving setup state: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve setup state",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"enforce_mfa": config.Common().EnforceMfa,
+		"setup_completed": setupCompleted,
 	})
 	return
-}
\ No newline at end of file
+}
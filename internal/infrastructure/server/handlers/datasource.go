@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
 	datasourceService "github.com/darksuei/suei-intelligence/internal/application/datasource"
 	"github.com/darksuei/suei-intelligence/internal/application/project"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
 	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
 	datasourceDomain "github.com/darksuei/suei-intelligence/internal/domain/datasource"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/etl"
@@ -18,35 +22,33 @@ import (
 )
 
 func SupportedDatasources(c *gin.Context) {
-    stripped := make([]map[string]interface{}, len(datasourceDomain.SupportedDatasources))
-    for i, ds := range datasourceDomain.SupportedDatasources {
-        copy := make(map[string]interface{})
-        for k, v := range ds {
-            if k != "form" {
-                copy[k] = v
-            }
-        }
-        stripped[i] = copy
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "message":     "success",
-        "datasources": stripped,
-    })
+	stripped := make([]gin.H, len(datasourceDomain.SupportedDatasources))
+	for i, ds := range datasourceDomain.SupportedDatasources {
+		stripped[i] = gin.H{
+			"name": ds.Name,
+			"logo": ds.Logo,
+			"desc": ds.Desc,
+			"sourceType": ds.SourceType,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "success",
+		"datasources": stripped,
+	})
 	return
 }
 
 func SupportedDatasource(c *gin.Context) {
 	sourceType := c.Param("sourceType")
 
-	for _, ds := range datasourceDomain.SupportedDatasources {
-		if ds["sourceType"] == sourceType {
-			c.JSON(http.StatusOK, gin.H{
-				"message":    "success",
-				"datasource": ds,
-			})
-			return
-		}
+	if ds := datasourceDomain.FindConnector(sourceType); ds != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "success",
+			"datasource": ds,
+			"schema":     ds.JSONSchema(), // <- the form as JSON Schema, in the shape of an Airbyte connectionSpecification
+		})
+		return
 	}
 
 	c.JSON(http.StatusNotFound, gin.H{
@@ -56,6 +58,11 @@ func SupportedDatasource(c *gin.Context) {
 }
 
 func NewDatasource(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		SourceType string `json:"sourceType" binding:"required"`
 		Configuration map[string]interface{} `json:"configuration" binding:"required"`
@@ -78,7 +85,7 @@ func NewDatasource(c *gin.Context) {
 	}
 
 	// Retrieve project
-	_project, err := project.RetrieveProject(projectKey, config.Database())
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
 
 	if err != nil || _project == nil {
 		log.Printf("Error retrieving project: %v", err)
@@ -88,6 +95,14 @@ func NewDatasource(c *gin.Context) {
 		return
 	}
 
+	// Archived projects are read-only and paused projects do not sync
+	if err := _project.CheckSyncable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	errs, err := datasourceDomain.ValidateInput(req.SourceType, req.Configuration)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -104,7 +119,7 @@ func NewDatasource(c *gin.Context) {
 	}
 
 	// Authorization
-	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "write")
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "write")
 
 	if err != nil || !allow {
 		c.JSON(http.StatusForbidden, gin.H{
@@ -113,6 +128,27 @@ func NewDatasource(c *gin.Context) {
 		return
 	}
 
+	// Resolve the creator before the ETL source exists, requests made with an API key have no account
+	createdByEmail, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || createdByEmail == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	_key, _ := utils.GetAPIKeyFromContext(c)
+
+	createdBy, err := datasourceService.RetrieveCreator(*createdByEmail, _key, organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	configuration := req.Configuration
 	configuration["sourceType"] = req.SourceType
 
@@ -142,17 +178,7 @@ func NewDatasource(c *gin.Context) {
 	}
 
 	// If success, create datasource
-	createdByEmail, err := utils.GetUserEmailFromContext(c)
-
-	if err != nil || createdByEmail == nil{
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get account",
-		})
-		return
-	}
-
-	// Create datasource
-	_datasource, err := datasourceService.NewDatasource(projectKey, req.SourceType, *sourceId, *createdByEmail, config.Database())
+	_datasource, err := datasourceService.NewDatasource(projectKey, organizationKey, req.SourceType, *sourceId, configuration, createdBy, config.Database())
 
 	if err != nil {
 		log.Printf("Error creating datasource: %v", err)
@@ -166,6 +192,14 @@ func NewDatasource(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.DatasourceCreated,
+		TargetType: "datasource",
+		TargetID: fmt.Sprintf("%d", _datasource.ID),
+		ProjectKey: projectKey,
+		Changes: auditDomain.BuildChanges(nil, _datasource),
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "success",
 		"datasource": _datasource,
@@ -174,6 +208,11 @@ func NewDatasource(c *gin.Context) {
 }
 
 func RetrieveDatasources(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	projectKey := c.Param("key") // assumes route is like /projects/:key
 	if projectKey == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -183,7 +222,7 @@ func RetrieveDatasources(c *gin.Context) {
 	}
 
 	// Authorization
-	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "read")
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "read")
 
 	if err != nil || !allow {
 		c.JSON(http.StatusForbidden, gin.H{
@@ -193,7 +232,7 @@ func RetrieveDatasources(c *gin.Context) {
 	}
 
 	// Retrieve datasources
-	_datasources, err := datasourceService.RetrieveDatasources(projectKey, config.Database())
+	_datasources, err := datasourceService.RetrieveDatasources(projectKey, organizationKey, config.Database())
 
 	if err != nil {
 		log.Printf("Error retrieving datasources: %v", err)
@@ -210,7 +249,290 @@ func RetrieveDatasources(c *gin.Context) {
 	return
 }
 
+// Update the connection configuration of a datasource. The ETL source is updated and re-tested,
+// and restored to the previous configuration when the test fails
+func UpdateDatasource(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Configuration map[string]interface{} `json:"configuration" binding:"required"` // <- masked secrets are kept
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	projectKey := c.Param("key") // assumes route is like /projects/:key/datasources/:id
+	if projectKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	datasourceIDString := c.Param("id")
+
+	datasourceID, err := strconv.ParseUint(datasourceIDString, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid datasource id",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve project
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		log.Printf("Error retrieving project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve project.",
+		})
+		return
+	}
+
+	// Archived projects are read-only and paused projects do not sync
+	if err := _project.CheckSyncable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	_before, err := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+
+	if err != nil || _before == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	stored, err := datasourceService.RetrieveConfiguration(_before)
+
+	if err != nil {
+		log.Printf("Error decrypting datasource configuration: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve datasource configuration.",
+		})
+		return
+	}
+
+	configuration := datasourceDomain.UnmaskSecrets(_before.SourceType, req.Configuration, stored)
+
+	errs, err := datasourceDomain.ValidateInput(_before.SourceType, configuration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errs != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors":  errs,
+		})
+		return
+	}
+
+	configuration["sourceType"] = _before.SourceType
+
+	// The ETL error may quote the configuration, only its category is returned
+	if err := etl.GetInstance().UpdateSourceConnection(_before.SourceID, configuration); err != nil {
+		log.Printf("Error updating datasource: %v", err)
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update datasource.",
+			"reason": datasourceDomain.ClassifyConnectionFailure(err),
+		})
+		return
+	}
+
+	// Test connection, restoring the previous configuration if it fails
+	test := testConnection(_before.SourceID)
+
+	if !test.Succeeded {
+		if err := rollbackSourceConnection(_before, stored); err != nil {
+			log.Printf("Error restoring source %s: %v", _before.SourceID, err)
+
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to connect to datasource, previous configuration not restored.",
+				"test": test,
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to connect to datasource, please check your connection details and try again.",
+			"test": test,
+		})
+		return
+	}
+
+	_datasource, err := datasourceService.UpdateConfiguration(projectKey, organizationKey, uint(datasourceID), configuration, config.Database())
+
+	if err != nil {
+		log.Printf("Error updating datasource: %v", err)
+
+		if err := rollbackSourceConnection(_before, stored); err != nil {
+			log.Printf("Error restoring source %s: %v", _before.SourceID, err)
+
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update datasource, previous configuration not restored.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update datasource.",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.DatasourceUpdated,
+		TargetType: "datasource",
+		TargetID: datasourceIDString,
+		ProjectKey: projectKey,
+		Changes: auditDomain.BuildChanges(_before, _datasource),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"datasource": _datasource,
+		"test": test,
+	})
+}
+
+// Test the connection of a datasource with its current configuration
+func TestDatasource(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	projectKey := c.Param("key") // assumes route is like /projects/:key/datasources/:id/test
+	if projectKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	datasourceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid datasource id",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Archived projects are read-only and paused projects do not sync
+	if !requireSyncableProject(c, projectKey, organizationKey) {
+		return
+	}
+
+	_datasource, err := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+
+	if err != nil || _datasource == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"test": testConnection(_datasource.SourceID),
+	})
+}
+
+// requireSyncableProject answers 409 unless the project may reach the ETL
+func requireSyncableProject(c *gin.Context, projectKey string, organizationKey string) bool {
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		log.Printf("Error retrieving project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve project.",
+		})
+		return false
+	}
+
+	if err := _project.CheckSyncable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func testConnection(sourceId string) datasourceDomain.ConnectionTest {
+	start := time.Now()
+	err := etl.GetInstance().TestSourceConnection(sourceId)
+	latency := time.Since(start).Milliseconds()
+
+	if err != nil {
+		return datasourceDomain.ConnectionTest{
+			LatencyMs: latency,
+			Reason: datasourceDomain.ClassifyConnectionFailure(err),
+			Error: err.Error(),
+		}
+	}
+
+	return datasourceDomain.ConnectionTest{
+		Succeeded: true,
+		LatencyMs: latency,
+	}
+}
+
+// rollbackSourceConnection restores the configuration of an ETL source after a failed update
+func rollbackSourceConnection(_datasource *datasourceDomain.Datasource, configuration map[string]interface{}) error {
+	// Datasources created before configurations were stored cannot be restored
+	if len(configuration) == 0 {
+		return errors.New("no stored configuration")
+	}
+
+	configuration["sourceType"] = _datasource.SourceType
+
+	return etl.GetInstance().UpdateSourceConnection(_datasource.SourceID, configuration)
+}
+
 func DeleteDatasource(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	projectKey := c.Param("key") // assumes route is like /projects/:key
 	if projectKey == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -236,7 +558,7 @@ func DeleteDatasource(c *gin.Context) {
 	}
 
 	// Authorization
-	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "write")
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "write")
 
 	if err != nil || !allow {
 		c.JSON(http.StatusForbidden, gin.H{
@@ -245,8 +567,30 @@ func DeleteDatasource(c *gin.Context) {
 		return
 	}
 
+	// Retrieve project
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		log.Printf("Error retrieving project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve project.",
+		})
+		return
+	}
+
+	// Archived projects are read-only
+	if err := _project.CheckWritable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Snapshot before delete for the audit log
+	_before, _ := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+
 	// Delete datasource
-	err = datasourceService.SoftDeleteDatasource(uint(datasourceID), projectKey, config.Database())
+	err = datasourceService.SoftDeleteDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
 
 	if err != nil {
 		log.Printf("Error deleting datasource: %v", err)
@@ -256,6 +600,242 @@ func DeleteDatasource(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.DatasourceDeleted,
+		TargetType: "datasource",
+		TargetID: datasourceIDString,
+		ProjectKey: projectKey,
+		Changes: auditDomain.BuildChanges(_before, nil),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+	return
+}
+
+// List the deleted datasources of a project, which can be restored until they are purged
+func RetrieveDeletedDatasources(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	projectKey := c.Param("key") // assumes route is like /projects/:key
+	if projectKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve deleted datasources
+	_datasources, err := datasourceService.RetrieveDeletedDatasources(projectKey, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving deleted datasources: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"datasources": _datasources,
+	})
+	return
+}
+
+func RestoreDatasource(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	projectKey := c.Param("key") // assumes route is like /projects/:key
+	if projectKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	datasourceIDString := c.Param("id")
+	if datasourceIDString == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project id is required",
+		})
+		return
+	}
+
+	datasourceID, err := strconv.ParseUint(datasourceIDString, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid datasource id",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve project
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		log.Printf("Error retrieving project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve project.",
+		})
+		return
+	}
+
+	// Archived projects are read-only
+	if err := _project.CheckWritable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Restore datasource
+	_datasource, err := datasourceService.RestoreDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error restoring datasource: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _datasource == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.DatasourceRestored,
+		TargetType: "datasource",
+		TargetID: datasourceIDString,
+		ProjectKey: projectKey,
+		Changes: auditDomain.BuildChanges(nil, _datasource),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"datasource": _datasource,
+	})
+	return
+}
+
+// Permanently delete a deleted datasource and its ETL source. This cannot be undone, so it
+// requires project admin
+func PurgeDatasource(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	projectKey := c.Param("key") // assumes route is like /projects/:key
+	if projectKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	datasourceIDString := c.Param("id")
+	if datasourceIDString == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project id is required",
+		})
+		return
+	}
+
+	datasourceID, err := strconv.ParseUint(datasourceIDString, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid datasource id",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Project, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve project
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		log.Printf("Error retrieving project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve project.",
+		})
+		return
+	}
+
+	// Archived projects are read-only
+	if err := _project.CheckWritable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Purge datasource
+	_datasource, err := datasourceService.PurgeDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error purging datasource: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _datasource == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.DatasourcePurged,
+		TargetType: "datasource",
+		TargetID: datasourceIDString,
+		ProjectKey: projectKey,
+		Changes: auditDomain.BuildChanges(_datasource, nil),
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
 	})
@@ -263,6 +843,11 @@ func DeleteDatasource(c *gin.Context) {
 }
 
 func RetrieveSourceSchema(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	projectKey := c.Param("key") // assumes route is like /projects/:key
 	if projectKey == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -287,9 +872,24 @@ func RetrieveSourceSchema(c *gin.Context) {
 		return
 	}
 
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.Datasource, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Archived projects are read-only and paused projects do not sync
+	if !requireSyncableProject(c, projectKey, organizationKey) {
+		return
+	}
+
 	// Retrieve datasource
-	_datasource, err := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, config.Database())
-	if err != nil {
+	_datasource, err := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+	if err != nil || _datasource == nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid datasource id",
 		})
@@ -312,6 +912,11 @@ func RetrieveSourceSchema(c *gin.Context) {
 }
 
 func RetrieveDatasourceSchemaMapping(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	projectKey := c.Param("key") // assumes route is like /projects/:key
 	if projectKey == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -336,9 +941,19 @@ func RetrieveDatasourceSchemaMapping(c *gin.Context) {
 		return
 	}
 
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.SchemaMapping, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
 	// Retrieve datasource
-	_datasource, err := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, config.Database())
-	if err != nil {
+	_datasource, err := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+	if err != nil || _datasource == nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid datasource id",
 		})
@@ -353,6 +968,11 @@ func RetrieveDatasourceSchemaMapping(c *gin.Context) {
 }
 
 func UpdateDatasourceSchemaMapping(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		SchemaMapping map[string]interface{} `json:"schemaMapping" binding:"required"`
 	}
@@ -389,7 +1009,44 @@ func UpdateDatasourceSchemaMapping(c *gin.Context) {
 		return
 	}
 
-	_datasource, err := datasourceService.UpdateSchemaMapping(projectKey, uint(datasourceID), req.SchemaMapping, config.Database())
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), projectKey, authorizationDomain.SchemaMapping, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve project
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		log.Printf("Error retrieving project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve project.",
+		})
+		return
+	}
+
+	// Archived projects are read-only
+	if err := _project.CheckWritable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Snapshot before update for the audit log
+	_before, _ := datasourceService.RetrieveDatasource(uint(datasourceID), projectKey, organizationKey, config.Database())
+
+	var mappingBefore interface{}
+	if _before != nil {
+		mappingBefore = _before.SchemaMapping
+	}
+
+	_datasource, err := datasourceService.UpdateSchemaMapping(projectKey, organizationKey, uint(datasourceID), req.SchemaMapping, config.Database())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -397,6 +1054,17 @@ func UpdateDatasourceSchemaMapping(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SchemaMappingUpdated,
+		TargetType: "datasource",
+		TargetID: datasourceIDString,
+		ProjectKey: projectKey,
+		Changes: auditDomain.BuildChanges(
+			map[string]interface{}{"SchemaMapping": mappingBefore},
+			map[string]interface{}{"SchemaMapping": _datasource.SchemaMapping},
+		),
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
 		"schemaMapping": _datasource.SchemaMapping,
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	invitationService "github.com/darksuei/suei-intelligence/internal/application/invitation"
+	"github.com/darksuei/suei-intelligence/internal/application/mfa"
+	"github.com/darksuei/suei-intelligence/internal/application/project"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	invitationDomain "github.com/darksuei/suei-intelligence/internal/domain/invitation"
+	projectDomain "github.com/darksuei/suei-intelligence/internal/domain/project"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+func NewInvitation(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Parse the request body
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+		Role string `json:"role" binding:"required"`
+		ProjectRoles map[string]string `json:"projectRoles"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	role, err := accountDomain.NewAccountRole(req.Role)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Invalid role.",
+		})
+		return
+	}
+
+	projectRoles := map[string]projectDomain.ProjectRole{}
+	for projectKey, value := range req.ProjectRoles {
+		projectRole, err := projectDomain.NewProjectRole(value)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "Invalid project role.",
+			})
+			return
+		}
+
+		// Project authorization holds for any key through org role inheritance, so only
+		// projects of the organization are accepted
+		_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+		if err != nil || _project == nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "Invalid project: " + projectKey + ".",
+			})
+			return
+		}
+
+		projectRoles[projectKey] = projectRole
+	}
+
+	// Authorization - inviting admins requires account admin, project roles require project admin
+	roles := utils.GetUserRolesFromContext(c)
+
+	action := "write"
+	if role == accountDomain.SuperAdmin || role == accountDomain.Admin {
+		action = "admin"
+	}
+
+	allow, err := authorizationService.EnforceRoles(roles, "org", authorizationDomain.Account, action)
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	for projectKey := range projectRoles {
+		allow, err := authorizationService.EnforceProjectRoles(roles, projectKey, authorizationDomain.Project, "admin")
+
+		if err != nil || !allow {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "forbidden",
+			})
+			return
+		}
+	}
+
+	invitedByEmail, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || invitedByEmail == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	// Create and deliver invitation
+	_invitation, err := invitationService.NewInvitation(req.Email, role, projectRoles, *invitedByEmail, organizationKey, config.Database(), config.Notifier())
+
+	if err != nil {
+		log.Printf("Error creating invitation: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.InvitationCreated,
+		TargetType: "invitation",
+		TargetID: _invitation.Email,
+		Changes: auditDomain.BuildChanges(nil, _invitation),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"invitation": invitationResponse(_invitation),
+	})
+}
+
+func RetrieveInvitations(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve invitations
+	_invitations, err := invitationService.RetrieveInvitations(organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving invitations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	invitations := make([]gin.H, 0, len(*_invitations))
+	for i := range *_invitations {
+		invitations = append(invitations, invitationResponse(&(*_invitations)[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"invitations": invitations,
+	})
+}
+
+func RevokeInvitation(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	invitationID, err := strconv.ParseUint(c.Param("id"), 10, 64) // assumes route is like /invitations/:id
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid invitation id",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Revoke invitation
+	_invitation, err := invitationService.RevokeInvitation(uint(invitationID), organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.InvitationRevoked,
+		TargetType: "invitation",
+		TargetID: _invitation.Email,
+		Changes: auditDomain.BuildChanges(_invitation, nil),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// RetrieveInvitationByToken lets an invitee preview an invitation before accepting it
+func RetrieveInvitationByToken(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameter: token",
+		})
+		return
+	}
+
+	_invitation, err := invitationService.RetrieveInvitationByToken(token, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"invitation": invitationResponse(_invitation),
+	})
+}
+
+// AcceptInvitation creates the invitee's account. The response carries the TOTP URI
+// the invitee enrolls before confirming MFA through /mfa/confirm
+func AcceptInvitation(c *gin.Context) {
+	// Parse the request body
+	var req struct {
+		Token string `json:"token" binding:"required"`
+		Name string `json:"name" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	// Accept invitation
+	_account, _invitation, err := invitationService.AcceptInvitation(req.Token, req.Name, req.Password, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Retrieve TOTP URI
+	uri, err := mfa.RetrieveTotpURI(_account.Email, _account.MFASecret)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve TOTP URI",
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(_account.Email),
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.InvitationAccepted,
+		TargetType: "invitation",
+		TargetID: _invitation.Email,
+		Changes: auditDomain.BuildChanges(nil, accountDomain.ToAccountDTO(_account)),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"account": accountDomain.ToAccountDTO(_account),
+		"uri": uri,
+	})
+}
+
+func invitationResponse(i *invitationDomain.Invitation) gin.H {
+	return gin.H{
+		"ID": i.ID,
+		"Email": i.Email,
+		"Role": i.Role,
+		"ProjectRoles": i.ProjectRoles,
+		"InvitedBy": i.InvitedBy,
+		"Status": i.Status(time.Now()),
+		"ExpiresAt": i.ExpiresAt,
+		"AcceptedAt": i.AcceptedAt,
+		"CreatedAt": i.CreatedAt,
+	}
+}
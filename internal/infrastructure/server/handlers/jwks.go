@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	signingkeyService "github.com/darksuei/suei-intelligence/internal/application/signingkey"
+)
+
+// Publish the public keys access tokens are signed with, so that other services can verify
+// them. Verifiers should refetch the key set when a token names an unknown key ID
+func RetrieveJWKS(c *gin.Context) {
+	jwks, err := signingkeyService.RetrieveJWKS()
+
+	if err != nil {
+		log.Printf("Error retrieving jwks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve signing keys",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
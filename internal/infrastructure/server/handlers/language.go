@@ -1,14 +1,17 @@
 package handlers
 
 import (
-	"errors"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
 	"github.com/darksuei/suei-intelligence/internal/application/metadata"
+	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
 )
 
@@ -26,6 +29,21 @@ func SupportedLanguages(c *gin.Context) {
 }
 
 func SetLanguagePreference(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
 	// Parse the request body
 	var req struct {
 		Code string `json:"code" binding:"required"`
@@ -55,8 +73,10 @@ func SetLanguagePreference(c *gin.Context) {
 		return
 	}
 
-	// Update metadata with the language
-	if err := metadata.SetLanguage(req.Code, config.Database()); err != nil {
+	_before := organizationLanguage(organizationKey)
+
+	// Update the organization with the language
+	if err := organizationService.SetLanguage(organizationKey, req.Code, config.Database()); err != nil {
 		log.Printf("Error updating language: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update language",
@@ -64,6 +84,13 @@ func SetLanguagePreference(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.LanguageUpdated,
+		TargetType: "organization",
+		TargetID: organizationKey,
+		Changes: auditDomain.BuildChanges(map[string]interface{}{"language": _before}, map[string]interface{}{"language": req.Code}),
+	})
+
 	c.JSON(http.StatusAccepted, gin.H{
 		"message": "Language updated successfully",
 		"code":    req.Code,
@@ -71,28 +98,33 @@ func SetLanguagePreference(c *gin.Context) {
 }
 
 func RetrieveLanguagePreference(c *gin.Context) {
-	language, err := metadata.RetrieveLanguage(config.Database())
-
-	if err != nil || *language == "" {
-		// Return the default language
-		for _, lang := range Languages {
-			if lang["default"] == "true" {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "success",
-					"language": lang["code"],
-				})
-				return
-			}
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"error": errors.New("Failed to get language preference"),
-		})
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
-		"language": language,
+		"language": organizationLanguage(organizationKey),
 	})
 	return
-}
\ No newline at end of file
+}
+
+// organizationLanguage resolves the language of an organization, falling back to the
+// language chosen at setup and then to the default language
+func organizationLanguage(organizationKey string) string {
+	if _organization, err := organizationService.RetrieveOrganization(organizationKey, config.Database()); err == nil && _organization != nil && _organization.Language != "" {
+		return _organization.Language
+	}
+
+	if language, err := metadata.RetrieveLanguage(config.Database()); err == nil && language != nil && *language != "" {
+		return *language
+	}
+
+	for _, lang := range Languages {
+		if lang["default"] == "true" {
+			return lang["code"]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	lockoutService "github.com/darksuei/suei-intelligence/internal/application/lockout"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	lockoutDomain "github.com/darksuei/suei-intelligence/internal/domain/lockout"
+)
+
+// enforceLockout rejects the request when the account or the client IP is locked out
+func enforceLockout(c *gin.Context, email string) bool {
+	_lockout := lockoutService.CheckLockout(email, clientIP(c))
+
+	if _lockout == nil {
+		return true
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(email),
+		ActorEmail: email,
+		Action: auditDomain.LockoutRejected,
+		Outcome: auditDomain.Failure,
+		TargetType: string(_lockout.Scope),
+		TargetID: lockoutSubject(c, email, _lockout.Scope),
+	})
+
+	retryAfter := int(math.Ceil(time.Until(_lockout.Until).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": "Too many failed attempts. Try again later.",
+		"retry_after": retryAfter,
+	})
+	return false
+}
+
+// recordFailedAttempt counts a failed attempt and audits any lockout it causes
+func recordFailedAttempt(c *gin.Context, email string, kind lockoutDomain.AttemptKind) {
+	lockouts := lockoutService.RecordFailedAttempt(email, clientIP(c), kind, config.Lockout())
+
+	for _, _lockout := range lockouts {
+		recordAudit(c, auditDomain.AuditEvent{
+			OrganizationKey: organizationKeyOf(email),
+			ActorEmail: email,
+			Action: auditDomain.LockedOut,
+			Outcome: auditDomain.Failure,
+			TargetType: string(_lockout.Scope),
+			TargetID: lockoutSubject(c, email, _lockout.Scope),
+			Changes: map[string]auditDomain.Change{
+				"LockedUntil": {Before: nil, After: _lockout.Until},
+			},
+		})
+	}
+}
+
+func lockoutSubject(c *gin.Context, email string, scope lockoutDomain.LockoutScope) string {
+	if scope == lockoutDomain.ScopeIP {
+		return clientIP(c)
+	}
+	return email
+}
+
+// clientIP is the address failed attempts are counted against. Forwarded addresses are
+// only used behind a trusted proxy, anyone else could rotate them to escape a lockout
+func clientIP(c *gin.Context) string {
+	if len(config.Common().TrustedProxies) == 0 {
+		return c.RemoteIP()
+	}
+	return c.ClientIP()
+}
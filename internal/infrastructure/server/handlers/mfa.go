@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	lockoutService "github.com/darksuei/suei-intelligence/internal/application/lockout"
 	"github.com/darksuei/suei-intelligence/internal/application/mfa"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	lockoutDomain "github.com/darksuei/suei-intelligence/internal/domain/lockout"
+	mfaDomain "github.com/darksuei/suei-intelligence/internal/domain/mfa"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -27,10 +32,16 @@ func RetrieveTotpURI(c *gin.Context) {
 		return
 	}
 
+	if !enforceLockout(c, req.Email) {
+		return
+	}
+
 	// Retrieve account
 	_account, err := accountService.RetrieveAccountWithPassword(req.Email, req.Password, config.Database())
 
 	if err != nil {
+		recordFailedAttempt(c, req.Email, lockoutDomain.AttemptPassword)
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
@@ -44,6 +55,14 @@ func RetrieveTotpURI(c *gin.Context) {
 		return
 	}
 
+	// Enrolled accounts rotate their secret through re-enrollment, which requires a valid code
+	if _account.MFAEnabled {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "MFA is already enabled. Please re-enroll to rotate the secret.",
+		})
+		return
+	}
+
 	// Retrieve TOTP URI
 	uri, err := mfa.RetrieveTotpURI(req.Email, _account.MFASecret)
 
@@ -78,10 +97,16 @@ func ConfirmMFA(c *gin.Context) {
 		return
 	}
 
+	if !enforceLockout(c, req.Email) {
+		return
+	}
+
 	// Retrieve account
 	_account, err := accountService.RetrieveAccountWithPassword(req.Email, req.Password, config.Database())
 
 	if err != nil {
+		recordFailedAttempt(c, req.Email, lockoutDomain.AttemptPassword)
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
@@ -107,6 +132,18 @@ func ConfirmMFA(c *gin.Context) {
 	isCodeValid := mfa.VerifyTOTP(_account.MFASecret, code, time.Now())
 
 	if !isCodeValid {
+		recordAudit(c, auditDomain.AuditEvent{
+			OrganizationKey: organizationKeyOf(_account.Email),
+			ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+			ActorEmail: _account.Email,
+			Action: auditDomain.MFAEnabled,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: _account.Email,
+		})
+
+		recordFailedAttempt(c, _account.Email, lockoutDomain.AttemptMFA)
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Invalid TOTP code.",
 		})
@@ -115,6 +152,366 @@ func ConfirmMFA(c *gin.Context) {
 
 	accountService.EnableTOTP(req.Email, config.Database())
 
+	// Recovery codes are issued on first enrollment only, regenerating them requires a valid code
+	var recoveryCodes []string
+
+	if !_account.MFAEnabled {
+		recoveryCodes, err = accountService.GenerateRecoveryCodes(_account.ID, config.Database())
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(_account.Email),
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.MFAEnabled,
+		TargetType: "account",
+		TargetID: _account.Email,
+		Changes: map[string]auditDomain.Change{
+			"MFAEnabled": {Before: _account.MFAEnabled, After: true},
+		},
+	})
+
+	if recoveryCodes == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "success",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"recovery_codes": recoveryCodes,
+	})
+	return
+}
+
+func RetrieveRecoveryCodeCount(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || email == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	remaining, err := accountService.RetrieveRecoveryCodeCount(*email, organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"message": "success",
+		"recovery_codes_remaining": remaining,
+	}
+
+	if remaining <= mfaDomain.RecoveryCodeWarningThreshold {
+		response["warning"] = fmt.Sprintf("Only %d recovery codes remain. Please regenerate them.", remaining)
+	}
+
+	c.JSON(http.StatusOK, response)
+	return
+}
+
+// Regenerate recovery codes, invalidating any unused ones
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || email == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	if !enforceLockout(c, *email) {
+		return
+	}
+
+	codeUint64, err := strconv.ParseUint(req.Code, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code format"})
+		return
+	}
+
+	recoveryCodes, err := accountService.RegenerateRecoveryCodes(*email, organizationKey, req.Password, uint32(codeUint64), config.Database())
+
+	if err != nil {
+		recordFailedAttempt(c, *email, lockoutDomain.AttemptMFA)
+
+		recordAudit(c, auditDomain.AuditEvent{
+			Action: auditDomain.RecoveryCodesRegenerated,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: *email,
+		})
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	lockoutService.ClearFailedAttempts(*email)
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RecoveryCodesRegenerated,
+		TargetType: "account",
+		TargetID: *email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"recovery_codes": recoveryCodes,
+	})
+	return
+}
+
+// Start MFA re-enrollment with a new secret, confirmed with ConfirmMFAEnrollment
+func StartMFAEnrollment(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+		Code string `json:"code,omitempty"` // <- required when MFA is enabled
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || email == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	if !enforceLockout(c, *email) {
+		return
+	}
+
+	var code *uint32
+
+	if req.Code != "" {
+		codeUint64, err := strconv.ParseUint(req.Code, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code format"})
+			return
+		}
+
+		_code := uint32(codeUint64)
+		code = &_code
+	}
+
+	uri, err := accountService.StartMFAEnrollment(*email, organizationKey, req.Password, code, config.Database())
+
+	if err != nil {
+		recordFailedAttempt(c, *email, lockoutDomain.AttemptMFA)
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"uri": uri,
+		"expires_in": int(mfaDomain.EnrollmentTTL.Seconds()),
+	})
+	return
+}
+
+// Confirm MFA re-enrollment, switching to the new secret
+func ConfirmMFAEnrollment(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || email == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	if !enforceLockout(c, *email) {
+		return
+	}
+
+	codeUint64, err := strconv.ParseUint(req.Code, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code format"})
+		return
+	}
+
+	recoveryCodes, err := accountService.ConfirmMFAEnrollment(*email, organizationKey, uint32(codeUint64), config.Database())
+
+	if err != nil {
+		recordFailedAttempt(c, *email, lockoutDomain.AttemptMFA)
+
+		recordAudit(c, auditDomain.AuditEvent{
+			Action: auditDomain.MFAReEnrolled,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: *email,
+		})
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	lockoutService.ClearFailedAttempts(*email)
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.MFAReEnrolled,
+		TargetType: "account",
+		TargetID: *email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"recovery_codes": recoveryCodes,
+	})
+	return
+}
+
+// Disable MFA for the current account
+func DisableMFA(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	if config.Common().EnforceMfa {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "MFA is enforced and cannot be disabled.",
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || email == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	if !enforceLockout(c, *email) {
+		return
+	}
+
+	codeUint64, err := strconv.ParseUint(req.Code, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code format"})
+		return
+	}
+
+	_, err = accountService.DisableMFA(*email, organizationKey, req.Password, uint32(codeUint64), config.Database())
+
+	if err != nil {
+		recordFailedAttempt(c, *email, lockoutDomain.AttemptMFA)
+
+		recordAudit(c, auditDomain.AuditEvent{
+			Action: auditDomain.MFADisabled,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: *email,
+		})
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	lockoutService.ClearFailedAttempts(*email)
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.MFADisabled,
+		TargetType: "account",
+		TargetID: *email,
+		Changes: map[string]auditDomain.Change{
+			"MFAEnabled": {Before: true, After: false},
+		},
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
 	})
@@ -6,16 +6,28 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
 	organizationService "github.com/darksuei/suei-intelligence/internal/application/organization"
+	setupService "github.com/darksuei/suei-intelligence/internal/application/setup"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
 )
 
+// NewOrganization creates a further organization together with its first SUPERADMIN account.
+// Organizations are tenants of the instance, so only instance operators create them
 func NewOrganization(c *gin.Context) {
 	// Parse the request body
 	var req struct {
 		Name string `json:"name" binding:"required"`
 		Scope string `json:"scope" binding:"required"`
+		Admin struct {
+			Name string `json:"name" binding:"required"`
+			Email string `json:"email" binding:"required,email"`
+			Password string `json:"password" binding:"required"`
+		} `json:"admin" binding:"required"`
 	}
 
 	if err := c.BindJSON(&req); err != nil {
@@ -26,25 +38,51 @@ func NewOrganization(c *gin.Context) {
 		return
 	}
 
-	// Create organization
-	_organization, err := organizationService.NewOrganization(req.Name, "default", req.Scope, config.Database())
+	// Authorization
+	if !requireInstanceOperator(c) {
+		return
+	}
+
+	_organization, _account, err := setupService.NewOrganization(req.Name, req.Scope, req.Admin.Name, req.Admin.Email, req.Admin.Password, config.Database())
 
 	if err != nil {
 		log.Printf("Error creating organization: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: _organization.Key,
+		Action: auditDomain.OrganizationCreated,
+		TargetType: "organization",
+		TargetID: _organization.Key,
+		Changes: auditDomain.BuildChanges(nil, _organization),
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "success",
 		"organization": _organization,
-	  })
+		"account": accountDomain.ToAccountDTO(_account),
+	})
 }
 
 func RetrieveOrganization(c *gin.Context) {
-	key := "default" // Default organization key
+	key, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
 
 	// Retrieve organization
 	_organization, err := organizationService.RetrieveOrganization(key, config.Database())
@@ -86,8 +124,26 @@ func UpdateOrganization (c *gin.Context) {
 		return
 	}
 
+	key, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Snapshot before update for the audit log
+	_before, _ := organizationService.RetrieveOrganization(key, config.Database())
+
 	// Update organization
-	_organization, err := organizationService.UpdateOrganization(&req.Name, "default", &req.Scope, config.Database())
+	_organization, err := organizationService.UpdateOrganization(&req.Name, key, &req.Scope, config.Database())
 
 	if err != nil {
 		log.Printf("Error updating organization: %v", err)
@@ -97,9 +153,30 @@ func UpdateOrganization (c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.OrganizationUpdated,
+		TargetType: "organization",
+		TargetID: _organization.Key,
+		Changes: auditDomain.BuildChanges(_before, _organization),
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "success",
 		"organization": _organization,
 	})
 	return
 }
+
+// requireOrganization resolves the caller's organization key, aborting the request when it is missing
+func requireOrganization(c *gin.Context) (string, bool) {
+	organizationKey, err := utils.GetOrganizationFromContext(c)
+
+	if err != nil || organizationKey == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Failed to get organization",
+		})
+		return "", false
+	}
+
+	return *organizationKey, true
+}
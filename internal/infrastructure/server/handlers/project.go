@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"sort"
 
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
 	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
 	"github.com/darksuei/suei-intelligence/internal/application/project"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
 	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
 	projectDomain "github.com/darksuei/suei-intelligence/internal/domain/project"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
@@ -14,6 +19,11 @@ import (
 )
 
 func NewProject(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	// Parse the request body
 	var req struct {
 		Name string `json:"name" binding:"required"`
@@ -50,7 +60,7 @@ func NewProject(c *gin.Context) {
 	}
 
 	// Create project
-	_project, err := project.NewProject(req.Name, req.Key, projectDomain.ProjectStage(req.Stage), req.BusinessDomain, *createdByEmail, config.Database())
+	_project, err := project.NewProject(req.Name, req.Key, projectDomain.ProjectStage(req.Stage), req.BusinessDomain, *createdByEmail, organizationKey, config.Database())
 
 	if err != nil {
 		log.Printf("Error creating project: %v", err)
@@ -60,6 +70,14 @@ func NewProject(c *gin.Context) {
 		return
 	}
 
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.ProjectCreated,
+		TargetType: "project",
+		TargetID: _project.Key,
+		ProjectKey: _project.Key,
+		Changes: auditDomain.BuildChanges(nil, _project),
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "success",
 		"project": _project,
@@ -67,6 +85,11 @@ func NewProject(c *gin.Context) {
 }
 
 func RetrieveProject(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	key := c.Param("key") // assumes route is like /projects/:key
 	if key == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -76,7 +99,7 @@ func RetrieveProject(c *gin.Context) {
 	}
 
 	// Authorization
-	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "read")
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, "read")
 
 	if err != nil || !allow {
 		c.JSON(http.StatusForbidden, gin.H{
@@ -86,7 +109,7 @@ func RetrieveProject(c *gin.Context) {
 	}
 
 	// Retrieve project
-	_project, err := project.RetrieveProject(key, config.Database())
+	_project, err := project.RetrieveProject(key, organizationKey, config.Database())
 
 	if err != nil {
 		log.Printf("Error retrieving project: %v", err)
@@ -110,17 +133,13 @@ func RetrieveProject(c *gin.Context) {
 }
 
 func RetrieveProjects(c *gin.Context) {
-	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "read")
-
-	if err != nil || !allow {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden",
-		})
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
 		return
 	}
 
 	// Retrieve projects
-	_projects, err := project.RetrieveProjects(config.Database())
+	_projects, err := project.RetrieveProjects(organizationKey, config.Database())
 
 	if err != nil {
 		log.Printf("Error retrieving projects: %v", err)
@@ -130,13 +149,31 @@ func RetrieveProjects(c *gin.Context) {
 		return
 	}
 
+	// Authorization - only list projects the caller can read
+	roles := utils.GetUserRolesFromContext(c)
+	readable := []projectDomain.Project{}
+
+	if _projects != nil {
+		for _, _project := range *_projects {
+			allow, err := authorizationService.EnforceProjectRoles(roles, _project.Key, authorizationDomain.Project, "read")
+			if err == nil && allow {
+				readable = append(readable, _project)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
-		"projects": _projects,
+		"projects": readable,
 	})
 }
 
 func UpdateProject(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
 	key := c.Param("key") // assumes route is like /projects/:key
 	if key == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -145,6 +182,16 @@ func UpdateProject(c *gin.Context) {
 		return
 	}
 
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, "write")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
     var req struct {
 		Name           *string                         `json:"name,omitempty"`
 		Key            *string                         `json:"key,omitempty"`
@@ -158,18 +205,367 @@ func UpdateProject(c *gin.Context) {
         return
     }
 
+    // Snapshot before update for the audit log
+    _before, _ := project.RetrieveProject(key, organizationKey, config.Database())
+
     updatedProject, err := project.UpdateProject(
         key,
+        organizationKey,
         req.Name,
 		req.Key,
         req.Stage,
         req.BusinessDomain,
         config.Database(),
     )
+    if errors.Is(err, projectDomain.ErrProjectArchived) {
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        return
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
+    recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.ProjectUpdated,
+		TargetType: "project",
+		TargetID: updatedProject.Key,
+		ProjectKey: updatedProject.Key,
+		Changes: auditDomain.BuildChanges(_before, updatedProject),
+	})
+
     c.JSON(http.StatusOK, updatedProject)
-}
\ No newline at end of file
+}
+
+func PauseProject(c *gin.Context) {
+	transitionProjectStatus(c, projectDomain.Pause, "write")
+}
+
+func ResumeProject(c *gin.Context) {
+	transitionProjectStatus(c, projectDomain.Resume, "write")
+}
+
+func ArchiveProject(c *gin.Context) {
+	transitionProjectStatus(c, projectDomain.Archive, "admin")
+}
+
+func RestoreProject(c *gin.Context) {
+	transitionProjectStatus(c, projectDomain.Restore, "admin")
+}
+
+func transitionProjectStatus(c *gin.Context, action projectDomain.ProjectStatusAction, authorizationAction string) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /project/:key/<action>
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, authorizationAction)
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve project
+	_project, err := project.RetrieveProject(key, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving project: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _project == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	previousStatus := _project.Status
+
+	// Apply status transition
+	_project, err = project.TransitionProjectStatus(key, organizationKey, action, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.ProjectStatusChanged,
+		TargetType: "project",
+		TargetID: key,
+		ProjectKey: key,
+		Changes: map[string]auditDomain.Change{
+			"Status": {Before: previousStatus, After: _project.Status},
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"project": _project,
+	})
+}
+
+func RetrieveProjectMembers(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /project/:key/members
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve members
+	_accounts, err := accountService.RetrieveProjectMembers(key, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving project members: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	entryKey := accountDomain.BuildRoleEntryKey(key, authorizationDomain.AuthorizationDomainProject)
+
+	members := make([]gin.H, 0, len(*_accounts))
+	for _, _account := range *_accounts {
+		members = append(members, gin.H{
+			"account": accountDomain.ToAccountDTO(&_account),
+			"roles": projectRolesOf(&_account, entryKey),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"members": members,
+	})
+}
+
+func GrantProjectRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /project/:key/members
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	var req struct {
+		Email string `json:"email" binding:"required"`
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	role, err := projectDomain.NewProjectRole(req.Role)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Invalid role.",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve project
+	_project, err := project.RetrieveProject(key, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	// Archived projects are read-only
+	if err := _project.CheckWritable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	entryKey := accountDomain.BuildRoleEntryKey(key, authorizationDomain.AuthorizationDomainProject)
+
+	// Snapshot before grant for the audit log
+	var rolesBefore []string
+	if _before, _ := accountService.RetrieveOrganizationAccount(req.Email, organizationKey, config.Database()); _before != nil {
+		rolesBefore = projectRolesOf(_before, entryKey)
+	}
+
+	// Grant role
+	_account, err := accountService.GrantProjectRole(req.Email, key, organizationKey, role, config.Database())
+
+	if err != nil {
+		log.Printf("Error granting project role: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.ProjectRoleGranted,
+		TargetType: "account",
+		TargetID: _account.Email,
+		ProjectKey: key,
+		Changes: map[string]auditDomain.Change{
+			"Roles": {Before: rolesBefore, After: projectRolesOf(_account, entryKey)},
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"account": accountDomain.ToAccountDTO(_account),
+	})
+}
+
+func RevokeProjectRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /project/:key/members
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Project key is required",
+		})
+		return
+	}
+
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameter: email",
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceProjectRoles(utils.GetUserRolesFromContext(c), key, authorizationDomain.Project, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve project
+	_project, err := project.RetrieveProject(key, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	// Archived projects are read-only
+	if err := _project.CheckWritable(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	entryKey := accountDomain.BuildRoleEntryKey(key, authorizationDomain.AuthorizationDomainProject)
+
+	// Snapshot before revoke for the audit log
+	var rolesBefore []string
+	if _before, _ := accountService.RetrieveOrganizationAccount(email, organizationKey, config.Database()); _before != nil {
+		rolesBefore = projectRolesOf(_before, entryKey)
+	}
+
+	// Revoke role
+	_account, err := accountService.RevokeProjectRole(email, key, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error revoking project role: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.ProjectRoleRevoked,
+		TargetType: "account",
+		TargetID: _account.Email,
+		ProjectKey: key,
+		Changes: map[string]auditDomain.Change{
+			"Roles": {Before: rolesBefore, After: projectRolesOf(_account, entryKey)},
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"account": accountDomain.ToAccountDTO(_account),
+	})
+}
+
+// projectRolesOf lists the internal role keys an account holds for a project entry
+func projectRolesOf(_account *accountDomain.Account, entryKey string) []string {
+	roles := []string{}
+	for k, v := range _account.InternalRoles {
+		if accountDomain.HasRoleEntryFor(k, entryKey) {
+			roles = append(roles, v)
+		}
+	}
+
+	sort.Strings(roles)
+	return roles
+}
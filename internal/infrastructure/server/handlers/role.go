@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	"github.com/darksuei/suei-intelligence/internal/application/project"
+	roleService "github.com/darksuei/suei-intelligence/internal/application/role"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+func NewRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Parse the request body
+	var req struct {
+		Name string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Permissions []authorizationDomain.Permission `json:"permissions" binding:"required,dive"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Create role
+	_role, err := roleService.NewRole(req.Name, req.Description, req.Permissions, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error creating role: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RoleCreated,
+		TargetType: "role",
+		TargetID: _role.Key,
+		Changes: auditDomain.BuildChanges(nil, _role),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"role": _role,
+	})
+}
+
+func RetrieveRoles(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve roles
+	_roles, err := roleService.RetrieveRoles(organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"roles": _roles,
+	})
+}
+
+func RetrieveRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /roles/:key
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Retrieve role
+	_role, err := roleService.RetrieveRole(key, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _role == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"role": _role,
+	})
+}
+
+func UpdateRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /roles/:key
+
+	var req struct {
+		Name *string `json:"name,omitempty"`
+		Description *string `json:"description,omitempty"`
+		Permissions *[]authorizationDomain.Permission `json:"permissions,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Snapshot before update for the audit log
+	_before, _ := roleService.RetrieveRole(key, organizationKey, config.Database())
+
+	// Update role
+	_role, err := roleService.UpdateRole(key, organizationKey, req.Name, req.Description, req.Permissions, config.Database())
+
+	if err != nil {
+		log.Printf("Error updating role: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RoleUpdated,
+		TargetType: "role",
+		TargetID: key,
+		Changes: auditDomain.BuildChanges(_before, _role),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"role": _role,
+	})
+}
+
+func DeleteRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /roles/:key
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	// Snapshot before delete for the audit log
+	_before, _ := roleService.RetrieveRole(key, organizationKey, config.Database())
+
+	// Delete role
+	if err := roleService.DeleteRole(key, organizationKey, config.Database()); err != nil {
+		log.Printf("Error deleting role: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RoleDeleted,
+		TargetType: "role",
+		TargetID: key,
+		Changes: auditDomain.BuildChanges(_before, nil),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// AssignRole assigns a custom role to an account on the org, or on a project when projectKey is set
+func AssignRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /roles/:key/assignments
+
+	var req struct {
+		Email string `json:"email" binding:"required"`
+		ProjectKey string `json:"projectKey"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	entityKey, domain, ok := authorizeRoleAssignment(c, organizationKey, req.ProjectKey)
+	if !ok {
+		return
+	}
+
+	// Assign role
+	_account, err := roleService.AssignRole(key, req.Email, entityKey, domain, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error assigning role: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RoleAssigned,
+		TargetType: "account",
+		TargetID: _account.Email,
+		ProjectKey: req.ProjectKey,
+		Changes: map[string]auditDomain.Change{
+			"Role": {Before: nil, After: key},
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"account": accountDomain.ToAccountDTO(_account),
+	})
+}
+
+// UnassignRole removes a custom role from an account on the org, or on a project when projectKey is set
+func UnassignRole(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	key := c.Param("key") // assumes route is like /roles/:key/assignments
+
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing required query parameter: email",
+		})
+		return
+	}
+
+	entityKey, domain, ok := authorizeRoleAssignment(c, organizationKey, c.Query("projectKey"))
+	if !ok {
+		return
+	}
+
+	// Unassign role
+	_account, err := roleService.UnassignRole(key, email, entityKey, domain, organizationKey, config.Database())
+
+	if err != nil {
+		log.Printf("Error unassigning role: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.RoleUnassigned,
+		TargetType: "account",
+		TargetID: _account.Email,
+		ProjectKey: c.Query("projectKey"),
+		Changes: map[string]auditDomain.Change{
+			"Role": {Before: key, After: nil},
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"account": accountDomain.ToAccountDTO(_account),
+	})
+}
+
+// authorizeRoleAssignment resolves the entity a role assignment targets and checks the caller may manage it.
+// Project assignments require project admin, org assignments require account admin
+func authorizeRoleAssignment(c *gin.Context, organizationKey string, projectKey string) (string, authorizationDomain.AuthorizationDomain, bool) {
+	roles := utils.GetUserRolesFromContext(c)
+
+	if projectKey == "" {
+		allow, err := authorizationService.EnforceRoles(roles, "org", authorizationDomain.Account, "admin")
+
+		if err != nil || !allow {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "forbidden",
+			})
+			return "", "", false
+		}
+
+		return organizationKey, authorizationDomain.AuthorizationDomainOrg, true
+	}
+
+	allow, err := authorizationService.EnforceProjectRoles(roles, projectKey, authorizationDomain.Project, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return "", "", false
+	}
+
+	_project, err := project.RetrieveProject(projectKey, organizationKey, config.Database())
+
+	if err != nil || _project == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return "", "", false
+	}
+
+	return projectKey, authorizationDomain.AuthorizationDomainProject, true
+}
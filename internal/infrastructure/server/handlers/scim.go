@@ -0,0 +1,663 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	scimService "github.com/darksuei/suei-intelligence/internal/application/scim"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	projectDomain "github.com/darksuei/suei-intelligence/internal/domain/project"
+	scimDomain "github.com/darksuei/suei-intelligence/internal/domain/scim"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+// SCIM tokens and group roles, managed by organization admins
+
+func RetrieveSCIMTokens(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	_tokens, err := scimService.RetrieveTokens(organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"tokens": _tokens,
+	})
+}
+
+// Create a bearer token for the organization's directory. Whoever holds it can provision and
+// deprovision accounts, so this requires organization admin
+func NewSCIMToken(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	_token, token, err := scimService.NewToken(req.Name, organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SCIMTokenCreated,
+		TargetType: "scim_token",
+		TargetID: strconv.FormatUint(uint64(_token.ID), 10),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"token": token, // <- only shown once
+		"scimToken": _token,
+	})
+}
+
+func DeleteSCIMToken(c *gin.Context) {
+	tokenId, err := strconv.ParseUint(c.Param("id"), 10, 64) // assumes route is like /organization/scim/tokens/:id
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid token id",
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	if err := scimService.DeleteToken(uint(tokenId), organizationKey, config.Database()); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SCIMTokenRevoked,
+		TargetType: "scim_token",
+		TargetID: c.Param("id"),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+func RetrieveDirectoryGroups(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Account, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	groups, err := scimService.RetrieveDirectoryGroups(organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"groups": groups,
+	})
+}
+
+// Map a directory group to the organization and project roles its members are granted
+func UpdateDirectoryGroupRoles(c *gin.Context) {
+	groupId, err := strconv.ParseUint(c.Param("id"), 10, 64) // assumes route is like /organization/scim/groups/:id/roles
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group id",
+		})
+		return
+	}
+
+	var req struct {
+		OrganizationRole string `json:"organizationRole,omitempty"` // <- ADMIN or GUEST, none when empty
+		ProjectRoles map[string]string `json:"projectRoles,omitempty"` // project key -> project role
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	projectRoles := map[string]projectDomain.ProjectRole{}
+	for projectKey, role := range req.ProjectRoles {
+		projectRoles[projectKey] = projectDomain.ProjectRole(role)
+	}
+
+	group, err := scimService.UpdateGroupRoles(uint(groupId), accountDomain.AccountRole(req.OrganizationRole), projectRoles, organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.GroupRolesUpdated,
+		TargetType: "directory_group",
+		TargetID: c.Param("id"),
+		Changes: map[string]auditDomain.Change{
+			"OrganizationRole": {After: group.OrganizationRole},
+			"ProjectRoles": {After: group.ProjectRoles},
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"group": group,
+	})
+}
+
+// SCIM 2.0 (RFC 7643, RFC 7644), authenticated with a SCIM token
+
+func SCIMServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, gin.H{
+		"schemas": []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch": gin.H{"supported": true},
+		"bulk": gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter": gin.H{"supported": true, "maxResults": scimDomain.MaxPageSize},
+		"changePassword": gin.H{"supported": false},
+		"sort": gin.H{"supported": false},
+		"etag": gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type": "oauthbearertoken",
+			"name": "Bearer Token",
+			"description": "SCIM token created by an organization admin",
+		}},
+	})
+}
+
+func SCIMRetrieveUsers(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	startIndex, count, ok := scimPagination(c)
+	if !ok {
+		return
+	}
+
+	response, err := scimService.RetrieveUsers(organizationKey, c.Query("filter"), startIndex, count, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, response)
+}
+
+func SCIMRetrieveUser(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	user, err := scimService.RetrieveUser(c.Param("id"), organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, user)
+}
+
+func SCIMNewUser(c *gin.Context) {
+	var req scimDomain.UserResource
+
+	if err := c.BindJSON(&req); err != nil {
+		scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidSyntax", "The request body is invalid."))
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	user, err := scimService.NewUser(&req, organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: auditDomain.AccountProvisioned,
+		TargetType: "account",
+		TargetID: user.UserName,
+	})
+
+	scimJSON(c, http.StatusCreated, user)
+}
+
+func SCIMReplaceUser(c *gin.Context) {
+	var req scimDomain.UserResource
+
+	if err := c.BindJSON(&req); err != nil {
+		scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidSyntax", "The request body is invalid."))
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	before, _ := scimService.RetrieveUser(c.Param("id"), organizationKey, scimBaseURL(c), config.Database())
+
+	user, err := scimService.ReplaceUser(c.Param("id"), &req, organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordUserChange(c, before, user)
+
+	scimJSON(c, http.StatusOK, user)
+}
+
+func SCIMPatchUser(c *gin.Context) {
+	var req scimDomain.PatchRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidSyntax", "The request body is invalid."))
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	before, _ := scimService.RetrieveUser(c.Param("id"), organizationKey, scimBaseURL(c), config.Database())
+
+	user, err := scimService.PatchUser(c.Param("id"), req.Operations, organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordUserChange(c, before, user)
+
+	scimJSON(c, http.StatusOK, user)
+}
+
+func SCIMDeleteUser(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	_account, err := scimService.DeleteUser(c.Param("id"), organizationKey, config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: auditDomain.AccountDeprovisioned,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+func SCIMRetrieveGroups(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	startIndex, count, ok := scimPagination(c)
+	if !ok {
+		return
+	}
+
+	response, err := scimService.RetrieveGroups(organizationKey, c.Query("filter"), startIndex, count, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, response)
+}
+
+func SCIMRetrieveGroup(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	group, err := scimService.RetrieveGroup(c.Param("id"), organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	scimJSON(c, http.StatusOK, group)
+}
+
+func SCIMNewGroup(c *gin.Context) {
+	var req scimDomain.GroupResource
+
+	if err := c.BindJSON(&req); err != nil {
+		scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidSyntax", "The request body is invalid."))
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	group, err := scimService.NewGroup(&req, organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: auditDomain.GroupProvisioned,
+		TargetType: "directory_group",
+		TargetID: group.ID,
+	})
+
+	scimJSON(c, http.StatusCreated, group)
+}
+
+func SCIMReplaceGroup(c *gin.Context) {
+	var req scimDomain.GroupResource
+
+	if err := c.BindJSON(&req); err != nil {
+		scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidSyntax", "The request body is invalid."))
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	before, _ := scimService.RetrieveGroup(c.Param("id"), organizationKey, scimBaseURL(c), config.Database())
+
+	group, err := scimService.ReplaceGroup(c.Param("id"), &req, organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: auditDomain.GroupUpdated,
+		TargetType: "directory_group",
+		TargetID: group.ID,
+		Changes: auditDomain.BuildChanges(before, group),
+	})
+
+	scimJSON(c, http.StatusOK, group)
+}
+
+func SCIMPatchGroup(c *gin.Context) {
+	var req scimDomain.PatchRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidSyntax", "The request body is invalid."))
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	before, _ := scimService.RetrieveGroup(c.Param("id"), organizationKey, scimBaseURL(c), config.Database())
+
+	group, err := scimService.PatchGroup(c.Param("id"), req.Operations, organizationKey, scimBaseURL(c), config.Database())
+
+	if err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: auditDomain.GroupUpdated,
+		TargetType: "directory_group",
+		TargetID: group.ID,
+		Changes: auditDomain.BuildChanges(before, group),
+	})
+
+	scimJSON(c, http.StatusOK, group)
+}
+
+func SCIMDeleteGroup(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	if _, err := scimService.DeleteGroup(c.Param("id"), organizationKey, config.Database()); err != nil {
+		scimFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: auditDomain.GroupDeleted,
+		TargetType: "directory_group",
+		TargetID: c.Param("id"),
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// recordUserChange audits an update of a user, and its deactivation or reactivation
+func recordUserChange(c *gin.Context, before *scimDomain.UserResource, after *scimDomain.UserResource) {
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: auditDomain.AccountUpdated,
+		TargetType: "account",
+		TargetID: after.UserName,
+		Changes: auditDomain.BuildChanges(before, after),
+	})
+
+	if before == nil || before.Active == nil || after.Active == nil || *before.Active == *after.Active {
+		return
+	}
+
+	action := auditDomain.AccountEnabled
+	if !*after.Active {
+		action = auditDomain.AccountDisabled
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		ActorEmail: scimActorOf(c),
+		Action: action,
+		TargetType: "account",
+		TargetID: after.UserName,
+	})
+}
+
+func scimPagination(c *gin.Context) (int, int, bool) {
+	startIndex, count := 1, scimDomain.DefaultPageSize
+
+	if value := c.Query("startIndex"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidValue", "Invalid startIndex."))
+			return 0, 0, false
+		}
+		startIndex = n
+	}
+
+	if value := c.Query("count"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			scimFailed(c, scimDomain.NewError(http.StatusBadRequest, "invalidValue", "Invalid count."))
+			return 0, 0, false
+		}
+		count = n
+	}
+
+	return startIndex, count, true
+}
+
+// scimBaseURL is the base of the resource locations, as the directory reached the service
+func scimBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+
+	return scheme + "://" + c.Request.Host + "/scim/v2"
+}
+
+// scimActorOf names the SCIM token of the request as the actor of audit events
+func scimActorOf(c *gin.Context) string {
+	if value, ok := c.Get("scimToken"); ok {
+		if token, ok := value.(*scimDomain.Token); ok {
+			return "scim:" + token.Name
+		}
+	}
+	return "scim"
+}
+
+func scimJSON(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", "application/scim+json")
+	c.JSON(status, body)
+}
+
+func scimFailed(c *gin.Context, err error) {
+	var scimErr *scimDomain.Error
+
+	if !errors.As(err, &scimErr) {
+		log.Printf("Error handling scim request: %v", err)
+		scimErr = scimDomain.NewError(http.StatusInternalServerError, "", err.Error())
+	}
+
+	body := gin.H{
+		"schemas": []string{scimDomain.SchemaError},
+		"status": strconv.Itoa(scimErr.Status),
+		"detail": scimErr.Detail,
+	}
+
+	if scimErr.ScimType != "" {
+		body["scimType"] = scimErr.ScimType
+	}
+
+	scimJSON(c, scimErr.Status, body)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	sessionService "github.com/darksuei/suei-intelligence/internal/application/session"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	sessionDomain "github.com/darksuei/suei-intelligence/internal/domain/session"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+// RetrieveSessions lists the caller's active sessions, flagging the one the request was made from
+func RetrieveSessions(c *gin.Context) {
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	_sessions, err := sessionService.RetrieveSessions(accountId, config.Database())
+
+	if err != nil {
+		log.Printf("Error retrieving sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	currentSessionId, _ := utils.GetSessionIdFromContext(c)
+
+	sessions := make([]gin.H, 0, len(*_sessions))
+	for _, _session := range *_sessions {
+		sessions = append(sessions, gin.H{
+			"ID": _session.ID,
+			"IP": _session.IP,
+			"UserAgent": _session.UserAgent,
+			"CreatedAt": _session.CreatedAt,
+			"LastUsedAt": _session.LastUsedAt,
+			"ExpiresAt": _session.ExpiresAt,
+			"Current": currentSessionId != nil && *currentSessionId == _session.ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"sessions": sessions,
+	})
+}
+
+func RevokeSession(c *gin.Context) {
+	sessionId, err := strconv.ParseUint(c.Param("id"), 10, 64) // assumes route is like /auth/sessions/:id
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session id",
+		})
+		return
+	}
+
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	_session, err := sessionService.RevokeSession(uint(sessionId), accountId, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SessionRevoked,
+		TargetType: "session",
+		TargetID: strconv.FormatUint(uint64(_session.ID), 10),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// RevokeSessions signs the caller out everywhere, including the current session
+func RevokeSessions(c *gin.Context) {
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	if err := sessionService.RevokeSessions(accountId, config.Database()); err != nil {
+		log.Printf("Error revoking sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	targetId := strconv.FormatUint(uint64(accountId), 10)
+	if email, err := utils.GetUserEmailFromContext(c); err == nil {
+		targetId = *email
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SessionsRevoked,
+		TargetType: "account",
+		TargetID: targetId,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// requireAccountId resolves the caller's account id, aborting the request when it is missing
+func requireAccountId(c *gin.Context) (uint, bool) {
+	userId, err := utils.GetUserIdFromContext(c)
+
+	if err == nil && userId != nil {
+		if accountId, err := strconv.ParseUint(*userId, 10, 64); err == nil {
+			return uint(accountId), true
+		}
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error": "Failed to get account",
+	})
+	return 0, false
+}
+
+func sessionClientOf(c *gin.Context) sessionDomain.Client {
+	return sessionDomain.Client{
+		IP: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
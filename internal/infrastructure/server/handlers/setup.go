@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	setupService "github.com/darksuei/suei-intelligence/internal/application/setup"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	metadataDomain "github.com/darksuei/suei-intelligence/internal/domain/metadata"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+// Setup runs first-run setup: it creates the organization and its first SUPERADMIN account.
+// It requires the bootstrap token and is locked once it has succeeded
+func Setup(c *gin.Context) {
+	// Parse the request body
+	var req struct {
+		BootstrapToken string `json:"bootstrapToken" binding:"required"`
+		Name string `json:"name" binding:"required"`
+		Scope string `json:"scope" binding:"required"`
+		Admin struct {
+			Name string `json:"name" binding:"required"`
+			Email string `json:"email" binding:"required,email"`
+			Password string `json:"password" binding:"required"`
+		} `json:"admin" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	_organization, _account, err := setupService.CompleteSetup(req.BootstrapToken, req.Name, req.Scope, req.Admin.Name, req.Admin.Email, req.Admin.Password, config.Database())
+
+	if errors.Is(err, metadataDomain.ErrSetupCompleted) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if errors.Is(err, metadataDomain.ErrInvalidBootstrapToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		log.Printf("Error completing setup: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: _organization.Key,
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.SetupCompleted,
+		TargetType: "organization",
+		TargetID: _organization.Key,
+		Changes: auditDomain.BuildChanges(nil, _organization),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"organization": _organization,
+		"account": accountDomain.ToAccountDTO(_account),
+	})
+}
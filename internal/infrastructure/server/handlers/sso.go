@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darksuei/suei-intelligence/internal/application/authentication"
+	authorizationService "github.com/darksuei/suei-intelligence/internal/application/authorization"
+	ssoService "github.com/darksuei/suei-intelligence/internal/application/sso"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	authorizationDomain "github.com/darksuei/suei-intelligence/internal/domain/authorization"
+	ssoDomain "github.com/darksuei/suei-intelligence/internal/domain/sso"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+func RetrieveSSOProvider(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "read")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	_provider, err := ssoService.RetrieveProvider(organizationKey, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not Found.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"provider": providerResponse(_provider),
+	})
+}
+
+// Configure the organization's OpenID Connect identity provider. Whoever controls it can
+// sign in as any account of the organization, so this requires organization admin
+func ConfigureSSOProvider(c *gin.Context) {
+	var req struct {
+		Issuer string `json:"issuer" binding:"required,url"`
+		ClientID string `json:"clientId" binding:"required"`
+		ClientSecret string `json:"clientSecret,omitempty"` // <- kept when omitted on update
+		Scopes []string `json:"scopes,omitempty"`
+		RoleClaim string `json:"roleClaim,omitempty"`
+		RoleMapping map[string]string `json:"roleMapping,omitempty"`
+		DefaultRole string `json:"defaultRole,omitempty"`
+		Enabled *bool `json:"enabled,omitempty"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	if req.Scopes == nil {
+		req.Scopes = []string{}
+	}
+
+	if req.RoleMapping == nil {
+		req.RoleMapping = map[string]string{}
+	}
+
+	// Snapshot before update for the audit log
+	_before, _ := ssoService.RetrieveProvider(organizationKey, config.Database())
+
+	_provider, err := ssoService.ConfigureProvider(organizationKey, &ssoDomain.Provider{
+		Issuer: req.Issuer,
+		ClientID: req.ClientID,
+		ClientSecret: req.ClientSecret,
+		Scopes: req.Scopes,
+		RoleClaim: req.RoleClaim,
+		RoleMapping: req.RoleMapping,
+		DefaultRole: accountDomain.AccountRole(req.DefaultRole),
+		Enabled: enabled,
+	}, config.Database(), config.SSO())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SSOConfigured,
+		TargetType: "organization",
+		TargetID: organizationKey,
+		Changes: auditDomain.BuildChanges(_before, _provider),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"provider": providerResponse(_provider),
+	})
+}
+
+func DeleteSSOProvider(c *gin.Context) {
+	organizationKey, ok := requireOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Authorization
+	allow, err := authorizationService.EnforceRoles(utils.GetUserRolesFromContext(c), "org", authorizationDomain.Organization, "admin")
+
+	if err != nil || !allow {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden",
+		})
+		return
+	}
+
+	if _, err := ssoService.DeleteProvider(organizationKey, config.Database()); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SSODeleted,
+		TargetType: "organization",
+		TargetID: organizationKey,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// Start signing in through the organization's identity provider
+func SSOLogin(c *gin.Context) {
+	organizationKey := c.Param("organizationKey") // assumes route is like /auth/sso/:organizationKey
+
+	uri, state, err := ssoService.BeginLogin(organizationKey, config.Database(), config.SSO(), config.Common())
+
+	if err != nil {
+		if !errors.Is(err, ssoDomain.ErrProviderNotConfigured) {
+			log.Printf("Error starting sso login: %v", err)
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// The identity provider redirects back with a top-level navigation, which Lax cookies survive
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoDomain.StateCookieName, state, int(ssoDomain.LoginStateTTL.Seconds()), "/", "", isSecureCallback(), true)
+
+	c.Redirect(http.StatusFound, uri)
+}
+
+// Complete signing in through an identity provider, which redirects here with an authorization code
+func SSOCallback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		ssoFailed(c, errors.New("Identity provider error: "+providerError))
+		return
+	}
+
+	state := c.Query("state")
+	code := c.Query("code")
+
+	if state == "" || code == "" {
+		ssoFailed(c, errors.New("Missing required query parameters: state, code"))
+		return
+	}
+
+	// The sign-in must complete in the browser that started it. Otherwise anyone could send a
+	// victim a callback URL with their own code and sign the victim in to their account
+	cookieState, _ := c.Cookie(ssoDomain.StateCookieName)
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoDomain.StateCookieName, "", -1, "/", "", isSecureCallback(), true)
+
+	if subtle.ConstantTimeCompare([]byte(cookieState), []byte(state)) != 1 {
+		ssoFailed(c, errors.New("Invalid or expired sign-in. Please try again."))
+		return
+	}
+
+	_account, provisioned, err := ssoService.CompleteLogin(state, code, config.Database(), config.SSO(), config.Common())
+
+	if err != nil {
+		log.Printf("Error completing sso login: %v", err)
+		ssoFailed(c, err)
+		return
+	}
+
+	if provisioned {
+		recordAudit(c, auditDomain.AuditEvent{
+			OrganizationKey: organizationKeyOf(_account.Email),
+			ActorEmail: _account.Email,
+			Action: auditDomain.AccountProvisioned,
+			TargetType: "account",
+			TargetID: _account.Email,
+		})
+	}
+
+	auth, err := authentication.LoginWithSSO(_account, sessionClientOf(c), config.Common(), config.JWT(), config.Database())
+
+	if err != nil {
+		ssoFailed(c, err)
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		OrganizationKey: organizationKeyOf(_account.Email),
+		ActorID: strconv.FormatUint(uint64(_account.ID), 10),
+		ActorEmail: _account.Email,
+		Action: auditDomain.SSOLoginSucceeded,
+		TargetType: "account",
+		TargetID: _account.Email,
+	})
+
+	// Hand the tokens to the frontend in the fragment, which is never sent to servers
+	if frontendURL := config.SSO().SSOFrontendURL; frontendURL != "" {
+		fragment := url.Values{
+			"access_token": {auth.AccessToken},
+			"refresh_token": {auth.RefreshToken},
+		}
+
+		c.Redirect(http.StatusFound, frontendURL+"#"+fragment.Encode())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"access_token": auth.AccessToken,
+		"refresh_token": auth.RefreshToken,
+		"provisioned": provisioned,
+	})
+}
+
+func ssoFailed(c *gin.Context, err error) {
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.SSOLoginFailed,
+		Outcome: auditDomain.Failure,
+		TargetType: "account",
+	})
+
+	if frontendURL := config.SSO().SSOFrontendURL; frontendURL != "" {
+		c.Redirect(http.StatusFound, frontendURL+"#"+url.Values{"error": {err.Error()}}.Encode())
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error": err.Error(),
+	})
+}
+
+// isSecureCallback reports whether the callback is served over HTTPS, so the state cookie can
+// be restricted to it
+func isSecureCallback() bool {
+	return strings.HasPrefix(ssoService.CallbackURL(config.SSO(), config.Common()), "https://")
+}
+
+func providerResponse(_provider *ssoDomain.Provider) gin.H {
+	return gin.H{
+		"Issuer": _provider.Issuer,
+		"ClientID": _provider.ClientID,
+		"Scopes": _provider.Scopes,
+		"RoleClaim": _provider.RoleClaim,
+		"RoleMapping": _provider.RoleMapping,
+		"DefaultRole": _provider.DefaultRole,
+		"Enabled": _provider.Enabled,
+		"CreatedAt": _provider.CreatedAt,
+		"UpdatedAt": _provider.UpdatedAt,
+	}
+}
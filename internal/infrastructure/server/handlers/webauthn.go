@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	webauthnService "github.com/darksuei/suei-intelligence/internal/application/webauthn"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	auditDomain "github.com/darksuei/suei-intelligence/internal/domain/audit"
+	lockoutDomain "github.com/darksuei/suei-intelligence/internal/domain/lockout"
+	webauthnDomain "github.com/darksuei/suei-intelligence/internal/domain/webauthn"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/cache"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/utils"
+)
+
+func RetrieveWebAuthnCredentials(c *gin.Context) {
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	_credentials, err := webauthnService.RetrieveCredentials(accountId, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	credentials := make([]gin.H, 0, len(*_credentials))
+	for _, _credential := range *_credentials {
+		credentials = append(credentials, credentialResponse(&_credential))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"credentials": credentials,
+	})
+}
+
+// Start registering a security key, returning the options for navigator.credentials.create
+func BeginWebAuthnRegistration(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	email, err := utils.GetUserEmailFromContext(c)
+
+	if err != nil || email == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get account",
+		})
+		return
+	}
+
+	if !enforceLockout(c, *email) {
+		return
+	}
+
+	// Adding a second factor requires the password, not just a session
+	if _, err := accountService.RetrieveAccountWithPassword(*email, req.Password, config.Database()); err != nil {
+		recordFailedAttempt(c, *email, lockoutDomain.AttemptPassword)
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	options, err := webauthnService.BeginRegistration(accountId, config.Database(), config.WebAuthn())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"publicKey": options,
+	})
+}
+
+// Finish registering a security key with the response of navigator.credentials.create
+func FinishWebAuthnRegistration(c *gin.Context) {
+	var req struct {
+		Name string `json:"name"`
+		Credential webauthnDomain.RegistrationResponse `json:"credential" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	_credential, err := webauthnService.FinishRegistration(accountId, req.Name, req.Credential, config.Database(), config.WebAuthn())
+
+	if err != nil {
+		recordAudit(c, auditDomain.AuditEvent{
+			Action: auditDomain.WebAuthnRegistered,
+			Outcome: auditDomain.Failure,
+			TargetType: "account",
+			TargetID: strconv.FormatUint(uint64(accountId), 10),
+		})
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.WebAuthnRegistered,
+		TargetType: "webauthn_credential",
+		TargetID: strconv.FormatUint(uint64(_credential.ID), 10),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"credential": credentialResponse(_credential),
+	})
+}
+
+func RenameWebAuthnCredential(c *gin.Context) {
+	credentialId, err := strconv.ParseUint(c.Param("id"), 10, 64) // assumes route is like /mfa/webauthn/credentials/:id
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid credential id",
+		})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	_credential, err := webauthnService.RenameCredential(uint(credentialId), accountId, req.Name, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.WebAuthnRenamed,
+		TargetType: "webauthn_credential",
+		TargetID: strconv.FormatUint(uint64(_credential.ID), 10),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"credential": credentialResponse(_credential),
+	})
+}
+
+func DeleteWebAuthnCredential(c *gin.Context) {
+	credentialId, err := strconv.ParseUint(c.Param("id"), 10, 64) // assumes route is like /mfa/webauthn/credentials/:id
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid credential id",
+		})
+		return
+	}
+
+	accountId, ok := requireAccountId(c)
+	if !ok {
+		return
+	}
+
+	_credential, err := webauthnService.DeleteCredential(uint(credentialId), accountId, config.Database())
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recordAudit(c, auditDomain.AuditEvent{
+		Action: auditDomain.WebAuthnRemoved,
+		TargetType: "webauthn_credential",
+		TargetID: strconv.FormatUint(uint64(_credential.ID), 10),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// Start a security key assertion for a login challenge, returning the options for
+// navigator.credentials.get. The response is sent to /auth/mfa
+func WebAuthnAssertionOptions(c *gin.Context) {
+	var req struct {
+		ChallengeID string `json:"challenge_id" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "Validation failed.",
+			"errors": utils.FormatValidationErrors(err),
+		})
+		return
+	}
+
+	email, err := cache.GetCache().Get(fmt.Sprintf("challenge-id-%s", req.ChallengeID))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Please restart login flow.",
+		})
+		return
+	}
+
+	_account, err := accountService.RetrieveAccount(email, config.Database())
+
+	if err != nil || _account == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid account.",
+		})
+		return
+	}
+
+	options, err := webauthnService.BeginAssertion(_account.ID, req.ChallengeID, config.Database(), config.WebAuthn())
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+		"publicKey": options,
+	})
+}
+
+func credentialResponse(_credential *webauthnDomain.Credential) gin.H {
+	return gin.H{
+		"ID": _credential.ID,
+		"Name": _credential.Name,
+		"CreatedAt": _credential.CreatedAt,
+		"LastUsedAt": _credential.LastUsedAt,
+	}
+}
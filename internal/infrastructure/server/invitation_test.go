@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewInvitation(t *testing.T) {
+	const password = "Passw0rd!invite"
+
+	admin := "invite-admin@example.com"
+	guest := "invite-guest@example.com"
+
+	newAccount(t, admin, "ADMIN", password)
+	newAccount(t, guest, "GUEST", password)
+	createProject(t, "invitation")
+
+	tests := []struct {
+		name       string
+		caller     string
+		password   string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{name: "guests cannot invite", caller: guest, password: password, body: map[string]interface{}{"email": "invite-1@example.com", "role": "GUEST"}, wantStatus: http.StatusForbidden},
+		{name: "admins cannot invite admins", caller: admin, password: password, body: map[string]interface{}{"email": "invite-2@example.com", "role": "ADMIN"}, wantStatus: http.StatusForbidden},
+		{name: "rejects an unknown role", caller: rootEmail, password: rootPassword, body: map[string]interface{}{"email": "invite-3@example.com", "role": "OWNER"}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "rejects an unknown project", caller: rootEmail, password: rootPassword, body: map[string]interface{}{"email": "invite-4@example.com", "role": "GUEST", "projectRoles": map[string]string{"unknown": "VIEWER"}}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "rejects a registered email", caller: rootEmail, password: rootPassword, body: map[string]interface{}{"email": guest, "role": "GUEST"}, wantStatus: http.StatusBadRequest},
+		{name: "admins invite guests", caller: admin, password: password, body: map[string]interface{}{"email": "invite-5@example.com", "role": "GUEST"}, wantStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("POST", "/invitations", login(t, tt.caller, tt.password), tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			// The invite token is only ever delivered to the invitee
+			if strings.Contains(body.String(), "oken") {
+				t.Fatalf("response carries the invite token: %s", body)
+			}
+		})
+	}
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	const password = "Passw0rd!accept"
+
+	email := "accept@example.com"
+	root := login(t, rootEmail, rootPassword)
+
+	createProject(t, "accept")
+
+	status, body := request("POST", "/invitations", root, map[string]interface{}{"email": email, "role": "GUEST", "projectRoles": map[string]string{"accept": "EDITOR"}})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to invite (%d): %s", status, body)
+	}
+
+	token := invitationToken(t, email)
+	accept := map[string]string{"token": token, "name": "accept", "password": password}
+
+	t.Run("previews the invitation", func(t *testing.T) {
+		status, body := request("GET", "/invitations/accept?token="+token, nil, nil)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+		}
+
+		if _invitation, _ := body["invitation"].(map[string]interface{}); _invitation["Email"] != email || _invitation["Status"] != "PENDING" {
+			t.Fatalf("invitation = %v, want a pending invitation for %s", _invitation, email)
+		}
+	})
+
+	t.Run("rejects an unknown token", func(t *testing.T) {
+		if status, body := request("POST", "/invitations/accept", nil, map[string]string{"token": "unknown", "name": "unknown", "password": password}); status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+	})
+
+	t.Run("creates the account with the invited roles", func(t *testing.T) {
+		status, body := request("POST", "/invitations/accept", nil, accept)
+		if status != http.StatusCreated {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusCreated, body)
+		}
+
+		headers := login(t, email, password)
+
+		if status, body := request("PUT", "/project/accept", headers, map[string]string{"businessDomain": "accepted"}); status != http.StatusOK {
+			t.Fatalf("invited project role: status = %d, want %d: %s", status, http.StatusOK, body)
+		}
+
+		if status, _ := request("POST", "/invitations", headers, map[string]string{"email": "accept-2@example.com", "role": "GUEST"}); status != http.StatusForbidden {
+			t.Fatalf("invited account role: status = %d, want %d", status, http.StatusForbidden)
+		}
+	})
+
+	t.Run("accepts an invitation once", func(t *testing.T) {
+		if status, body := request("POST", "/invitations/accept", nil, accept); status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+	})
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	email := "revoke@example.com"
+	root := login(t, rootEmail, rootPassword)
+
+	status, body := request("POST", "/invitations", root, map[string]string{"email": email, "role": "GUEST"})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to invite (%d): %s", status, body)
+	}
+
+	id := fmt.Sprint(body["invitation"].(map[string]interface{})["ID"])
+
+	if status, body := request("DELETE", "/invitations/"+id, root, nil); status != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+	}
+
+	if status, body := request("POST", "/invitations/accept", nil, map[string]string{"token": invitationToken(t, email), "name": "revoke", "password": "Passw0rd!revoke"}); status != http.StatusBadRequest {
+		t.Fatalf("accepting a revoked invitation: status = %d, want %d: %s", status, http.StatusBadRequest, body)
+	}
+}
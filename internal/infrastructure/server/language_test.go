@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLanguagePreference(t *testing.T) {
+	const (
+		guest      = "language-guest@example.com"
+		otherAdmin = "language-admin@example.com"
+		password   = "Passw0rd!language"
+	)
+
+	newAccount(t, guest, "GUEST", password)
+	newOrganization(t, "Language", otherAdmin, password)
+
+	root := login(t, rootEmail, rootPassword)
+
+	// language returns the language preference of the caller's organization
+	language := func(t *testing.T, headers map[string]string) interface{} {
+		t.Helper()
+
+		status, body := request("GET", "/get-language", headers, nil)
+		expectStatus(t, body.String(), status, http.StatusOK)
+		return body["language"]
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "anonymous callers cannot set the language", wantStatus: http.StatusUnauthorized},
+		{name: "guests cannot set the language", headers: login(t, guest, password), wantStatus: http.StatusForbidden},
+		{name: "superadmins set the language of their organization", headers: root, wantStatus: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("PUT", "/set-language", tt.headers, map[string]string{"code": "AF"})
+			expectStatus(t, body.String(), status, tt.wantStatus)
+		})
+	}
+
+	t.Run("anonymous callers cannot read the language", func(t *testing.T) {
+		status, body := request("GET", "/get-language", nil, nil)
+		expectStatus(t, body.String(), status, http.StatusUnauthorized)
+	})
+
+	t.Run("stores the language per organization", func(t *testing.T) {
+		if got := language(t, root); got != "AF" {
+			t.Fatalf("language = %v, want AF", got)
+		}
+		if got := language(t, login(t, guest, password)); got != "AF" {
+			t.Fatalf("language of a member = %v, want AF", got)
+		}
+		if got := language(t, login(t, otherAdmin, password)); got == "AF" {
+			t.Fatal("language was changed for another organization")
+		}
+	})
+
+	t.Run("records the change under the caller's organization", func(t *testing.T) {
+		status, body := request("GET", "/audit-events?action=organization.language_updated", root, nil)
+		expectStatus(t, body.String(), status, http.StatusOK)
+
+		if events, _ := body["events"].([]interface{}); len(events) == 0 {
+			t.Fatalf("events = %v, want the language change", body["events"])
+		}
+
+		status, body = request("GET", "/audit-events?action=organization.language_updated", login(t, otherAdmin, password), nil)
+		expectStatus(t, body.String(), status, http.StatusOK)
+
+		if events, _ := body["events"].([]interface{}); len(events) != 0 {
+			t.Fatalf("events of another organization = %v, want none", events)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server"
+)
+
+// attempt is a sign-in from a client IP
+type attempt struct {
+	email      string
+	password   string
+	ip         string
+	unlock     bool // <- root unlocks the account instead of signing in
+	wantStatus int
+}
+
+func repeat(n int, a attempt) []attempt {
+	attempts := make([]attempt, n)
+	for i := range attempts {
+		attempts[i] = a
+	}
+	return attempts
+}
+
+func TestLockout(t *testing.T) {
+	const password = "Passw0rd!lockout"
+
+	accounts := []string{"lockout-1@example.com", "lockout-2@example.com", "lockout-3@example.com", "lockout-4@example.com"}
+
+	for _, email := range accounts {
+		newAccount(t, email, "GUEST", password)
+	}
+
+	// Each case signs in from its own addresses, so that lockouts of client IPs stay within it
+	wrong := func(email string, ip string) attempt {
+		return attempt{email: email, password: "wrong", ip: ip, wantStatus: http.StatusBadRequest}
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "locks an account after repeated failures",
+			attempts: append(repeat(5, wrong(accounts[0], "198.51.100.1")),
+				attempt{email: accounts[0], password: password, ip: "198.51.100.1", wantStatus: http.StatusTooManyRequests},
+				attempt{email: accounts[0], password: password, ip: "198.51.100.2", wantStatus: http.StatusTooManyRequests},
+			),
+		},
+		{
+			name: "clears failed attempts on sign-in",
+			attempts: append(append(repeat(4, wrong(accounts[1], "198.51.100.3")),
+				attempt{email: accounts[1], password: password, ip: "198.51.100.3", wantStatus: http.StatusOK}),
+				append(repeat(4, wrong(accounts[1], "198.51.100.3")),
+					attempt{email: accounts[1], password: password, ip: "198.51.100.3", wantStatus: http.StatusOK})...,
+			),
+		},
+		{
+			name: "unlocks an account on request of an admin",
+			attempts: append(repeat(5, wrong(accounts[2], "198.51.100.4")),
+				attempt{email: accounts[2], password: password, ip: "198.51.100.4", wantStatus: http.StatusTooManyRequests},
+				attempt{email: accounts[2], unlock: true, wantStatus: http.StatusOK},
+				attempt{email: accounts[2], password: password, ip: "198.51.100.4", wantStatus: http.StatusOK},
+			),
+		},
+		{
+			name: "locks a client IP across accounts",
+			attempts: append(ipFailures(20, "198.51.100.5"),
+				attempt{email: accounts[3], password: password, ip: "198.51.100.5", wantStatus: http.StatusTooManyRequests},
+				attempt{email: accounts[3], password: password, ip: "198.51.100.6", wantStatus: http.StatusOK},
+			),
+		},
+	}
+
+	root := login(t, rootEmail, rootPassword)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, a := range tt.attempts {
+				var status int
+				var body response
+
+				if a.unlock {
+					status, body = request("POST", "/account/unlock?email="+a.email, root, nil)
+				} else {
+					status, body = request("POST", "/auth/login", map[string]string{"X-Forwarded-For": a.ip}, map[string]string{"email": a.email, "password": a.password})
+				}
+
+				if status != a.wantStatus {
+					t.Fatalf("attempt %d: status = %d, want %d: %s", i+1, status, a.wantStatus, body)
+				}
+
+				if status == http.StatusTooManyRequests {
+					if retryAfter, _ := body["retry_after"].(float64); retryAfter < 1 {
+						t.Fatalf("attempt %d: retry_after = %v, want at least a second", i+1, body["retry_after"])
+					}
+				}
+			}
+		})
+	}
+}
+
+// ipFailures fails to sign in to a different unknown account on each attempt from a client IP
+func ipFailures(n int, ip string) []attempt {
+	attempts := make([]attempt, n)
+	for i := range attempts {
+		attempts[i] = attempt{email: fmt.Sprintf("unknown-%d@example.com", i), password: "wrong", ip: ip, wantStatus: http.StatusBadRequest}
+	}
+	return attempts
+}
+
+func TestLockoutDelay(t *testing.T) {
+	const password = "Passw0rd!delay"
+
+	email := "lockout-delay@example.com"
+	newAccount(t, email, "GUEST", password)
+
+	lockoutCfg := config.Lockout()
+	previous := *lockoutCfg
+	lockoutCfg.LockoutDelayBase, lockoutCfg.LockoutDelayMax = time.Minute, time.Minute
+	t.Cleanup(func() { *lockoutCfg = previous })
+
+	headers := map[string]string{"X-Forwarded-For": "198.51.100.30"}
+
+	start := time.Now()
+
+	if status, body := request("POST", "/auth/login", headers, map[string]string{"email": email, "password": "wrong"}); status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", status, http.StatusBadRequest, body)
+	}
+
+	t.Run("answers a failed attempt without holding the response", func(t *testing.T) {
+		if elapsed := time.Since(start); elapsed > 10*time.Second {
+			t.Fatalf("failed attempt took %v", elapsed)
+		}
+	})
+
+	t.Run("rejects attempts before the delay has passed", func(t *testing.T) {
+		status, body := request("POST", "/auth/login", headers, map[string]string{"email": email, "password": password})
+		if status != http.StatusTooManyRequests {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusTooManyRequests, body)
+		}
+
+		if retryAfter, _ := body["retry_after"].(float64); retryAfter < 1 || retryAfter > 60 {
+			t.Fatalf("retry_after = %v, want the remaining delay", body["retry_after"])
+		}
+	})
+
+	t.Run("lifts the delay when an admin unlocks the account", func(t *testing.T) {
+		if status, body := request("POST", "/account/unlock?email="+email, login(t, rootEmail, rootPassword), nil); status != http.StatusOK {
+			t.Fatalf("failed to unlock account (%d): %s", status, body)
+		}
+
+		login(t, email, password)
+	})
+}
+
+func TestLockoutUntrustedProxy(t *testing.T) {
+	const password = "Passw0rd!proxy"
+
+	email := "lockout-proxy@example.com"
+	newAccount(t, email, "GUEST", password)
+
+	// Without trusted proxies, X-Forwarded-For must not change the address attempts are counted against
+	commonCfg := config.Common()
+	previous := commonCfg.TrustedProxies
+	commonCfg.TrustedProxies = nil
+	untrusted := server.InitializeRouter()
+	commonCfg.TrustedProxies = previous
+
+	signIn := func(email string, password string, forwardedFor string) int {
+		content, _ := json.Marshal(map[string]string{"email": email, "password": password})
+
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewReader(content))
+		req.RemoteAddr = "203.0.113.9:1234"
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+
+		recorder := httptest.NewRecorder()
+		untrusted.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	for i := 0; i < 20; i++ {
+		if status := signIn(fmt.Sprintf("proxy-unknown-%d@example.com", i), "wrong", fmt.Sprintf("198.51.100.%d", 100+i)); status != http.StatusBadRequest {
+			t.Fatalf("attempt %d: status = %d, want %d", i+1, status, http.StatusBadRequest)
+		}
+	}
+
+	if status := signIn(email, password, "198.51.100.200"); status != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", status, http.StatusTooManyRequests)
+	}
+}
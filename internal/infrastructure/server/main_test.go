@@ -0,0 +1,396 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/darksuei/suei-intelligence/internal/application/authorization"
+	"github.com/darksuei/suei-intelligence/internal/application/datasource"
+	"github.com/darksuei/suei-intelligence/internal/application/metadata"
+	"github.com/darksuei/suei-intelligence/internal/application/secret"
+	"github.com/darksuei/suei-intelligence/internal/application/setup"
+	"github.com/darksuei/suei-intelligence/internal/application/signingkey"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/server"
+)
+
+const (
+	rootEmail    = "root@example.com"
+	rootPassword = "Passw0rd!x"
+)
+
+var (
+	router  *gin.Engine
+	airbyte *airbyteStub
+)
+
+// TestMain starts the application against a temporary sqlite database and a stub of the
+// Airbyte API, then sets the instance up with a root account
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	log.SetOutput(io.Discard)
+
+	repository, err := filepath.Abs("../../..")
+	if err != nil {
+		panic(err)
+	}
+
+	dir, err := os.MkdirTemp("", "suei-server-test")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Mkdir(filepath.Join(dir, "data"), 0o755); err != nil {
+		panic(err)
+	}
+
+	for _, file := range []string{"model.conf", "policy.csv"} {
+		content, err := os.ReadFile(filepath.Join(repository, "data", file))
+		if err != nil {
+			panic(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, "data", file), content, 0o644); err != nil {
+			panic(err)
+		}
+	}
+
+	// Every path in the configuration defaults to ./data
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	airbyte = newAirbyteStub()
+	defer airbyte.Close()
+
+	env := map[string]string{
+		"AIRBYTECLOUD": "false",
+		"AIRBYTEENDPOINT": airbyte.URL,
+		"AIRBYTECLIENTID": "client",
+		"AIRBYTECLIENTSECRET": "secret",
+		"AIRBYTEWORKSPACEID": "workspace",
+		"APPENV": "test",
+		"APPHOST": "localhost",
+		"APPPORT": "8080",
+		"BOOTSTRAPTOKEN": "bootstrap",
+		"LOCKOUTDELAYBASE": "0s", // <- attempts are sent back to back, TestLockoutDelay covers the delay
+		"LOCKOUTDELAYMAX": "0s",
+		"NOTIFIERTYPE": "file",
+		"TRUSTEDPROXIES": "192.0.2.1", // <- the remote address of test requests, so that they can set the client IP
+	}
+
+	for key, value := range env {
+		os.Setenv(key, value)
+	}
+
+	config.Initialize()
+
+	if err := datasource.LoadConnectors(config.Connector()); err != nil {
+		panic(err)
+	}
+
+	database.Initialize(config.Database())
+	database.Migrate(config.Database())
+
+	metadata.LoadBootstrapToken(config.Common().BootstrapToken, config.Database())
+
+	if err := setup.ReconcileSetup(config.Database()); err != nil {
+		panic(err)
+	}
+
+	authorization.Initialize(config.Casbin(), config.Database())
+	secret.Initialize(config.Secrets(), config.Database(), func() error {
+		return datasource.ReencryptConfigurations(config.Database())
+	}, func() error {
+		return signingkey.ReencryptKeys(config.Database())
+	})
+	signingkey.Initialize(config.JWT(), config.Database())
+
+	router = server.InitializeRouter()
+
+	status, body := request("POST", "/setup", nil, map[string]interface{}{
+		"bootstrapToken": "bootstrap",
+		"name": "Acme",
+		"scope": "PRIVATE",
+		"admin": map[string]string{"name": "Root", "email": rootEmail, "password": rootPassword},
+	})
+	if status != http.StatusCreated && status != http.StatusOK {
+		panic("setup failed: " + body.String())
+	}
+
+	return m.Run()
+}
+
+// response is a decoded JSON response body
+type response map[string]interface{}
+
+func (r response) String() string {
+	content, _ := json.Marshal(r)
+	return string(content)
+}
+
+// request sends a JSON request through the router
+func request(method string, path string, headers map[string]string, body interface{}) (int, response) {
+	var reader io.Reader
+
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			panic(err)
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	decoded := response{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &decoded)
+
+	return recorder.Code, decoded
+}
+
+// login signs an account in with its password and returns the Authorization header
+func login(t *testing.T, email string, password string) map[string]string {
+	t.Helper()
+
+	headers, _ := signIn(t, email, password)
+	return headers
+}
+
+// signIn signs an account in with its password and returns the Authorization header and
+// the refresh token of the new session
+func signIn(t *testing.T, email string, password string) (map[string]string, string) {
+	t.Helper()
+
+	status, body := request("POST", "/auth/login", nil, map[string]string{"email": email, "password": password})
+	if status != http.StatusOK {
+		t.Fatalf("login failed (%d): %s", status, body)
+	}
+
+	token, _ := body["access_token"].(string)
+	if token == "" {
+		t.Fatalf("login returned no access token: %s", body)
+	}
+
+	refreshToken, _ := body["refresh_token"].(string)
+
+	return map[string]string{"Authorization": "Bearer " + token}, refreshToken
+}
+
+// createProject creates a project as root, unless it exists already
+func createProject(t *testing.T, key string) {
+	t.Helper()
+
+	root := login(t, rootEmail, rootPassword)
+
+	if status, _ := request("GET", "/project/"+key, root, nil); status == http.StatusOK {
+		return
+	}
+
+	status, body := request("POST", "/project", root, map[string]string{
+		"name": strings.ToUpper(key),
+		"key": key,
+		"description": "test project",
+		"stage": "SANDBOX",
+		"businessDomain": "test",
+	})
+	if status != http.StatusCreated && status != http.StatusOK {
+		t.Fatalf("failed to create project (%d): %s", status, body)
+	}
+}
+
+var (
+	inviteToken = regexp.MustCompile(`Invite token: (\S+)`)
+	resetToken  = regexp.MustCompile(`Reset token: (\S+)`)
+)
+
+// invitationToken returns the token of the last invitation delivered to an email
+func invitationToken(t *testing.T, email string) string {
+	t.Helper()
+
+	return notifiedToken(t, email, inviteToken)
+}
+
+// notifiedToken returns the token of the last message delivered to an email that matches pattern
+func notifiedToken(t *testing.T, email string, pattern *regexp.Regexp) string {
+	t.Helper()
+
+	// The file notifier writes one JSON line per message
+	content, err := os.ReadFile(config.Notifier().NotifierFilePath)
+	if err != nil {
+		t.Fatalf("failed to read notifications: %v", err)
+	}
+
+	var token string
+
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		message := response{}
+		_ = json.Unmarshal([]byte(line), &message)
+
+		if !strings.Contains(fmt.Sprint(message["to"]), email) {
+			continue
+		}
+
+		if match := pattern.FindStringSubmatch(fmt.Sprint(message["body"])); match != nil {
+			token = match[1]
+		}
+	}
+
+	if token == "" {
+		t.Fatalf("no token was sent to %s", email)
+	}
+
+	return token
+}
+
+// newAccount invites an account as root and accepts the invitation with the given password
+func newAccount(t *testing.T, email string, role string, password string) {
+	t.Helper()
+
+	inviteAccount(t, login(t, rootEmail, rootPassword), email, role, password)
+}
+
+// inviteAccount invites an account and accepts the invitation with the given password
+func inviteAccount(t *testing.T, headers map[string]string, email string, role string, password string) {
+	t.Helper()
+
+	status, body := request("POST", "/invitations", headers, map[string]string{"email": email, "role": role})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to invite %s (%d): %s", email, status, body)
+	}
+
+	status, body = request("POST", "/invitations/accept", nil, map[string]string{"token": invitationToken(t, email), "name": strings.Split(email, "@")[0], "password": password})
+	if status != http.StatusCreated && status != http.StatusOK {
+		t.Fatalf("failed to accept invitation (%d): %s", status, body)
+	}
+}
+
+// airbyteStub implements the parts of the Airbyte API the application calls
+type airbyteStub struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	sources map[string]map[string]interface{} // <- configuration of each source
+	created int
+}
+
+const (
+	wrongPassword = "wrong"          // <- source password the stub fails connection tests for
+	rejectedHost  = "rejected.local" // <- source host the stub rejects updates to, quoting the password
+	stuckHost     = "stuck.local"    // <- source host the stub rejects any further update of
+)
+
+func newAirbyteStub() *airbyteStub {
+	stub := &airbyteStub{sources: map[string]map[string]interface{}{}}
+
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/applications/token"):
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/sources"):
+			var payload struct {
+				Configuration map[string]interface{} `json:"configuration"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+
+			sourceId := uuid.New().String()
+			stub.sources[sourceId] = payload.Configuration
+			stub.created++
+			_ = json.NewEncoder(w).Encode(map[string]string{"sourceId": sourceId})
+		case r.Method == http.MethodPatch:
+			sourceId := filepath.Base(r.URL.Path)
+			current, ok := stub.sources[sourceId]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			var payload struct {
+				Configuration map[string]interface{} `json:"configuration"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+
+			if current["host"] == stuckHost {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if payload.Configuration["host"] == rejectedHost {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprintf(w, `{"message":"invalid configuration, password %v was rejected"}`, payload.Configuration["password"])
+				return
+			}
+
+			stub.sources[sourceId] = payload.Configuration
+			_ = json.NewEncoder(w).Encode(map[string]string{"sourceId": sourceId})
+		case r.Method == http.MethodDelete:
+			delete(stub.sources, filepath.Base(r.URL.Path))
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/streams"):
+			configuration, ok := stub.sources[r.URL.Query().Get("sourceId")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if configuration["password"] == wrongPassword {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"message":"FATAL: password authentication failed for user \"user\""}`))
+				return
+			}
+			_, _ = w.Write([]byte("[]"))
+		default:
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+
+	return stub
+}
+
+// counts returns how many sources were created in total and how many still exist
+func (s *airbyteStub) counts() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.created, len(s.sources)
+}
+
+// configuration returns the configuration of a source
+func (s *airbyteStub) configuration(sourceId string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sources[sourceId]
+}
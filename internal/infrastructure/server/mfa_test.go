@@ -0,0 +1,206 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mfaDomain "github.com/darksuei/suei-intelligence/internal/domain/mfa"
+)
+
+// totp returns the current TOTP code of a secret
+func totp(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	code, err := mfaDomain.GenerateTOTP(secret, at)
+	if err != nil {
+		t.Fatalf("failed to generate totp code: %v", err)
+	}
+
+	return fmt.Sprintf("%06d", code)
+}
+
+// secretOf returns the secret of an otpauth:// URI
+func secretOf(t *testing.T, status int, body response) string {
+	t.Helper()
+
+	if status != http.StatusCreated {
+		t.Fatalf("failed to retrieve totp uri (%d): %s", status, body)
+	}
+
+	uri, _ := body["uri"].(string)
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Query().Get("secret") == "" {
+		t.Fatalf("invalid totp uri: %s", uri)
+	}
+
+	return parsed.Query().Get("secret")
+}
+
+func TestMFA(t *testing.T) {
+	const (
+		email    = "mfa@example.com"
+		password = "Passw0rd!mfa"
+	)
+
+	newAccount(t, email, "GUEST", password)
+
+	// A code of another time window, which no clock drift allowance accepts
+	stale := func(secret string) string { return totp(t, secret, time.Now().Add(-time.Hour)) }
+
+	var secret string
+	var recoveryCodes []interface{}
+
+	t.Run("enrolls with a TOTP code", func(t *testing.T) {
+		status, body := request("POST", "/mfa/totp-uri", nil, map[string]string{"email": email, "password": password})
+		secret = secretOf(t, status, body)
+
+		status, body = request("POST", "/mfa/confirm", nil, map[string]string{"email": email, "password": password, "code": totp(t, secret, time.Now())})
+		if status != http.StatusOK {
+			t.Fatalf("failed to confirm mfa (%d): %s", status, body)
+		}
+
+		recoveryCodes, _ = body["recovery_codes"].([]interface{})
+		if len(recoveryCodes) != mfaDomain.RecoveryCodeCount {
+			t.Fatalf("%d recovery codes were issued, want %d", len(recoveryCodes), mfaDomain.RecoveryCodeCount)
+		}
+	})
+
+	logins := []struct {
+		name       string
+		code       func() string
+		recovery   func() string
+		wantStatus int
+	}{
+		{name: "rejects a wrong TOTP code", code: func() string { return stale(secret) }, wantStatus: http.StatusBadRequest},
+		{name: "rejects a code that is not a number", code: func() string { return "abcdef" }, wantStatus: http.StatusBadRequest},
+		{name: "signs in with a TOTP code", code: func() string { return totp(t, secret, time.Now()) }, wantStatus: http.StatusOK},
+		{name: "signs in with a recovery code", recovery: func() string { return recoveryCodes[0].(string) }, wantStatus: http.StatusOK},
+		{name: "rejects a spent recovery code", recovery: func() string { return recoveryCodes[0].(string) }, wantStatus: http.StatusBadRequest},
+		{name: "accepts recovery codes regardless of case and dashes", recovery: func() string {
+			return strings.ToUpper(strings.ReplaceAll(recoveryCodes[1].(string), "-", ""))
+		}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range logins {
+		t.Run(tt.name, func(t *testing.T) {
+			req := map[string]string{"challenge_id": beginMFA(t, email, password, "[totp]")}
+
+			if tt.code != nil {
+				req["code"] = tt.code()
+			}
+			if tt.recovery != nil {
+				req["recovery_code"] = tt.recovery()
+			}
+
+			status, body := request("POST", "/auth/mfa", nil, req)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if status == http.StatusOK && body["access_token"] == nil {
+				t.Fatalf("mfa returned no access token: %s", body)
+			}
+		})
+	}
+
+	t.Run("rotates the secret on re-enrollment", func(t *testing.T) {
+		headers := mfaLogin(t, email, password, totp(t, secret, time.Now()))
+
+		status, body := request("POST", "/mfa/enroll", headers, map[string]string{"password": password})
+		if status != http.StatusBadRequest {
+			t.Fatalf("re-enrollment without a code: status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+
+		status, body = request("POST", "/mfa/enroll", headers, map[string]string{"password": password, "code": totp(t, secret, time.Now())})
+		rotated := secretOf(t, status, body)
+
+		// The current secret stays in use until the new one is confirmed
+		status, body = request("POST", "/mfa/enroll/confirm", headers, map[string]string{"code": stale(rotated)})
+		if status != http.StatusBadRequest {
+			t.Fatalf("confirmation with a wrong code: status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+
+		status, body = request("POST", "/mfa/enroll/confirm", headers, map[string]string{"code": totp(t, rotated, time.Now())})
+		if status != http.StatusOK {
+			t.Fatalf("failed to confirm re-enrollment (%d): %s", status, body)
+		}
+
+		status, body = request("POST", "/auth/mfa", nil, map[string]string{"challenge_id": beginMFA(t, email, password, "[totp]"), "code": totp(t, secret, time.Now())})
+		if status != http.StatusBadRequest {
+			t.Fatalf("code of the rotated-out secret: status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+
+		secret = rotated
+		mfaLogin(t, email, password, totp(t, secret, time.Now()))
+	})
+
+	t.Run("disables MFA", func(t *testing.T) {
+		headers := mfaLogin(t, email, password, totp(t, secret, time.Now()))
+
+		status, body := request("POST", "/mfa/disable", headers, map[string]string{"password": password, "code": stale(secret)})
+		if status != http.StatusBadRequest {
+			t.Fatalf("disable with a wrong code: status = %d, want %d: %s", status, http.StatusBadRequest, body)
+		}
+
+		status, body = request("POST", "/mfa/disable", headers, map[string]string{"password": password, "code": totp(t, secret, time.Now())})
+		if status != http.StatusOK {
+			t.Fatalf("failed to disable mfa (%d): %s", status, body)
+		}
+
+		login(t, email, password)
+	})
+
+	t.Run("resets MFA on request of an admin", func(t *testing.T) {
+		status, body := request("POST", "/mfa/totp-uri", nil, map[string]string{"email": email, "password": password})
+		secret = secretOf(t, status, body)
+
+		status, body = request("POST", "/mfa/confirm", nil, map[string]string{"email": email, "password": password, "code": totp(t, secret, time.Now())})
+		if status != http.StatusOK {
+			t.Fatalf("failed to confirm mfa (%d): %s", status, body)
+		}
+
+		beginMFA(t, email, password, "[totp]")
+
+		status, body = request("POST", "/account/mfa/reset?email="+email, login(t, rootEmail, rootPassword), nil)
+		if status != http.StatusOK {
+			t.Fatalf("failed to reset mfa (%d): %s", status, body)
+		}
+
+		login(t, email, password)
+	})
+}
+
+// mfaLogin signs in with a password and a TOTP code and returns the Authorization header
+func mfaLogin(t *testing.T, email string, password string, code string) map[string]string {
+	t.Helper()
+
+	status, body := request("POST", "/auth/mfa", nil, map[string]string{"challenge_id": beginMFA(t, email, password, "[totp]"), "code": code})
+	if status != http.StatusOK {
+		t.Fatalf("mfa failed (%d): %s", status, body)
+	}
+
+	token, _ := body["access_token"].(string)
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// beginMFA signs in with a password and returns the MFA challenge of the login, which
+// must offer the given methods
+func beginMFA(t *testing.T, email string, password string, methods string) string {
+	t.Helper()
+
+	status, body := request("POST", "/auth/login", nil, map[string]string{"email": email, "password": password})
+	if status != http.StatusOK || body["mfa_required"] != true {
+		t.Fatalf("login required no mfa (%d): %s", status, body)
+	}
+
+	if got := fmt.Sprint(body["mfa_methods"]); got != methods {
+		t.Fatalf("mfa methods = %s, want %s", got, methods)
+	}
+
+	challengeID, _ := body["challenge_id"].(string)
+	return challengeID
+}
@@ -5,14 +5,26 @@ import (
 	"net/http"
 	"strings"
 
+	apiKeyService "github.com/darksuei/suei-intelligence/internal/application/apikey"
+	"github.com/darksuei/suei-intelligence/internal/application/authentication"
+	signingkeyService "github.com/darksuei/suei-intelligence/internal/application/signingkey"
 	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/apikey"
+	authenticationDomain "github.com/darksuei/suei-intelligence/internal/domain/authentication"
+	"github.com/darksuei/suei-intelligence/internal/domain/signingkey"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware validates JWT and sets user info in context
+// AuthMiddleware validates JWT and sets user info in context.
+// Project API keys are accepted too, either as a Bearer token or in the X-API-Key header
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			authenticateAPIKey(c, key)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid auth header"})
@@ -21,12 +33,30 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
+		if apikey.IsAPIKey(tokenString) {
+			authenticateAPIKey(c, tokenString)
+			return
+		}
+
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			keyId, _ := token.Header["kid"].(string)
+
+			_signingKey, publicKey, err := signingkeyService.VerificationKey(keyId)
+			if err != nil {
+				return nil, err
+			}
+
+			// The token must be signed with the algorithm of the key it names
+			if token.Method.Alg() != string(_signingKey.Algorithm) {
 				return nil, errors.New("invalid signing method")
 			}
-			return []byte(config.Common().JWTSecret), nil
-		})
+			return publicKey, nil
+		},
+			jwt.WithValidMethods([]string{string(signingkey.AlgorithmRS256), string(signingkey.AlgorithmEdDSA)}),
+			jwt.WithIssuer(config.JWT().JWTIssuer),
+			jwt.WithAudience(config.JWT().JWTAudience),
+			jwt.WithExpirationRequired(),
+		)
 		if err != nil || !token.Valid {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
@@ -50,10 +80,46 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		organization, ok := claims["org"].(string)
+		if !ok || organization == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing org claim"})
+			return
+		}
+
+		// Reject tokens revoked since they were issued
+		accessToken := authenticationDomain.ParseAccessTokenClaims(claims)
+
+		if err := authentication.ValidateAccessToken(accessToken, config.Database()); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			return
+		}
+
 		c.Set("userID", userID)
 		c.Set("email", email)
+		c.Set("organization", organization)
+		if sessionID, ok := claims["sid"]; ok {
+			c.Set("sessionID", sessionID)
+		}
+		c.Set("accessToken", accessToken)
 		c.Set("roles", claims["roles"].([]interface{})) // can be empty
 		
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// authenticateAPIKey sets the key's project role in context. Keys do not act as an account,
+// so no userID is set
+func authenticateAPIKey(c *gin.Context, key string) {
+	_key, organization, err := apiKeyService.AuthenticateKey(key, config.Database())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+		return
+	}
+
+	c.Set("email", "apikey:"+_key.Prefix)
+	c.Set("organization", organization)
+	c.Set("apiKey", _key)
+	c.Set("roles", []string{_key.BuildRoleKey()})
+
+	c.Next()
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	scimService "github.com/darksuei/suei-intelligence/internal/application/scim"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	"github.com/darksuei/suei-intelligence/internal/domain/scim"
+)
+
+// SCIMAuthMiddleware validates a SCIM bearer token and sets the organization it provisions in context
+func SCIMAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+			abortSCIM(c, "missing or invalid auth header")
+			return
+		}
+
+		organization, token, err := scimService.AuthenticateToken(strings.TrimPrefix(authHeader, "Bearer "), config.Database())
+		if err != nil {
+			abortSCIM(c, "invalid token")
+			return
+		}
+
+		c.Set("organization", organization)
+		c.Set("scimToken", token)
+
+		c.Next()
+	}
+}
+
+func abortSCIM(c *gin.Context, detail string) {
+	c.Header("Content-Type", "application/scim+json")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"schemas": []string{scim.SchemaError},
+		"status": "401",
+		"detail": detail,
+	})
+}
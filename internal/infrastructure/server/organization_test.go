@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newOrganization creates another organization as root, with a first SUPERADMIN signing in
+// with the given email and password
+func newOrganization(t *testing.T, name string, adminEmail string, password string) {
+	t.Helper()
+
+	status, body := request("POST", "/organization", login(t, rootEmail, rootPassword), map[string]interface{}{
+		"name": name,
+		"scope": "PRIVATE",
+		"admin": map[string]string{"name": strings.Split(adminEmail, "@")[0], "email": adminEmail, "password": password},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create organization (%d): %s", status, body)
+	}
+}
+
+func TestNewOrganization(t *testing.T) {
+	const password = "Passw0rd!organization"
+
+	admin := "organization-admin@example.com"
+	newAccount(t, admin, "SUPERADMIN", password)
+
+	body := func(adminEmail string) map[string]interface{} {
+		return map[string]interface{}{
+			"name": "Tenant",
+			"scope": "PRIVATE",
+			"admin": map[string]string{"name": strings.Split(adminEmail, "@")[0], "email": adminEmail, "password": password},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{name: "anonymous callers cannot create organizations", body: body("tenant-1@example.com"), wantStatus: http.StatusUnauthorized},
+		{name: "superadmins who do not operate the instance cannot create organizations", headers: login(t, admin, password), body: body("tenant-2@example.com"), wantStatus: http.StatusForbidden},
+		{name: "rejects a registered email", headers: login(t, rootEmail, rootPassword), body: body(admin), wantStatus: http.StatusBadRequest},
+		{name: "instance operators create organizations", headers: login(t, rootEmail, rootPassword), body: body("tenant-3@example.com"), wantStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("POST", "/organization", tt.headers, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+		})
+	}
+
+	t.Run("the first superadmin manages the new organization", func(t *testing.T) {
+		status, body := request("GET", "/organization", login(t, "tenant-3@example.com", password), nil)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+		}
+
+		if _organization, _ := body["organization"].(map[string]interface{}); _organization["Name"] != "Tenant" {
+			t.Fatalf("organization = %v, want Tenant", _organization)
+		}
+	})
+}
+
+func TestOrganizationIsolation(t *testing.T) {
+	const password = "Passw0rd!isolation"
+
+	guest := "isolation-guest@example.com"
+	otherGuest := "isolation-other-guest@example.com"
+	otherAdmin := "isolation-other-admin@example.com"
+
+	newAccount(t, guest, "GUEST", password)
+	createProject(t, "isolation")
+
+	newOrganization(t, "Isolation", otherAdmin, password)
+	inviteAccount(t, login(t, otherAdmin, password), otherGuest, "GUEST", password)
+
+	createOwnProject := func(t *testing.T) {
+		status, body := request("POST", "/project", login(t, otherAdmin, password), map[string]string{
+			"name": "Other isolation",
+			"key": "otherisolation",
+			"description": "test project",
+			"stage": "SANDBOX",
+			"businessDomain": "test",
+		})
+		if status != http.StatusCreated {
+			t.Fatalf("failed to create project (%d): %s", status, body)
+		}
+	}
+
+	createOwnProject(t)
+
+	tests := []struct {
+		name       string
+		email      string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+	}{
+		{name: "a guest reads a project of its organization", email: guest, method: "GET", path: "/project/isolation", wantStatus: http.StatusOK},
+		{name: "a guest of another organization cannot read the project", email: otherGuest, method: "GET", path: "/project/isolation", wantStatus: http.StatusNotFound},
+		{name: "a guest cannot read a project of another organization", email: guest, method: "GET", path: "/project/otherisolation", wantStatus: http.StatusNotFound},
+		{name: "a superadmin of another organization cannot read the project", email: otherAdmin, method: "GET", path: "/project/isolation", wantStatus: http.StatusNotFound},
+		{name: "a superadmin of another organization cannot join the project", email: otherAdmin, method: "PUT", path: "/project/isolation/members", body: map[string]string{"email": otherAdmin, "role": "OWNER"}, wantStatus: http.StatusNotFound},
+		{name: "a superadmin of another organization cannot read its accounts", email: otherAdmin, method: "GET", path: "/account?email=" + rootEmail, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request(tt.method, tt.path, login(t, tt.email, password), tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+		})
+	}
+
+	t.Run("lists the projects of the organization only", func(t *testing.T) {
+		status, body := request("GET", "/projects", login(t, otherGuest, password), nil)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+		}
+
+		if projects := body.String(); strings.Contains(projects, `"isolation"`) || !strings.Contains(projects, `"otherisolation"`) {
+			t.Fatalf("projects = %s, want otherisolation only", projects)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestChangePassword(t *testing.T) {
+	const password = "Passw0rd!change"
+	const newPassword = "Passw0rd!changed"
+
+	email := "change-password@example.com"
+	newAccount(t, email, "GUEST", password)
+
+	headers, refreshToken := signIn(t, email, password)
+
+	tests := []struct {
+		name       string
+		body       map[string]string
+		wantStatus int
+	}{
+		{name: "rejects a wrong current password", body: map[string]string{"currentPassword": "Passw0rd!wrong", "newPassword": newPassword}, wantStatus: http.StatusBadRequest},
+		{name: "rejects a weak password", body: map[string]string{"currentPassword": password, "newPassword": "weak"}, wantStatus: http.StatusBadRequest},
+		{name: "changes the password", body: map[string]string{"currentPassword": password, "newPassword": newPassword}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("PUT", "/account/password", headers, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+		})
+	}
+
+	t.Run("revokes existing refresh tokens", func(t *testing.T) {
+		if status, body := request("POST", "/auth/refresh-token", nil, map[string]string{"refresh_token": refreshToken}); status == http.StatusOK {
+			t.Fatalf("refresh token issued before the change: status = %d: %s", status, body)
+		}
+	})
+
+	t.Run("signs in with the new password only", func(t *testing.T) {
+		if status, _ := request("POST", "/auth/login", nil, map[string]string{"email": email, "password": password}); status == http.StatusOK {
+			t.Fatal("the old password still signs in")
+		}
+
+		login(t, email, newPassword)
+	})
+}
+
+func TestChangePasswordLockout(t *testing.T) {
+	const password = "Passw0rd!guess"
+
+	email := "change-password-lockout@example.com"
+	newAccount(t, email, "GUEST", password)
+
+	headers := login(t, email, password)
+	headers["X-Forwarded-For"] = "198.51.100.20"
+
+	change := func(currentPassword string) (int, response) {
+		return request("PUT", "/account/password", headers, map[string]string{"currentPassword": currentPassword, "newPassword": "Passw0rd!guessed"})
+	}
+
+	for i := 0; i < 5; i++ {
+		if status, body := change("Passw0rd!wrong"); status != http.StatusBadRequest {
+			t.Fatalf("attempt %d: status = %d, want %d: %s", i+1, status, http.StatusBadRequest, body)
+		}
+	}
+
+	t.Run("locks the account after repeated wrong passwords", func(t *testing.T) {
+		if status, body := change(password); status != http.StatusTooManyRequests {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusTooManyRequests, body)
+		}
+
+		if status, body := request("POST", "/auth/login", map[string]string{"X-Forwarded-For": "198.51.100.21"}, map[string]string{"email": email, "password": password}); status != http.StatusTooManyRequests {
+			t.Fatalf("sign-in: status = %d, want %d: %s", status, http.StatusTooManyRequests, body)
+		}
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	const password = "Passw0rd!reset"
+	const newPassword = "Passw0rd!resetted"
+
+	email := "reset-password@example.com"
+	newAccount(t, email, "GUEST", password)
+
+	_, refreshToken := signIn(t, email, password)
+
+	t.Run("does not reveal unknown accounts", func(t *testing.T) {
+		if status, body := request("POST", "/auth/forgot-password", nil, map[string]string{"email": "reset-unknown@example.com"}); status != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+		}
+	})
+
+	if status, body := request("POST", "/auth/forgot-password", nil, map[string]string{"email": email}); status != http.StatusOK {
+		t.Fatalf("failed to request a reset (%d): %s", status, body)
+	}
+
+	token := notifiedToken(t, email, resetToken)
+
+	tests := []struct {
+		name       string
+		body       map[string]string
+		wantStatus int
+	}{
+		{name: "rejects an unknown token", body: map[string]string{"token": "unknown", "password": newPassword}, wantStatus: http.StatusBadRequest},
+		{name: "rejects a weak password", body: map[string]string{"token": token, "password": "weak"}, wantStatus: http.StatusBadRequest},
+		{name: "resets the password", body: map[string]string{"token": token, "password": newPassword}, wantStatus: http.StatusOK},
+		{name: "consumes the token", body: map[string]string{"token": token, "password": "Passw0rd!again"}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("POST", "/auth/reset-password", nil, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+		})
+	}
+
+	t.Run("revokes existing refresh tokens", func(t *testing.T) {
+		if status, body := request("POST", "/auth/refresh-token", nil, map[string]string{"refresh_token": refreshToken}); status == http.StatusOK {
+			t.Fatalf("refresh token issued before the reset: status = %d: %s", status, body)
+		}
+	})
+
+	t.Run("signs in with the new password", func(t *testing.T) {
+		login(t, email, newPassword)
+	})
+}
@@ -0,0 +1,94 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestProjectStatus(t *testing.T) {
+	const (
+		viewer   = "lifecycle-viewer@example.com"
+		password = "Passw0rd!lifecycle"
+	)
+
+	createProject(t, "lifecycle")
+	newAccount(t, viewer, "GUEST", password)
+
+	root := login(t, rootEmail, rootPassword)
+
+	status, body := request("PUT", "/project/lifecycle/members", root, map[string]string{"email": viewer, "role": "VIEWER"})
+	if status != http.StatusOK && status != http.StatusCreated {
+		t.Fatalf("failed to grant project role (%d): %s", status, body)
+	}
+
+	status, body = request("POST", "/project/lifecycle/datasources", root, postgresDatasource)
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create datasource (%d): %s", status, body)
+	}
+
+	created, _ := body["datasource"].(map[string]interface{})
+	id, _ := created["ID"].(float64)
+	sourceId, _ := created["SourceID"].(string)
+	path := fmt.Sprintf("/project/lifecycle/datasources/%v", id)
+
+	t.Run("suspends datasource syncs while paused", func(t *testing.T) {
+		status, body := request("POST", "/project/lifecycle/pause", root, nil)
+		expectStatus(t, "pause: "+body.String(), status, http.StatusOK)
+
+		createdBefore, _ := airbyte.counts()
+
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			body   interface{}
+		}{
+			{name: "create", method: "POST", path: "/project/lifecycle/datasources", body: postgresDatasource},
+			{name: "update", method: "PUT", path: path, body: map[string]interface{}{"configuration": created["Configuration"]}},
+			{name: "test", method: "POST", path: path + "/test"},
+			{name: "source schema", method: "GET", path: path + "/source-schema-definition"},
+		}
+
+		for _, tt := range tests {
+			status, body := request(tt.method, tt.path, root, tt.body)
+			expectStatus(t, tt.name+": "+body.String(), status, http.StatusConflict)
+		}
+
+		if createdAfter, _ := airbyte.counts(); createdAfter != createdBefore {
+			t.Fatalf("sources created = %d, want %d", createdAfter, createdBefore)
+		}
+		if host := airbyte.configuration(sourceId)["host"]; host != "db.local" {
+			t.Fatalf("source host = %v, want db.local", host)
+		}
+	})
+
+	t.Run("resumes datasource syncs", func(t *testing.T) {
+		status, body := request("POST", "/project/lifecycle/resume", root, nil)
+		expectStatus(t, "resume: "+body.String(), status, http.StatusOK)
+
+		status, body = request("POST", path+"/test", root, nil)
+		expectStatus(t, "test: "+body.String(), status, http.StatusOK)
+	})
+
+	t.Run("keeps members of archived projects", func(t *testing.T) {
+		status, body := request("POST", "/project/lifecycle/archive", root, nil)
+		expectStatus(t, "archive: "+body.String(), status, http.StatusOK)
+
+		status, body = request("DELETE", "/project/lifecycle/members?email="+viewer, root, nil)
+		expectStatus(t, "revoke: "+body.String(), status, http.StatusConflict)
+
+		status, body = request("GET", "/project/lifecycle/members", root, nil)
+		expectStatus(t, "members: "+body.String(), status, http.StatusOK)
+
+		kept := false
+		members, _ := body["members"].([]interface{})
+		for _, member := range members {
+			_account, _ := member.(map[string]interface{})["account"].(map[string]interface{})
+			kept = kept || _account["Email"] == viewer
+		}
+		if !kept {
+			t.Fatalf("members = %v, want the viewer", members)
+		}
+	})
+}
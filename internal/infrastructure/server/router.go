@@ -1,6 +1,9 @@
 package server
 
 import (
+	"log"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
 	"github.com/darksuei/suei-intelligence/internal/infrastructure/server/handlers"
 	middleware "github.com/darksuei/suei-intelligence/internal/infrastructure/server/middlewares"
 	"github.com/gin-contrib/cors"
@@ -10,6 +13,11 @@ import (
 func InitializeRouter() *gin.Engine {
 	router := gin.Default()
 
+	// Client IPs are only read from X-Forwarded-For when set by a trusted proxy
+	if err := router.SetTrustedProxies(config.Common().TrustedProxies); err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
+
 	// Cors Settings
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
@@ -22,34 +30,82 @@ func InitializeRouter() *gin.Engine {
 	// Health
 	router.GET("/health", handlers.Health)
 
+	// Access token signing keys
+	router.GET("/.well-known/jwks.json", handlers.RetrieveJWKS)
+
 	// Config
 	router.GET("/config", handlers.RetrieveConfig)
 
+	// Setup
+	router.POST("/setup", handlers.Setup)
+
 	// Language Settings
 	router.GET("/supported-languages", handlers.SupportedLanguages)
-	router.PUT("/set-language", handlers.SetLanguagePreference)
-	router.GET("/get-language", handlers.RetrieveLanguagePreference)
+	router.PUT("/set-language", middleware.AuthMiddleware(), handlers.SetLanguagePreference)
+	router.GET("/get-language", middleware.AuthMiddleware(), handlers.RetrieveLanguagePreference)
 
 	// Organization
-	router.POST("/organization", handlers.NewOrganization)
-	router.PUT("/organization", handlers.UpdateOrganization)
-	router.GET("/organization", handlers.RetrieveOrganization)
+	router.POST("/organization", middleware.AuthMiddleware(), handlers.NewOrganization)
+	router.PUT("/organization", middleware.AuthMiddleware(), handlers.UpdateOrganization)
+	router.GET("/organization", middleware.AuthMiddleware(), handlers.RetrieveOrganization)
+	router.GET("/organization/sso", middleware.AuthMiddleware(), handlers.RetrieveSSOProvider)
+	router.PUT("/organization/sso", middleware.AuthMiddleware(), handlers.ConfigureSSOProvider)
+	router.DELETE("/organization/sso", middleware.AuthMiddleware(), handlers.DeleteSSOProvider)
+	router.GET("/organization/scim/tokens", middleware.AuthMiddleware(), handlers.RetrieveSCIMTokens)
+	router.POST("/organization/scim/tokens", middleware.AuthMiddleware(), handlers.NewSCIMToken)
+	router.DELETE("/organization/scim/tokens/:id", middleware.AuthMiddleware(), handlers.DeleteSCIMToken)
+	router.GET("/organization/scim/groups", middleware.AuthMiddleware(), handlers.RetrieveDirectoryGroups)
+	router.PUT("/organization/scim/groups/:id/roles", middleware.AuthMiddleware(), handlers.UpdateDirectoryGroupRoles)
 
 	// Account
-	router.POST("/account", handlers.NewAccount)
-	router.GET("/account", handlers.RetrieveAccountByEmail)
-	router.PUT("/account", handlers.UpdateAccount)
+	router.GET("/account", middleware.AuthMiddleware(), handlers.RetrieveAccountByEmail)
+	router.PUT("/account", middleware.AuthMiddleware(), handlers.UpdateAccount)
+	router.PUT("/account/password", middleware.AuthMiddleware(), handlers.ChangePassword)
+	router.POST("/account/unlock", middleware.AuthMiddleware(), handlers.UnlockAccount)
+	router.POST("/account/mfa/reset", middleware.AuthMiddleware(), handlers.ResetMFA)
+	router.POST("/account/disable", middleware.AuthMiddleware(), handlers.DisableAccount)
+	router.POST("/account/enable", middleware.AuthMiddleware(), handlers.EnableAccount)
+	router.POST("/account/transfer", middleware.AuthMiddleware(), handlers.TransferOwnership)
+	router.DELETE("/account", middleware.AuthMiddleware(), handlers.DeleteAccount)
 	router.GET("/accounts", middleware.AuthMiddleware(), handlers.RetrieveAccounts)
 
+	// Invitations
+	router.POST("/invitations", middleware.AuthMiddleware(), handlers.NewInvitation)
+	router.GET("/invitations", middleware.AuthMiddleware(), handlers.RetrieveInvitations)
+	router.DELETE("/invitations/:id", middleware.AuthMiddleware(), handlers.RevokeInvitation)
+	router.GET("/invitations/accept", handlers.RetrieveInvitationByToken)
+	router.POST("/invitations/accept", handlers.AcceptInvitation)
+
 	// MFA
 	router.POST("/mfa/totp-uri", handlers.RetrieveTotpURI)
 	router.POST("/mfa/confirm", handlers.ConfirmMFA)
+	router.GET("/mfa/recovery-codes", middleware.AuthMiddleware(), handlers.RetrieveRecoveryCodeCount)
+	router.POST("/mfa/recovery-codes", middleware.AuthMiddleware(), handlers.RegenerateRecoveryCodes)
+	router.POST("/mfa/enroll", middleware.AuthMiddleware(), handlers.StartMFAEnrollment)
+	router.POST("/mfa/enroll/confirm", middleware.AuthMiddleware(), handlers.ConfirmMFAEnrollment)
+	router.POST("/mfa/disable", middleware.AuthMiddleware(), handlers.DisableMFA)
+
+	// MFA - security keys
+	router.GET("/mfa/webauthn/credentials", middleware.AuthMiddleware(), handlers.RetrieveWebAuthnCredentials)
+	router.POST("/mfa/webauthn/register/options", middleware.AuthMiddleware(), handlers.BeginWebAuthnRegistration)
+	router.POST("/mfa/webauthn/register", middleware.AuthMiddleware(), handlers.FinishWebAuthnRegistration)
+	router.PUT("/mfa/webauthn/credentials/:id", middleware.AuthMiddleware(), handlers.RenameWebAuthnCredential)
+	router.DELETE("/mfa/webauthn/credentials/:id", middleware.AuthMiddleware(), handlers.DeleteWebAuthnCredential)
 
 	// Auth
 	router.POST("/auth/login", handlers.Login)
 	router.POST("/auth/mfa", handlers.MFA)
+	router.POST("/auth/mfa/webauthn/options", handlers.WebAuthnAssertionOptions)
 	router.POST("/auth/refresh-token", handlers.RefreshToken)
 	router.POST("/auth/revoke-token", handlers.RevokeToken)
+	router.POST("/auth/logout", middleware.AuthMiddleware(), handlers.Logout)
+	router.GET("/auth/sessions", middleware.AuthMiddleware(), handlers.RetrieveSessions)
+	router.DELETE("/auth/sessions", middleware.AuthMiddleware(), handlers.RevokeSessions)
+	router.DELETE("/auth/sessions/:id", middleware.AuthMiddleware(), handlers.RevokeSession)
+	router.POST("/auth/forgot-password", handlers.ForgotPassword)
+	router.POST("/auth/reset-password", handlers.ResetPassword)
+	router.GET("/auth/sso/callback", handlers.SSOCallback)
+	router.GET("/auth/sso/:organizationKey", handlers.SSOLogin)
 
 	// Project
 	router.POST("/project", middleware.AuthMiddleware(), handlers.NewProject)
@@ -57,12 +113,35 @@ func InitializeRouter() *gin.Engine {
 	router.PUT("/project/:key", middleware.AuthMiddleware(), handlers.UpdateProject)
 	router.GET("/projects", middleware.AuthMiddleware(), handlers.RetrieveProjects)
 
+	// Project - lifecycle
+	router.POST("/project/:key/pause", middleware.AuthMiddleware(), handlers.PauseProject)
+	router.POST("/project/:key/resume", middleware.AuthMiddleware(), handlers.ResumeProject)
+	router.POST("/project/:key/archive", middleware.AuthMiddleware(), handlers.ArchiveProject)
+	router.POST("/project/:key/restore", middleware.AuthMiddleware(), handlers.RestoreProject)
+
+	// Project - members
+	router.GET("/project/:key/members", middleware.AuthMiddleware(), handlers.RetrieveProjectMembers)
+	router.PUT("/project/:key/members", middleware.AuthMiddleware(), handlers.GrantProjectRole)
+	router.DELETE("/project/:key/members", middleware.AuthMiddleware(), handlers.RevokeProjectRole)
+
+	// Project - API keys
+	router.GET("/project/:key/api-keys", middleware.AuthMiddleware(), handlers.RetrieveAPIKeys)
+	router.POST("/project/:key/api-keys", middleware.AuthMiddleware(), handlers.NewAPIKey)
+	router.DELETE("/project/:key/api-keys/:id", middleware.AuthMiddleware(), handlers.RevokeAPIKey)
+
 	// Datasource
 	router.GET("/supported-datasources", middleware.AuthMiddleware(), handlers.SupportedDatasources)
 	router.GET("/supported-datasources/:sourceType", middleware.AuthMiddleware(), handlers.SupportedDatasource)
 	router.POST("/project/:key/datasources", middleware.AuthMiddleware(), handlers.NewDatasource)
 	router.GET("/project/:key/datasources", middleware.AuthMiddleware(), handlers.RetrieveDatasources)
+	router.PUT("/project/:key/datasources/:id", middleware.AuthMiddleware(), handlers.UpdateDatasource)
 	router.DELETE("/project/:key/datasources/:id", middleware.AuthMiddleware(), handlers.DeleteDatasource)
+	router.POST("/project/:key/datasources/:id/test", middleware.AuthMiddleware(), handlers.TestDatasource)
+
+	// Datasource - trash
+	router.GET("/project/:key/datasources/trash", middleware.AuthMiddleware(), handlers.RetrieveDeletedDatasources)
+	router.POST("/project/:key/datasources/:id/restore", middleware.AuthMiddleware(), handlers.RestoreDatasource)
+	router.DELETE("/project/:key/datasources/:id/purge", middleware.AuthMiddleware(), handlers.PurgeDatasource)
 
 	// Datasource - schemas
 	router.GET("/internal-schema-definition", middleware.AuthMiddleware(), handlers.RetrieveInternalSchema)
@@ -70,6 +149,42 @@ func InitializeRouter() *gin.Engine {
 	router.GET("/project/:key/datasources/:id/schema-mapping", middleware.AuthMiddleware(), handlers.RetrieveDatasourceSchemaMapping)
 	router.PUT("/project/:key/datasources/:id/schema-mapping", middleware.AuthMiddleware(), handlers.UpdateDatasourceSchemaMapping)
 
+	// Authorization - policies
+	router.GET("/authorization/policies", middleware.AuthMiddleware(), handlers.RetrievePolicies)
+	router.POST("/authorization/policies", middleware.AuthMiddleware(), handlers.AddPolicy)
+	router.DELETE("/authorization/policies", middleware.AuthMiddleware(), handlers.RemovePolicy)
+	router.GET("/authorization/role-inheritance", middleware.AuthMiddleware(), handlers.RetrieveRoleInheritance)
+	router.POST("/authorization/role-inheritance", middleware.AuthMiddleware(), handlers.AddRoleInheritance)
+	router.DELETE("/authorization/role-inheritance", middleware.AuthMiddleware(), handlers.RemoveRoleInheritance)
+
+	// Authorization - custom roles
+	router.GET("/roles", middleware.AuthMiddleware(), handlers.RetrieveRoles)
+	router.POST("/roles", middleware.AuthMiddleware(), handlers.NewRole)
+	router.GET("/roles/:key", middleware.AuthMiddleware(), handlers.RetrieveRole)
+	router.PUT("/roles/:key", middleware.AuthMiddleware(), handlers.UpdateRole)
+	router.DELETE("/roles/:key", middleware.AuthMiddleware(), handlers.DeleteRole)
+	router.PUT("/roles/:key/assignments", middleware.AuthMiddleware(), handlers.AssignRole)
+	router.DELETE("/roles/:key/assignments", middleware.AuthMiddleware(), handlers.UnassignRole)
+
+	// SCIM
+	router.GET("/scim/v2/ServiceProviderConfig", middleware.SCIMAuthMiddleware(), handlers.SCIMServiceProviderConfig)
+	router.GET("/scim/v2/Users", middleware.SCIMAuthMiddleware(), handlers.SCIMRetrieveUsers)
+	router.POST("/scim/v2/Users", middleware.SCIMAuthMiddleware(), handlers.SCIMNewUser)
+	router.GET("/scim/v2/Users/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMRetrieveUser)
+	router.PUT("/scim/v2/Users/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMReplaceUser)
+	router.PATCH("/scim/v2/Users/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMPatchUser)
+	router.DELETE("/scim/v2/Users/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMDeleteUser)
+	router.GET("/scim/v2/Groups", middleware.SCIMAuthMiddleware(), handlers.SCIMRetrieveGroups)
+	router.POST("/scim/v2/Groups", middleware.SCIMAuthMiddleware(), handlers.SCIMNewGroup)
+	router.GET("/scim/v2/Groups/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMRetrieveGroup)
+	router.PUT("/scim/v2/Groups/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMReplaceGroup)
+	router.PATCH("/scim/v2/Groups/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMPatchGroup)
+	router.DELETE("/scim/v2/Groups/:id", middleware.SCIMAuthMiddleware(), handlers.SCIMDeleteGroup)
+
+	// Audit
+	router.GET("/audit-events", middleware.AuthMiddleware(), handlers.RetrieveAuditEvents)
+	router.GET("/audit-events/export", middleware.AuthMiddleware(), handlers.ExportAuditEvents)
+
 	// Metrics
 	handlers.MetricsHandler(router)
 
@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	scimDomain "github.com/darksuei/suei-intelligence/internal/domain/scim"
+)
+
+func TestSCIMProvisioning(t *testing.T) {
+	const email = "scim-ada@example.com"
+
+	status, body := request("POST", "/organization/scim/tokens", login(t, rootEmail, rootPassword), map[string]string{"name": "directory"})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create scim token (%d): %s", status, body)
+	}
+
+	token, _ := body["token"].(string)
+	directory := map[string]string{"Authorization": "Bearer " + token}
+
+	if status, _ := request("GET", "/scim/v2/Users", map[string]string{"Authorization": "Bearer scim_invalid"}, nil); status != http.StatusUnauthorized {
+		t.Fatalf("invalid token: status = %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	status, body = request("POST", "/scim/v2/Users", directory, map[string]interface{}{
+		"schemas": []string{scimDomain.SchemaUser},
+		"userName": email,
+		"name": map[string]string{"givenName": "Ada", "familyName": "Lovelace"},
+		"emails": []map[string]interface{}{{"value": email, "type": "work", "primary": true}},
+		"active": true,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to provision user (%d): %s", status, body)
+	}
+
+	userId, _ := body["id"].(string)
+
+	filters := []struct {
+		filter      string
+		wantStatus  int
+		wantResults float64
+	}{
+		{filter: `userName eq "` + email + `"`, wantStatus: http.StatusOK, wantResults: 1},
+		{filter: `userName eq "SCIM-ADA@EXAMPLE.COM" and active eq true`, wantStatus: http.StatusOK, wantResults: 1},
+		{filter: `emails.type eq "home"`, wantStatus: http.StatusOK, wantResults: 0},
+		{filter: `userName eq`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range filters {
+		t.Run("filter "+tt.filter, func(t *testing.T) {
+			status, body := request("GET", "/scim/v2/Users?filter="+url.QueryEscape(tt.filter), directory, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if status != http.StatusOK {
+				if body["scimType"] != "invalidFilter" {
+					t.Fatalf("scimType = %v, want invalidFilter", body["scimType"])
+				}
+				return
+			}
+
+			if body["totalResults"] != tt.wantResults {
+				t.Fatalf("totalResults = %v, want %v", body["totalResults"], tt.wantResults)
+			}
+		})
+	}
+
+	status, body = request("POST", "/scim/v2/Groups", directory, map[string]interface{}{
+		"schemas": []string{scimDomain.SchemaGroup},
+		"displayName": "Directory admins",
+		"members": []map[string]string{{"value": userId}},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("failed to provision group (%d): %s", status, body)
+	}
+
+	groupId, _ := body["id"].(string)
+
+	status, body = request("PUT", "/organization/scim/groups/"+groupId+"/roles", login(t, rootEmail, rootPassword), map[string]string{"organizationRole": "ADMIN"})
+	if status != http.StatusOK {
+		t.Fatalf("failed to map group roles (%d): %s", status, body)
+	}
+
+	expectAccount(t, email, func(_account *accountDomain.Account) bool { return _account.Role == accountDomain.Admin }, "role ADMIN granted by the group")
+
+	status, body = request("PATCH", "/scim/v2/Groups/"+groupId, directory, map[string]interface{}{
+		"schemas": []string{scimDomain.SchemaPatchOp},
+		"Operations": []map[string]interface{}{{"op": "remove", "path": `members[value eq "` + userId + `"]`}},
+	})
+	if status != http.StatusOK && status != http.StatusNoContent {
+		t.Fatalf("failed to remove group member (%d): %s", status, body)
+	}
+
+	expectAccount(t, email, func(_account *accountDomain.Account) bool { return _account.Role == accountDomain.Guest }, "role GUEST once out of the group")
+
+	status, body = request("PATCH", "/scim/v2/Users/"+userId, directory, map[string]interface{}{
+		"schemas": []string{scimDomain.SchemaPatchOp},
+		"Operations": []map[string]interface{}{{"op": "replace", "value": map[string]bool{"active": false}}},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("failed to deactivate user (%d): %s", status, body)
+	}
+
+	expectAccount(t, email, func(_account *accountDomain.Account) bool { return _account.IsDisabled() }, "disabled")
+}
+
+func expectAccount(t *testing.T, email string, check func(_account *accountDomain.Account) bool, want string) {
+	t.Helper()
+
+	_account, err := accountService.RetrieveAccount(email, config.Database())
+	if err != nil || _account == nil {
+		t.Fatalf("failed to retrieve account %s: %v", email, err)
+	}
+
+	if !check(_account) {
+		t.Fatalf("account %s is not %s: %+v", email, want, _account)
+	}
+}
@@ -0,0 +1,108 @@
+package server_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	datasourceService "github.com/darksuei/suei-intelligence/internal/application/datasource"
+	"github.com/darksuei/suei-intelligence/internal/application/secret"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	datasourceDomain "github.com/darksuei/suei-intelligence/internal/domain/datasource"
+	secretDomain "github.com/darksuei/suei-intelligence/internal/domain/secret"
+	"github.com/darksuei/suei-intelligence/internal/infrastructure/database"
+)
+
+// storedDatasource reads a datasource as stored, bypassing the service
+func storedDatasource(t *testing.T, id float64) *datasourceDomain.Datasource {
+	t.Helper()
+
+	var _datasource datasourceDomain.Datasource
+
+	if err := database.GetDB(config.Database()).First(&_datasource, uint(id)).Error; err != nil {
+		t.Fatalf("failed to read datasource %v: %v", id, err)
+	}
+
+	return &_datasource
+}
+
+// expectPassword checks the stored configuration of a datasource decrypts to its password
+func expectPassword(t *testing.T, _datasource *datasourceDomain.Datasource, want string) {
+	t.Helper()
+
+	configuration, err := datasourceService.RetrieveConfiguration(_datasource)
+	if err != nil {
+		t.Fatalf("failed to decrypt configuration: %v", err)
+	}
+
+	if configuration["password"] != want {
+		t.Fatalf("password = %v, want %s", configuration["password"], want)
+	}
+}
+
+func TestDatasourceConfigurationEncryption(t *testing.T) {
+	createProject(t, "secrets")
+
+	status, body := request("POST", "/project/secrets/datasources", login(t, rootEmail, rootPassword), postgresDatasource)
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create datasource (%d): %s", status, body)
+	}
+
+	created, _ := body["datasource"].(map[string]interface{})
+	id, _ := created["ID"].(float64)
+
+	t.Run("masks secrets in responses", func(t *testing.T) {
+		configuration, _ := created["Configuration"].(map[string]interface{})
+
+		if configuration["password"] != secretDomain.Mask {
+			t.Fatalf("password = %v, want %s", configuration["password"], secretDomain.Mask)
+		}
+		if configuration["host"] != "db.local" {
+			t.Fatalf("host = %v, want db.local", configuration["host"])
+		}
+	})
+
+	t.Run("encrypts the stored configuration", func(t *testing.T) {
+		_datasource := storedDatasource(t, id)
+
+		if !strings.HasPrefix(_datasource.Configuration, "v1:") || strings.Contains(_datasource.Configuration, "hunter2") {
+			t.Fatalf("configuration is not stored encrypted: %s", _datasource.Configuration)
+		}
+
+		expectPassword(t, _datasource, "hunter2")
+	})
+
+	t.Run("binds the configuration to its datasource", func(t *testing.T) {
+		_datasource := storedDatasource(t, id)
+		_datasource.SourceID += "-other"
+
+		if _, err := datasourceService.RetrieveConfiguration(_datasource); err == nil {
+			t.Fatal("configuration of another datasource decrypted")
+		}
+	})
+
+	t.Run("re-encrypts with a rotated data key", func(t *testing.T) {
+		if err := secret.RotateDataKey(); err != nil {
+			t.Fatalf("failed to rotate data key: %v", err)
+		}
+
+		_datasource := storedDatasource(t, id)
+		if secret.IsCurrent(_datasource.Configuration) {
+			t.Fatal("configuration is encrypted with the data key just rotated in")
+		}
+
+		// Retired data keys still decrypt until what they encrypted is re-encrypted
+		expectPassword(t, _datasource, "hunter2")
+
+		if err := datasourceService.ReencryptConfigurations(config.Database()); err != nil {
+			t.Fatalf("failed to re-encrypt configurations: %v", err)
+		}
+
+		_datasource = storedDatasource(t, id)
+		if !secret.IsCurrent(_datasource.Configuration) {
+			t.Fatal("configuration was not re-encrypted with the active data key")
+		}
+
+		expectPassword(t, _datasource, "hunter2")
+	})
+}
@@ -0,0 +1,129 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// refresh exchanges a refresh token, returning the new Authorization header and refresh token
+func refresh(refreshToken string) (int, map[string]string, string) {
+	status, body := request("POST", "/auth/refresh-token", nil, map[string]string{"refresh_token": refreshToken})
+
+	accessToken, _ := body["access_token"].(string)
+	rotated, _ := body["refresh_token"].(string)
+
+	return status, map[string]string{"Authorization": "Bearer " + accessToken}, rotated
+}
+
+// currentSession returns the id of the session an Authorization header belongs to
+func currentSession(t *testing.T, headers map[string]string) interface{} {
+	t.Helper()
+
+	status, body := request("GET", "/auth/sessions", headers, nil)
+	if status != http.StatusOK {
+		t.Fatalf("failed to retrieve sessions (%d): %s", status, body)
+	}
+
+	sessions, _ := body["sessions"].([]interface{})
+
+	for _, _session := range sessions {
+		if current := _session.(map[string]interface{}); current["Current"] == true {
+			return current["ID"]
+		}
+	}
+
+	t.Fatalf("no current session in %s", body)
+	return nil
+}
+
+func expectStatus(t *testing.T, what string, status int, want int) {
+	t.Helper()
+
+	if status != want {
+		t.Fatalf("%s: status = %d, want %d", what, status, want)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	const (
+		email    = "session@example.com"
+		password = "Passw0rd!session"
+	)
+
+	newAccount(t, email, "GUEST", password)
+
+	t.Run("rotates the refresh token", func(t *testing.T) {
+		_, refreshToken := signIn(t, email, password)
+
+		status, headers, rotated := refresh(refreshToken)
+		expectStatus(t, "refresh", status, http.StatusOK)
+
+		if rotated == "" || rotated == refreshToken {
+			t.Fatalf("refresh token was not rotated")
+		}
+
+		status, _, _ = refresh(rotated)
+		expectStatus(t, "refresh with the rotated token", status, http.StatusOK)
+
+		status, _ = request("GET", "/auth/sessions", headers, nil)
+		expectStatus(t, "access token of the refreshed session", status, http.StatusOK)
+	})
+
+	t.Run("revokes the session when a rotated token is replayed", func(t *testing.T) {
+		_, refreshToken := signIn(t, email, password)
+
+		status, headers, rotated := refresh(refreshToken)
+		expectStatus(t, "refresh", status, http.StatusOK)
+
+		status, _, _ = refresh(refreshToken)
+		expectStatus(t, "replayed refresh token", status, http.StatusUnauthorized)
+
+		status, _, _ = refresh(rotated)
+		expectStatus(t, "refresh token of the revoked session", status, http.StatusUnauthorized)
+
+		status, _ = request("GET", "/auth/sessions", headers, nil)
+		expectStatus(t, "access token of the revoked session", status, http.StatusUnauthorized)
+	})
+
+	t.Run("revokes a single session", func(t *testing.T) {
+		revoked, revokedRefreshToken := signIn(t, email, password)
+		other, _ := signIn(t, email, password)
+
+		status, _ := request("DELETE", fmt.Sprintf("/auth/sessions/%v", currentSession(t, revoked)), other, nil)
+		expectStatus(t, "revoke", status, http.StatusOK)
+
+		status, _ = request("GET", "/auth/sessions", revoked, nil)
+		expectStatus(t, "access token of the revoked session", status, http.StatusUnauthorized)
+
+		status, _, _ = refresh(revokedRefreshToken)
+		expectStatus(t, "refresh token of the revoked session", status, http.StatusUnauthorized)
+
+		status, _ = request("GET", "/auth/sessions", other, nil)
+		expectStatus(t, "access token of another session", status, http.StatusOK)
+	})
+
+	t.Run("does not revoke sessions of another account", func(t *testing.T) {
+		headers := login(t, email, password)
+
+		status, _ := request("DELETE", fmt.Sprintf("/auth/sessions/%v", currentSession(t, headers)), login(t, rootEmail, rootPassword), nil)
+		expectStatus(t, "revoke", status, http.StatusNotFound)
+
+		status, _ = request("GET", "/auth/sessions", headers, nil)
+		expectStatus(t, "access token of the session", status, http.StatusOK)
+	})
+
+	t.Run("signs out everywhere", func(t *testing.T) {
+		headers, _ := signIn(t, email, password)
+		_, otherRefreshToken := signIn(t, email, password)
+
+		status, _ := request("DELETE", "/auth/sessions", headers, nil)
+		expectStatus(t, "sign out everywhere", status, http.StatusOK)
+
+		status, _ = request("GET", "/auth/sessions", headers, nil)
+		expectStatus(t, "access token of the current session", status, http.StatusUnauthorized)
+
+		status, _, _ = refresh(otherRefreshToken)
+		expectStatus(t, "refresh token of another session", status, http.StatusUnauthorized)
+	})
+}
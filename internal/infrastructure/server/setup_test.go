@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	setup := func(bootstrapToken string, email string) map[string]interface{} {
+		return map[string]interface{}{
+			"bootstrapToken": bootstrapToken,
+			"name": "Second",
+			"scope": "PRIVATE",
+			"admin": map[string]string{"name": "Second", "email": email, "password": "Passw0rd!setup"},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{name: "refuses a second setup", body: setup("bootstrap", "setup@example.com"), wantStatus: http.StatusConflict},
+		{name: "refuses a second setup without the bootstrap token", body: setup("", "setup@example.com"), wantStatus: http.StatusBadRequest},
+		{name: "refuses a second setup with a wrong bootstrap token", body: setup("wrong", "setup@example.com"), wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request("POST", "/setup", nil, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if status, _ := request("POST", "/auth/login", nil, map[string]string{"email": "setup@example.com", "password": "Passw0rd!setup"}); status == http.StatusOK {
+				t.Fatal("a refused setup created an account")
+			}
+		})
+	}
+
+	t.Run("reports setup as completed", func(t *testing.T) {
+		status, body := request("GET", "/config", nil, nil)
+		if status != http.StatusOK || body["setup_completed"] != true {
+			t.Fatalf("GET /config = %d %s, want setup_completed", status, body)
+		}
+	})
+
+	t.Run("makes the account that set the instance up its operator", func(t *testing.T) {
+		status, body := request("GET", "/account?email="+rootEmail, login(t, rootEmail, rootPassword), nil)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+		}
+
+		if _account, _ := body["account"].(map[string]interface{}); _account["InstanceOperator"] != true {
+			t.Fatalf("account = %v, want an instance operator", _account)
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package server_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	accountService "github.com/darksuei/suei-intelligence/internal/application/account"
+	"github.com/darksuei/suei-intelligence/internal/config"
+	accountDomain "github.com/darksuei/suei-intelligence/internal/domain/account"
+	ssoDomain "github.com/darksuei/suei-intelligence/internal/domain/sso"
+)
+
+const (
+	idpClientID     = "suei"
+	idpClientSecret = "idp-secret"
+)
+
+// authorizationCode is an authorization code issued by the stub identity provider
+type authorizationCode struct {
+	codeChallenge string
+	redirectURI   string
+	claims        jwt.MapClaims
+}
+
+// identityProviderStub is an OpenID provider issuing ID tokens with the claims a test asks for
+type identityProviderStub struct {
+	*httptest.Server
+
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorizationCode
+}
+
+func newIdentityProviderStub(t *testing.T) *identityProviderStub {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	stub := &identityProviderStub{key: key, codes: map[string]authorizationCode{}}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer": stub.URL,
+			"authorization_endpoint": stub.URL + "/authorize",
+			"token_endpoint": stub.URL + "/token",
+			"jwks_uri": stub.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "idp-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+
+		if clientID != idpClientID || clientSecret != idpClientSecret || r.FormValue("grant_type") != "authorization_code" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		stub.mu.Lock()
+		code, ok := stub.codes[r.FormValue("code")]
+		delete(stub.codes, r.FormValue("code"))
+		stub.mu.Unlock()
+
+		// PKCE: the verifier must hash to the challenge of the authorization request
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != code.codeChallenge || r.FormValue("redirect_uri") != code.redirectURI {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, code.claims)
+		token.Header["kid"] = "idp-key"
+
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+
+	stub.Server = httptest.NewServer(mux)
+	t.Cleanup(stub.Close)
+
+	return stub
+}
+
+// authorize plays the user signing in at the identity provider: it issues a code for the
+// authorization request and returns it. Claims override the defaults of a valid ID token
+func (s *identityProviderStub) authorize(t *testing.T, authorizationURL string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil || !strings.HasPrefix(authorizationURL, s.URL+"/authorize") {
+		t.Fatalf("unexpected authorization url: %s", authorizationURL)
+	}
+
+	query := parsed.Query()
+
+	if query.Get("client_id") != idpClientID || query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("invalid authorization request: %s", authorizationURL)
+	}
+
+	idClaims := jwt.MapClaims{
+		"iss": s.URL,
+		"aud": idpClientID,
+		"sub": "subject",
+		"email": "sso@example.com",
+		"email_verified": true,
+		"name": "SSO User",
+		"nonce": query.Get("nonce"),
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+
+	for claim, value := range claims {
+		if value == nil {
+			delete(idClaims, claim)
+			continue
+		}
+		idClaims[claim] = value
+	}
+
+	code := "code-" + query.Get("state")
+
+	s.mu.Lock()
+	s.codes[code] = authorizationCode{codeChallenge: query.Get("code_challenge"), redirectURI: query.Get("redirect_uri"), claims: idClaims}
+	s.mu.Unlock()
+
+	return code
+}
+
+// ssoLogin signs in through the identity provider. It returns the callback status and body
+func ssoLogin(t *testing.T, idp *identityProviderStub, organizationKey string, claims jwt.MapClaims, withCookie bool) (int, response) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/auth/sso/"+organizationKey, nil))
+
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("sso login failed (%d): %s", recorder.Code, recorder.Body.String())
+	}
+
+	authorizationURL := recorder.Header().Get("Location")
+	state, _ := url.Parse(authorizationURL)
+
+	var stateCookie *http.Cookie
+
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == ssoDomain.StateCookieName {
+			stateCookie = cookie
+		}
+	}
+
+	if stateCookie == nil || !stateCookie.HttpOnly || stateCookie.Value != state.Query().Get("state") {
+		t.Fatalf("sso login set no state cookie: %v", recorder.Result().Cookies())
+	}
+
+	code := idp.authorize(t, authorizationURL, claims)
+
+	callback := httptest.NewRequest("GET", "/auth/sso/callback?"+url.Values{"state": {state.Query().Get("state")}, "code": {code}}.Encode(), nil)
+
+	if withCookie {
+		callback.AddCookie(stateCookie)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, callback)
+
+	decoded := response{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &decoded)
+
+	return recorder.Code, decoded
+}
+
+func TestSSOLogin(t *testing.T) {
+	idp := newIdentityProviderStub(t)
+	root := login(t, rootEmail, rootPassword)
+
+	status, body := request("GET", "/organization", root, nil)
+	if status != http.StatusOK {
+		t.Fatalf("failed to retrieve organization (%d): %s", status, body)
+	}
+
+	organizationKey, _ := body["organization"].(map[string]interface{})["Key"].(string)
+
+	status, body = request("PUT", "/organization/sso", root, map[string]interface{}{
+		"issuer": idp.URL,
+		"clientId": idpClientID,
+		"clientSecret": idpClientSecret,
+		"roleClaim": "groups",
+		"roleMapping": map[string]string{"admins": "ADMIN", "guests": "GUEST"},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("failed to configure sso (%d): %s", status, body)
+	}
+
+	tests := []struct {
+		name            string
+		claims          jwt.MapClaims
+		withoutCookie   bool
+		wantStatus      int
+		wantProvisioned bool
+		wantRole        accountDomain.AccountRole
+	}{
+		{name: "provisions the account just in time", claims: jwt.MapClaims{"groups": []string{"guests"}}, wantStatus: http.StatusOK, wantProvisioned: true, wantRole: accountDomain.Guest},
+		{name: "syncs the mapped role", claims: jwt.MapClaims{"groups": []string{"admins", "guests"}}, wantStatus: http.StatusOK, wantRole: accountDomain.Admin},
+		{name: "rejects a callback from another browser", claims: jwt.MapClaims{"groups": []string{"guests"}}, withoutCookie: true, wantStatus: http.StatusUnauthorized, wantRole: accountDomain.Admin},
+		{name: "rejects another issuer", claims: jwt.MapClaims{"iss": "https://attacker.example.com", "groups": []string{"guests"}}, wantStatus: http.StatusUnauthorized, wantRole: accountDomain.Admin},
+		{name: "rejects another audience", claims: jwt.MapClaims{"aud": "another-client", "groups": []string{"guests"}}, wantStatus: http.StatusUnauthorized, wantRole: accountDomain.Admin},
+		{name: "rejects a replayed nonce", claims: jwt.MapClaims{"nonce": "replayed", "groups": []string{"guests"}}, wantStatus: http.StatusUnauthorized, wantRole: accountDomain.Admin},
+		{name: "rejects an expired token", claims: jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix(), "groups": []string{"guests"}}, wantStatus: http.StatusUnauthorized, wantRole: accountDomain.Admin},
+		{name: "rejects an unverified email", claims: jwt.MapClaims{"email_verified": false, "groups": []string{"guests"}}, wantStatus: http.StatusUnauthorized, wantRole: accountDomain.Admin},
+		{name: "rejects an email without email_verified", claims: jwt.MapClaims{"email_verified": nil, "groups": []string{"guests"}}, wantStatus: http.StatusUnauthorized, wantRole: accountDomain.Admin},
+		{name: "rejects an identity no role is mapped for", claims: jwt.MapClaims{"email": "unmapped@example.com", "groups": []string{"others"}}, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := ssoLogin(t, idp, organizationKey, tt.claims, !tt.withoutCookie)
+
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if status == http.StatusOK {
+				if body["access_token"] == nil {
+					t.Fatalf("sso login returned no access token: %s", body)
+				}
+				if body["provisioned"] != tt.wantProvisioned {
+					t.Fatalf("provisioned = %v, want %v", body["provisioned"], tt.wantProvisioned)
+				}
+			}
+
+			email, _ := tt.claims["email"].(string)
+			if email == "" {
+				email = "sso@example.com"
+			}
+
+			_account, err := accountService.RetrieveAccount(email, config.Database())
+			if err != nil {
+				t.Fatalf("failed to retrieve account: %v", err)
+			}
+
+			if tt.wantRole == "" {
+				if _account != nil {
+					t.Fatalf("account %s was provisioned", email)
+				}
+				return
+			}
+
+			if _account == nil || _account.Role != tt.wantRole {
+				t.Fatalf("account = %v, want role %s", _account, tt.wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darksuei/suei-intelligence/internal/domain/apikey"
+)
+
+// GetAPIKeyFromContext returns the API key a request authenticated with
+func GetAPIKeyFromContext(c *gin.Context) (*apikey.APIKey, error) {
+	val, exists := c.Get("apiKey")
+
+	if !exists {
+		return nil, errors.New("failed to retrieve api key from context")
+	}
+
+	_key, ok := val.(*apikey.APIKey)
+
+	if !ok {
+		return nil, errors.New("invalid api key type")
+	}
+
+	return _key, nil
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	authenticationDomain "github.com/darksuei/suei-intelligence/internal/domain/authentication"
+)
+
+func GetAccessTokenFromContext(c *gin.Context) (*authenticationDomain.AccessTokenClaims, error) {
+	val, exists := c.Get("accessToken")
+
+	if !exists {
+		return nil, errors.New("failed to retrieve accessToken from context")
+	}
+
+	accessToken, ok := val.(authenticationDomain.AccessTokenClaims)
+
+	if !ok {
+		return nil, errors.New("invalid accessToken type")
+	}
+
+	return &accessToken, nil
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetOrganizationFromContext(c *gin.Context) (*string, error) {
+	val, exists := c.Get("organization")
+
+	if !exists {
+		return nil, errors.New("failed to retrieve organization from context")
+	}
+
+	organization, ok := val.(string)
+
+	if !ok {
+		return nil, errors.New("invalid organization type")
+	}
+
+	return &organization, nil
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetSessionIdFromContext(c *gin.Context) (*uint, error) {
+	val, exists := c.Get("sessionID")
+
+	if !exists {
+		return nil, errors.New("failed to retrieve sessionId from context")
+	}
+
+	// JWT numeric claims are decoded as float64
+	v, ok := val.(float64)
+
+	if !ok {
+		return nil, errors.New("invalid sessionid type")
+	}
+
+	sessionId := uint(v)
+
+	return &sessionId, nil
+}
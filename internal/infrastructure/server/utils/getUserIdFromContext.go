@@ -2,22 +2,29 @@ package utils
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 func GetUserIdFromContext(c *gin.Context) (*string, error) {
-	val, exists := c.Get("userId")
+	val, exists := c.Get("userID")
 
 	if !exists {
 		return nil, errors.New("failed to retrieve userId from context")
 	}
 
-	userId, ok := val.(string)
+	var userId string
 
-	if !ok {
+	// JWT numeric claims are decoded as float64
+	switch v := val.(type) {
+	case string:
+		userId = v
+	case float64:
+		userId = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
 		return nil, errors.New("invalid userid type")
 	}
 
 	return &userId, nil
-}
\ No newline at end of file
+}
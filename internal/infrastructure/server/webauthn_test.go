@@ -0,0 +1,298 @@
+package server_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/darksuei/suei-intelligence/internal/config"
+)
+
+// softAuthenticator is an ES256 security key in software, answering ceremonies the way a
+// browser and authenticator would
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	credentialID := make([]byte, 16)
+	_, _ = rand.Read(credentialID)
+
+	return &softAuthenticator{key: key, credentialID: credentialID, signCount: 1}
+}
+
+func (a *softAuthenticator) id() string {
+	return base64.RawURLEncoding.EncodeToString(a.credentialID)
+}
+
+// register answers navigator.credentials.create
+func (a *softAuthenticator) register(challenge string, origin string) map[string]string {
+	x := a.key.PublicKey.X.FillBytes(make([]byte, 32))
+	y := a.key.PublicKey.Y.FillBytes(make([]byte, 32))
+
+	// COSE key: kty EC2, alg ES256, crv P-256
+	coseKey := cborMap(
+		cborInt(1), cborInt(2),
+		cborInt(3), cborInt(-7),
+		cborInt(-1), cborInt(1),
+		cborInt(-2), cborBytes(x),
+		cborInt(-3), cborBytes(y),
+	)
+
+	// Attested credential data: AAGUID, credential ID length, credential ID, public key
+	attested := append(make([]byte, 16), byte(len(a.credentialID)>>8), byte(len(a.credentialID)))
+	attested = append(append(attested, a.credentialID...), coseKey...)
+
+	authData := append(a.authenticatorData(0x41, a.signCount), attested...)
+
+	attestationObject := cborMap(
+		cborText("fmt"), cborText("none"),
+		cborText("attStmt"), cborMap(),
+		cborText("authData"), cborBytes(authData),
+	)
+
+	return map[string]string{
+		"id": a.id(),
+		"clientDataJSON": clientDataJSON("webauthn.create", challenge, origin),
+		"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+	}
+}
+
+// assert answers navigator.credentials.get, reporting the given signature counter
+func (a *softAuthenticator) assert(t *testing.T, challenge string, origin string, signCount uint32) map[string]string {
+	t.Helper()
+
+	clientData := clientDataJSON("webauthn.get", challenge, origin)
+	rawClientData, _ := base64.RawURLEncoding.DecodeString(clientData)
+
+	authData := a.authenticatorData(0x01, signCount)
+	clientDataHash := sha256.Sum256(rawClientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign assertion: %v", err)
+	}
+
+	return map[string]string{
+		"id": a.id(),
+		"clientDataJSON": clientData,
+		"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+		"signature": base64.RawURLEncoding.EncodeToString(signature),
+	}
+}
+
+func (a *softAuthenticator) authenticatorData(flags byte, signCount uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(config.WebAuthn().WebAuthnRPID))
+
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, signCount)
+}
+
+func clientDataJSON(ceremony string, challenge string, origin string) string {
+	content, _ := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": origin})
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+// The CBOR subset authenticators encode attestation objects and COSE keys with
+
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 1<<8:
+		return []byte{major<<5 | 24, byte(n)}
+	case n < 1<<16:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	}
+	return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+}
+
+func cborInt(n int64) []byte {
+	if n < 0 {
+		return cborHead(1, uint64(-1-n))
+	}
+	return cborHead(0, uint64(n))
+}
+
+func cborBytes(b []byte) []byte {
+	return append(cborHead(2, uint64(len(b))), b...)
+}
+
+func cborText(s string) []byte {
+	return append(cborHead(3, uint64(len(s))), s...)
+}
+
+func cborMap(pairs ...[]byte) []byte {
+	return append(cborHead(5, uint64(len(pairs)/2)), bytes.Join(pairs, nil)...)
+}
+
+func challengeOf(t *testing.T, status int, body response) string {
+	t.Helper()
+
+	if status != http.StatusOK {
+		t.Fatalf("failed to retrieve webauthn options (%d): %s", status, body)
+	}
+
+	challenge, _ := body["publicKey"].(map[string]interface{})["challenge"].(string)
+	return challenge
+}
+
+func TestWebAuthn(t *testing.T) {
+	const (
+		email    = "webauthn@example.com"
+		password = "Passw0rd!webauthn"
+	)
+
+	origin := config.WebAuthn().WebAuthnOrigins[0]
+
+	newAccount(t, email, "ADMIN", password)
+	headers := login(t, email, password)
+
+	authenticator := newSoftAuthenticator(t)
+
+	registrations := []struct {
+		name       string
+		origin     string
+		challenge  string
+		replay     bool
+		wantStatus int
+	}{
+		{name: "rejects another origin", origin: "https://attacker.example.com", wantStatus: http.StatusBadRequest},
+		{name: "rejects another challenge", challenge: "forged", wantStatus: http.StatusBadRequest},
+		{name: "registers the security key", wantStatus: http.StatusCreated},
+		{name: "rejects a replayed registration", replay: true, wantStatus: http.StatusBadRequest},
+	}
+
+	var registered map[string]string
+
+	for _, tt := range registrations {
+		t.Run("registration "+tt.name, func(t *testing.T) {
+			credential := registered
+
+			if !tt.replay {
+				status, body := request("POST", "/mfa/webauthn/register/options", headers, map[string]string{"password": password})
+				challenge := challengeOf(t, status, body)
+
+				if tt.challenge != "" {
+					challenge = tt.challenge
+				}
+				if tt.origin == "" {
+					tt.origin = origin
+				}
+
+				credential = authenticator.register(challenge, tt.origin)
+			}
+
+			status, body := request("POST", "/mfa/webauthn/register", headers, map[string]interface{}{"name": "Soft key", "credential": credential})
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if status == http.StatusCreated {
+				registered = credential
+			}
+		})
+	}
+
+	status, body := request("GET", "/mfa/webauthn/credentials", headers, nil)
+	credentials, _ := body["credentials"].([]interface{})
+
+	if status != http.StatusOK || len(credentials) != 1 {
+		t.Fatalf("credentials = %s, want exactly one", body)
+	}
+
+	credentialID := credentials[0].(map[string]interface{})["ID"]
+
+	assertions := []struct {
+		name        string
+		origin      string
+		challenge   string
+		sameCounter bool
+		replay      bool
+		wantStatus  int
+	}{
+		{name: "rejects another origin", origin: "https://attacker.example.com", wantStatus: http.StatusBadRequest},
+		{name: "rejects another challenge", challenge: "forged", wantStatus: http.StatusBadRequest},
+		{name: "rejects a signature counter that did not increase", sameCounter: true, wantStatus: http.StatusBadRequest},
+		{name: "signs in with the security key", wantStatus: http.StatusOK},
+		{name: "rejects a replayed assertion", replay: true, wantStatus: http.StatusBadRequest},
+	}
+
+	var asserted map[string]interface{}
+
+	for _, tt := range assertions {
+		t.Run("assertion "+tt.name, func(t *testing.T) {
+			req := asserted
+
+			if !tt.replay {
+				challengeID := beginMFA(t, email, password, "[webauthn]")
+				status, body := request("POST", "/auth/mfa/webauthn/options", nil, map[string]string{"challenge_id": challengeID})
+				challenge := challengeOf(t, status, body)
+
+				if tt.challenge != "" {
+					challenge = tt.challenge
+				}
+				if tt.origin == "" {
+					tt.origin = origin
+				}
+
+				signCount := authenticator.signCount + 1
+				if tt.sameCounter {
+					signCount = authenticator.signCount
+				}
+
+				req = map[string]interface{}{"challenge_id": challengeID, "webauthn": authenticator.assert(t, challenge, tt.origin, signCount)}
+
+				if tt.wantStatus == http.StatusOK {
+					authenticator.signCount = signCount
+				}
+			}
+
+			status, body := request("POST", "/auth/mfa", nil, req)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+
+			if status == http.StatusOK {
+				if body["access_token"] == nil {
+					t.Fatalf("mfa returned no access token: %s", body)
+				}
+				asserted = req
+			}
+		})
+	}
+
+	t.Run("credential removal", func(t *testing.T) {
+		path := fmt.Sprintf("/mfa/webauthn/credentials/%v", credentialID)
+
+		// Credentials are removed only by the account they belong to
+		if status, body := request("DELETE", path, login(t, rootEmail, rootPassword), nil); status != http.StatusNotFound {
+			t.Fatalf("another account removed the credential (%d): %s", status, body)
+		}
+
+		if status, body := request("DELETE", path, headers, nil); status != http.StatusOK {
+			t.Fatalf("failed to remove the credential (%d): %s", status, body)
+		}
+
+		// Without a security key, nor TOTP, the password alone signs in again
+		login(t, email, password)
+	})
+}